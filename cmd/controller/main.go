@@ -66,6 +66,7 @@ func main() {
 	flags.DurationVar(&config.ResyncPeriod, "resync-period", 5*time.Hour, "The resync period for the controller")
 	flags.Float64Var(&config.DriftThreshold, "drift-threshold", 0.10, "The maximum percentage of configurations that can be run drift detection at any one time")
 	flags.IntVar(&config.APIServerPort, "apiserver-port", 10080, "The port the apiserver should be listening on")
+	flags.IntVar(&config.StateVersions, "state-versions", 5, "The number of versions of the terraform state retained per configuration, zero disables versioning")
 	flags.IntVar(&config.MetricsPort, "metrics-port", 9090, "The port the metric endpoint binds to")
 	flags.IntVar(&config.WebhookPort, "webhooks-port", 10081, "The port the webhook endpoint binds to")
 	flags.StringSliceVar(&config.ExecutorSecrets, "executor-secret", []string{}, "Name of a secret in controller namespace which should be added to the job")
@@ -75,14 +76,25 @@ func main() {
 	flags.StringVar(&config.InfracostsSecretName, "cost-secret", "", "Name of the secret on the controller namespace containing your infracost token")
 	flags.StringVar(&config.JobTemplate, "job-template", "", "Name of configmap in the controller namespace containing a template for the job")
 	flags.StringSliceVar(&config.JobLabels, "job-label", []string{}, "A collection of key=values to add to all jobs")
+	flags.StringVar(&config.LogStore.Bucket, "log-store-bucket", "", "The name of the bucket to archive the job logs into (s3 only)")
+	flags.StringVar(&config.LogStore.Endpoint, "log-store-endpoint", "", "An optional endpoint for an s3 compatible service (s3 only)")
+	flags.StringVar(&config.LogStore.Region, "log-store-region", "", "The region of the bucket to archive the job logs into (s3 only)")
+	flags.StringVar(&config.LogStore.Type, "log-store", "", "The store used to archive the job logs (kubernetes or s3), disabled when empty")
 	flags.StringVar(&config.Namespace, "namespace", os.Getenv("KUBE_NAMESPACE"), "The namespace the controller is running in and where jobs will run")
 	flags.StringVar(&config.PolicyImage, "policy-image", "bridgecrew/checkov:latest", "The image to use for the policy")
 	flags.StringVar(&config.PreloadImage, "preload-image", fmt.Sprintf("ghcr.io/appvia/terranetes-executor:%s", version.Version), "The image to use for the preload")
+	flags.StringVar(&config.RegoImage, "rego-image", "openpolicyagent/conftest:latest", "The image to use when evaluating rego policies")
+	flags.StringVar(&config.SourceCacheDir, "source-cache-dir", "", "The directory used to cache the module sources for the jobs, disabled when empty")
+	flags.StringVar(&config.SourceCacheSize, "source-cache-size", "10Gi", "The maximum size of the module source cache, the least recently used sources are evicted")
 	flags.StringVar(&config.TLSAuthority, "tls-ca", "", "The filename to the ca certificate")
 	flags.StringVar(&config.TLSCert, "tls-cert", "tls.pem", "The name of the file containing the TLS certificate")
 	flags.StringVar(&config.TLSDir, "tls-dir", "", "The directory the certificates are held")
 	flags.StringVar(&config.TLSKey, "tls-key", "tls-key.pem", "The name of the file containing the TLS key")
+	flags.StringVar(&config.TerraformEngine, "terraform-engine", "terraform", "The default engine used to run configurations (terraform or tofu)")
 	flags.StringVar(&config.TerraformImage, "terraform-image", "hashicorp/terraform:latest", "The image to use for the terraform")
+	flags.StringSliceVar(&config.TerraformVersions, "terraform-versions", []string{}, "The terraform versions permitted when resolving version constraints, i.e. 1.5.7,1.6.6")
+	flags.StringSliceVar(&config.TofuVersions, "tofu-versions", []string{}, "The opentofu versions permitted when resolving version constraints, i.e. 1.6.2")
+	flags.StringVar(&config.TofuImage, "tofu-image", "ghcr.io/opentofu/opentofu:latest", "The image to use for configurations using the opentofu engine")
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "[error] %s\n", err)
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils/oci"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
+)
+
+// signatureKeys are the public keys trusted to sign the source
+type signatureKeys struct {
+	// cosign are the keys used to verify modules held in an oci registry
+	cosign []crypto.PublicKey
+	// git are the keys used to verify the commits and tags of git sources
+	git sources.GitKeys
+}
+
+// loadSignatureKeys reads the public keys from the directory, the type of key is taken from the
+// extension of the file
+func loadSignatureKeys(dir string) (*signatureKeys, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := &signatureKeys{}
+
+	for _, entry := range entries {
+		// @note: skip the hidden files and directories used by kubernetes to project the secret
+		if strings.HasPrefix(entry.Name(), ".") || entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.TrimPrefix(filepath.Ext(entry.Name()), ".") {
+		case terraformv1alpha1.SignatureKeyCosign:
+			parsed, err := oci.ParsePublicKeys(content)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cosign key: %q, error: %w", entry.Name(), err)
+			}
+			keys.cosign = append(keys.cosign, parsed...)
+
+		case terraformv1alpha1.SignatureKeyGPG:
+			keys.git.GPG = append(keys.git.GPG, content)
+
+		case terraformv1alpha1.SignatureKeySSH:
+			keys.git.SSH = append(keys.git.SSH, string(content))
+		}
+	}
+	if len(keys.cosign) == 0 && keys.git.IsEmpty() {
+		return nil, errors.New("no public keys found")
+	}
+
+	return keys, nil
+}
+
+// IsSupported returns an error if the signature of the source cannot be verified with the keys
+func (s *signatureKeys) IsSupported(location string) error {
+	switch {
+	case oci.IsSource(location) && len(s.cosign) == 0:
+		return errors.New("module must be signed, but no cosign keys are trusted to verify oci sources")
+	case sources.IsGitSource(location) && s.git.IsEmpty():
+		return errors.New("module must be signed, but no gpg or ssh keys are trusted to verify git sources")
+	case !oci.IsSource(location) && !sources.IsGitSource(location):
+		return errors.New("module must be signed, only git and oci sources support signatures")
+	}
+
+	return nil
+}
+
+// Verify checks the source retrieved into the directory was signed by one of the keys
+func (s *signatureKeys) Verify(ctx context.Context, location, dir, digest string) error {
+	if oci.IsSource(location) {
+		return oci.VerifySource(ctx, location, digest, s.cosign)
+	}
+
+	return sources.VerifyGitSignature(dir, sources.GitRef(location), s.git)
+}
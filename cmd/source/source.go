@@ -20,6 +20,7 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -35,7 +36,10 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/oci"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
 	"github.com/appvia/terranetes-controller/pkg/utils/template"
 	"github.com/appvia/terranetes-controller/pkg/version"
 )
@@ -49,25 +53,59 @@ var gitConfig = `
   insteadOf = {{ .Destination }}
 `
 
+// Options are the options for retrieving the source
+type Options struct {
+	// CacheToken is the token presented to the source cache
+	CacheToken string
+	// CacheURL is the endpoint of the source cache, if any
+	CacheURL string
+	// Destination is the directory to save the source into
+	Destination string
+	// Digest is the expected digest of the source, used to verify a pinned source
+	Digest string
+	// Revision is the git commit the source is pinned to
+	Revision string
+	// RevisionFile is the path to write the resolved revision of the source to
+	RevisionFile string
+	// SignatureKeys is the directory holding the public keys the source must be signed by
+	SignatureKeys string
+	// Source is the source which needs to be downloaded
+	Source string
+	// TerminationLog is the path the reason for a rejected source is written to, surfacing
+	// the failure on the pod status
+	TerminationLog string
+	// Timeout is the timeout for the operation
+	Timeout time.Duration
+	// TmpDirectory indicates we use a temporary directory to download the assets
+	TmpDirectory bool
+}
+
 func main() {
-	var source, destination string
-	var timeout time.Duration
-	var tmpDirectory bool
+	var options Options
 
 	cmd := &cobra.Command{
 		Use:     "source [options]",
 		Short:   "Used to retrieve the source code for the terraform controller",
 		Version: version.Version,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return Run(context.Background(), source, destination, timeout, tmpDirectory)
+			// @note: the token is taken from the environment to keep it off the command line
+			options.CacheToken = os.Getenv(sources.CacheTokenEnv)
+
+			return Run(context.Background(), options)
 		},
 	}
 
 	flags := cmd.Flags()
-	flags.DurationVarP(&timeout, "timeout", "t", 10*time.Minute, "The timeout for the operation")
-	flags.StringVarP(&source, "source", "s", "", "Source which needs to be downloaded")
-	flags.StringVarP(&destination, "dest", "d", "", "Directory where the source code to be saved")
-	flags.BoolVar(&tmpDirectory, "tmpdir", true, "Use a temporary directory to download the assets")
+	flags.DurationVarP(&options.Timeout, "timeout", "t", 10*time.Minute, "The timeout for the operation")
+	flags.StringVar(&options.CacheURL, "cache-url", "", "The endpoint of the source cache used to retrieve and store the source")
+	flags.StringVar(&options.Digest, "digest", "", "The expected digest of the source, the download fails when the content differs")
+	flags.StringVar(&options.Revision, "revision", "", "The git commit to pin the source to")
+	flags.StringVar(&options.RevisionFile, "revision-file", "", "The path to write the resolved revision of the source to")
+	flags.StringVar(&options.SignatureKeys, "signature-keys", "", "A directory of public keys, when provided the source must be signed by one of the keys")
+	flags.StringVar(&options.TerminationLog, "termination-log", "/dev/termination-log", "The path to write the reason the source was rejected to")
+	flags.StringVarP(&options.Source, "source", "s", "", "Source which needs to be downloaded")
+	flags.StringVarP(&options.Destination, "dest", "d", "", "Directory where the source code to be saved")
+	flags.BoolVar(&options.TmpDirectory, "tmpdir", true, "Use a temporary directory to download the assets")
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "[error] failed to run: %s", err)
@@ -78,16 +116,46 @@ func main() {
 
 // Run is called to execute the action
 // nolint: gocyclo
-func Run(ctx context.Context, source, destination string, timeout time.Duration, tmpdir bool) error {
+func Run(ctx context.Context, options Options) error {
+	source, destination := options.Source, options.Destination
+
 	if source == "" {
 		return errors.New("no source defined")
 	}
 	if destination == "" {
 		return errors.New("no destination directory defined")
 	}
-	if timeout < 0 {
+	if options.Timeout < 0 {
 		return errors.New("timeout can not be less than zero")
 	}
+	if options.Digest != "" && !sources.IsDigest(options.Digest) {
+		return fmt.Errorf("invalid digest: %q", options.Digest)
+	}
+
+	var keys *signatureKeys
+	if options.SignatureKeys != "" {
+		var err error
+		if keys, err = loadSignatureKeys(options.SignatureKeys); err != nil {
+			return fmt.Errorf("failed to load the signature keys: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, options.Timeout)
+	defer cancel()
+
+	// @step: if the source has been pinned and is cached we can skip the download. Note, sources
+	// which must be signed are always retrieved so the signature can be verified
+	if options.Digest != "" && options.CacheURL != "" && keys == nil {
+		found, err := fetchFromCache(ctx, options)
+		if err != nil {
+			log.WithError(err).Warn("failed to retrieve the source from the cache, falling back to a download")
+		}
+		if found {
+			log.WithField("digest", options.Digest).Info("successfully retrieved the source from the cache")
+
+			return writeRevision(options, options.Revision, options.Digest)
+		}
+	}
 
 	location := source
 
@@ -160,39 +228,74 @@ func Run(ctx context.Context, source, destination string, timeout time.Duration,
 		location = strings.Replace(location, "https://github.com", "git::https://github.com", 1)
 	}
 
+	detectors := []getter.Detector{
+		new(getter.GitHubDetector),
+		new(getter.GitLabDetector),
+		new(getter.GitDetector),
+		new(getter.BitBucketDetector),
+		new(getter.GCSDetector),
+		new(getter.S3Detector),
+	}
+
+	// @step: pin the source to the revision if one was provided. We handle the subdirectory
+	// ourselves so the git metadata is available to resolve the revision
+	detected, err := getter.Detect(location, pwd, detectors)
+	if err != nil {
+		return fmt.Errorf("failed to detect the source type: %w", err)
+	}
+	pinned, err := sources.PinRevision(detected, options.Revision)
+	if err != nil {
+		return fmt.Errorf("failed to pin the source revision: %w", err)
+	}
+	if pinned, err = oci.PinDigest(pinned, options.Revision); err != nil {
+		return fmt.Errorf("failed to pin the source revision: %w", err)
+	}
+	location, subdir := getter.SourceDirSubdir(pinned)
+
+	// @step: ensure we are able to verify the signature of the source before downloading
+	if keys != nil {
+		if err := keys.IsSupported(location); err != nil {
+			return terminate(options, err)
+		}
+	}
+
 	log.WithFields(log.Fields{
-		"dest":   destination,
-		"source": source,
+		"dest":     destination,
+		"revision": options.Revision,
+		"source":   source,
 	}).Info("downloading the assets")
 
 	// @step: create a temporary directory
 	dest := destination
-	if tmpdir {
+	switch {
+	case options.TmpDirectory:
 		dest = "/tmp/source"
 
 		if err := os.RemoveAll(dest); err != nil {
 			return fmt.Errorf("failed to remove temporary directory: %w", err)
 		}
+
+	case subdir != "":
+		tmpdir, err := os.MkdirTemp("", "source")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpdir)
+
+		dest = tmpdir
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	registry := &oci.Getter{}
 
 	client := &getter.Client{
-		Ctx: ctx,
-		Dst: dest,
-		Detectors: []getter.Detector{
-			new(getter.GitHubDetector),
-			new(getter.GitLabDetector),
-			new(getter.GitDetector),
-			new(getter.BitBucketDetector),
-			new(getter.GCSDetector),
-			new(getter.S3Detector),
-		},
-		Mode:    getter.ClientModeAny,
-		Options: []getter.ClientOption{},
-		Pwd:     pwd,
-		Src:     location,
+		Ctx:       ctx,
+		Dst:       dest,
+		Detectors: detectors,
+		Getters:   oci.Getters(registry),
+		Mode:      getter.ClientModeAny,
+		Options:   []getter.ClientOption{},
+		Pwd:       pwd,
+		Src:       location,
 	}
 
 	doneCh := make(chan struct{})
@@ -237,13 +340,111 @@ func Run(ctx context.Context, source, destination string, timeout time.Duration,
 	}
 	log.WithField("source", source).Info("successfully downloaded the source")
 
+	module := dest
+	if subdir != "" {
+		if module, err = getter.SubdirGlob(dest, subdir); err != nil {
+			return err
+		}
+	}
+
+	// @step: resolve the revision and digest of the module
+	var revision string
+	switch {
+	case sources.IsGitSource(location):
+		if revision, err = sources.GitRevision(dest); err != nil {
+			log.WithError(err).Warn("failed to resolve the git revision of the source")
+		}
+	case oci.IsSource(location):
+		revision = registry.Digest
+	}
+	digest, err := sources.Digest(module)
+	if err != nil {
+		return fmt.Errorf("failed to compute the digest of the source: %w", err)
+	}
+	if options.Digest != "" && digest != options.Digest {
+		return fmt.Errorf("source digest: %q does not match the expected digest: %q", digest, options.Digest)
+	}
+
+	log.WithFields(log.Fields{
+		"digest":   digest,
+		"revision": revision,
+	}).Info("resolved the revision of the source")
+
+	// @step: verify the source has been signed by one of the trusted keys
+	if keys != nil {
+		if err := keys.Verify(ctx, location, dest, registry.Digest); err != nil {
+			return terminate(options, fmt.Errorf("module signature verification failed: %w", err))
+		}
+		log.WithField("source", source).Info("successfully verified the signature of the source")
+	}
+
 	// @step: if we were using a temporary directory we need to copy the files over
-	if !tmpdir {
-		return nil
+	if module != destination {
+		//nolint:gosec
+		if err := exec.Command("cp", []string{"-rT", module + "/", destination}...).Run(); err != nil {
+			return fmt.Errorf("failed to copy the source: %w", err)
+		}
+	}
+
+	// @step: store the source in the cache for the later stages
+	if options.CacheURL != "" {
+		if err := sources.Upload(ctx, options.CacheURL, options.CacheToken, digest, module); err != nil {
+			log.WithError(err).Warn("failed to upload the source to the cache")
+		}
+	}
+
+	return writeRevision(options, revision, digest)
+}
+
+// terminate records the reason the source was rejected in the termination log, so the failure
+// is surfaced on the pod status, and returns the error
+func terminate(options Options, err error) error {
+	if options.TerminationLog != "" {
+		if e := os.WriteFile(options.TerminationLog, []byte(err.Error()), 0600); e != nil {
+			log.WithError(e).Warn("failed to write the termination log")
+		}
+	}
+
+	return err
+}
+
+// fetchFromCache retrieves the pinned source from the cache into the destination
+func fetchFromCache(ctx context.Context, options Options) (bool, error) {
+	tmpdir, err := os.MkdirTemp("", "cache")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	found, err := sources.Fetch(ctx, options.CacheURL, options.CacheToken, options.Digest, tmpdir)
+	if err != nil || !found {
+		return false, err
 	}
 
 	//nolint:gosec
-	return exec.Command("cp", []string{"-rT", "/tmp/source/", destination}...).Run()
+	if err := exec.Command("cp", []string{"-rT", tmpdir + "/", options.Destination}...).Run(); err != nil {
+		return false, fmt.Errorf("failed to copy the source: %w", err)
+	}
+
+	return true, nil
+}
+
+// writeRevision writes the resolved revision of the source to the revision file
+func writeRevision(options Options, revision, digest string) error {
+	if options.RevisionFile == "" {
+		return nil
+	}
+
+	encoded, err := json.Marshal(&terraformv1alpha1.SourceStatus{
+		Digest:   digest,
+		Module:   options.Source,
+		Revision: revision,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(options.RevisionFile, encoded, 0600)
 }
 
 // sanitizeSource is responsible for sanitizing the source url
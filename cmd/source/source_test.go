@@ -18,10 +18,24 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
 )
 
 func TestSantizeSource(t *testing.T) {
@@ -135,3 +149,203 @@ func TestSantizeSource(t *testing.T) {
 		assert.Equal(t, c.Destination, destination, "case %d, expected destination to match", i)
 	}
 }
+
+// newModuleServer returns a server hosting a module archive
+func newModuleServer(t *testing.T) *httptest.Server {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# module\n"), 0600))
+
+	encoded := &bytes.Buffer{}
+	require.NoError(t, sources.Archive(dir, encoded))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write(encoded.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRunRecordsRevision(t *testing.T) {
+	t.Setenv("GIT_PASSWORD", "")
+	t.Setenv("GIT_USERNAME", "")
+	t.Setenv("HOME", t.TempDir())
+	server := newModuleServer(t)
+
+	options := Options{
+		Destination:  t.TempDir(),
+		RevisionFile: filepath.Join(t.TempDir(), "source.json"),
+		Source:       server.URL + "/module.tar.gz",
+		Timeout:      time.Minute,
+	}
+	require.NoError(t, Run(context.Background(), options))
+
+	content, err := os.ReadFile(filepath.Join(options.Destination, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# module\n", string(content))
+
+	encoded, err := os.ReadFile(options.RevisionFile)
+	require.NoError(t, err)
+	status := &terraformv1alpha1.SourceStatus{}
+	require.NoError(t, json.Unmarshal(encoded, status))
+
+	expected, err := sources.Digest(options.Destination)
+	require.NoError(t, err)
+	assert.Equal(t, expected, status.Digest)
+	assert.Equal(t, options.Source, status.Module)
+	assert.Empty(t, status.Revision)
+}
+
+func TestRunDigestMismatch(t *testing.T) {
+	t.Setenv("GIT_PASSWORD", "")
+	t.Setenv("GIT_USERNAME", "")
+	t.Setenv("HOME", t.TempDir())
+	server := newModuleServer(t)
+
+	err := Run(context.Background(), Options{
+		Destination: t.TempDir(),
+		Digest:      sources.DigestPrefix + strings.Repeat("a", 64),
+		Source:      server.URL + "/module.tar.gz",
+		Timeout:     time.Minute,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match the expected digest")
+}
+
+func TestRunInvalidDigest(t *testing.T) {
+	err := Run(context.Background(), Options{
+		Destination: t.TempDir(),
+		Digest:      "invalid",
+		Source:      "https://example.com/module.tar.gz",
+	})
+	assert.EqualError(t, err, "invalid digest: \"invalid\"")
+}
+
+// newSignedRepository creates a git repository with a commit signed by a new ssh key, returning
+// the repository and a directory holding the public key. Any tags are signed by the key
+func newSignedRepository(t *testing.T, sign bool, tags ...string) (string, string) {
+	for _, x := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(x); err != nil {
+			t.Skipf("%s is not available", x)
+		}
+	}
+	key := filepath.Join(t.TempDir(), "key")
+	//nolint:gosec
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).Run())
+
+	keys := t.TempDir()
+	public, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(keys, "release.ssh"), public, 0600))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# module\n"), 0600))
+
+	options := []string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"-c", "gpg.format=ssh", "-c", "user.signingkey=" + key, "-c", fmt.Sprintf("commit.gpgsign=%t", sign)}
+	commands := [][]string{{"init", "-q"}, {"add", "main.tf"}, {"commit", "-q", "-m", "initial commit"}}
+	for _, tag := range tags {
+		commands = append(commands, []string{"tag", "-s", "-m", "release", tag})
+	}
+	for _, args := range commands {
+		//nolint:gosec
+		output, err := exec.Command("git", append(options, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	return dir, keys
+}
+
+func TestRunSignedSource(t *testing.T) {
+	t.Setenv("GIT_PASSWORD", "")
+	t.Setenv("GIT_USERNAME", "")
+	t.Setenv("HOME", t.TempDir())
+	repository, keys := newSignedRepository(t, true)
+
+	options := Options{
+		Destination:   filepath.Join(t.TempDir(), "module"),
+		SignatureKeys: keys,
+		Source:        "git::file://" + repository,
+		Timeout:       time.Minute,
+	}
+	require.NoError(t, Run(context.Background(), options))
+
+	content, err := os.ReadFile(filepath.Join(options.Destination, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# module\n", string(content))
+}
+
+func TestRunUnsignedSource(t *testing.T) {
+	t.Setenv("GIT_PASSWORD", "")
+	t.Setenv("GIT_USERNAME", "")
+	t.Setenv("HOME", t.TempDir())
+	repository, keys := newSignedRepository(t, false)
+
+	options := Options{
+		Destination:    filepath.Join(t.TempDir(), "module"),
+		SignatureKeys:  keys,
+		Source:         "git::file://" + repository,
+		TerminationLog: filepath.Join(t.TempDir(), "termination-log"),
+		Timeout:        time.Minute,
+	}
+	err := Run(context.Background(), options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "module signature verification failed")
+
+	message, err := os.ReadFile(options.TerminationLog)
+	require.NoError(t, err)
+	assert.Contains(t, string(message), "module signature verification failed")
+}
+
+func TestRunSignedSourceTag(t *testing.T) {
+	t.Setenv("GIT_PASSWORD", "")
+	t.Setenv("GIT_USERNAME", "")
+	t.Setenv("HOME", t.TempDir())
+	repository, keys := newSignedRepository(t, false, "v1.0.0")
+
+	// @note: an unsigned tag on the same commit as the signed one
+	//nolint:gosec
+	output, err := exec.Command("git", "-C", repository, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"tag", "-a", "-m", "unsigned", "v2.0.0").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	options := Options{
+		Destination:   filepath.Join(t.TempDir(), "module"),
+		SignatureKeys: keys,
+		Source:        "git::file://" + repository + "?ref=v1.0.0",
+		Timeout:       time.Minute,
+	}
+	require.NoError(t, Run(context.Background(), options))
+
+	options.Destination = filepath.Join(t.TempDir(), "module")
+	options.Source = "git::file://" + repository + "?ref=v2.0.0"
+	err = Run(context.Background(), options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "module signature verification failed")
+}
+
+func TestRunSignatureUnsupportedSource(t *testing.T) {
+	t.Setenv("GIT_PASSWORD", "")
+	t.Setenv("GIT_USERNAME", "")
+	t.Setenv("HOME", t.TempDir())
+	server := newModuleServer(t)
+	_, keys := newSignedRepository(t, true)
+
+	err := Run(context.Background(), Options{
+		Destination:   t.TempDir(),
+		SignatureKeys: keys,
+		Source:        server.URL + "/module.tar.gz",
+		Timeout:       time.Minute,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only git and oci sources support signatures")
+}
+
+func TestRunNoSignatureKeys(t *testing.T) {
+	err := Run(context.Background(), Options{
+		Destination:   t.TempDir(),
+		SignatureKeys: t.TempDir(),
+		Source:        "https://example.com/module.tar.gz",
+	})
+	assert.EqualError(t, err, "failed to load the signature keys: no public keys found")
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+)
+
+const (
+	// archiveChunkSize is the size of spooled output which triggers an upload to the store
+	archiveChunkSize = 1 << 20
+	// archiveInterval is the interval the spooled output is uploaded to the store, bounding
+	// the logs lost should the step be killed
+	archiveInterval = time.Minute
+)
+
+// archiver spools the output of the commands to a temporary file and uploads it to the store in
+// chunks, so the output is never held in memory and is archived as the commands run. The uploads
+// are made by a background uploader, so writing the output is never stalled on the store
+type archiver struct {
+	sync.Mutex
+	// done is closed to stop the uploader
+	done chan struct{}
+	// file is the spool holding the output yet to be uploaded
+	file *os.File
+	// key is the key the logs are archived under
+	key logstore.Key
+	// ready is signalled when the spool has reached the chunk size
+	ready chan struct{}
+	// sensitive are the values redacted from the logs
+	sensitive []string
+	// size is the amount of output held in the spool
+	size int
+	// store is the store the logs are archived into
+	store logstore.Store
+	// wg is used to wait on the uploader
+	wg sync.WaitGroup
+}
+
+// newArchiver returns an archiver for the step, uploading the output periodically until closed
+func newArchiver(cc client.Client, step Step) (*archiver, error) {
+	key, err := logstore.ParseKey(step.ArchiveKey)
+	if err != nil {
+		return nil, err
+	}
+	options := step.Archive
+	options.Namespace = step.Namespace
+
+	store, err := logstore.New(cc, options)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp("", "step-archive-")
+	if err != nil {
+		return nil, err
+	}
+
+	a := &archiver{
+		done:      make(chan struct{}),
+		file:      file,
+		key:       key,
+		ready:     make(chan struct{}, 1),
+		sensitive: logstore.SensitiveValues(os.Environ()),
+		store:     store,
+	}
+	a.wg.Add(1)
+	go a.upload(archiveInterval)
+
+	return a, nil
+}
+
+// upload uploads the spooled output on the interval, or once the spool reaches the chunk size,
+// until the archiver is closed
+func (a *archiver) upload(interval time.Duration) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-ticker.C:
+		case <-a.ready:
+		}
+		if err := a.flush(false); err != nil {
+			log.WithError(err).Error("failed to archive the logs")
+		}
+	}
+}
+
+// Write appends the output to the spool, signalling the uploader once the spool exceeds the chunk
+// size. Errors are logged rather than returned, as a failure to archive should not fail the commands
+func (a *archiver) Write(p []byte) (int, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	n, err := a.file.Write(p)
+	a.size += n
+	if err != nil {
+		log.WithError(err).Error("failed to spool the logs for archiving")
+
+		return len(p), nil
+	}
+
+	if a.size >= archiveChunkSize {
+		select {
+		case a.ready <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close stops the uploader, uploads any remaining output to the store and removes the spool
+func (a *archiver) Close() error {
+	close(a.done)
+	a.wg.Wait()
+
+	err := a.flush(true)
+
+	if e := a.file.Close(); e != nil {
+		log.WithError(e).Error("failed to close the spool")
+	}
+	if e := os.Remove(a.file.Name()); e != nil {
+		log.WithError(e).Error("failed to remove the spool")
+	}
+
+	return err
+}
+
+// flush uploads the spooled output to the store; unless final only complete lines are uploaded,
+// so a secret is not split across uploads when redacted. The lock is only held while reading and
+// trimming the spool, never during the upload. Must only be called by the uploader, or once the
+// uploader has stopped
+func (a *archiver) flush(final bool) error {
+	a.Lock()
+	content := make([]byte, a.size)
+	_, err := a.file.ReadAt(content, 0)
+	a.Unlock()
+
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	if !final {
+		index := bytes.LastIndexByte(content, '\n')
+		switch {
+		case index < 0 && len(content) < archiveChunkSize:
+			return nil
+		case index < 0:
+			// @note: a line exceeding the chunk size is uploaded as is to bound the spool
+			index = len(content) - 1
+		}
+		content = content[:index+1]
+	}
+
+	// @note: we use a fresh context as the step context may have been cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := a.store.Put(ctx, a.key, logstore.Redact(content, a.sensitive)); err != nil {
+		return err
+	}
+
+	return a.trim(len(content))
+}
+
+// trim removes the uploaded output from the head of the spool, keeping any output written since
+func (a *archiver) trim(uploaded int) error {
+	a.Lock()
+	defer a.Unlock()
+
+	remainder := make([]byte, a.size-uploaded)
+	if _, err := a.file.ReadAt(remainder, int64(uploaded)); err != nil && err != io.EOF {
+		return err
+	}
+
+	if err := a.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	a.size = 0
+
+	if len(remainder) > 0 {
+		n, err := a.file.Write(remainder)
+		a.size = n
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
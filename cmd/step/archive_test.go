@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+)
+
+type fakeStore struct {
+	puts [][]byte
+}
+
+func (f *fakeStore) Delete(_ context.Context, _ logstore.Key) error {
+	return nil
+}
+
+func (f *fakeStore) Get(_ context.Context, _ logstore.Key) ([]byte, bool, error) {
+	return bytes.Join(f.puts, nil), len(f.puts) > 0, nil
+}
+
+func (f *fakeStore) Put(_ context.Context, _ logstore.Key, content []byte) error {
+	f.puts = append(f.puts, content)
+
+	return nil
+}
+
+func newTestArchiver(t *testing.T, store logstore.Store, sensitive ...string) *archiver {
+	file, err := os.CreateTemp(t.TempDir(), "spool-")
+	require.NoError(t, err)
+
+	return &archiver{done: make(chan struct{}), file: file, ready: make(chan struct{}, 1), sensitive: sensitive, store: store}
+}
+
+// blockingStore blocks the uploads until released
+type blockingStore struct {
+	fakeStore
+	sync.Mutex
+	// started is signalled when an upload has started
+	started chan struct{}
+	// release is closed to allow the uploads to complete
+	release chan struct{}
+}
+
+func (b *blockingStore) Put(ctx context.Context, key logstore.Key, content []byte) error {
+	b.started <- struct{}{}
+	<-b.release
+
+	b.Lock()
+	defer b.Unlock()
+
+	return b.fakeStore.Put(ctx, key, content)
+}
+
+func TestArchiverUploadsOnClose(t *testing.T) {
+	store := &fakeStore{}
+	a := newTestArchiver(t, store, "secret")
+
+	_, err := a.Write([]byte("hello secret\nno newline"))
+	require.NoError(t, err)
+	assert.Empty(t, store.puts)
+
+	require.NoError(t, a.Close())
+	require.Len(t, store.puts, 1)
+	assert.Equal(t, "hello [REDACTED]\nno newline", string(store.puts[0]))
+
+	_, err = os.Stat(a.file.Name())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestArchiverUploadsCompleteLinesInChunks(t *testing.T) {
+	store := &fakeStore{}
+	a := newTestArchiver(t, store)
+
+	line := strings.Repeat("x", 1023) + "\n"
+	for i := 0; i < archiveChunkSize/len(line); i++ {
+		_, err := a.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	assert.Empty(t, store.puts)
+	assert.Len(t, a.ready, 1)
+
+	require.NoError(t, a.flush(false))
+	require.Len(t, store.puts, 1)
+	assert.Equal(t, archiveChunkSize, len(store.puts[0]))
+	assert.Equal(t, 0, a.size)
+
+	_, err := a.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, a.flush(false))
+	assert.Len(t, store.puts, 1)
+	assert.Equal(t, len("partial"), a.size)
+
+	_, err = a.Write([]byte(" line\nmore"))
+	require.NoError(t, err)
+	require.NoError(t, a.flush(false))
+	require.Len(t, store.puts, 2)
+	assert.Equal(t, "partial line\n", string(store.puts[1]))
+	assert.Equal(t, len("more"), a.size)
+
+	require.NoError(t, a.Close())
+	require.Len(t, store.puts, 3)
+	assert.Equal(t, "more", string(store.puts[2]))
+}
+
+func TestArchiverUploadsLongLines(t *testing.T) {
+	store := &fakeStore{}
+	a := newTestArchiver(t, store)
+
+	_, err := a.Write(bytes.Repeat([]byte("x"), archiveChunkSize))
+	require.NoError(t, err)
+	require.NoError(t, a.flush(false))
+	require.Len(t, store.puts, 1)
+	assert.Equal(t, 0, a.size)
+
+	require.NoError(t, a.Close())
+	assert.Len(t, store.puts, 1)
+}
+
+func TestArchiverWriteNotBlockedByUpload(t *testing.T) {
+	store := &blockingStore{started: make(chan struct{}, 10), release: make(chan struct{})}
+	a := newTestArchiver(t, store)
+	a.wg.Add(1)
+	go a.upload(time.Hour)
+
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < archiveChunkSize/len(line); i++ {
+		_, err := a.Write(line)
+		require.NoError(t, err)
+	}
+
+	select {
+	case <-store.started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the uploader to have started an upload")
+	}
+
+	// @step: the upload is blocked, writing should still complete
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < 10; i++ {
+			_, _ = a.Write([]byte("more\n"))
+		}
+	}()
+	select {
+	case <-written:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the writes not to be blocked by the upload")
+	}
+
+	close(store.release)
+	require.NoError(t, a.Close())
+
+	content, found, err := store.Get(context.TODO(), logstore.Key{})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, archiveChunkSize+len("more\n")*10, len(content))
+	assert.True(t, bytes.HasSuffix(content, []byte(strings.Repeat("more\n", 10))))
+}
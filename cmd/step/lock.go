@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// acquireLock is used to acquire the lease, waiting for any current holder to release it or for the
+// lease to go stale. It returns a context which is cancelled should the lock be lost, and a function
+// to release the lock
+func acquireLock(ctx context.Context, cc client.Client, step Step) (context.Context, func(), error) {
+	holder, err := os.Hostname()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve the hostname for the lock holder: %w", err)
+	}
+
+	logger := log.WithFields(log.Fields{
+		"holder":    holder,
+		"lock":      step.Lock,
+		"namespace": step.Namespace,
+	})
+	logger.Info("attempting to acquire the lock")
+
+	err = utils.RetryWithTimeout(ctx, step.LockTimeout, 5*time.Second, func() (bool, error) {
+		acquired, err := kubernetes.AcquireLease(ctx, cc, step.Namespace, step.Lock, holder, step.LockDuration)
+		if err != nil {
+			logger.WithError(err).Error("failed to acquire the lock")
+
+			return false, nil
+		}
+		if !acquired {
+			lease := &coordinationv1.Lease{}
+			lease.Namespace = step.Namespace
+			lease.Name = step.Lock
+
+			if found, _ := kubernetes.GetIfExists(ctx, cc, lease); found {
+				logger.WithField("current", ptr.Deref(lease.Spec.HolderIdentity, "")).Info("lock is held, waiting for it to be released")
+			}
+		}
+
+		return acquired, nil
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrCancelled) {
+			return nil, nil, fmt.Errorf("failed to acquire the lock %s/%s within %s", step.Namespace, step.Lock, step.LockTimeout)
+		}
+
+		return nil, nil, err
+	}
+	logger.Info("successfully acquired the lock")
+
+	// @step: keep renewing the lease until released, cancelling the context if the lock is lost
+	locked, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(step.LockDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			switch err := kubernetes.RenewLease(ctx, cc, step.Namespace, step.Lock, holder); {
+			case err == nil:
+				continue
+			case errors.Is(err, kubernetes.ErrLeaseLost):
+				logger.Error("lock has been lost to another holder, cancelling the commands")
+				cancel()
+
+				return
+			default:
+				logger.WithError(err).Warn("failed to renew the lock")
+			}
+		}
+	}()
+
+	release := func() {
+		close(done)
+		cancel()
+
+		// @note: we use a fresh context here as the parent may have been cancelled by a signal
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := kubernetes.ReleaseLease(ctx, cc, step.Namespace, step.Lock, holder); err != nil {
+			logger.WithError(err).Error("failed to release the lock")
+
+			return
+		}
+		logger.Info("successfully released the lock")
+	}
+
+	return locked, release, nil
+}
@@ -36,6 +36,7 @@ import (
 
 	"github.com/appvia/terranetes-controller/pkg/utils"
 	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
 	"github.com/appvia/terranetes-controller/pkg/version"
 )
 
@@ -62,15 +63,25 @@ func main() {
 
 	flags := cmd.Flags()
 	flags.DurationVar(&step.Timeout, "timeout", 30*time.Second, "Timeout for wait-on file to appear")
+	flags.StringVar(&step.Archive.Bucket, "archive-bucket", "", "The name of the bucket to archive the logs into (s3 only)")
+	flags.StringVar(&step.Archive.Endpoint, "archive-endpoint", "", "An optional endpoint for an s3 compatible service (s3 only)")
+	flags.StringVar(&step.Archive.Region, "archive-region", "", "The region of the bucket to archive the logs into (s3 only)")
+	flags.StringVar(&step.Archive.Type, "archive-store", "", "The store used to archive the logs (kubernetes or s3)")
+	flags.StringVar(&step.ArchiveKey, "archive-key", "", "Archive the logs of the commands under the key (namespace/name/uid/generation/stage)")
 	flags.StringVar(&step.Comment, "comment", "", "Adds a banner before executing the step")
 	flags.StringVar(&step.ErrorFile, "on-error", "", "The path to a file to indicate we have failed")
 	flags.StringVar(&step.Namespace, "namespace", os.Getenv("KUBE_NAMESPACE"), "Namespace to upload any secrets")
 	flags.StringVar(&step.SuccessFile, "on-success", "", "The path of the file used to indicate the step was successful")
 	flags.StringVarP(&step.Shell, "shell", "s", "/bin/sh", "The shell to execute the command in")
 	flags.StringVar(&step.FailureFile, "is-failure", "", "The path of the file used to indicate failure above")
+	flags.StringVar(&step.Lock, "lock", "", "The name of a lease used to lock while the commands are running")
+	flags.DurationVar(&step.LockDuration, "lock-duration", 30*time.Second, "The duration of the lock lease, after which an unrenewed lock is considered stale")
+	flags.DurationVar(&step.LockTimeout, "lock-timeout", 10*time.Minute, "The max time to wait to acquire the lock")
 	flags.StringSliceVarP(&step.UploadFile, "upload", "u", []string{}, "Upload file as a kubernetes secret")
 	flags.StringVar(&step.WaitFile, "wait-on", "", "The path to a file to indicate this step can be run")
-	flags.StringSliceVarP(&step.Commands, "command", "c", []string{}, "Command to execute")
+	flags.StringArrayVarP(&step.Commands, "command", "c", []string{}, "Command to execute, the flag can be repeated")
+
+	cmd.AddCommand(newPlanSummaryCommand())
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "[Error] %s\n", err)
@@ -86,7 +97,7 @@ func Run(ctx context.Context, step Step) error {
 	}
 
 	var cc client.Client
-	if len(step.UploadFile) > 0 {
+	if len(step.UploadFile) > 0 || step.Lock != "" || step.Archive.Type == logstore.StoreKubernetes {
 		ci, err := kubernetes.NewRuntimeClient(nil)
 		if err != nil {
 			return err
@@ -94,8 +105,24 @@ func Run(ctx context.Context, step Step) error {
 		cc = ci
 	}
 
+	// @step: when archiving we spool a copy of the output, uploading it in chunks and
+	// regardless of the outcome of the commands
+	var output io.Writer = os.Stdout
+	if step.ArchiveKey != "" {
+		archive, err := newArchiver(cc, step)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := archive.Close(); err != nil {
+				log.WithError(err).Error("failed to archive the logs")
+			}
+		}()
+		output = io.MultiWriter(os.Stdout, archive)
+	}
+
 	if step.Comment != "" {
-		fmt.Printf(`
+		fmt.Fprintf(output, `
 =======================================================
 %s
 =======================================================
@@ -127,31 +154,26 @@ func Run(ctx context.Context, step Step) error {
 		}
 	}
 
+	// @step: acquire the lock if required
+	if step.Lock != "" {
+		locked, release, err := acquireLock(ctx, cc, step)
+		if err != nil {
+			return err
+		}
+		defer release()
+		ctx = locked
+	}
+
 	for i, command := range step.Commands {
 		//nolint:gosec
 		cmd := exec.CommandContext(ctx, step.Shell, "-c", command)
 		cmd.Env = os.Environ()
+		// @note: using the same writer ensures the output is written by a single goroutine
+		cmd.Stdout = output
+		cmd.Stderr = output
 
 		logger := log.WithField("command", i)
 
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			logger.WithError(err).Error("failed to acquire stdout pipe on command")
-
-			return err
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			logger.WithError(err).Error("failed to acquire stderr pipe on command")
-
-			return err
-		}
-
-		//nolint:errcheck
-		go io.Copy(os.Stdout, stdout)
-		//nolint:errcheck
-		go io.Copy(os.Stdout, stderr)
-
 		if err := cmd.Start(); err != nil {
 			logger.WithError(err).Error("failed to execute the command")
 
@@ -176,18 +198,21 @@ func Run(ctx context.Context, step Step) error {
 	log.Info("successfully executed the step")
 
 	// @step: upload any files as kubernetes secrets
-	for name, path := range step.UploadKeyPairs() {
-		err := utils.Retry(ctx, 2, true, 5*time.Second, func() (bool, error) {
-			err := uploadSecret(ctx, cc, step.Namespace, name, path)
-			if err == nil {
-				return true, nil
-			}
-			log.WithError(err).WithField("secret", name).Error("failed to upload secret")
+	for name, paths := range step.UploadKeyPairs() {
+		for _, path := range paths {
+			path := path
+			err := utils.Retry(ctx, 2, true, 5*time.Second, func() (bool, error) {
+				err := uploadSecret(ctx, cc, step.Namespace, name, path)
+				if err == nil {
+					return true, nil
+				}
+				log.WithError(err).WithField("secret", name).Error("failed to upload secret")
 
-			return false, nil
-		})
-		if err != nil {
-			return err
+				return false, nil
+			})
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
+)
+
+// newPlanSummaryCommand returns the command used to summarize the resource changes in the json
+// terraform plan, so only the summary and not the plan itself is uploaded to the plan secret
+func newPlanSummaryCommand() *cobra.Command {
+	var plan, output string
+
+	cmd := &cobra.Command{
+		Use:   "plan-summary",
+		Short: "Used to summarize the resource changes in a json terraform plan",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return summarizePlan(plan, output)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&plan, "plan", "", "The path to the json representation of the terraform plan")
+	flags.StringVar(&output, "output", "", "The path to write the summary of the resource changes to")
+
+	_ = cmd.MarkFlagRequired("plan")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// summarizePlan writes the summary of the resource changes in the json plan to the output
+func summarizePlan(plan, output string) error {
+	in, err := os.ReadFile(plan)
+	if err != nil {
+		return err
+	}
+
+	changes, err := terraform.ParsePlanChanges(in)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, encoded, 0600)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+func TestSummarizePlan(t *testing.T) {
+	dir := t.TempDir()
+	plan := filepath.Join(dir, "plan.json")
+	output := filepath.Join(dir, "plan-changes.json")
+
+	require.NoError(t, os.WriteFile(plan, []byte(`{"prior_state": {"values": {"secret": "value"}}, "resource_changes": [
+		{"address": "aws_s3_bucket.bucket", "mode": "managed", "change": {"actions": ["create"]}}
+	]}`), 0600))
+	require.NoError(t, summarizePlan(plan, output))
+
+	encoded, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "secret")
+
+	changes := &terraformv1alpha1.TerraformPlanChanges{}
+	require.NoError(t, json.Unmarshal(encoded, changes))
+	assert.Equal(t, 1, changes.Create)
+	assert.Equal(t, []terraformv1alpha1.TerraformPlanResourceChange{
+		{Action: terraformv1alpha1.ResourceChangeCreate, Address: "aws_s3_bucket.bucket"},
+	}, changes.Resources)
+}
+
+func TestSummarizePlanMissing(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Error(t, summarizePlan(filepath.Join(dir, "missing.json"), filepath.Join(dir, "out.json")))
+}
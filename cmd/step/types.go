@@ -22,10 +22,17 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
 )
 
 // Step represents a stage to run
 type Step struct {
+	// Archive are the options for the store used to archive the logs
+	Archive logstore.Options
+	// ArchiveKey is the key of the build the logs are archived under, in the format
+	// namespace/name/uid/generation/stage
+	ArchiveKey string
 	// Commands is the commands and arguments to run
 	Commands []string
 	// Comment adds a banner to the stage
@@ -34,6 +41,12 @@ type Step struct {
 	ErrorFile string
 	// FailureFile is the path to a file indicating failure
 	FailureFile string
+	// Lock is the name of a lease to hold while the commands are running
+	Lock string
+	// LockDuration is the duration of the lease, a lease not renewed within this time is considered stale
+	LockDuration time.Duration
+	// LockTimeout is the max time to wait to acquire the lock
+	LockTimeout time.Duration
 	// Namespace is the namespace to upload any files to as a secret
 	Namespace string
 	// Shell is the shell to execute the command in
@@ -60,6 +73,12 @@ func (s Step) IsValid() error {
 	case len(s.UploadFile) > 0 && s.Namespace == "":
 		return errors.New("namespace must be specified when uploading files")
 
+	case s.Lock != "" && s.Namespace == "":
+		return errors.New("namespace must be specified when using a lock")
+
+	case s.Lock != "" && s.LockDuration < 3*time.Second:
+		return errors.New("lock duration must be at least 3 seconds")
+
 	case len(s.UploadFile) > 0:
 		for _, x := range s.UploadFile {
 			if e := strings.Split(x, "="); len(e) != 2 {
@@ -68,19 +87,31 @@ func (s Step) IsValid() error {
 		}
 	}
 
+	if s.ArchiveKey != "" {
+		if _, err := logstore.ParseKey(s.ArchiveKey); err != nil {
+			return fmt.Errorf("archive %w", err)
+		}
+		options := s.Archive
+		options.Namespace = s.Namespace
+
+		if err := options.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// UploadKeyPairs returns a map of key pairs to upload
-func (s Step) UploadKeyPairs() map[string]string {
+// UploadKeyPairs returns a map of secret names to the files to upload into them
+func (s Step) UploadKeyPairs() map[string][]string {
 	if len(s.UploadFile) == 0 {
 		return nil
 	}
 
-	keys := make(map[string]string)
+	keys := make(map[string][]string)
 	for _, x := range s.UploadFile {
 		if e := strings.Split(x, "="); len(e) == 2 {
-			keys[e[0]] = e[1]
+			keys[e[0]] = append(keys[e[0]], e[1])
 		}
 	}
 
@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// BackendTypeCustom indicates the state is stored in a backend rendered from a custom template
+	BackendTypeCustom = "custom"
+	// BackendTypeHTTP indicates the state is stored in a http backend
+	BackendTypeHTTP = "http"
+	// BackendTypeKubernetes indicates the state is stored in the default kubernetes backend
+	BackendTypeKubernetes = "kubernetes"
+	// BackendTypePostgres indicates the state is stored in a postgres database
+	BackendTypePostgres = "pg"
+	// BackendTypeS3 indicates the state is stored in a s3 compatible bucket
+	BackendTypeS3 = "s3"
+)
+
+const (
+	// BackendAccessKeyID is the key in the backend secret holding the s3 access key
+	BackendAccessKeyID = "AWS_ACCESS_KEY_ID"
+	// BackendSecretAccessKey is the key in the backend secret holding the s3 secret key
+	BackendSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	// BackendConnectionString is the key in the backend secret holding the postgres connection string
+	BackendConnectionString = "PG_CONN_STR"
+	// BackendUsername is the key in the backend secret holding the http username
+	BackendUsername = "TF_HTTP_USERNAME"
+	// BackendPassword is the key in the backend secret holding the http password
+	BackendPassword = "TF_HTTP_PASSWORD"
+)
+
+// Backend defines a typed terraform state backend. Only one of the backend types should
+// be defined.
+type Backend struct {
+	// HTTP stores the terraform state behind a http endpoint which implements the terraform
+	// http backend protocol. The state for each configuration is stored under
+	// <address>/<namespace>/<name>/<uid>
+	// +kubebuilder:validation:Optional
+	HTTP *HTTPBackend `json:"http,omitempty"`
+	// Postgres stores the terraform state in a postgres database. The state for each
+	// configuration is stored in a schema named <schemaPrefix>_<uid>. The connection string
+	// is read from the PG_CONN_STR key of the secret
+	// +kubebuilder:validation:Optional
+	Postgres *PostgresBackend `json:"postgres,omitempty"`
+	// S3 stores the terraform state in a s3 compatible bucket. The state for each configuration
+	// is stored under <keyPrefix>/<namespace>/<name>/<uid>.tfstate
+	// +kubebuilder:validation:Optional
+	S3 *S3Backend `json:"s3,omitempty"`
+	// SecretRef is a reference to a secret in the controller namespace holding the credentials
+	// for the backend. Depending on the backend the keys are AWS_ACCESS_KEY_ID and
+	// AWS_SECRET_ACCESS_KEY, PG_CONN_STR or TF_HTTP_USERNAME and TF_HTTP_PASSWORD
+	// +kubebuilder:validation:Optional
+	SecretRef *v1.SecretReference `json:"secretRef,omitempty"`
+}
+
+// S3Backend defines the configuration for a s3 compatible backend
+type S3Backend struct {
+	// Bucket is the name of the bucket the state is stored in
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+	// DynamoDBTable is an optional dynamodb table used for state locking
+	// +kubebuilder:validation:Optional
+	DynamoDBTable string `json:"dynamodbTable,omitempty"`
+	// Encrypt enables server side encryption of the state
+	// +kubebuilder:validation:Optional
+	Encrypt bool `json:"encrypt,omitempty"`
+	// Endpoint is a custom endpoint for s3 compatible storage, i.e minio
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// ForcePathStyle enables path style addressing of the bucket, which is commonly required
+	// by s3 compatible storage
+	// +kubebuilder:validation:Optional
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+	// KeyPrefix is the prefix for all the state keys in the bucket
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=terranetes
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	// Region is the region of the bucket
+	// +kubebuilder:validation:Required
+	Region string `json:"region"`
+}
+
+// PostgresBackend defines the configuration for a postgres backend
+type PostgresBackend struct {
+	// SchemaPrefix is the prefix for the schema names created per configuration
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=terranetes
+	SchemaPrefix string `json:"schemaPrefix,omitempty"`
+}
+
+// HTTPBackend defines the configuration for a http backend
+type HTTPBackend struct {
+	// Address is the base url of the http backend
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+	// EnableLocking indicates the endpoint supports locking via the LOCK and UNLOCK methods
+	// +kubebuilder:validation:Optional
+	EnableLocking bool `json:"enableLocking,omitempty"`
+	// SkipCertVerification disables the verification of the server certificate
+	// +kubebuilder:validation:Optional
+	SkipCertVerification bool `json:"skipCertVerification,omitempty"`
+}
+
+// GetType returns the type of backend defined
+func (b *Backend) GetType() string {
+	switch {
+	case b.HTTP != nil:
+		return BackendTypeHTTP
+	case b.Postgres != nil:
+		return BackendTypePostgres
+	case b.S3 != nil:
+		return BackendTypeS3
+	}
+
+	return ""
+}
+
+// HasSecretRef returns true if the backend references a credentials secret
+func (b *Backend) HasSecretRef() bool {
+	return b.SecretRef != nil && b.SecretRef.Name != ""
+}
+
+// BackendStatus is the status of the backend holding the terraform state
+type BackendStatus struct {
+	// Checksum is a checksum of the backend configuration the state currently resides in
+	// +kubebuilder:validation:Optional
+	Checksum string `json:"checksum,omitempty"`
+	// Type is the type of backend the state currently resides in
+	// +kubebuilder:validation:Optional
+	Type string `json:"type,omitempty"`
+	// PendingChecksum is the checksum of a changed backend configuration the state has not
+	// yet been migrated to
+	// +kubebuilder:validation:Optional
+	PendingChecksum string `json:"pendingChecksum,omitempty"`
+	// PendingType is the type of the backend the state is waiting to be migrated to
+	// +kubebuilder:validation:Optional
+	PendingType string `json:"pendingType,omitempty"`
+}
+
+// HasPendingMigration returns true if the state is waiting to be migrated
+func (b *BackendStatus) HasPendingMigration() bool {
+	return b != nil && b.PendingChecksum != ""
+}
@@ -87,6 +87,21 @@ type CloudResourceSpec struct {
 	// for any drift between the expected and current state. If any drift is detected the
 	// status is changed and a kubernetes event raised.
 	EnableDriftDetection bool `json:"enableDriftDetection,omitempty"`
+	// DriftPolicy controls what happens when drift is detected and how often drift detection
+	// runs. Note this requires enableDriftDetection to be set.
+	// +kubebuilder:validation:Optional
+	DriftPolicy *DriftPolicy `json:"driftPolicy,omitempty"`
+	// DependsOn is a collection of configurations in the same namespace which must be ready
+	// before this cloud resource is planned. Outputs from the dependencies can be mapped to
+	// variables, and a change to those outputs will trigger a new plan.
+	// +kubebuilder:validation:Optional
+	DependsOn DependencyList `json:"dependsOn,omitempty"`
+	// Engine is the engine used to run the terraform module, either terraform or tofu (OpenTofu).
+	// When not set the engine defined on the provider is used, falling back to the default
+	// engine of the controller.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=terraform;tofu
+	Engine string `json:"engine,omitempty"`
 	// Plan is the reference to the plan which this cloud resource is associated with. This
 	// field is required, and needs both the name and version the plan revision to use
 	// +kubebuilder:validation:Required
@@ -95,6 +110,10 @@ type CloudResourceSpec struct {
 	// configuration.
 	// +kubebuilder:validation:Optional
 	ProviderRef *ProviderReference `json:"providerRef,omitempty"`
+	// Providers is a list of additional providers used by the terraform module. When defined
+	// these override the additional providers defined in the revision.
+	// +kubebuilder:validation:Optional
+	Providers []ProviderReference `json:"providers,omitempty"`
 	// WriteConnectionSecretToRef is the name for a secret. On execution of the terraform module
 	// any module outputs are written to this secret. The outputs are automatically uppercased
 	// and ready to be consumed as environment variables.
@@ -113,7 +132,9 @@ type CloudResourceSpec struct {
 	ValueFrom ValueFromList `json:"valueFrom,omitempty"`
 	// TerraformVersion provides the ability to override the default terraform version. Before
 	// changing this field its best to consult with platform administrator. As the
-	// value of this field is used to change the tag of the terraform container image.
+	// value of this field is used to change the tag of the terraform container image. The
+	// value can also be a version constraint, i.e. "~> 1.6", which is resolved to the newest
+	// version permitted by the controller satisfying the constraint.
 	// +kubebuilder:validation:Optional
 	TerraformVersion string `json:"terraformVersion,omitempty"`
 }
@@ -137,7 +158,7 @@ func (c *CloudResourceSpec) HasValueFrom() bool {
 	return len(c.ValueFrom) > 0
 }
 
-// +kubebuilder:webhook:name=cloudresources.terraform.appvia.io,mutating=false,path=/validate/terraform.appvia.io/cloudresources,verbs=create;update,groups="terraform.appvia.io",resources=cloudresources,versions=v1alpha1,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:name=cloudresources.terraform.appvia.io,mutating=false,path=/validate/terraform.appvia.io/cloudresources,verbs=create;delete;update,groups="terraform.appvia.io",resources=cloudresources,versions=v1alpha1,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1
 // +kubebuilder:webhook:name=cloudresources.terraform.appvia.io,mutating=true,path=/mutate/terraform.appvia.io/cloudresources,verbs=create;update,groups="terraform.appvia.io",resources=cloudresources,versions=v1alpha1,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1
 
 // +genclient
@@ -284,6 +305,11 @@ func (c *CloudResource) GetTerraformPolicySecretName() string {
 	return fmt.Sprintf("policy-%s", string(c.GetUID()))
 }
 
+// GetTerraformRegoSecretName returns the name of the secret holding the rego policy report
+func (c *CloudResource) GetTerraformRegoSecretName() string {
+	return fmt.Sprintf("rego-%s", string(c.GetUID()))
+}
+
 // GetTerraformCostSecretName returns the name which should be used for the costs report
 func (c *CloudResource) GetTerraformCostSecretName() string {
 	return fmt.Sprintf("costs-%s", string(c.GetUID()))
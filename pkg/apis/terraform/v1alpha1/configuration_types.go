@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -66,6 +67,19 @@ func (v *ValueFromList) HasContextReferences() bool {
 	return false
 }
 
+// GetContextReferences returns the value from sources referencing the named context
+func (v *ValueFromList) GetContextReferences(name string) []ValueFromSource {
+	var list []ValueFromSource
+
+	for _, x := range *v {
+		if x.Context != nil && *x.Context == name {
+			list = append(list, x)
+		}
+	}
+
+	return list
+}
+
 // HasSecretReferences returns true if the configuration has secret references
 func (v *ValueFromList) HasSecretReferences() bool {
 	for _, x := range *v {
@@ -80,12 +94,43 @@ func (v *ValueFromList) HasSecretReferences() bool {
 const (
 	// ApplyAnnotation is the annotation used to mark a resource as a plan rather than apply
 	ApplyAnnotation = "terraform.appvia.io/apply"
+	// ContextAnnotation is the annotation used to trigger a reconcile when a context the
+	// configuration consumes values from has changed
+	ContextAnnotation = "terraform.appvia.io/context"
+	// DependencyAnnotation is the annotation used to notify a configuration one of its
+	// dependencies has been updated
+	DependencyAnnotation = "terraform.appvia.io/dependency"
 	// DriftAnnotation is the annotation used to mark a resource for drift detection
 	DriftAnnotation = "terraform.appvia.io/drift"
+	// DriftApprovalAnnotation is the annotation used to approve the remediation of drift, the
+	// value must match the drift detection timestamp being approved
+	DriftApprovalAnnotation = "terraform.appvia.io/drift-approval"
+	// MigrateStateAnnotation is the annotation used to approve the migration of the terraform state
+	// to a changed backend, the value must match the pending checksum of the backend
+	MigrateStateAnnotation = "terraform.appvia.io/migrate-state"
 	// ReconcileAnnotation is the label used control reconciliation
 	ReconcileAnnotation = "terraform.appvia.io/reconcile"
+	// RestoreApprovalAnnotation is the annotation used to approve a pending restore of the terraform
+	// state, the value is the unix timestamp of the approval
+	RestoreApprovalAnnotation = "terraform.appvia.io/restore-approval"
+	// RestoreStateAnnotation is the annotation used to request the terraform state is restored to
+	// a previous version
+	RestoreStateAnnotation = "terraform.appvia.io/restore-state"
+	// StateCommandAnnotation is the annotation used to request a terraform state command, i.e.
+	// mv, rm, show, pull or push, is run against the state of the configuration. The value is a
+	// json list of the command and its arguments, i.e. ["mv", "aws_instance.a", "aws_instance.b"]
+	StateCommandAnnotation = "terraform.appvia.io/state-command"
+	// StateCommandIDAnnotation is the annotation holding the unique identifier of the requested
+	// state command, the value is the unix timestamp of the request
+	StateCommandIDAnnotation = "terraform.appvia.io/state-command-id"
 	// RetryAnnotation is the annotation used to mark a resource for retry
 	RetryAnnotation = "terraform.appvia.io/retry"
+	// ReplaceAnnotation is a comma separated list of resource addresses to be replaced on the
+	// next terraform run, the annotation is removed once the run has been applied
+	ReplaceAnnotation = "terraform.appvia.io/replace"
+	// TargetAnnotation is a comma separated list of resource or module addresses the next
+	// terraform run is limited to, the annotation is removed once the run has been applied
+	TargetAnnotation = "terraform.appvia.io/target"
 	// OrphanAnnotation is the label used to orphan a configuration
 	OrphanAnnotation = "terraform.appvia.io/orphan"
 	// VersionAnnotation is the label used to hold the version
@@ -95,6 +140,27 @@ const (
 const (
 	// TerraformStateSecretKey is the key used by the terraform state secret
 	TerraformStateSecretKey = "tfstate"
+	// TerraformPlanSecretKey is the key used by the terraform plan secret
+	TerraformPlanSecretKey = "plan.out"
+	// TerraformPlanChangesSecretKey is the key used for the summary of the resource changes in the
+	// terraform plan. Note, the json representation of the plan is not kept, as it holds the prior
+	// state and configuration and can easily exceed the size of a secret
+	TerraformPlanChangesSecretKey = "plan-changes.json"
+	// TerraformSourceSecretKey is the key used for the resolved revision of the module source
+	TerraformSourceSecretKey = "source.json"
+)
+
+const (
+	// ResourceChangeCreate indicates the resource will be created
+	ResourceChangeCreate = "create"
+	// ResourceChangeDelete indicates the resource will be deleted
+	ResourceChangeDelete = "delete"
+	// ResourceChangeImport indicates an existing resource will be imported without change
+	ResourceChangeImport = "import"
+	// ResourceChangeReplace indicates the resource will be destroyed and recreated
+	ResourceChangeReplace = "replace"
+	// ResourceChangeUpdate indicates the resource will be updated in place
+	ResourceChangeUpdate = "update"
 )
 
 const (
@@ -102,6 +168,15 @@ const (
 	CheckovJobTemplateConfigMapKey = "checkov.yaml"
 	// TerraformBackendSecretKey is the key name for the terraform backend in the secret
 	TerraformBackendSecretKey = "backend.tf"
+	// TerraformStateOutputSecretKey is the key holding the output of a terraform state command
+	TerraformStateOutputSecretKey = "output"
+	// TerraformStatePushSecretKey is the key holding the terraform state to be pushed
+	TerraformStatePushSecretKey = "push"
+	// TerraformStateSnapshotSecretKey is the key holding the snapshot of the terraform state taken
+	// before a state command changed it
+	TerraformStateSnapshotSecretKey = "snapshot"
+	// TerraformImportsSecretKey is the key name for the terraform import blocks in the secret
+	TerraformImportsSecretKey = "imports.tf"
 	// TerraformVariablesConfigMapKey is the key name for the terraform variables in the configmap
 	TerraformVariablesConfigMapKey = "variables.tfvars.json"
 	// TerraformProviderConfigMapKey is the key name for the terraform variables in the configmap
@@ -119,8 +194,30 @@ const (
 	ConfigurationUIDLabel = "terraform.appvia.io/configuration-uid"
 	// ConfigurationNamespaceLabel is the label used to identify a configuration namespace
 	ConfigurationNamespaceLabel = "terraform.appvia.io/namespace"
+	// ConfigurationStateSerialLabel is the label holding the serial of a terraform state version
+	ConfigurationStateSerialLabel = "terraform.appvia.io/state-serial"
+	// ConfigurationStateCommandLabel is the label holding the identifier of the state command a job
+	// was run for
+	ConfigurationStateCommandLabel = "terraform.appvia.io/state-command"
+	// ConfigurationStateVersionLabel is the label holding the version of a terraform state version
+	ConfigurationStateVersionLabel = "terraform.appvia.io/state-version"
+	// ConfigurationTimestampLabel is the label holding the unix timestamp a terraform state version
+	// was taken
+	ConfigurationTimestampLabel = "terraform.appvia.io/timestamp"
 	// ConfigurationStageLabel is the label used to identify a configuration stage
 	ConfigurationStageLabel = "terraform.appvia.io/stage"
+	// ConfigurationBackendLabel is the label holding the checksum of the backend a migration
+	// job is moving the state to
+	ConfigurationBackendLabel = "terraform.appvia.io/backend"
+	// ConfigurationContextsLabel is the label holding the checksum of the context values the
+	// job was run with
+	ConfigurationContextsLabel = "terraform.appvia.io/contexts"
+	// ConfigurationDependenciesLabel is the label holding the checksum of the dependency outputs
+	// the job was run with
+	ConfigurationDependenciesLabel = "terraform.appvia.io/dependencies"
+	// ConfigurationOperationLabel is the label holding the checksum of the one-shot operation,
+	// i.e. the replace and target annotations, the job was run with
+	ConfigurationOperationLabel = "terraform.appvia.io/operation"
 	// ConfigurationPlanLabel is the label which contains the plan name for a configuration
 	ConfigurationPlanLabel = RevisionPlanNameLabel
 	// ConfigurationRevisionLabelName is the name of the revision being used
@@ -134,8 +231,14 @@ const (
 	StageTerraformApply = "apply"
 	// StageTerraformDestroy is the stage for a terraform destroy
 	StageTerraformDestroy = "destroy"
+	// StageTerraformMigrate is the stage for migrating the terraform state between backends
+	StageTerraformMigrate = "migrate"
 	// StageTerraformPlan is the stage for a terraform plan
 	StageTerraformPlan = "plan"
+	// StageTerraformRestore is the stage for restoring a previous version of the terraform state
+	StageTerraformRestore = "restore"
+	// StageTerraformState is the stage for running a terraform state command
+	StageTerraformState = "state"
 	// StageTerraformVerify is the stage for a verify
 	StageTerraformVerify = "verify"
 )
@@ -150,6 +253,11 @@ var ConfigurationGVK = schema.GroupVersionKind{
 // ProviderReference is the reference to the provider which is used to create
 // the configuration
 type ProviderReference struct {
+	// Alias is an optional alias for the provider, rendered as the alias of the provider block
+	// in the terraform code. This is only used by the additional providers in spec.providers,
+	// and is required when more than one provider of the same type is used.
+	// +kubebuilder:validation:Optional
+	Alias string `json:"alias,omitempty"`
 	// Name is the name of the provider which contains the credentials to use for this
 	// configuration.
 	// +kubebuilder:validation:Required
@@ -169,6 +277,65 @@ func (p *ProviderReference) IsValid() error {
 	return nil
 }
 
+// IsValidProviders returns an error if the additional provider references are invalid
+func (c *ConfigurationSpec) IsValidProviders() error {
+	seen := make(map[string]bool)
+
+	for i, x := range c.Providers {
+		switch {
+		case x.Name == "":
+			return fmt.Errorf("spec.providers[%d].name is required", i)
+		case seen[x.Name+"/"+x.Alias]:
+			return fmt.Errorf("spec.providers[%d] is a duplicate of another provider reference", i)
+		}
+		seen[x.Name+"/"+x.Alias] = true
+	}
+
+	return nil
+}
+
+// IsCompatibleProviders returns an error if the providers cannot be used together, i.e. the
+// same provider type is used without a unique alias or from different registry sources, or the
+// injected or federated providers require different service accounts. The additional providers are expected
+// in the same order as spec.providers, and any missing providers are ignored
+func (c *Configuration) IsCompatibleProviders(provider *Provider, providers []*Provider) error {
+	seen := make(map[string]bool)
+	sources := make(map[ProviderType]string)
+	var serviceAccount string
+
+	for i, x := range append([]*Provider{provider}, providers...) {
+		if x == nil || i > len(c.Spec.Providers) {
+			continue
+		}
+		alias, path := "", "spec.providerRef"
+		if i > 0 {
+			alias, path = c.Spec.Providers[i-1].Alias, fmt.Sprintf("spec.providers[%d]", i-1)
+		}
+
+		key := fmt.Sprintf("%s/%s", x.Spec.Provider, alias)
+		if seen[key] {
+			return fmt.Errorf("%s requires a unique alias, provider type %q is already in use", path, x.Spec.Provider)
+		}
+		seen[key] = true
+
+		if source, found := sources[x.Spec.Provider]; found && source != x.GetRegistrySource() {
+			return fmt.Errorf("%s uses a different registry source for provider type %q", path, x.Spec.Provider)
+		}
+		sources[x.Spec.Provider] = x.GetRegistrySource()
+
+		if (x.Spec.Source != SourceInjected && x.Spec.Source != SourceFederated) || x.Spec.ServiceAccount == nil {
+			continue
+		}
+		if serviceAccount != "" && *x.Spec.ServiceAccount != serviceAccount {
+			return fmt.Errorf("%s uses service account %q, all injected or federated providers must use the same service account (%s)",
+				path, *x.Spec.ServiceAccount, serviceAccount)
+		}
+		serviceAccount = *x.Spec.ServiceAccount
+	}
+
+	return nil
+}
+
 // WriteConnectionSecret defines the options around the secret produced by the terraform code
 type WriteConnectionSecret struct {
 	// Name is the of the secret where you want to the terraform output to be written. The terraform outputs
@@ -247,6 +414,261 @@ func (w *WriteConnectionSecret) KeysMap() (map[string]string, error) {
 	return keys, nil
 }
 
+// DependencyList is a list of configurations this configuration depends on
+type DependencyList []Dependency
+
+// Has returns true if the configuration depends on the named configuration
+func (d DependencyList) Has(name string) bool {
+	for _, x := range d {
+		if x.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsValid checks if all the dependencies are valid, else returns an error
+func (d DependencyList) IsValid() error {
+	names := make(map[string]bool)
+
+	for i, x := range d {
+		switch {
+		case x.Name == "":
+			return fmt.Errorf("spec.dependsOn[%d].name is required", i)
+		case names[x.Name]:
+			return fmt.Errorf("spec.dependsOn[%d].name %q is duplicated", i, x.Name)
+		}
+		names[x.Name] = true
+
+		for j, output := range x.Outputs {
+			if output.Key == "" {
+				return fmt.Errorf("spec.dependsOn[%d].outputs[%d].key is required", i, j)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResourceAddressRegex is the regex for the address of a resource within the module
+var ResourceAddressRegex = regexp.MustCompile(
+	`^(module\.[a-zA-Z_][a-zA-Z0-9_-]*(\[[^\]]+\])?\.)*[a-zA-Z_][a-zA-Z0-9_-]*\.[a-zA-Z_][a-zA-Z0-9_-]*(\[[^\]]+\])?$`,
+)
+
+// ModuleAddressRegex is the regex for the address of a module call within the module
+var ModuleAddressRegex = regexp.MustCompile(
+	`^module\.[a-zA-Z_][a-zA-Z0-9_-]*(\[[^\]]+\])?(\.module\.[a-zA-Z_][a-zA-Z0-9_-]*(\[[^\]]+\])?)*$`,
+)
+
+// ImportList is a collection of existing resources to import into the terraform state
+type ImportList []Import
+
+// Has returns true if the import is in the list
+func (i ImportList) Has(item Import) bool {
+	for _, x := range i {
+		if x == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsValid checks if all the imports are valid, else returns an error
+func (i ImportList) IsValid() error {
+	addresses := make(map[string]bool)
+
+	for j, x := range i {
+		switch {
+		case x.Address == "":
+			return fmt.Errorf("spec.imports[%d].address is required", j)
+		case !ResourceAddressRegex.MatchString(x.Address):
+			return fmt.Errorf("spec.imports[%d].address %q is not a valid resource address", j, x.Address)
+		case addresses[x.Address]:
+			return fmt.Errorf("spec.imports[%d].address %q is duplicated", j, x.Address)
+		case x.ID == "":
+			return fmt.Errorf("spec.imports[%d].id is required", j)
+		case strings.Contains(x.ID, "${"), strings.Contains(x.ID, "%{"):
+			return fmt.Errorf("spec.imports[%d].id cannot contain template sequences", j)
+		}
+		addresses[x.Address] = true
+	}
+
+	return nil
+}
+
+const (
+	// StateCommandMove moves a resource or module to a different address in the state
+	StateCommandMove = "mv"
+	// StateCommandPull retrieves the terraform state
+	StateCommandPull = "pull"
+	// StateCommandPush replaces the terraform state with the state provided
+	StateCommandPush = "push"
+	// StateCommandRemove removes resources or modules from the state
+	StateCommandRemove = "rm"
+	// StateCommandShow shows the attributes of a resource in the state
+	StateCommandShow = "show"
+)
+
+// StateCommand is a terraform state command requested against the state of a configuration
+type StateCommand struct {
+	// Name is the name of the state command, i.e. mv
+	Name string
+	// Arguments are the arguments passed to the state command
+	Arguments []string
+}
+
+// NewStateCommand returns a validated state command, i.e. mv aws_instance.a aws_instance.b
+func NewStateCommand(name string, arguments ...string) (*StateCommand, error) {
+	command := &StateCommand{Name: name, Arguments: arguments}
+
+	isAddress := func(address string) bool {
+		return ResourceAddressRegex.MatchString(address) || ModuleAddressRegex.MatchString(address)
+	}
+
+	switch command.Name {
+	case "":
+		return nil, errors.New("state command is required")
+	case StateCommandMove:
+		if len(command.Arguments) != 2 {
+			return nil, errors.New("state command mv requires a source and destination address")
+		}
+	case StateCommandRemove:
+		if len(command.Arguments) == 0 {
+			return nil, errors.New("state command rm requires at least one address")
+		}
+	case StateCommandShow:
+		if len(command.Arguments) != 1 || !ResourceAddressRegex.MatchString(command.Arguments[0]) {
+			return nil, errors.New("state command show requires a single resource address")
+		}
+	case StateCommandPull:
+		if len(command.Arguments) > 0 {
+			return nil, errors.New("state command pull does not take any arguments")
+		}
+	case StateCommandPush:
+		if len(command.Arguments) > 1 || (len(command.Arguments) == 1 && command.Arguments[0] != "-force") {
+			return nil, errors.New("state command push only accepts the -force argument")
+		}
+
+		return command, nil
+	default:
+		return nil, fmt.Errorf("state command %q is not supported", command.Name)
+	}
+
+	for _, address := range command.Arguments {
+		if !isAddress(address) {
+			return nil, fmt.Errorf("state command address %q is not a valid resource or module address", address)
+		}
+	}
+
+	return command, nil
+}
+
+// ParseStateCommand parses and validates the state command from the annotation, i.e.
+// ["mv", "aws_instance.a", "aws_instance.b"]
+func ParseStateCommand(value string) (*StateCommand, error) {
+	var fields []string
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, errors.New("state command must be a json list of the command and arguments")
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("state command is required")
+	}
+
+	return NewStateCommand(fields[0], fields[1:]...)
+}
+
+// IsMutating returns true if the state command changes the terraform state
+func (s *StateCommand) IsMutating() bool {
+	switch s.Name {
+	case StateCommandMove, StateCommandPush, StateCommandRemove:
+		return true
+	}
+
+	return false
+}
+
+// Encode returns the state command as the value of the state command annotation
+func (s *StateCommand) Encode() string {
+	encoded, _ := json.Marshal(append([]string{s.Name}, s.Arguments...))
+
+	return string(encoded)
+}
+
+// String returns the state command as it would be requested, quoting any argument containing
+// whitespace or quotes
+func (s *StateCommand) String() string {
+	list := []string{s.Name}
+	for _, x := range s.Arguments {
+		if strings.ContainsAny(x, " \t\n'\"") {
+			x = strconv.Quote(x)
+		}
+		list = append(list, x)
+	}
+
+	return strings.Join(list, " ")
+}
+
+// Import is an existing resource which should be imported into the terraform state
+type Import struct {
+	// Address is the terraform address of the resource within the module the existing
+	// resource is imported to, i.e. aws_s3_bucket.this
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+	// ID is the provider specific identifier of the existing resource
+	// +kubebuilder:validation:Required
+	ID string `json:"id"`
+}
+
+// Dependency is a reference to a configuration which must be ready before this configuration
+// is run, along with the outputs which should be passed in as variables
+type Dependency struct {
+	// Name is the name of the configuration, in the same namespace, which this configuration
+	// depends on
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Outputs is a collection of terraform outputs from the dependency which are passed into
+	// this configuration as variables
+	// +kubebuilder:validation:Optional
+	Outputs []DependencyOutput `json:"outputs,omitempty"`
+}
+
+// HasRequiredOutputs returns true if any of the outputs taken from the dependency are required
+func (d *Dependency) HasRequiredOutputs() bool {
+	for _, x := range d.Outputs {
+		if !x.Optional {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DependencyOutput maps a terraform output of a dependency to a variable
+type DependencyOutput struct {
+	// Key is the name of the terraform output on the dependency
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+	// Name is the name of the variable the output is passed in as. If not set the key is
+	// used as the name of the variable
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// Optional indicates the output may not exist on the dependency, in which case the
+	// variable is not set
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// GetName returns the name or the key if not set
+func (d *DependencyOutput) GetName() string {
+	if len(d.Name) == 0 {
+		return d.Key
+	}
+
+	return d.Name
+}
+
 // ValueFromSource defines a value which is taken from a secret
 type ValueFromSource struct {
 	// Context is the context is the name of the terraform context where the
@@ -341,7 +763,8 @@ func (p *PlanReference) IsValid() error {
 type ConfigurationSpec struct {
 	// Auth is used to configure any options required when the source of the terraform
 	// module is private or requires credentials to retrieve. This could be SSH keys or git
-	// user/pass or AWS credentials for an s3 bucket.
+	// user/pass, AWS credentials for an s3 bucket or OCI_USERNAME and OCI_PASSWORD for an
+	// oci registry.
 	// +kubebuilder:validation:Optional
 	Auth *v1.SecretReference `json:"auth,omitempty"`
 	// EnableAutoApproval when enabled indicates the configuration does not need to be
@@ -353,6 +776,26 @@ type ConfigurationSpec struct {
 	// for any drift between the expected and current state. If any drift is detected the
 	// status is changed and a kubernetes event raised.
 	EnableDriftDetection bool `json:"enableDriftDetection,omitempty"`
+	// DriftPolicy controls what happens when drift is detected and how often drift detection
+	// runs. Note this requires enableDriftDetection to be set.
+	// +kubebuilder:validation:Optional
+	DriftPolicy *DriftPolicy `json:"driftPolicy,omitempty"`
+	// DependsOn is a collection of configurations in the same namespace which must be ready
+	// before this configuration is planned. Outputs from the dependencies can be mapped to
+	// variables, and a change to those outputs will trigger a new plan.
+	// +kubebuilder:validation:Optional
+	DependsOn DependencyList `json:"dependsOn,omitempty"`
+	// Engine is the engine used to run the terraform module, either terraform or tofu (OpenTofu).
+	// When not set the engine defined on the provider is used, falling back to the default
+	// engine of the controller.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=terraform;tofu
+	Engine string `json:"engine,omitempty"`
+	// Imports is a collection of existing resources which should be imported into the state
+	// of the configuration. The imports are rendered as terraform import blocks, shown in the
+	// plan and recorded on the status once applied. Note this requires terraform 1.5 or above.
+	// +kubebuilder:validation:Optional
+	Imports ImportList `json:"imports,omitempty"`
 	// Module is the URL to the source of the terraform module. The format of the URL is
 	// a direct implementation of terraform's module reference. Please see the following
 	// repository for more details https://github.com/hashicorp/go-getter
@@ -366,6 +809,12 @@ type ConfigurationSpec struct {
 	// configuration.
 	// +kubebuilder:validation:Optional
 	ProviderRef *ProviderReference `json:"providerRef,omitempty"`
+	// Providers is a list of additional providers used by the terraform module, i.e. a
+	// kubernetes or vault provider alongside the cloud provider, or the same provider in a
+	// different region via an alias. Each provider is rendered into the terraform code and the
+	// credentials of each are made available to the terraform jobs.
+	// +kubebuilder:validation:Optional
+	Providers []ProviderReference `json:"providers,omitempty"`
 	// WriteConnectionSecretToRef is the name for a secret. On execution of the terraform module
 	// any module outputs are written to this secret. The outputs are automatically uppercased
 	// and ready to be consumed as environment variables.
@@ -384,12 +833,14 @@ type ConfigurationSpec struct {
 	ValueFrom ValueFromList `json:"valueFrom,omitempty"`
 	// TerraformVersion provides the ability to override the default terraform version. Before
 	// changing this field its best to consult with platform administrator. As the
-	// value of this field is used to change the tag of the terraform container image.
+	// value of this field is used to change the tag of the terraform container image. The
+	// value can also be a version constraint, i.e. "~> 1.6", which is resolved to the newest
+	// version permitted by the controller satisfying the constraint.
 	// +kubebuilder:validation:Optional
 	TerraformVersion string `json:"terraformVersion,omitempty"`
 }
 
-// +kubebuilder:webhook:name=configurations.terraform.appvia.io,mutating=false,path=/validate/terraform.appvia.io/configurations,verbs=create;update,groups="terraform.appvia.io",resources=configurations,versions=v1alpha1,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:name=configurations.terraform.appvia.io,mutating=false,path=/validate/terraform.appvia.io/configurations,verbs=create;delete;update,groups="terraform.appvia.io",resources=configurations,versions=v1alpha1,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1
 // +kubebuilder:webhook:name=configurations.terraform.appvia.io,mutating=true,path=/mutate/terraform.appvia.io/configurations,verbs=create;update,groups="terraform.appvia.io",resources=configurations,versions=v1alpha1,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1
 
 // +genclient
@@ -401,6 +852,7 @@ type ConfigurationSpec struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Module",type="string",JSONPath=".spec.module"
 // +kubebuilder:printcolumn:name="Plan",type="string",JSONPath=".spec.plan.name",priority=1
+// +kubebuilder:printcolumn:name="Engine",type="string",JSONPath=".status.engine",priority=1
 // +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".spec.writeConnectionSecretToRef.name"
 // +kubebuilder:printcolumn:name="Drift Detection",type="boolean",JSONPath=".spec.enableDriftDetection"
 // +kubebuilder:printcolumn:name="Estimated",type="string",JSONPath=".status.costs.monthly"
@@ -428,6 +880,140 @@ type CostStatus struct {
 	Monthly string `json:"monthly,omitempty"`
 }
 
+// DriftMode is the action taken when drift is detected
+type DriftMode string
+
+const (
+	// DriftModeDetect only records the drift on the status
+	DriftModeDetect DriftMode = "Detect"
+	// DriftModeRemediate applies the plan when drift is detected
+	DriftModeRemediate DriftMode = "Remediate"
+	// DriftModeRequireApproval applies the plan once the remediation has been approved
+	DriftModeRequireApproval DriftMode = "RequireApproval"
+)
+
+// DriftPolicy defines how drift on the configuration is handled
+type DriftPolicy struct {
+	// Mode is the action taken when drift is detected. Detect only records the drift on the
+	// status, Remediate applies the plan to bring the resources back in line with the
+	// configuration, and RequireApproval waits for the drift approval annotation before
+	// applying the plan.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Detect;Remediate;RequireApproval
+	// +kubebuilder:default=Detect
+	Mode DriftMode `json:"mode,omitempty"`
+	// Interval is the minimum time between drift detection runs on this configuration,
+	// overriding the interval configured on the controller
+	// +kubebuilder:validation:Optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// IsValid checks the drift policy is valid
+func (d *DriftPolicy) IsValid() error {
+	switch d.Mode {
+	case "", DriftModeDetect, DriftModeRemediate, DriftModeRequireApproval:
+	default:
+		return fmt.Errorf("spec.driftPolicy.mode must be one of %s, %s or %s", DriftModeDetect, DriftModeRemediate, DriftModeRequireApproval)
+	}
+	if d.Interval != nil && d.Interval.Duration <= 0 {
+		return errors.New("spec.driftPolicy.interval must be greater than zero")
+	}
+
+	return nil
+}
+
+// MaxRunHistory is the maximum number of runs retained in the history of a configuration
+const MaxRunHistory = 20
+
+// RunReason is the reason a terraform run was triggered
+type RunReason string
+
+const (
+	// RunReasonContexts indicates the run was triggered by a change in the context values
+	RunReasonContexts RunReason = "Contexts"
+	// RunReasonDependencies indicates the run was triggered by a change in the dependency outputs
+	RunReasonDependencies RunReason = "Dependencies"
+	// RunReasonDrift indicates the run was triggered by drift detection
+	RunReasonDrift RunReason = "Drift"
+	// RunReasonOperation indicates the run was triggered by the replace or target annotations
+	RunReasonOperation RunReason = "Operation"
+	// RunReasonRetry indicates the run was triggered by the retry annotation
+	RunReasonRetry RunReason = "Retry"
+	// RunReasonSpecChange indicates the run was triggered by a change to the specification
+	RunReasonSpecChange RunReason = "SpecChange"
+)
+
+// RunResult is the outcome of a terraform run
+type RunResult string
+
+const (
+	// RunResultFailed indicates the run failed
+	RunResultFailed RunResult = "Failed"
+	// RunResultSucceeded indicates the run completed successfully
+	RunResultSucceeded RunResult = "Succeeded"
+)
+
+// RunHistory is a record of a terraform run against the configuration
+type RunHistory struct {
+	// Arguments are the additional terraform arguments the run was executed with, i.e. the
+	// replace or target operations requested
+	// +kubebuilder:validation:Optional
+	Arguments string `json:"arguments,omitempty"`
+	// CompletionTime is the time the run finished
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Changes is a summary of the resource changes planned or applied by the run
+	// +kubebuilder:validation:Optional
+	Changes *TerraformPlanChanges `json:"changes,omitempty"`
+	// CostDelta is the predicted change to the monthly cost introduced by the run
+	// +kubebuilder:validation:Optional
+	CostDelta string `json:"costDelta,omitempty"`
+	// Generation is the generation of the configuration the run was executed for
+	// +kubebuilder:validation:Required
+	Generation int64 `json:"generation"`
+	// Job is the name of the job which executed the run
+	// +kubebuilder:validation:Required
+	Job string `json:"job"`
+	// Reason is the reason the run was triggered
+	// +kubebuilder:validation:Optional
+	Reason RunReason `json:"reason,omitempty"`
+	// Result is the outcome of the run
+	// +kubebuilder:validation:Required
+	Result RunResult `json:"result"`
+	// Stage is the terraform stage executed by the run
+	// +kubebuilder:validation:Required
+	Stage string `json:"stage"`
+	// StartTime is the time the run started
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// GetRun returns the run executed by the given job, if any
+func (c *ConfigurationStatus) GetRun(job string) (*RunHistory, bool) {
+	for i := 0; i < len(c.History); i++ {
+		if c.History[i].Job == job {
+			return &c.History[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// AddRun records the run in the history, replacing any existing entry for the same job and
+// retaining at most MaxRunHistory runs
+func (c *ConfigurationStatus) AddRun(run RunHistory) {
+	if existing, found := c.GetRun(run.Job); found {
+		*existing = run
+
+		return
+	}
+	c.History = append(c.History, run)
+
+	if len(c.History) > MaxRunHistory {
+		c.History = c.History[len(c.History)-MaxRunHistory:]
+	}
+}
+
 // ResourceStatus is the status of the resources
 type ResourceStatus string
 
@@ -444,6 +1030,165 @@ const (
 	UnknownResourceStatus ResourceStatus = ""
 )
 
+// SourceStatus defines the resolved revision of the module source
+type SourceStatus struct {
+	// Digest is the sha256 digest of the module content, used to verify the module and as
+	// the key within the source cache
+	// +kubebuilder:validation:Optional
+	Digest string `json:"digest,omitempty"`
+	// Generation is the generation of the configuration the source was resolved for
+	// +kubebuilder:validation:Optional
+	Generation int64 `json:"generation,omitempty"`
+	// Module is the module source which was resolved
+	// +kubebuilder:validation:Optional
+	Module string `json:"module,omitempty"`
+	// Revision is the commit the module source resolved to for git sources, or the digest of
+	// the manifest for oci sources
+	// +kubebuilder:validation:Optional
+	Revision string `json:"revision,omitempty"`
+}
+
+// GetSource returns the resolved source if it still matches the module, else nil
+func (c *ConfigurationStatus) GetSource(module string) *SourceStatus {
+	if c.Source == nil || c.Source.Module != module || c.Source.Digest == "" {
+		return nil
+	}
+
+	return c.Source
+}
+
+// StateCommandStatus is the status of a terraform state command run against the configuration
+type StateCommandStatus struct {
+	// Command is the state command which was run, i.e. mv aws_instance.a aws_instance.b
+	// +kubebuilder:validation:Required
+	Command string `json:"command"`
+	// ID is the unique identifier of the request for the state command
+	// +kubebuilder:validation:Required
+	ID string `json:"id"`
+	// Job is the name of the job which ran the state command
+	// +kubebuilder:validation:Optional
+	Job string `json:"job,omitempty"`
+	// Result is the outcome of the state command
+	// +kubebuilder:validation:Optional
+	Result RunResult `json:"result,omitempty"`
+}
+
+// TerraformPlanStatus defines the status of the last terraform plan produced for the configuration
+type TerraformPlanStatus struct {
+	// ApprovedChecksum is the checksum of the terraform plan which was approved to be applied. An
+	// approval is bound to the plan it was given for, any later plan must be approved again
+	// +kubebuilder:validation:Optional
+	ApprovedChecksum string `json:"approvedChecksum,omitempty"`
+	// Checksum is the sha256 checksum of the terraform plan produced by the plan stage. The
+	// apply stage will refuse to apply a plan which does not match this checksum
+	// +kubebuilder:validation:Optional
+	Checksum string `json:"checksum,omitempty"`
+	// Changes is a summary of the resource changes contained in the terraform plan
+	// +kubebuilder:validation:Optional
+	Changes *TerraformPlanChanges `json:"changes,omitempty"`
+	// Contexts is a checksum of the values consumed from contexts when the terraform plan
+	// was produced
+	// +kubebuilder:validation:Optional
+	Contexts string `json:"contexts,omitempty"`
+	// Dependencies is a checksum of the outputs consumed from the dependencies when the
+	// terraform plan was produced
+	// +kubebuilder:validation:Optional
+	Dependencies string `json:"dependencies,omitempty"`
+	// Generation is the generation of the configuration the plan was produced for
+	// +kubebuilder:validation:Optional
+	Generation int64 `json:"generation,omitempty"`
+	// Job is the name of the job which produced the terraform plan
+	// +kubebuilder:validation:Optional
+	Job string `json:"job,omitempty"`
+	// Operation is a checksum of the one-shot operation, i.e. the replace and target
+	// annotations, the terraform plan was produced with
+	// +kubebuilder:validation:Optional
+	Operation string `json:"operation,omitempty"`
+}
+
+// GetContexts returns the checksum of the context values the plan was produced with
+func (t *TerraformPlanStatus) GetContexts() string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Contexts
+}
+
+// GetDependencies returns the checksum of the dependency outputs the plan was produced with
+func (t *TerraformPlanStatus) GetDependencies() string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Dependencies
+}
+
+// GetJob returns the name of the job which produced the terraform plan
+func (t *TerraformPlanStatus) GetJob() string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Job
+}
+
+// GetOperation returns the checksum of the operation the plan was produced with
+func (t *TerraformPlanStatus) GetOperation() string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Operation
+}
+
+// IsStale returns true if the terraform plan was not produced for the given generation
+func (t *TerraformPlanStatus) IsStale(generation int64) bool {
+	return t.Generation != generation
+}
+
+// TerraformPlanChanges is a summary of the resource changes in a terraform plan
+type TerraformPlanChanges struct {
+	// Create is the number of resources which will be created
+	// +kubebuilder:validation:Optional
+	Create int `json:"create"`
+	// Delete is the number of resources which will be deleted
+	// +kubebuilder:validation:Optional
+	Delete int `json:"delete"`
+	// Import is the number of existing resources which will be imported
+	// +kubebuilder:validation:Optional
+	Import int `json:"import,omitempty"`
+	// Replace is the number of resources which will be destroyed and recreated
+	// +kubebuilder:validation:Optional
+	Replace int `json:"replace"`
+	// Update is the number of resources which will be updated in place
+	// +kubebuilder:validation:Optional
+	Update int `json:"update"`
+	// Resources is a list of the resources which will be changed by the plan, limited to the
+	// first 100 resources by address
+	// +kubebuilder:validation:Optional
+	Resources []TerraformPlanResourceChange `json:"resources,omitempty"`
+	// Truncated is the number of changed resources omitted from the list of resources
+	// +kubebuilder:validation:Optional
+	Truncated int `json:"truncated,omitempty"`
+}
+
+// HasChanges returns true if the plan contains any resource changes
+func (t *TerraformPlanChanges) HasChanges() bool {
+	return t.Create+t.Delete+t.Import+t.Replace+t.Update > 0
+}
+
+// TerraformPlanResourceChange is a change to a single resource in the terraform plan
+type TerraformPlanResourceChange struct {
+	// Action is the change which will be made to the resource, i.e. create, update, delete,
+	// replace or import
+	// +kubebuilder:validation:Required
+	Action string `json:"action"`
+	// Address is the terraform address of the resource
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+}
+
 // ConfigurationRevisionStatus defines the observed state of Configuration
 type ConfigurationRevisionStatus struct {
 	// Revision is the revision number of the configuration
@@ -455,13 +1200,43 @@ type ConfigurationRevisionStatus struct {
 // +k8s:openapi-gen=true
 type ConfigurationStatus struct {
 	corev1alpha1.CommonStatus `json:",inline"`
+	// Backend is the status of the backend holding the terraform state
+	// +kubebuilder:validation:Optional
+	Backend *BackendStatus `json:"backend,omitempty"`
 	// Costs is the predicted costs of this configuration. Note this field is only populated
 	// when the integration has been configured by the administrator.
 	// +kubebuilder:validation:Optional
 	Costs *CostStatus `json:"costs,omitempty"`
+	// Contexts is a checksum of the values consumed from contexts when the configuration
+	// was last applied
+	// +kubebuilder:validation:Optional
+	Contexts string `json:"contexts,omitempty"`
+	// Dependencies is a checksum of the outputs consumed from the dependencies when the
+	// configuration was last applied
+	// +kubebuilder:validation:Optional
+	Dependencies string `json:"dependencies,omitempty"`
 	// DriftTimestamp is the timestamp of the last drift detection
 	// +kubebuilder:validation:Optional
 	DriftTimestamp string `json:"driftTimestamp,omitempty"`
+	// Engine is the engine, either terraform or tofu, which was last used to run this configuration
+	// +kubebuilder:validation:Optional
+	Engine string `json:"engine,omitempty"`
+	// History is a record of the most recent terraform runs against the configuration, ordered
+	// from oldest to newest
+	// +kubebuilder:validation:Optional
+	History []RunHistory `json:"history,omitempty"`
+	// Imported is the collection of imports from the spec which have been applied to the
+	// terraform state
+	// +kubebuilder:validation:Optional
+	Imported ImportList `json:"imported,omitempty"`
+	// Source is the revision of the module source resolved during the last terraform plan. The
+	// later stages use this revision to ensure they run against the same module code
+	// +kubebuilder:validation:Optional
+	Source *SourceStatus `json:"source,omitempty"`
+	// ResolvedTerraformVersion is the version of terraform, or opentofu, resolved from the
+	// spec.terraformVersion and the policy constraints, i.e. the image tag the jobs are run with
+	// +kubebuilder:validation:Optional
+	ResolvedTerraformVersion string `json:"resolvedTerraformVersion,omitempty"`
 	// Resources is the number of managed cloud resources which are currently under management.
 	// This field is taken from the terraform state itself.
 	// +kubebuilder:validation:Optional
@@ -469,8 +1244,15 @@ type ConfigurationStatus struct {
 	// ResourceStatus indicates the status of the resources and if the resources are insync with the
 	// configuration
 	ResourceStatus ResourceStatus `json:"resourceStatus,omitempty"`
-	// TerraformVersion is the version of terraform which was last used to run this
-	// configuration
+	// StateCommand is the status of the last terraform state command run against the configuration
+	// +kubebuilder:validation:Optional
+	StateCommand *StateCommandStatus `json:"stateCommand,omitempty"`
+	// TerraformPlan is the status of the last terraform plan produced for this configuration. This
+	// is the plan which is applied during the apply stage.
+	// +kubebuilder:validation:Optional
+	TerraformPlan *TerraformPlanStatus `json:"terraformPlan,omitempty"`
+	// TerraformVersion is the version of terraform, or opentofu, which last wrote the terraform
+	// state. This field is taken from the terraform state itself.
 	// +kubebuilder:validation:Optional
 	TerraformVersion string `json:"terraformVersion,omitempty"`
 }
@@ -568,11 +1350,121 @@ func (c *Configuration) HasApproval() bool {
 	return c.GetAnnotations()[ApplyAnnotation] == "true"
 }
 
+// IsApprovalRequired returns true if the terraform plans must be approved before being applied
+func (c *Configuration) IsApprovalRequired() bool {
+	return !c.Spec.EnableAutoApproval
+}
+
 // NeedsApproval returns true if the configuration needs approval
 func (c *Configuration) NeedsApproval() bool {
 	return c.GetAnnotations()[ApplyAnnotation] == "false"
 }
 
+// GetDriftMode returns the action taken when drift is detected, defaulting to detect only
+func (c *Configuration) GetDriftMode() DriftMode {
+	if c.Spec.DriftPolicy == nil || c.Spec.DriftPolicy.Mode == "" {
+		return DriftModeDetect
+	}
+
+	return c.Spec.DriftPolicy.Mode
+}
+
+// GetDriftInterval returns the drift interval for the configuration, or the default if not overridden
+func (c *Configuration) GetDriftInterval(fallback time.Duration) time.Duration {
+	if c.Spec.DriftPolicy == nil || c.Spec.DriftPolicy.Interval == nil || c.Spec.DriftPolicy.Interval.Duration <= 0 {
+		return fallback
+	}
+
+	return c.Spec.DriftPolicy.Interval.Duration
+}
+
+// IsDriftRemediationPending returns true if drift was found by the latest drift detection and the
+// drift policy requires it to be remediated
+func (c *Configuration) IsDriftRemediationPending() bool {
+	drift := c.GetAnnotations()[DriftAnnotation]
+
+	switch {
+	case c.GetDriftMode() == DriftModeDetect:
+		return false
+	case drift == "", drift != c.Status.DriftTimestamp:
+		return false
+	}
+
+	return c.Status.ResourceStatus == ResourcesOutOfSync
+}
+
+// IsDriftRemediationApproved returns true if the remediation of the latest drift has been approved
+func (c *Configuration) IsDriftRemediationApproved() bool {
+	switch c.GetDriftMode() {
+	case DriftModeRemediate:
+		return true
+	case DriftModeRequireApproval:
+		return c.GetAnnotations()[DriftApprovalAnnotation] == c.GetAnnotations()[DriftAnnotation]
+	}
+
+	return false
+}
+
+// GetPendingImports returns the imports which have not yet been applied to the state
+func (c *Configuration) GetPendingImports() ImportList {
+	var list ImportList
+	for _, x := range c.Spec.Imports {
+		if !c.Status.Imported.Has(x) {
+			list = append(list, x)
+		}
+	}
+
+	return list
+}
+
+// GetReplaceAddresses returns the resource addresses requested for replacement, if any
+func (c *Configuration) GetReplaceAddresses() []string {
+	return splitAddresses(c.GetAnnotations()[ReplaceAnnotation])
+}
+
+// GetTargetAddresses returns the resource or module addresses the run is limited to, if any
+func (c *Configuration) GetTargetAddresses() []string {
+	return splitAddresses(c.GetAnnotations()[TargetAnnotation])
+}
+
+// HasOperation returns true if a replace or target operation has been requested
+func (c *Configuration) HasOperation() bool {
+	return len(c.GetReplaceAddresses()) > 0 || len(c.GetTargetAddresses()) > 0
+}
+
+// GetOperationArguments returns the terraform arguments for the requested replace and target
+// operations, or an error if any of the addresses are invalid
+func (c *Configuration) GetOperationArguments() ([]string, error) {
+	var list []string
+
+	for _, address := range c.GetReplaceAddresses() {
+		if !ResourceAddressRegex.MatchString(address) {
+			return nil, fmt.Errorf("replace address %q is not a valid resource address", address)
+		}
+		list = append(list, "-replace="+address)
+	}
+	for _, address := range c.GetTargetAddresses() {
+		if !ResourceAddressRegex.MatchString(address) && !ModuleAddressRegex.MatchString(address) {
+			return nil, fmt.Errorf("target address %q is not a valid resource or module address", address)
+		}
+		list = append(list, "-target="+address)
+	}
+
+	return list, nil
+}
+
+// splitAddresses splits a comma separated list of addresses, dropping any empty entries
+func splitAddresses(value string) []string {
+	var list []string
+	for _, x := range strings.Split(value, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			list = append(list, x)
+		}
+	}
+
+	return list
+}
+
 // IsManaged returns true if the configuration is managed
 func (c *Configuration) IsManaged() bool {
 	switch {
@@ -597,11 +1489,43 @@ func (c *Configuration) GetTerraformStateSecretName() string {
 	return fmt.Sprintf("tfstate-default-%s", string(c.GetUID()))
 }
 
+// GetTerraformMigrationSecretName returns the name of the secret holding the previous backend
+// configuration while the state is migrated
+func (c *Configuration) GetTerraformMigrationSecretName() string {
+	return fmt.Sprintf("migrate-%s", string(c.GetUID()))
+}
+
+// GetTerraformStateVersionSecretName returns the name of the secret holding a version of the terraform state
+func (c *Configuration) GetTerraformStateVersionSecretName(version int) string {
+	return fmt.Sprintf("tfstate-%s-v%d", string(c.GetUID()), version)
+}
+
+// GetTerraformStateCommandSecretName returns the name of the secret holding the input and output
+// of the terraform state commands
+func (c *Configuration) GetTerraformStateCommandSecretName() string {
+	return fmt.Sprintf("statecmd-%s", string(c.GetUID()))
+}
+
+// GetTerraformPlanSecretName returns the name of the secret holding the terraform plan
+func (c *Configuration) GetTerraformPlanSecretName() string {
+	return fmt.Sprintf("tfplan-%s", string(c.GetUID()))
+}
+
+// GetTerraformLockName returns the name of the lease used to lock the terraform state
+func (c *Configuration) GetTerraformLockName() string {
+	return fmt.Sprintf("tflock-%s", string(c.GetUID()))
+}
+
 // GetTerraformPolicySecretName returns the name of the secret holding the terraform state
 func (c *Configuration) GetTerraformPolicySecretName() string {
 	return fmt.Sprintf("policy-%s", string(c.GetUID()))
 }
 
+// GetTerraformRegoSecretName returns the name of the secret holding the rego policy report
+func (c *Configuration) GetTerraformRegoSecretName() string {
+	return fmt.Sprintf("rego-%s", string(c.GetUID()))
+}
+
 // GetTerraformCostSecretName returns the name which should be used for the costs report
 func (c *Configuration) GetTerraformCostSecretName() string {
 	return fmt.Sprintf("costs-%s", string(c.GetUID()))
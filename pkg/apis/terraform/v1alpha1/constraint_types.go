@@ -18,9 +18,12 @@
 package v1alpha1
 
 import (
+	"fmt"
 	"regexp"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -36,6 +39,232 @@ type Constraints struct {
 	// labels
 	// +kubebuilder:validation:Optional
 	Checkov *PolicyConstraint `json:"checkov,omitempty"`
+	// Rego provides the ability to evaluate the terraform plan against a collection of Open
+	// Policy Agent rego modules. Deny rules block the configuration, while warn rules are
+	// reported but permitted to continue.
+	// +kubebuilder:validation:Optional
+	Rego *RegoConstraint `json:"rego,omitempty"`
+	// Costs provides the ability to place a budget on the predicted costs of the configurations.
+	// Configurations which breach the budget are blocked from applying. Note, this requires the
+	// cost integration to be enabled on the controller.
+	// +kubebuilder:validation:Optional
+	Costs *CostConstraint `json:"costs,omitempty"`
+	// Maintenance provides the ability to restrict when the selected configurations are
+	// permitted to apply changes or run drift detection. Outside of the windows any applies
+	// are deferred until the next window opens.
+	// +kubebuilder:validation:Optional
+	Maintenance *MaintenanceConstraint `json:"maintenance,omitempty"`
+	// Operations provides the ability to restrict who may request one-shot operations, such
+	// as replacing or targeting resources, on the selected configurations
+	// +kubebuilder:validation:Optional
+	Operations *OperationsConstraint `json:"operations,omitempty"`
+	// Signatures provides the ability to require the module sources of the selected
+	// configurations are signed by one of the trusted keys. Modules held in an oci registry
+	// must carry a cosign signature, while git sources must have a signed commit or tag.
+	// +kubebuilder:validation:Optional
+	Signatures *SignatureConstraint `json:"signatures,omitempty"`
+	// Versions provides the ability to constrain the terraform versions the selected
+	// configurations are permitted to run with, i.e. ">= 1.5.0, < 2.0.0"
+	// +kubebuilder:validation:Optional
+	Versions *VersionConstraint `json:"versions,omitempty"`
+}
+
+// VersionConstraint defines the terraform versions the configurations are permitted to use
+type VersionConstraint struct {
+	// Constraint is the version constraint the terraform version of the configurations must
+	// satisfy, i.e. ">= 1.5.0, < 2.0.0"
+	// +kubebuilder:validation:Required
+	Constraint string `json:"constraint"`
+	// Selector is the selector on the namespace or labels on the configuration. By leaving
+	// this field empty you are implicitly selecting all configurations.
+	// +kubebuilder:validation:Optional
+	Selector *Selector `json:"selector,omitempty"`
+}
+
+const (
+	// SignatureKeyCosign is a PEM encoded public key verifying cosign signatures on oci modules
+	SignatureKeyCosign = "cosign"
+	// SignatureKeyGPG is an armored gpg public key verifying signed git commits and tags
+	SignatureKeyGPG = "gpg"
+	// SignatureKeySSH is an ssh public key verifying signed git commits and tags
+	SignatureKeySSH = "ssh"
+)
+
+// SignatureConstraint defines the keys trusted to sign the modules of the configurations
+type SignatureConstraint struct {
+	// Keys is a collection of public keys trusted to sign the modules. A module must be
+	// signed by at least one of the keys in order to be allowed to run.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Keys []SignatureKey `json:"keys"`
+	// Selector is the selector on the namespace or labels on the configuration. By leaving
+	// this field empty you are implicitly selecting all configurations.
+	// +kubebuilder:validation:Optional
+	Selector *Selector `json:"selector,omitempty"`
+}
+
+// SignatureKey is a public key trusted to sign modules
+type SignatureKey struct {
+	// Name is the name of the key, this must be unique within the constraint
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9-_.]+$`
+	Name string `json:"name"`
+	// PublicKey is the content of the public key
+	// +kubebuilder:validation:Required
+	PublicKey string `json:"publicKey"`
+	// Type is the type of key, cosign keys are PEM encoded public keys used to verify oci
+	// modules, while gpg (armored) and ssh keys are used to verify git commits and tags
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=cosign;gpg;ssh
+	Type string `json:"type"`
+}
+
+// GetKey returns the key the public key is stored under in the configuration secret
+func (s *SignatureKey) GetKey() string {
+	return fmt.Sprintf("signature-%s", s.GetFilename())
+}
+
+// GetFilename returns the filename of the public key when mounted into the job
+func (s *SignatureKey) GetFilename() string {
+	return fmt.Sprintf("%s.%s", s.Name, s.Type)
+}
+
+// CostConstraint defines a budget on the predicted monthly costs of the configurations
+type CostConstraint struct {
+	// MaxMonthly is the maximum predicted monthly cost permitted for a single configuration
+	// +kubebuilder:validation:Optional
+	MaxMonthly *resource.Quantity `json:"maxMonthly,omitempty"`
+	// MaxMonthlyIncrease is the maximum increase in the predicted monthly cost which a single
+	// change to a configuration is permitted to introduce
+	// +kubebuilder:validation:Optional
+	MaxMonthlyIncrease *resource.Quantity `json:"maxMonthlyIncrease,omitempty"`
+	// MaxNamespaceMonthly is the maximum predicted monthly cost of all the configurations
+	// within a namespace combined
+	// +kubebuilder:validation:Optional
+	MaxNamespaceMonthly *resource.Quantity `json:"maxNamespaceMonthly,omitempty"`
+	// Selector is the selector on the namespace or labels on the configuration. By leaving this
+	// fields empty you can implicitly selecting all configurations.
+	// +kubebuilder:validation:Optional
+	Selector *Selector `json:"selector,omitempty"`
+}
+
+// RegoConstraint defines the rego policies the configurations must comply with
+type RegoConstraint struct {
+	// External is a collection of external sources containing rego modules. Each of the sources
+	// is retrieved into /run/rego/NAME and evaluated alongside the inline modules.
+	// +kubebuilder:validation:Optional
+	External []ExternalCheck `json:"external,omitempty"`
+	// Modules is a collection of inline rego modules which are evaluated against the terraform
+	// plan. Rules named deny or violation block the configuration, rules named warn are reported
+	// on the status.
+	// +kubebuilder:validation:Optional
+	Modules []RegoModule `json:"modules,omitempty"`
+	// Selector is the selector on the namespace or labels on the configuration. By leaving this
+	// fields empty you can implicitly selecting all configurations.
+	// +kubebuilder:validation:Optional
+	Selector *Selector `json:"selector,omitempty"`
+}
+
+// ExternalNames returns the name of the external sources
+func (r *RegoConstraint) ExternalNames() []string {
+	var list []string
+
+	for _, x := range r.External {
+		list = append(list, x.Name)
+	}
+
+	return list
+}
+
+// RegoModule is an inline rego module
+type RegoModule struct {
+	// Name is the name of the module, this is used as the filename when the module is
+	// evaluated and must be unique within the constraint
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Content is the rego source of the module
+	// +kubebuilder:validation:Required
+	Content string `json:"content"`
+}
+
+// GetKey returns the key the module is stored under in the configuration secret
+func (r *RegoModule) GetKey() string {
+	return fmt.Sprintf("rego-%s.rego", r.Name)
+}
+
+// MaintenanceConstraint defines the windows in which the selected configurations are permitted
+// to apply changes
+type MaintenanceConstraint struct {
+	// Selector is the selector on the namespace or labels on the configuration. By leaving this
+	// field empty you are implicitly selecting all configurations.
+	// +kubebuilder:validation:Optional
+	Selector *Selector `json:"selector,omitempty"`
+	// Windows is a collection of maintenance windows. A configuration is permitted to apply
+	// changes when any of the windows are open.
+	// +kubebuilder:validation:Required
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// MaintenanceWindow defines a recurring period in which changes are permitted
+type MaintenanceWindow struct {
+	// Name is an optional name for the window, used when reporting on the status
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// Schedule is a cron expression (minute hour day-of-month month day-of-week) defining when
+	// the window opens, i.e. "0 22 * * 1-5" opens the window at 22:00 on weekdays. Only numeric
+	// values, ranges, steps, lists and the @hourly, @daily, @weekly, @monthly and @yearly
+	// macros are supported
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+	// Duration is how long the window remains open once it has opened, i.e. 4h
+	// +kubebuilder:validation:Required
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the IANA time zone the schedule is evaluated in, i.e. Europe/London. Defaults
+	// to UTC.
+	// +kubebuilder:validation:Optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// GetLocation returns the location the schedule should be evaluated in
+func (m *MaintenanceWindow) GetLocation() (*time.Location, error) {
+	if m.TimeZone == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(m.TimeZone)
+}
+
+// OperationsConstraint restricts who may request one-shot operations, i.e. the replace and
+// target annotations, on the selected configurations
+type OperationsConstraint struct {
+	// Groups is a collection of groups whose members are permitted to request the operations
+	// +kubebuilder:validation:Optional
+	Groups []string `json:"groups,omitempty"`
+	// Selector is the selector on the namespace or labels on the configuration. By leaving
+	// this field empty you are implicitly selecting all configurations.
+	// +kubebuilder:validation:Optional
+	Selector *Selector `json:"selector,omitempty"`
+	// Users is a collection of users permitted to request the operations
+	// +kubebuilder:validation:Optional
+	Users []string `json:"users,omitempty"`
+}
+
+// IsPermitted returns true if the user or any of the groups are permitted by the constraint
+func (o *OperationsConstraint) IsPermitted(user string, groups []string) bool {
+	for _, x := range o.Users {
+		if x == user {
+			return true
+		}
+	}
+	for _, x := range o.Groups {
+		for _, group := range groups {
+			if x == group {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 // ModuleConstraint provides a collection of constraints on modules
@@ -79,6 +79,22 @@ func (c *ContextSpec) GetVariable(key string) (interface{}, bool, error) {
 	return value, found, nil
 }
 
+// GetVariableDescription returns the description of the variable, or an empty string if
+// the variable does not exist
+func (c *ContextSpec) GetVariableDescription(key string) string {
+	if !c.HasVariable(key) {
+		return ""
+	}
+
+	values := make(map[string]interface{})
+	if err := json.NewDecoder(bytes.NewReader(c.Variables[key].Raw)).Decode(&values); err != nil {
+		return ""
+	}
+	description, _ := values[ContextDescription].(string)
+
+	return description
+}
+
 // GetVariableValue returns the string value of the a variable
 func (c *ContextSpec) GetVariableValue(name string) (runtime.RawExtension, bool) {
 	if found := c.HasVariable(name); !found {
@@ -125,10 +141,58 @@ type Context struct {
 	Status ContextStatus `json:"status,omitempty"`
 }
 
+// ContextConsumer is a resource consuming a variable from the context
+type ContextConsumer struct {
+	// Kind is the kind of the resource, i.e. Configuration or CloudResource
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+	// Name is the name of the resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Namespace is the namespace of the resource
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+	// Optional indicates the resource does not require the variable to be present
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ContextVariableStatus is the usage of a variable from the context
+type ContextVariableStatus struct {
+	// Consumers is a list of resources consuming the variable
+	// +kubebuilder:validation:Optional
+	Consumers []ContextConsumer `json:"consumers,omitempty"`
+	// Name is the name of the variable
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
 // ContextStatus defines the observed state of a terraform
 // +k8s:openapi-gen=true
 type ContextStatus struct {
 	corev1alpha1.CommonStatus `json:",inline"`
+	// Unused is a list of the variables in the context which are not consumed by any resource
+	// +kubebuilder:validation:Optional
+	Unused []string `json:"unused,omitempty"`
+	// Variables is the usage of the variables consumed from the context
+	// +kubebuilder:validation:Optional
+	Variables []ContextVariableStatus `json:"variables,omitempty"`
+}
+
+// GetConsumers returns the resources consuming the variable from the context
+func (c *ContextStatus) GetConsumers(name string) []ContextConsumer {
+	for _, x := range c.Variables {
+		if x.Name == name {
+			return x.Consumers
+		}
+	}
+
+	return nil
+}
+
+// GetCommonStatus returns the common status
+func (c *Context) GetCommonStatus() *corev1alpha1.CommonStatus {
+	return &c.Status.CommonStatus
 }
 
 // GetNamespacedName returns the namespaced resource type
@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package v1alpha1
+
+const (
+	// EngineOpenTofu indicates the configuration is run using opentofu
+	EngineOpenTofu = "tofu"
+	// EngineTerraform indicates the configuration is run using hashicorp terraform
+	EngineTerraform = "terraform"
+)
+
+// GetEngine returns the engine used to run the configuration, the engine on the configuration takes
+// precedence over the provider, which in turn takes precedence over the default engine
+func (c *Configuration) GetEngine(provider *Provider, engine string) string {
+	switch {
+	case c.Spec.Engine != "":
+		return c.Spec.Engine
+	case provider != nil && provider.Spec.Engine != "":
+		return provider.Spec.Engine
+	}
+
+	return engine
+}
+
+// IsEngineSupported returns true if the engine is supported by the controller
+func IsEngineSupported(engine string) bool {
+	switch engine {
+	case EngineOpenTofu, EngineTerraform:
+		return true
+	}
+
+	return false
+}
@@ -19,6 +19,9 @@ package v1alpha1
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -55,7 +58,8 @@ var (
 	PreloadProviderLabel = "terranetes.appvia.io/preload-provider-name"
 )
 
-// ProviderType is the type of cloud
+// ProviderType is the type of cloud, i.e. the local name of the terraform provider. Providers outside
+// of the well known types below are supported by declaring the registry source on the provider
 type ProviderType string
 
 // String returns the string representation of the provider type
@@ -94,8 +98,141 @@ const (
 	SourceSecret = "secret"
 	// SourceInjected indicates the source is pod identity
 	SourceInjected = "injected"
+	// SourceFederated indicates a projected service account token is exchanged for short-lived
+	// cloud credentials via workload identity federation
+	SourceFederated = "federated"
 )
 
+const (
+	// DefaultFederatedTokenExpiration is the default expiration of the projected token in seconds
+	DefaultFederatedTokenExpiration = 3600
+	// FederatedTokenPath is the directory the projected tokens are mounted under in the jobs
+	FederatedTokenPath = "/run/federated"
+)
+
+// ProviderFederation defines the workload identity federation used to exchange a projected service
+// account token for short-lived cloud credentials. The fields required depend on the provider type;
+// aws requires the roleARN, google the workloadIdentityProvider and azurerm or azuread the clientID
+// and tenantID.
+type ProviderFederation struct {
+	// Audience is the audience of the projected service account token. When not defined this
+	// defaults to sts.amazonaws.com for aws, api://AzureADTokenExchange for azure and the
+	// workload identity provider for google.
+	// +kubebuilder:validation:Optional
+	Audience string `json:"audience,omitempty"`
+	// ClientID is the client id of the azure application or managed identity with the federated
+	// credential
+	// +kubebuilder:validation:Optional
+	ClientID string `json:"clientID,omitempty"`
+	// ExpirationSeconds is the requested duration of the projected service account token
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=600
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+	// RoleARN is the aws role assumed via AssumeRoleWithWebIdentity
+	// +kubebuilder:validation:Optional
+	RoleARN string `json:"roleARN,omitempty"`
+	// ServiceAccountEmail is an optional google service account impersonated using the federated
+	// credentials
+	// +kubebuilder:validation:Optional
+	ServiceAccountEmail string `json:"serviceAccountEmail,omitempty"`
+	// TenantID is the azure tenant of the application or managed identity
+	// +kubebuilder:validation:Optional
+	TenantID string `json:"tenantID,omitempty"`
+	// WorkloadIdentityProvider is the full resource name of the google workload identity pool
+	// provider, i.e. projects/NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER
+	// +kubebuilder:validation:Optional
+	WorkloadIdentityProvider string `json:"workloadIdentityProvider,omitempty"`
+}
+
+// IsFederated returns true if the provider exchanges a projected token for cloud credentials
+func (p *Provider) IsFederated() bool {
+	return p.Spec.Source == SourceFederated && p.Spec.Federation != nil
+}
+
+// GetFederatedTokenFile returns the path of the projected service account token within the jobs
+func (p *Provider) GetFederatedTokenFile() string {
+	return fmt.Sprintf("%s/%s/token", FederatedTokenPath, p.Name)
+}
+
+// GetFederatedCredentialsFile returns the path of the generated credentials file within the jobs, used
+// by providers which require a credentials configuration, i.e. google
+func (p *Provider) GetFederatedCredentialsFile() string {
+	return fmt.Sprintf("%s/%s/credentials.json", FederatedTokenPath, p.Name)
+}
+
+// GetFederatedCredentialsKey returns the key of the generated credentials in the job configuration secret
+func (p *Provider) GetFederatedCredentialsKey() string {
+	return fmt.Sprintf("federated-%s.json", p.Name)
+}
+
+// GetFederatedAudience returns the audience of the projected service account token
+func (p *Provider) GetFederatedAudience() string {
+	switch {
+	case p.Spec.Federation == nil:
+		return ""
+	case p.Spec.Federation.Audience != "":
+		return p.Spec.Federation.Audience
+	}
+
+	switch p.Spec.Provider {
+	case AWSProviderType:
+		return "sts.amazonaws.com"
+	case AzureProviderType, AzureActiveDirectoryProviderType:
+		return "api://AzureADTokenExchange"
+	case GCPProviderType:
+		return fmt.Sprintf("//iam.googleapis.com/%s", p.Spec.Federation.WorkloadIdentityProvider)
+	}
+
+	return ""
+}
+
+// GetFederatedTokenExpiration returns the expiration of the projected service account token
+func (p *Provider) GetFederatedTokenExpiration() int64 {
+	if p.Spec.Federation == nil || p.Spec.Federation.ExpirationSeconds == nil {
+		return DefaultFederatedTokenExpiration
+	}
+
+	return *p.Spec.Federation.ExpirationSeconds
+}
+
+// ProviderRegistry defines the source of a terraform provider within the registry
+type ProviderRegistry struct {
+	// Source is the address of the provider in the registry, i.e. cloudflare/cloudflare or
+	// registry.terraform.io/datadog/datadog
+	// +kubebuilder:validation:Required
+	Source string `json:"source"`
+	// Version is an optional version constraint for the provider, i.e. ~> 4.0
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+}
+
+// IsValid returns an error if the registry source is invalid
+func (p *ProviderRegistry) IsValid() error {
+	if p.Source == "" {
+		return errors.New("spec.registry.source: source is required")
+	}
+	parts := strings.Split(p.Source, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return errors.New("spec.registry.source: must be in the format [hostname/]namespace/type")
+	}
+	for _, x := range parts {
+		if x == "" {
+			return errors.New("spec.registry.source: must be in the format [hostname/]namespace/type")
+		}
+	}
+
+	return nil
+}
+
+// GetRegistrySource returns the registry source of the provider if defined
+func (p *Provider) GetRegistrySource() string {
+	if p.Spec.Registry == nil {
+		return ""
+	}
+
+	return p.Spec.Registry.Source
+}
+
 // PreloadConfiguration defines the definitions for preload options
 type PreloadConfiguration struct {
 	// Cluster is the name of the kubernetes cluster we use to pivot the data around
@@ -132,12 +269,28 @@ type ProviderSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Configuration *runtime.RawExtension `json:"configuration,omitempty"`
+	// Backend is a typed terraform state backend used by all configurations using this provider.
+	// The field cannot be used alongside the backendTemplate. Changing the backend of a provider
+	// requires the state of existing configurations to be migrated via tnctl state migrate.
+	// +kubebuilder:validation:Optional
+	Backend *Backend `json:"backend,omitempty"`
 	// BackendTemplate is the reference to a backend template used for the terraform
 	// state storage. This field can override the default backend template, which is supplied as
 	// a command line argument to the controller binary. The contents of the secret MUST be a
 	// single field 'backend.tf' which contains the backend template.
 	// +kubebuilder:validation:Optional
 	BackendTemplate *v1.SecretReference `json:"backendTemplate,omitempty"`
+	// Engine is the engine used to run the configurations using this provider, either terraform
+	// or tofu (OpenTofu). Configurations can override the engine, and when not set the default
+	// engine of the controller is used.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=terraform;tofu
+	Engine string `json:"engine,omitempty"`
+	// Federation defines the workload identity federation used when the provider source is
+	// 'federated'. A projected service account token is mounted into the jobs and exchanged for
+	// short-lived cloud credentials, removing the need for long-lived keys.
+	// +kubebuilder:validation:Optional
+	Federation *ProviderFederation `json:"federation,omitempty"`
 	// Job defined a custom collection of labels and annotations to be applied to all jobs
 	// which are created and 'use' this provider.
 	// +kubebuilder:validation:Optional
@@ -145,10 +298,16 @@ type ProviderSpec struct {
 	// Preload defines the configuration for the preloading of contextual data from the cloud vendor.
 	// +kubebuilder:validation:Optional
 	Preload *PreloadConfiguration `json:"preload,omitempty"`
-	// ProviderType defines the cloud provider which is being used, currently supported providers are
-	// aws, google or azurerm.
+	// ProviderType defines the terraform provider which is being used, i.e. aws, google, azurerm,
+	// or any other provider such as cloudflare when the registry source is defined.
 	// +kubebuilder:validation:Required
 	Provider ProviderType `json:"provider"`
+	// Registry defines the terraform registry source and version constraint of the provider. When
+	// defined a required_providers block is rendered for the provider, permitting the use of any
+	// provider from the registry. Note, the terraform module should not declare its own
+	// required_providers block when this is used.
+	// +kubebuilder:validation:Optional
+	Registry *ProviderRegistry `json:"registry,omitempty"`
 	// SecretRef is a reference to a kubernetes secret. This is required only when using the source: secret.
 	// The secret should include the environment variables required to by the terraform provider.
 	// +kubebuilder:validation:Optional
@@ -160,11 +319,12 @@ type ProviderSpec struct {
 	Selector *Selector `json:"selector,omitempty"`
 	// ServiceAccount is the name of a service account to use when the provider source is 'injected'. The
 	// service account should exist in the terraform controller namespace and be configure per cloud vendor
-	// requirements for pod identity.
+	// requirements for pod identity. When the source is 'federated' this optionally overrides the service
+	// account whose projected token is exchanged for the cloud credentials.
 	// +kubebuilder:validation:Optional
 	ServiceAccount *string `json:"serviceAccount,omitempty"`
 	// Source defines the type of credentials the provider is wrapper, this could be wrapping a static secret
-	// or using a managed identity. The currently supported values are secret and injected.
+	// or using a managed identity. The currently supported values are secret, injected and federated.
 	// +kubebuilder:validation:Required
 	Source SourceType `json:"source"`
 	// Summary provides a human readable description of the provider
@@ -252,6 +412,11 @@ func (p *Provider) HasBackendTemplate() bool {
 	return p.Spec.BackendTemplate != nil
 }
 
+// HasBackend returns true if the provider has a typed backend
+func (p *Provider) HasBackend() bool {
+	return p.Spec.Backend != nil
+}
+
 // IsPreloadingEnabled returns true if the provider is enabled for preloading
 func (p *Provider) IsPreloadingEnabled() bool {
 	if p.Spec.Preload != nil && ptr.Deref(p.Spec.Preload.Enabled, false) {
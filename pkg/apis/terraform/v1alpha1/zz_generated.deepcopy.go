@@ -26,6 +26,56 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Backend) DeepCopyInto(out *Backend) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPBackend)
+		**out = **in
+	}
+	if in.Postgres != nil {
+		in, out := &in.Postgres, &out.Postgres
+		*out = new(PostgresBackend)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Backend)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backend.
+func (in *Backend) DeepCopy() *Backend {
+	if in == nil {
+		return nil
+	}
+	out := new(Backend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendStatus) DeepCopyInto(out *BackendStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendStatus.
+func (in *BackendStatus) DeepCopy() *BackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudResource) DeepCopyInto(out *CloudResource) {
 	*out = *in
@@ -109,12 +159,29 @@ func (in *CloudResourceSpec) DeepCopyInto(out *CloudResourceSpec) {
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.DriftPolicy != nil {
+		in, out := &in.DriftPolicy, &out.DriftPolicy
+		*out = new(DriftPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make(DependencyList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.Plan = in.Plan
 	if in.ProviderRef != nil {
 		in, out := &in.ProviderRef, &out.ProviderRef
 		*out = new(ProviderReference)
 		**out = **in
 	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]ProviderReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.WriteConnectionSecretToRef != nil {
 		in, out := &in.WriteConnectionSecretToRef, &out.WriteConnectionSecretToRef
 		*out = new(WriteConnectionSecret)
@@ -253,6 +320,23 @@ func (in *ConfigurationSpec) DeepCopyInto(out *ConfigurationSpec) {
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.DriftPolicy != nil {
+		in, out := &in.DriftPolicy, &out.DriftPolicy
+		*out = new(DriftPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make(DependencyList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make(ImportList, len(*in))
+		copy(*out, *in)
+	}
 	if in.Plan != nil {
 		in, out := &in.Plan, &out.Plan
 		*out = new(PlanReference)
@@ -263,6 +347,11 @@ func (in *ConfigurationSpec) DeepCopyInto(out *ConfigurationSpec) {
 		*out = new(ProviderReference)
 		**out = **in
 	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]ProviderReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.WriteConnectionSecretToRef != nil {
 		in, out := &in.WriteConnectionSecretToRef, &out.WriteConnectionSecretToRef
 		*out = new(WriteConnectionSecret)
@@ -296,16 +385,48 @@ func (in *ConfigurationSpec) DeepCopy() *ConfigurationSpec {
 func (in *ConfigurationStatus) DeepCopyInto(out *ConfigurationStatus) {
 	*out = *in
 	in.CommonStatus.DeepCopyInto(&out.CommonStatus)
+	if in.Backend != nil {
+		in, out := &in.Backend, &out.Backend
+		*out = new(BackendStatus)
+		**out = **in
+	}
 	if in.Costs != nil {
 		in, out := &in.Costs, &out.Costs
 		*out = new(CostStatus)
 		**out = **in
 	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]RunHistory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Imported != nil {
+		in, out := &in.Imported, &out.Imported
+		*out = make(ImportList, len(*in))
+		copy(*out, *in)
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(SourceStatus)
+		**out = **in
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(int)
 		**out = **in
 	}
+	if in.StateCommand != nil {
+		in, out := &in.StateCommand, &out.StateCommand
+		*out = new(StateCommandStatus)
+		**out = **in
+	}
+	if in.TerraformPlan != nil {
+		in, out := &in.TerraformPlan, &out.TerraformPlan
+		*out = new(TerraformPlanStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigurationStatus.
@@ -331,6 +452,36 @@ func (in *Constraints) DeepCopyInto(out *Constraints) {
 		*out = new(PolicyConstraint)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Rego != nil {
+		in, out := &in.Rego, &out.Rego
+		*out = new(RegoConstraint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Costs != nil {
+		in, out := &in.Costs, &out.Costs
+		*out = new(CostConstraint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceConstraint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = new(OperationsConstraint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Signatures != nil {
+		in, out := &in.Signatures, &out.Signatures
+		*out = new(SignatureConstraint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = new(VersionConstraint)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Constraints.
@@ -370,6 +521,21 @@ func (in *Context) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContextConsumer) DeepCopyInto(out *ContextConsumer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextConsumer.
+func (in *ContextConsumer) DeepCopy() *ContextConsumer {
+	if in == nil {
+		return nil
+	}
+	out := new(ContextConsumer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContextList) DeepCopyInto(out *ContextList) {
 	*out = *in
@@ -428,6 +594,18 @@ func (in *ContextSpec) DeepCopy() *ContextSpec {
 func (in *ContextStatus) DeepCopyInto(out *ContextStatus) {
 	*out = *in
 	in.CommonStatus.DeepCopyInto(&out.CommonStatus)
+	if in.Unused != nil {
+		in, out := &in.Unused, &out.Unused
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]ContextVariableStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextStatus.
@@ -440,6 +618,61 @@ func (in *ContextStatus) DeepCopy() *ContextStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContextVariableStatus) DeepCopyInto(out *ContextVariableStatus) {
+	*out = *in
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]ContextConsumer, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextVariableStatus.
+func (in *ContextVariableStatus) DeepCopy() *ContextVariableStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContextVariableStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CostConstraint) DeepCopyInto(out *CostConstraint) {
+	*out = *in
+	if in.MaxMonthly != nil {
+		in, out := &in.MaxMonthly, &out.MaxMonthly
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxMonthlyIncrease != nil {
+		in, out := &in.MaxMonthlyIncrease, &out.MaxMonthlyIncrease
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxNamespaceMonthly != nil {
+		in, out := &in.MaxNamespaceMonthly, &out.MaxNamespaceMonthly
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CostConstraint.
+func (in *CostConstraint) DeepCopy() *CostConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(CostConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CostStatus) DeepCopyInto(out *CostStatus) {
 	*out = *in
@@ -502,6 +735,82 @@ func (in *DefaultVariablesSelector) DeepCopy() *DefaultVariablesSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dependency) DeepCopyInto(out *Dependency) {
+	*out = *in
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]DependencyOutput, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependency.
+func (in *Dependency) DeepCopy() *Dependency {
+	if in == nil {
+		return nil
+	}
+	out := new(Dependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in DependencyList) DeepCopyInto(out *DependencyList) {
+	{
+		in := &in
+		*out = make(DependencyList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyList.
+func (in DependencyList) DeepCopy() DependencyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyOutput) DeepCopyInto(out *DependencyOutput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyOutput.
+func (in *DependencyOutput) DeepCopy() *DependencyOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftPolicy) DeepCopyInto(out *DriftPolicy) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftPolicy.
+func (in *DriftPolicy) DeepCopy() *DriftPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalCheck) DeepCopyInto(out *ExternalCheck) {
 	*out = *in
@@ -542,6 +851,55 @@ func (in *ExternalSource) DeepCopy() *ExternalSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPBackend) DeepCopyInto(out *HTTPBackend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPBackend.
+func (in *HTTPBackend) DeepCopy() *HTTPBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Import) DeepCopyInto(out *Import) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Import.
+func (in *Import) DeepCopy() *Import {
+	if in == nil {
+		return nil
+	}
+	out := new(Import)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ImportList) DeepCopyInto(out *ImportList) {
+	{
+		in := &in
+		*out = make(ImportList, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportList.
+func (in ImportList) DeepCopy() ImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobMetadata) DeepCopyInto(out *JobMetadata) {
 	*out = *in
@@ -571,6 +929,47 @@ func (in *JobMetadata) DeepCopy() *JobMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceConstraint) DeepCopyInto(out *MaintenanceConstraint) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceConstraint.
+func (in *MaintenanceConstraint) DeepCopy() *MaintenanceConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleConstraint) DeepCopyInto(out *ModuleConstraint) {
 	*out = *in
@@ -596,6 +995,36 @@ func (in *ModuleConstraint) DeepCopy() *ModuleConstraint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationsConstraint) DeepCopyInto(out *OperationsConstraint) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationsConstraint.
+func (in *OperationsConstraint) DeepCopy() *OperationsConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationsConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Plan) DeepCopyInto(out *Plan) {
 	*out = *in
@@ -866,6 +1295,21 @@ func (in *PolicyStatus) DeepCopy() *PolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackend) DeepCopyInto(out *PostgresBackend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackend.
+func (in *PostgresBackend) DeepCopy() *PostgresBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreloadConfiguration) DeepCopyInto(out *PreloadConfiguration) {
 	*out = *in
@@ -918,6 +1362,26 @@ func (in *Provider) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderFederation) DeepCopyInto(out *ProviderFederation) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderFederation.
+func (in *ProviderFederation) DeepCopy() *ProviderFederation {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderFederation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderList) DeepCopyInto(out *ProviderList) {
 	*out = *in
@@ -965,6 +1429,21 @@ func (in *ProviderReference) DeepCopy() *ProviderReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderRegistry) DeepCopyInto(out *ProviderRegistry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderRegistry.
+func (in *ProviderRegistry) DeepCopy() *ProviderRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
 	*out = *in
@@ -973,11 +1452,21 @@ func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
 		*out = new(runtime.RawExtension)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Backend != nil {
+		in, out := &in.Backend, &out.Backend
+		*out = new(Backend)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BackendTemplate != nil {
 		in, out := &in.BackendTemplate, &out.BackendTemplate
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(ProviderFederation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Job != nil {
 		in, out := &in.Job, &out.Job
 		*out = new(JobMetadata)
@@ -988,6 +1477,11 @@ func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
 		*out = new(PreloadConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(ProviderRegistry)
+		**out = **in
+	}
 	if in.SecretRef != nil {
 		in, out := &in.SecretRef, &out.SecretRef
 		*out = new(v1.SecretReference)
@@ -1035,6 +1529,53 @@ func (in *ProviderStatus) DeepCopy() *ProviderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegoConstraint) DeepCopyInto(out *RegoConstraint) {
+	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = make([]ExternalCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]RegoModule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegoConstraint.
+func (in *RegoConstraint) DeepCopy() *RegoConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(RegoConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegoModule) DeepCopyInto(out *RegoModule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegoModule.
+func (in *RegoModule) DeepCopy() *RegoModule {
+	if in == nil {
+		return nil
+	}
+	out := new(RegoModule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Revision) DeepCopyInto(out *Revision) {
 	*out = *in
@@ -1266,6 +1807,49 @@ func (in *RevisionTerranetesDependency) DeepCopy() *RevisionTerranetesDependency
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunHistory) DeepCopyInto(out *RunHistory) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = new(TerraformPlanChanges)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunHistory.
+func (in *RunHistory) DeepCopy() *RunHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(RunHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Backend) DeepCopyInto(out *S3Backend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Backend.
+func (in *S3Backend) DeepCopy() *S3Backend {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Backend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -1291,6 +1875,151 @@ func (in *Selector) DeepCopy() *Selector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureConstraint) DeepCopyInto(out *SignatureConstraint) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]SignatureKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureConstraint.
+func (in *SignatureConstraint) DeepCopy() *SignatureConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureKey) DeepCopyInto(out *SignatureKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureKey.
+func (in *SignatureKey) DeepCopy() *SignatureKey {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceStatus) DeepCopyInto(out *SourceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceStatus.
+func (in *SourceStatus) DeepCopy() *SourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateCommand) DeepCopyInto(out *StateCommand) {
+	*out = *in
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateCommand.
+func (in *StateCommand) DeepCopy() *StateCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(StateCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateCommandStatus) DeepCopyInto(out *StateCommandStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateCommandStatus.
+func (in *StateCommandStatus) DeepCopy() *StateCommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StateCommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TerraformPlanChanges) DeepCopyInto(out *TerraformPlanChanges) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TerraformPlanResourceChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerraformPlanChanges.
+func (in *TerraformPlanChanges) DeepCopy() *TerraformPlanChanges {
+	if in == nil {
+		return nil
+	}
+	out := new(TerraformPlanChanges)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TerraformPlanResourceChange) DeepCopyInto(out *TerraformPlanResourceChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerraformPlanResourceChange.
+func (in *TerraformPlanResourceChange) DeepCopy() *TerraformPlanResourceChange {
+	if in == nil {
+		return nil
+	}
+	out := new(TerraformPlanResourceChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TerraformPlanStatus) DeepCopyInto(out *TerraformPlanStatus) {
+	*out = *in
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = new(TerraformPlanChanges)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerraformPlanStatus.
+func (in *TerraformPlanStatus) DeepCopy() *TerraformPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TerraformPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ValueFromList) DeepCopyInto(out *ValueFromList) {
 	{
@@ -1337,6 +2066,26 @@ func (in *ValueFromSource) DeepCopy() *ValueFromSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionConstraint) DeepCopyInto(out *VersionConstraint) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(Selector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionConstraint.
+func (in *VersionConstraint) DeepCopy() *VersionConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WriteConnectionSecret) DeepCopyInto(out *WriteConnectionSecret) {
 	*out = *in
@@ -19,6 +19,7 @@ package apiserver
 
 import (
 	"bufio"
+	"crypto/subtle"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +27,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,6 +36,8 @@ import (
 	"github.com/appvia/terranetes-controller/pkg/utils"
 	"github.com/appvia/terranetes-controller/pkg/utils/filters"
 	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
 )
 
 var sanitizeRegEx = regexp.MustCompile(`^[a-zA-Z0-9\-\.\:]{1,64}$`)
@@ -90,6 +94,29 @@ func (s *Server) handleBuilds(w http.ResponseWriter, req *http.Request) {
 	}
 	log.WithFields(fields).Debug("received request for builds")
 
+	key := logstore.Key{
+		Generation: values["generation"],
+		Name:       values["name"],
+		Namespace:  values["namespace"],
+		Stage:      values["stage"],
+		UID:        values["uid"],
+	}
+
+	// @step: the caller can request only the archived copy of the logs
+	if req.URL.Query().Get("archived") == "true" {
+		content, found := s.findArchivedLogs(req, key)
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("[error] no archived logs found for the build\n"))
+
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(content)
+
+		return
+	}
+
 	labels := []string{
 		terraformv1alpha1.ConfigurationGenerationLabel + "=" + values["generation"],
 		terraformv1alpha1.ConfigurationNameLabel + "=" + values["name"],
@@ -99,6 +126,7 @@ func (s *Server) handleBuilds(w http.ResponseWriter, req *http.Request) {
 	}
 
 	var pod *v1.Pod
+	var archived []byte
 
 	// @step: try and find the pod running the terraform job: We have to assume also
 	// the pods hasn't been scheduled yet
@@ -124,6 +152,13 @@ func (s *Server) handleBuilds(w http.ResponseWriter, req *http.Request) {
 		if len(list.Items) == 0 {
 			log.WithFields(fields).Warn("no jobs found")
 
+			// @note: the job may have been garbage collected, fall back to the archived logs
+			if content, found := s.findArchivedLogs(req, key); found {
+				archived = content
+
+				return true, nil
+			}
+
 			return false, nil
 		}
 
@@ -170,6 +205,15 @@ func (s *Server) handleBuilds(w http.ResponseWriter, req *http.Request) {
 
 		return
 	}
+	if archived != nil {
+		log.WithFields(fields).Debug("serving the archived logs")
+
+		w.Write([]byte("\n[info] job no longer exists, showing the archived logs\n"))
+		w.Write(archived)
+		w.Write([]byte("[build] completed\n"))
+
+		return
+	}
 	log.WithFields(fields).WithField("pod", pod.Name).Debug("found the pod")
 
 	err = func() error {
@@ -210,3 +254,87 @@ func (s *Server) handleBuilds(w http.ResponseWriter, req *http.Request) {
 
 	w.Write([]byte("[build] completed\n"))
 }
+
+// findArchivedLogs returns the archived logs for the build if the store is enabled
+func (s *Server) findArchivedLogs(req *http.Request, key logstore.Key) ([]byte, bool) {
+	if s.LogStore == nil {
+		return nil, false
+	}
+
+	content, found, err := s.LogStore.Get(req.Context(), key)
+	if err != nil {
+		log.WithError(err).WithField("key", key.String()).Error("failed to retrieve the archived logs")
+
+		return nil, false
+	}
+
+	return content, found
+}
+
+// withSourceToken wraps the handler, refusing any request which does not present the token shared
+// with the jobs, ensuring only the jobs can access the source cache
+func (s *Server) withSourceToken(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		header := req.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+
+		if s.SourceCacheToken == "" || token == header ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(s.SourceCacheToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		handler(w, req)
+	}
+}
+
+// handleGetSource is http handler used by the jobs to retrieve a cached module source
+//
+//nolint:errcheck
+func (s *Server) handleGetSource(w http.ResponseWriter, req *http.Request) {
+	digest := mux.Vars(req)["digest"]
+	if !sources.IsDigest(digest) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	reader, found, err := s.SourceCache.Get(digest)
+	if err != nil {
+		log.WithError(err).WithField("digest", digest).Error("failed to retrieve the cached source")
+
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", "application/gzip")
+	io.Copy(w, reader)
+}
+
+// handlePutSource is http handler used by the jobs to store a module source in the cache
+func (s *Server) handlePutSource(w http.ResponseWriter, req *http.Request) {
+	digest := mux.Vars(req)["digest"]
+	if !sources.IsDigest(digest) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if err := s.SourceCache.Put(digest, http.MaxBytesReader(w, req.Body, sources.MaxArchiveSize)); err != nil {
+		log.WithError(err).WithField("digest", digest).Error("failed to cache the source")
+
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
+)
+
+const buildsQuery = "/v1/builds/apps/bucket/logs?generation=1&name=bucket&namespace=apps&stage=plan&uid=1234"
+
+func newTestLogStore(t *testing.T) logstore.Store {
+	store := logstore.NewKubernetesStore(fake.NewClientBuilder().WithScheme(schema.GetScheme()).Build(), "terraform-system")
+	key := logstore.Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "plan"}
+	require.NoError(t, store.Put(context.Background(), key, []byte("archived logs\n")))
+
+	return store
+}
+
+func TestBuildsHandlerInvalidInput(t *testing.T) {
+	svc := &Server{Client: k8sfake.NewSimpleClientset(), Namespace: "terraform-system"}
+	req := httptest.NewRequest(http.MethodGet, "/v1/builds/apps/bucket/logs?generation=1", nil)
+	w := httptest.NewRecorder()
+
+	svc.handleBuilds(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+func TestBuildsHandlerArchivedNoStore(t *testing.T) {
+	svc := &Server{Client: k8sfake.NewSimpleClientset(), Namespace: "terraform-system"}
+	req := httptest.NewRequest(http.MethodGet, buildsQuery+"&archived=true", nil)
+	w := httptest.NewRecorder()
+
+	svc.handleBuilds(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	assert.Equal(t, "[error] no archived logs found for the build\n", w.Body.String())
+}
+
+func TestBuildsHandlerArchived(t *testing.T) {
+	svc := &Server{Client: k8sfake.NewSimpleClientset(), LogStore: newTestLogStore(t), Namespace: "terraform-system"}
+	req := httptest.NewRequest(http.MethodGet, buildsQuery+"&archived=true", nil)
+	w := httptest.NewRecorder()
+
+	svc.handleBuilds(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, "archived logs\n", w.Body.String())
+}
+
+func TestBuildsHandlerFallbackToArchive(t *testing.T) {
+	svc := &Server{Client: k8sfake.NewSimpleClientset(), LogStore: newTestLogStore(t), Namespace: "terraform-system"}
+	req := httptest.NewRequest(http.MethodGet, buildsQuery, nil)
+	w := httptest.NewRecorder()
+
+	svc.handleBuilds(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "[info] job no longer exists, showing the archived logs\narchived logs\n[build] completed\n")
+}
+
+// newTestSourceServer returns an api server with the source cache enabled
+func newTestSourceServer(t *testing.T) http.Handler {
+	cache, err := sources.NewCache(t.TempDir(), sources.MaxArchiveSize)
+	require.NoError(t, err)
+
+	return (&Server{
+		Client:           k8sfake.NewSimpleClientset(),
+		Namespace:        "terraform-system",
+		SourceCache:      cache,
+		SourceCacheToken: "token",
+	}).Serve()
+}
+
+// newSourceRequest returns a request to the source cache presenting the token
+func newSourceRequest(method, path string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Authorization", "Bearer token")
+
+	return req
+}
+
+// newTestModule returns the archive and digest of a test module
+func newTestModule(t *testing.T) ([]byte, string) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# module\n"), 0600))
+
+	digest, err := sources.Digest(dir)
+	require.NoError(t, err)
+	encoded := &bytes.Buffer{}
+	require.NoError(t, sources.Archive(dir, encoded))
+
+	return encoded.Bytes(), digest
+}
+
+func TestSourcesCacheDisabled(t *testing.T) {
+	handler := (&Server{Client: k8sfake.NewSimpleClientset(), Namespace: "terraform-system"}).Serve()
+	_, digest := newTestModule(t)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodGet, "/v1/sources/"+digest, nil))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestSourcesInvalidDigest(t *testing.T) {
+	handler := newTestSourceServer(t)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newSourceRequest(method, "/v1/sources/sha256:invalid", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode, "method: %s", method)
+	}
+}
+
+func TestSourcesNotCached(t *testing.T) {
+	handler := newTestSourceServer(t)
+	_, digest := newTestModule(t)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodGet, "/v1/sources/"+digest, nil))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestSourcesPutAndGet(t *testing.T) {
+	handler := newTestSourceServer(t)
+	archive, digest := newTestModule(t)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodPut, "/v1/sources/"+digest, bytes.NewReader(archive)))
+	assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodGet, "/v1/sources/"+digest, nil))
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, "application/gzip", w.Result().Header.Get("Content-Type"))
+	assert.Equal(t, archive, w.Body.Bytes())
+}
+
+func TestSourcesPutDigestMismatch(t *testing.T) {
+	handler := newTestSourceServer(t)
+	archive, _ := newTestModule(t)
+	digest := sources.DigestPrefix + strings.Repeat("a", 64)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodPut, "/v1/sources/"+digest, bytes.NewReader(archive)))
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodGet, "/v1/sources/"+digest, nil))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestSourcesUnauthorized(t *testing.T) {
+	handler := newTestSourceServer(t)
+	archive, digest := newTestModule(t)
+
+	for _, token := range []string{"", "Bearer invalid", "token"} {
+		for _, method := range []string{http.MethodGet, http.MethodPut} {
+			req := httptest.NewRequest(method, "/v1/sources/"+digest, bytes.NewReader(archive))
+			if token != "" {
+				req.Header.Set("Authorization", token)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode, "method: %s, token: %q", method, token)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSourceRequest(http.MethodGet, "/v1/sources/"+digest, nil))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
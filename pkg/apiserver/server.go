@@ -25,14 +25,22 @@ import (
 
 	"github.com/appvia/terranetes-controller/pkg/apiserver/logging"
 	"github.com/appvia/terranetes-controller/pkg/apiserver/recovery"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
 )
 
 // Server is the api server
 type Server struct {
 	// Client is the controller-runtime client
 	Client kubernetes.Interface
+	// LogStore is the store holding the archived logs, if enabled
+	LogStore logstore.Store
 	// Namespace is the kubernetes namespace where the jobs are run
 	Namespace string
+	// SourceCache is the cache holding the module sources, if enabled
+	SourceCache *sources.Cache
+	// SourceCacheToken is the bearer token the jobs must present to access the source cache
+	SourceCacheToken string
 }
 
 // Serve returns the http handler: is externally facing and called from the user namespace
@@ -44,6 +52,10 @@ func (s *Server) Serve() http.Handler {
 
 	router.HandleFunc("/healthz", s.handleHealth).Methods(http.MethodGet)
 	router.HandleFunc("/v1/builds/{namespace}/{name}/logs", s.handleBuilds).Methods(http.MethodGet)
+	if s.SourceCache != nil {
+		router.HandleFunc("/v1/sources/{digest}", s.withSourceToken(s.handleGetSource)).Methods(http.MethodGet)
+		router.HandleFunc("/v1/sources/{digest}", s.withSourceToken(s.handlePutSource)).Methods(http.MethodPut)
+	}
 
 	return router
 }
@@ -27,11 +27,16 @@ spec:
     spec:
       # https://github.com/kubernetes/kubernetes/issues/74848
       restartPolicy: Never
-      {{- if eq .Provider.Source "injected" }}
-      serviceAccountName: {{ .Provider.ServiceAccount }}
-      {{- else }}
-      serviceAccountName: {{ .ServiceAccount }}
+      {{- $serviceAccount := .ServiceAccount }}
+      {{- range .Providers }}
+      {{- if or (eq .Source "injected") (and (eq .Source "federated") .ServiceAccount) }}
+      {{- $serviceAccount = .ServiceAccount }}
       {{- end }}
+      {{- end }}
+      {{- if or (eq .Provider.Source "injected") (and (eq .Provider.Source "federated") .Provider.ServiceAccount) }}
+      {{- $serviceAccount = .Provider.ServiceAccount }}
+      {{- end }}
+      serviceAccountName: {{ $serviceAccount }}
       securityContext:
         runAsUser: 65534
         runAsGroup: 65534
@@ -53,10 +58,54 @@ spec:
                 path: backend.tf
               - key: provider.tf
                 path: provider.tf
+              {{- if and .EnableImports (or (eq .Stage "plan") (eq .Stage "apply")) }}
+              - key: imports.tf
+                path: imports.tf
+              {{- end }}
               {{- if .EnableVariables }}
               - key: variables.tfvars.json
                 path: variables.tfvars.json
               {{- end }}
+        {{- if eq .Stage "apply" }}
+        # Contains the terraform plan produced and approved during the plan stage
+        - name: plan
+          secret:
+            secretName: {{ .Secrets.TerraformPlan }}
+            optional: false
+            items:
+              - key: plan.out
+                path: plan.out
+        {{- end }}
+        {{- if eq .Stage "migrate" }}
+        # Contains the backend configuration the state is being migrated from
+        - name: migrate
+          secret:
+            secretName: {{ .Secrets.Migration }}
+            optional: false
+            items:
+              - key: backend.tf
+                path: backend.tf
+        {{- end }}
+        {{- if eq .Stage "restore" }}
+        # Contains the version of the terraform state being restored
+        - name: restore
+          secret:
+            secretName: {{ .Secrets.StateVersion }}
+            optional: false
+            items:
+              - key: tfstate
+                path: tfstate
+        {{- end }}
+        {{- if and (eq .Stage "state") (eq .StateCommand.Name "push") }}
+        # Contains the terraform state being pushed by the state command
+        - name: push
+          secret:
+            secretName: {{ .Secrets.StateCommand }}
+            optional: false
+            items:
+              - key: push
+                path: tfstate.json
+        {{- end }}
         {{- if and (.Policy) (not .Policy.Source) (eq .Stage "plan") }}
         - name: checkov
           secret :
@@ -66,9 +115,50 @@ spec:
               - key: checkov.yaml
                 path: checkov.yaml
         {{- end }}
+        {{- if and (.Rego) (.Rego.Modules) (eq .Stage "plan") }}
+        # Contains the inline rego modules defined in the matching policy
+        - name: rego
+          secret:
+            secretName: {{ .Secrets.Config }}
+            optional: false
+            items:
+              {{- range .Rego.Modules }}
+              - key: {{ .GetKey }}
+                path: {{ .Name }}.rego
+              {{- end }}
+        {{- end }}
+        {{- if .Signatures }}
+        # Contains the public keys trusted to sign the module source
+        - name: signatures
+          secret:
+            secretName: {{ .Secrets.Config }}
+            optional: false
+            items:
+              {{- range .Signatures }}
+              - key: {{ .GetKey }}
+                path: {{ .GetFilename }}
+              {{- end }}
+        {{- end }}
+        {{- range $index, $federated := .Federated }}
+        # Contains the projected token exchanged for the {{ .Name }} provider credentials
+        - name: federated-{{ $index }}
+          projected:
+            sources:
+              - serviceAccountToken:
+                  audience: "{{ .Audience }}"
+                  expirationSeconds: {{ .ExpirationSeconds }}
+                  path: token
+              {{- if .CredentialsKey }}
+              - secret:
+                  name: {{ $.Secrets.Config }}
+                  items:
+                    - key: {{ .CredentialsKey }}
+                      path: credentials.json
+              {{- end }}
+        {{- end }}
 
       initContainers:
-        - name: setup
+        - name: {{ .SetupContainerName }}
           image: {{ .Images.Executor }}
           imagePullPolicy: {{ .ImagePullPolicy }}
           command:
@@ -79,10 +169,28 @@ spec:
             - --command=/bin/mkdir -p /run/steps
             - --command=/bin/cp /run/config/* /data
             - --command=/bin/cp /bin/step /run/bin/step
-            - --command=/bin/source --dest=/data --source={{ .Configuration.Module }}
+            {{- if eq .Stage "migrate" }}
+            - --command=/bin/cp /data/backend.tf /run/backend.tf
+            - --command=/bin/cp /run/migrate/backend.tf /data/backend.tf
+            {{- else if or (eq .Stage "restore") (eq .Stage "state") }}
+            {{- /* the state is restored or changed without the module source */}}
+            {{- else if eq .Stage "plan" }}
+            - --command=/bin/source --dest=/data --source={{ .Configuration.Module }} --revision-file=/run/source.json{{ if .SourceCache }} --cache-url={{ .SourceCache }}{{ end }}{{ if .Signatures }} --signature-keys=/run/signatures{{ end }}
+            {{- else if .Source }}
+            - --command=/bin/source --dest=/data --source={{ .Configuration.Module }} --digest={{ .Source.Digest }}{{ if .Source.Revision }} --revision={{ .Source.Revision }}{{ end }}{{ if .SourceCache }} --cache-url={{ .SourceCache }}{{ end }}{{ if .Signatures }} --signature-keys=/run/signatures{{ end }}
+            {{- else }}
+            - --command=/bin/source --dest=/data --source={{ .Configuration.Module }}{{ if .Signatures }} --signature-keys=/run/signatures{{ end }}
+            {{- end }}
           env:
             - name: HOME
               value: /data
+            {{- if .SourceCache }}
+            - name: SOURCE_CACHE_TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: {{ .Secrets.SourceCache }}
+                  key: {{ .Secrets.SourceCacheKey }}
+            {{- end }}
           envFrom:
           {{- if .Secrets.Config }}
             - secretRef:
@@ -107,12 +215,22 @@ spec:
               mountPath: /run
             - name: source
               mountPath: /data
+            {{- if .Signatures }}
+            - name: signatures
+              mountPath: /run/signatures
+              readOnly: true
+            {{- end }}
+            {{- if eq .Stage "migrate" }}
+            - name: migrate
+              mountPath: /run/migrate
+              readOnly: true
+            {{- end }}
 
         - name: init
           image: {{ .Images.Terraform }}
           workingDir: /data
           command:
-            - /bin/terraform
+            - {{ .TerraformBinary }}
           args:
             - init
           envFrom:
@@ -173,6 +291,31 @@ spec:
         {{- end }}
         {{- end }}
 
+        {{- if and (.Rego) (eq .Stage "plan") }}
+        {{- $image := .Images.Executor }}
+        {{- $imagePullPolicy := .ImagePullPolicy }}
+        {{- range .Rego.External }}
+        - name: rego-external-{{ .Name }}
+          image: {{ $image }}
+          imagePullPolicy: {{ $imagePullPolicy }}
+          workingDir: /run
+          command:
+            - /run/bin/step
+          args:
+            - --comment=Retrieve external rego source for {{ .Name }}
+            - --command=/bin/mkdir -p /run/rego
+            - --command=/bin/source --dest=/run/rego/{{ .Name }} --source={{ .URL }}
+          envFrom:
+          {{- if and (.SecretRef) (.SecretRef.Name) }}
+            - secretRef:
+                name: {{ .SecretRef.Name }}
+          {{- end }}
+          volumeMounts:
+            - name: run
+              mountPath: /run
+        {{- end }}
+        {{- end }}
+
       containers:
       - name: {{ .TerraformContainerName }}
         image: {{ .Images.Terraform }}
@@ -182,22 +325,73 @@ spec:
           - /run/bin/step
         args:
           - --comment=Executing Terraform
+          - --namespace=$(KUBE_NAMESPACE)
+          - --lock=$(TERRAFORM_LOCK_NAME)
+          {{- template "archive" . }}
           {{- if eq .Stage "plan" }}
-          - --command=/bin/terraform plan {{ .TerraformArguments }} -out=/run/plan.out -lock=false
-          - --command=/bin/terraform show -json /run/plan.out > /run/plan.json
+          - {{ printf "--command=%s plan %s -out=/run/plan.out -lock=false" .TerraformBinary .TerraformArguments | quote }}
+          - --command={{ .TerraformBinary }} show -json /run/plan.out > /run/plan.json
+          - --command=/run/bin/step plan-summary --plan=/run/plan.json --output=/run/plan-changes.json
+          - --upload=$(TERRAFORM_PLAN_NAME)=/run/plan.out
+          - --upload=$(TERRAFORM_PLAN_NAME)=/run/plan-changes.json
+          - --upload=$(TERRAFORM_PLAN_NAME)=/run/source.json
           {{- end }}
           {{- if eq .Stage "apply" }}
-          - --command=/bin/terraform apply {{ .TerraformArguments }} -auto-approve -lock=false
+          - --command=/bin/echo "{{ .Plan.Checksum }}  /run/plan/plan.out" | /usr/bin/sha256sum -c
+          - --command={{ .TerraformBinary }} apply -auto-approve -lock=false /run/plan/plan.out
           {{- if .SaveTerraformState }}
-          - --command=/bin/terraform state pull > /run/tfstate
+          - --command={{ .TerraformBinary }} state pull > /run/tfstate
           - --command=/bin/gzip /run/tfstate
           - --command=/bin/mv /run/tfstate.gz /run/tfstate
-          - --namespace=$(KUBE_NAMESPACE)
           - --upload=$(TERRAFORM_STATE_NAME)=/run/tfstate
           {{- end }}
           {{- end }}
+          {{- if eq .Stage "migrate" }}
+          - --command=/bin/cp /run/backend.tf /data/backend.tf
+          - --command={{ .TerraformBinary }} init -migrate-state -force-copy -input=false
+          {{- if .SaveTerraformState }}
+          - --command={{ .TerraformBinary }} state pull > /run/tfstate
+          - --command=/bin/gzip /run/tfstate
+          - --command=/bin/mv /run/tfstate.gz /run/tfstate
+          - --upload=$(TERRAFORM_STATE_NAME)=/run/tfstate
+          {{- end }}
+          {{- end }}
+          {{- if eq .Stage "restore" }}
+          - --command=/bin/gzip -dc /run/restore/tfstate > /run/tfstate.json
+          - --command={{ .TerraformBinary }} state push -lock=false -force /run/tfstate.json
+          {{- if .SaveTerraformState }}
+          - --command={{ .TerraformBinary }} state pull > /run/tfstate
+          - --command=/bin/gzip /run/tfstate
+          - --command=/bin/mv /run/tfstate.gz /run/tfstate
+          - --upload=$(TERRAFORM_STATE_NAME)=/run/tfstate
+          {{- end }}
+          {{- end }}
+          {{- if eq .Stage "state" }}
+          {{- if .StateCommand.Mutating }}
+          - --command={{ .TerraformBinary }} state pull > /run/snapshot
+          {{- end }}
+          {{- if eq .StateCommand.Name "pull" }}
+          - --command={{ .TerraformBinary }} state pull > /run/output
+          {{- else if eq .StateCommand.Name "show" }}
+          - {{ printf "--command=%s state show -no-color %s > /run/output" .TerraformBinary .StateCommand.Arguments | quote }}
+          {{- else if eq .StateCommand.Name "push" }}
+          - --command={{ .TerraformBinary }} state push -lock=false {{ .StateCommand.Arguments }} /run/push/tfstate.json > /run/output
+          {{- else }}
+          - {{ printf "--command=%s state %s -lock=false %s > /run/output" .TerraformBinary .StateCommand.Name .StateCommand.Arguments | quote }}
+          {{- end }}
+          - --upload=$(TERRAFORM_STATE_COMMAND_NAME)=/run/output
+          {{- if .StateCommand.Mutating }}
+          - --upload=$(TERRAFORM_STATE_COMMAND_NAME)=/run/snapshot
+          {{- if .SaveTerraformState }}
+          - --command={{ .TerraformBinary }} state pull > /run/tfstate
+          - --command=/bin/gzip /run/tfstate
+          - --command=/bin/mv /run/tfstate.gz /run/tfstate
+          - --upload=$(TERRAFORM_STATE_NAME)=/run/tfstate
+          {{- end }}
+          {{- end }}
+          {{- end }}
           {{- if eq .Stage "destroy" }}
-          - --command=/bin/terraform destroy {{ .TerraformArguments }} -auto-approve
+          - --command={{ .TerraformBinary }} destroy {{ .TerraformArguments }} -auto-approve
           {{- end }}
           - --on-error=/run/steps/terraform.failed
           - --on-success=/run/steps/terraform.complete
@@ -212,13 +406,27 @@ spec:
             valueFrom:
               fieldRef:
                 fieldPath: metadata.namespace
+          - name: TERRAFORM_LOCK_NAME
+            value: {{ .Lock }}
+          - name: TERRAFORM_PLAN_NAME
+            value: {{ .Secrets.TerraformPlan }}
           - name: TERRAFORM_STATE_NAME
             value: {{ .Secrets.TerraformState }}
+          {{- if eq .Stage "state" }}
+          - name: TERRAFORM_STATE_COMMAND_NAME
+            value: {{ .Secrets.StateCommand }}
+          {{- end }}
         envFrom:
         {{- if eq .Provider.Source "secret" }}
           - secretRef:
               name: {{ .Provider.SecretRef.Name }}
         {{- end }}
+        {{- range .Providers }}
+        {{- if eq .Source "secret" }}
+          - secretRef:
+              name: {{ .SecretRef.Name }}
+        {{- end }}
+        {{- end }}
         {{- range .ExecutorSecrets }}
           - secretRef:
               name: {{ . }}
@@ -244,6 +452,26 @@ spec:
             mountPath: /run
           - name: source
             mountPath: /data
+          {{- if eq .Stage "apply" }}
+          - name: plan
+            mountPath: /run/plan
+            readOnly: true
+          {{- end }}
+          {{- if eq .Stage "restore" }}
+          - name: restore
+            mountPath: /run/restore
+            readOnly: true
+          {{- end }}
+          {{- if and (eq .Stage "state") (eq .StateCommand.Name "push") }}
+          - name: push
+            mountPath: /run/push
+            readOnly: true
+          {{- end }}
+          {{- range $index, $federated := .Federated }}
+          - name: federated-{{ $index }}
+            mountPath: {{ .Path }}
+            readOnly: true
+          {{- end }}
 
       {{- if and (.EnableInfraCosts) (eq .Stage "plan") }}
       - name: costs
@@ -257,6 +485,7 @@ spec:
           - --command=/usr/bin/infracost breakdown --path /run/plan.json --format json > /run/costs.json
           - --namespace=$(KUBE_NAMESPACE)
           - --upload=$(COST_REPORT_NAME)=/run/costs.json
+          {{- template "archive" . }}
           - --is-failure=/run/steps/terraform.failed
           - --timeout=5m
           - --wait-on=/run/steps/terraform.complete
@@ -307,6 +536,7 @@ spec:
           - --command=/bin/cat /run/results_cli.txt
           - --namespace=$(KUBE_NAMESPACE)
           - --upload=$(POLICY_REPORT_NAME)=/run/results_json.json
+          {{- template "archive" . }}
           - --is-failure=/run/steps/terraform.failed
           - --wait-on=/run/steps/terraform.complete
         env:
@@ -340,3 +570,55 @@ spec:
           - name: source
             mountPath: /data
       {{- end }}
+
+      {{- if and (.Rego) (eq .Stage "plan") }}
+      - name: verify-rego
+        image: {{ .Images.Rego }}
+        imagePullPolicy: {{ .ImagePullPolicy }}
+        workingDir: /run
+        command:
+          - /run/bin/step
+        args:
+          - --comment=Evaluating Against Rego Policies
+          - --command=/usr/local/bin/conftest test --all-namespaces --no-fail --policy /run/rego /run/plan.json
+          - --command=/usr/local/bin/conftest test --all-namespaces --no-fail --policy /run/rego --output json /run/plan.json > /run/rego_results.json
+          - --namespace=$(KUBE_NAMESPACE)
+          - --upload=$(REGO_REPORT_NAME)=/run/rego_results.json
+          {{- template "archive" . }}
+          - --is-failure=/run/steps/terraform.failed
+          - --wait-on=/run/steps/terraform.complete
+        env:
+          - name: KUBE_NAMESPACE
+            valueFrom:
+              fieldRef:
+                fieldPath: metadata.namespace
+          - name: REGO_REPORT_NAME
+            value: {{ .Secrets.RegoReport }}
+        securityContext:
+          capabilities:
+            drop: [ALL]
+        volumeMounts:
+          - name: run
+            mountPath: /run
+          {{- if .Rego.Modules }}
+          - name: rego
+            mountPath: /run/rego/inline
+          {{- end }}
+      {{- end }}
+
+{{- /* archive are the arguments used by the step containers to archive their logs */}}
+{{- define "archive" }}
+          {{- if .LogStore }}
+          - --archive-key={{ .Configuration.Namespace }}/{{ .Configuration.Name }}/{{ .Configuration.UUID }}/{{ .Configuration.Generation }}/{{ .Stage }}
+          - --archive-store={{ .LogStore.Type }}
+          {{- if .LogStore.Bucket }}
+          - --archive-bucket={{ .LogStore.Bucket }}
+          {{- end }}
+          {{- if .LogStore.Endpoint }}
+          - --archive-endpoint={{ .LogStore.Endpoint }}
+          {{- end }}
+          {{- if .LogStore.Region }}
+          - --archive-region={{ .LogStore.Region }}
+          {{- end }}
+          {{- end }}
+{{- end }}
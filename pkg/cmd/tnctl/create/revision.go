@@ -34,6 +34,7 @@ import (
 	"github.com/appvia/terranetes-controller/pkg/cmd"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/create/assets"
 	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/oci"
 	"github.com/appvia/terranetes-controller/pkg/utils/template"
 	"github.com/appvia/terranetes-controller/pkg/version"
 )
@@ -73,6 +74,10 @@ type RevisionCommand struct {
 	File string
 	// Provider is the name of the provider to use
 	Provider string
+	// Push is an optional oci reference to push the module to
+	Push string
+	// PlainHTTP indicates the oci registry should be contacted over http
+	PlainHTTP bool
 }
 
 var revisionCommandDesc = `
@@ -85,6 +90,13 @@ $ tnctl create revision .
 
 Create a terranetes revision from a terraform module in a git repository
 $ tnctl create revision -n test.01 -m https://examples.com/terraform-module.git?ref=v1.0.0
+
+Create a terranetes revision from a terraform module in an oci registry
+$ tnctl create revision oci://ghcr.io/appvia/modules/bucket:v1.0.0
+
+Push the module in the current directory to an oci registry, using the pushed module
+as the source of the revision. Credentials are taken from OCI_USERNAME and OCI_PASSWORD
+$ tnctl create revision --push oci://ghcr.io/appvia/modules/bucket:v1.0.0 .
 `
 
 // NewRevisionCommand creates a new command
@@ -113,6 +125,8 @@ func NewRevisionCommand(factory cmd.Factory) *cobra.Command {
 	flags.StringVarP(&o.Revision, "revision", "r", "", "The semvar version of this revision")
 	flags.StringVarP(&o.File, "file", "f", "", "The path to save the revision to")
 	flags.StringVar(&o.Provider, "provider", "aws", "The name of the terranetes provider to use")
+	flags.StringVar(&o.Push, "push", "", "An oci reference (oci://registry/repository:tag) to push the module to")
+	flags.BoolVar(&o.PlainHTTP, "plain-http", false, "Indicates the oci registry should be contacted over http")
 
 	return c
 }
@@ -131,6 +145,13 @@ func (o *RevisionCommand) Run(ctx context.Context) (err error) {
 	}()
 	o.Println("%s Successfully downloaded module to: %s", cmd.IconGood, path)
 
+	// @step: push the module to the oci registry if required
+	if o.Push != "" {
+		if err := o.pushModule(ctx, path); err != nil {
+			return err
+		}
+	}
+
 	// @step: we need to parse the terraform code
 	module, diag := tfconfig.LoadModule(path)
 	if diag.HasErrors() {
@@ -187,6 +208,32 @@ func (o *RevisionCommand) Run(ctx context.Context) (err error) {
 	return o.renderRevision(generated)
 }
 
+// pushModule is used to push the module to an oci registry, updating the module source of the
+// revision to the pushed digest
+func (o *RevisionCommand) pushModule(ctx context.Context, path string) error {
+	ref, err := oci.ParseReference(o.Push)
+	if err != nil {
+		return err
+	}
+
+	client := oci.NewClient()
+	client.PlainHTTP = o.PlainHTTP
+
+	digest, err := client.Push(ctx, ref, path)
+	if err != nil {
+		return fmt.Errorf("failed to push the module: %w", err)
+	}
+	ref.Digest = digest
+
+	o.Module = ref.String()
+	if o.PlainHTTP {
+		o.Module += "?insecure=true"
+	}
+	o.Println("%s Successfully pushed module to: %s", cmd.IconGood, o.Module)
+
+	return nil
+}
+
 // renderRevision is used to render the revision
 func (o *RevisionCommand) renderRevision(revision []byte) error {
 	if o.File == "" {
@@ -26,7 +26,7 @@ import (
 func TestAssetNames(t *testing.T) {
 	a := AssetNames()
 	assert.NotEmpty(t, a)
-	assert.Equal(t, []string{"describe.yaml.tpl"}, a)
+	assert.Equal(t, []string{"context.yaml.tpl", "describe.yaml.tpl"}, a)
 }
 
 func TestAsset(t *testing.T) {
@@ -0,0 +1,41 @@
+Name:         {{ .Object.Name }}
+Created:      {{ .Object.CreationTimestamp }}
+
+Conditions:
+==========
+{{- if .Object.Status.Conditions }}
+{{ printf "%-18s %-18s %s" "Name" "Reason" "Message" }}
+{{- range $condition := .Object.Status.Conditions }}
+{{ printf "%-18s %-18s %s" .Name .Reason (default "" .Message) }}
+{{- end }}
+{{- else }}
+ None
+{{- end }}
+
+Variables:
+=========
+{{- if .Variables }}
+{{ printf "%-28s %-10s %s" "Name" "Consumers" "Description" }}
+{{- range $variable := .Variables }}
+{{ printf "%-28s %-10d %s" .Name (len .Consumers) (ternary (default "-" .Description) "Missing from the context" .Defined) }}
+{{- end }}
+{{- else }}
+ None
+{{- end }}
+{{- range $variable := .Variables }}
+{{- if .Consumers }}
+
+{{ .Name }}:
+{{- range $consumer := .Consumers }}
+├─ {{ printf "%-14s %s/%s" .Kind .Namespace .Name }}{{ if .Optional }} (optional){{ end }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+Unused Variables:
+================
+{{- if .Object.Status.Unused }}
+{{ join ", " .Object.Status.Unused }}
+{{- else }}
+ None
+{{- end }}
@@ -38,6 +38,9 @@ Authentication: {{ .Object.Namespace }}/{{ .Object.Spec.Auth.Name }}
 Authentication: None
 {{- end }}
 Module:         {{ .Object.Spec.Module }}
+{{- with .Object.Status.Source }}
+Revision:       {{ default .Digest .Revision }}
+{{- end }}
 Provider:       {{ .Object.Spec.ProviderRef.Name }}
 {{- if .Object.Spec.WriteConnectionSecretToRef }}
 Secret:         {{ .Object.Namespace }}/{{ .Object.Spec.WriteConnectionSecretToRef.Name }}
@@ -45,6 +48,30 @@ Secret:         {{ .Object.Namespace }}/{{ .Object.Spec.WriteConnectionSecretToR
 Secret:         None
 {{- end }}
 
+{{- if .ShowPlan }}
+
+Terraform Plan:
+==============
+{{- with .Object.Status.TerraformPlan }}
+Generation:     {{ .Generation }}
+Job:            {{ default "-" .Job }}
+{{- if .Changes }}
+{{- if .Changes.HasChanges }}
+Changes:        {{ .Changes.Create }} to create, {{ .Changes.Update }} to update, {{ .Changes.Replace }} to replace, {{ .Changes.Delete }} to delete{{ if .Changes.Import }}, {{ .Changes.Import }} to import{{ end }}
+{{ range $change := .Changes.Resources }}
+{{ printf "%-10s %s" $change.Action $change.Address }}
+{{- end }}
+{{- else }}
+Changes:        No changes, the infrastructure matches the configuration.
+{{- end }}
+{{- else }}
+Changes:        No resource changes have been recorded for this plan.
+{{- end }}
+{{- else }}
+Status:         No terraform plan has been recorded for this configuration.
+{{- end }}
+{{- end }}
+
 {{- if .Policy }}
 
 Checkov Security Policy:
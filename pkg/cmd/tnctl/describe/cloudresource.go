@@ -38,6 +38,8 @@ type CloudResourceCommand struct {
 	Namespace string
 	// ShowPassedChecks is a flag to show passed checks
 	ShowPassedChecks bool
+	// ShowPlan is a flag to show the resource changes in the terraform plan
+	ShowPlan bool
 }
 
 // NewDescribeCloudResourceCommand returns a new instance of the get command
@@ -60,6 +62,7 @@ func NewDescribeCloudResourceCommand(factory cmd.Factory) *cobra.Command {
 
 	flags := c.Flags()
 	flags.BoolVar(&o.ShowPassedChecks, "show-passed-checks", true, "Indicates we should show passed checks")
+	flags.BoolVar(&o.ShowPlan, "plan", false, "Indicates we should show the resource changes in the terraform plan")
 	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "Namespace of the resource/s")
 
 	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
@@ -93,6 +96,7 @@ func (o *CloudResourceCommand) Run(ctx context.Context) error {
 	return (&Command{
 		Factory:          o.Factory,
 		ShowPassedChecks: o.ShowPassedChecks,
+		ShowPlan:         o.ShowPlan,
 		Namespace:        o.Namespace,
 		Name:             cloudresource.Status.ConfigurationName,
 	}).Run(ctx)
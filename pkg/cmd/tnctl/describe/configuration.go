@@ -45,6 +45,7 @@ func NewDescribeConfigurationCommand(factory cmd.Factory) *cobra.Command {
 
 	flags := c.Flags()
 	flags.BoolVar(&o.ShowPassedChecks, "show-passed-checks", true, "Indicates we should show passed checks")
+	flags.BoolVar(&o.ShowPlan, "plan", false, "Indicates we should show the resource changes in the terraform plan")
 	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "Namespace of the resource/s")
 
 	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
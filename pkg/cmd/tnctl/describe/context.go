@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2023  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package describe
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/describe/assets"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/template"
+)
+
+// ContextCommand describes a context
+type ContextCommand struct {
+	cmd.Factory
+	// Name is the name of the context we are describing
+	Name string
+}
+
+// contextVariable is the usage of a variable rendered by the template
+type contextVariable struct {
+	// Consumers is a list of resources consuming the variable
+	Consumers []terraformv1alpha1.ContextConsumer
+	// Defined indicates the variable is defined in the context
+	Defined bool
+	// Description is the description of the variable
+	Description string
+	// Name is the name of the variable
+	Name string
+}
+
+// NewDescribeContextCommand returns a new instance of the describe context command
+func NewDescribeContextCommand(factory cmd.Factory) *cobra.Command {
+	o := &ContextCommand{Factory: factory}
+
+	c := &cobra.Command{
+		Use:     "context [OPTIONS] NAME",
+		Args:    cobra.MaximumNArgs(1),
+		Short:   "Used to describe the variables of a context and where they are used",
+		Long:    strings.TrimPrefix(longDescription, "\n"),
+		PreRunE: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	return c
+}
+
+// Run is called to run the command
+func (o *ContextCommand) Run(ctx context.Context) error {
+	txt := &terraformv1alpha1.Context{}
+	txt.Name = o.Name
+
+	// @step: get a client to the cluster
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the context
+	if found, err := kubernetes.GetIfExists(ctx, cc, txt); err != nil {
+		return err
+	} else if !found {
+		return errors.New("the context does not exist")
+	}
+
+	// @step: merge the variables defined in the context with those referenced by consumers
+	variables := make(map[string]*contextVariable)
+	for name := range txt.Spec.Variables {
+		variables[name] = &contextVariable{
+			Defined:     true,
+			Description: txt.Spec.GetVariableDescription(name),
+			Name:        name,
+		}
+	}
+	for _, x := range txt.Status.Variables {
+		if _, found := variables[x.Name]; !found {
+			variables[x.Name] = &contextVariable{Name: x.Name}
+		}
+		variables[x.Name].Consumers = x.Consumers
+	}
+
+	var list []*contextVariable
+	for _, x := range variables {
+		list = append(list, x)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	x, err := template.New(string(assets.MustAsset("context.yaml.tpl")), map[string]interface{}{
+		"Object":    txt,
+		"Variables": list,
+	})
+	if err != nil {
+		return err
+	}
+	o.Println("%s", x)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2023  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package describe
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestDescribeCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Running Test Suite")
+}
+
+var _ = Describe("Describe Context Command", func() {
+	var cc client.Client
+	var command *ContextCommand
+	var stdout *bytes.Buffer
+	var err error
+
+	BeforeEach(func() {
+		var streams genericclioptions.IOStreams
+
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			WithStatusSubresource(&terraformv1alpha1.Context{}).
+			Build()
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+
+		factory, err := cmd.NewFactory(cmd.WithClient(cc), cmd.WithStreams(streams))
+		Expect(err).ToNot(HaveOccurred())
+
+		command = &ContextCommand{Factory: factory}
+	})
+
+	When("the context does not exist", func() {
+		BeforeEach(func() {
+			command.Name = "missing"
+			err = command.Run(context.Background())
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("the context does not exist"))
+		})
+	})
+
+	When("the context exists", func() {
+		BeforeEach(func() {
+			txt := fixtures.NewTerranettesContext("default")
+			txt.Status.Unused = []string{"public_subnets"}
+			txt.Status.Variables = []terraformv1alpha1.ContextVariableStatus{
+				{
+					Name: "missing",
+					Consumers: []terraformv1alpha1.ContextConsumer{
+						{Kind: terraformv1alpha1.CloudResourceKind, Name: "database", Namespace: "apps", Optional: true},
+					},
+				},
+				{
+					Name: "vpc_id",
+					Consumers: []terraformv1alpha1.ContextConsumer{
+						{Kind: terraformv1alpha1.ConfigurationKind, Name: "bucket", Namespace: "apps"},
+					},
+				},
+			}
+			Expect(cc.Create(context.Background(), txt)).To(Succeed())
+
+			command.Name = "default"
+			err = command.Run(context.Background())
+		})
+
+		It("should not return an error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should show the variables of the context", func() {
+			Expect(stdout.String()).To(ContainSubstring("missing                      1          Missing from the context"))
+			Expect(stdout.String()).To(ContainSubstring("public_subnets               0          netwrk"))
+			Expect(stdout.String()).To(ContainSubstring("vpc_id                       1          netwrk"))
+		})
+
+		It("should show the consumers of the variables", func() {
+			Expect(stdout.String()).To(ContainSubstring("vpc_id:\n├─ Configuration  apps/bucket"))
+			Expect(stdout.String()).To(ContainSubstring("missing:\n├─ CloudResource  apps/database (optional)"))
+		})
+
+		It("should show the unused variables", func() {
+			Expect(stdout.String()).To(ContainSubstring("Unused Variables:\n================\npublic_subnets"))
+		})
+	})
+})
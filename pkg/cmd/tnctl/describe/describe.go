@@ -43,6 +43,8 @@ type Command struct {
 	Namespace string
 	// ShowPassedChecks is a flag to show passed checks
 	ShowPassedChecks bool
+	// ShowPlan is a flag to show the resource changes in the terraform plan
+	ShowPlan bool
 }
 
 var longDescription = `
@@ -54,8 +56,14 @@ produced by infracosts or checkov scans.
 Describe a configuration in a namespace
 $ tnctl describe configuration -n apps NAME
 
+Describe a configuration and the changes in the terraform plan
+$ tnctl describe configuration -n apps NAME --plan
+
 Describe a cloudresource in a namespace
 $ tnctl describe cloudresource -n apps NAME
+
+Describe a context and the resources consuming its variables
+$ tnctl describe context NAME
 `
 
 // NewCommand returns a new instance of the get command
@@ -69,6 +77,7 @@ func NewCommand(factory cmd.Factory) *cobra.Command {
 	cmd.AddCommand(
 		NewDescribeCloudResourceCommand(factory),
 		NewDescribeConfigurationCommand(factory),
+		NewDescribeContextCommand(factory),
 	)
 
 	return cmd
@@ -158,6 +167,7 @@ func (o *Command) Run(ctx context.Context) error {
 		"Name":               configuration.GetName(),
 		"Namespace":          configuration.GetNamespace(),
 		"Object":             configuration,
+		"ShowPlan":           o.ShowPlan,
 	}
 
 	// @step: check if the configuration has a policy report
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package history
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+// NewCloudResourceHistoryCommand returns a new instance of the command
+func NewCloudResourceHistoryCommand(factory cmd.Factory) *cobra.Command {
+	o := &Command{Factory: factory}
+
+	c := &cobra.Command{
+		Use:     "cloudresource NAME [OPTIONS]",
+		Short:   "Displays the run history for the given cloudresource",
+		Long:    longDescription,
+		PreRunE: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			o.Kind = terraformv1alpha1.CloudResourceKind
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteCloudResources(factory),
+	}
+	c.SetIn(o.GetStreams().In)
+	c.SetErr(o.GetStreams().ErrOut)
+	c.SetOut(o.GetStreams().Out)
+
+	flags := c.Flags()
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in, used to retrieve the archived logs")
+	flags.IntVar(&o.Logs, "logs", 0, "Show the logs for the given run number")
+	flags.DurationVar(&o.WaitInterval, "timeout", 3*time.Second, "Indicates how long we should wait for logs to be available")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the resource")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/logs"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+var longDescription = `
+Lists the recent terraform runs against a cloudresource or configuration. Each
+run records the stage, generation, result, resource changes (+create ~update
+-delete ±replace), the predicted change to the monthly cost and the reason the
+run was triggered, including the arguments of any replace or target operation.
+
+Viewing the run history of a configuration
+$ tnctl history configuration NAME
+
+Viewing the run history of a cloudresource
+$ tnctl history cloudresource NAME
+
+Viewing the logs of a previous run
+$ tnctl history configuration NAME --logs 2
+`
+
+// Command represents the options
+type Command struct {
+	cmd.Factory
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
+	// Name is the name of the resource
+	Name string
+	// Namespace is the namespace of the resource
+	Namespace string
+	// Kind is the kind of resource
+	Kind string
+	// Logs is the run to show the logs for
+	Logs int
+	// WaitInterval is the interval to wait for the logs
+	WaitInterval time.Duration
+}
+
+// NewCommand returns a new instance of the history command
+func NewCommand(factory cmd.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "history KIND",
+		Short: "Displays the run history for the resource",
+		Long:  longDescription,
+	}
+	c.SetIn(factory.GetStreams().In)
+	c.SetErr(factory.GetStreams().ErrOut)
+	c.SetOut(factory.GetStreams().Out)
+
+	c.AddCommand(
+		NewCloudResourceHistoryCommand(factory),
+		NewConfigurationHistoryCommand(factory),
+	)
+
+	return c
+}
+
+// Run executes the command
+func (o *Command) Run(ctx context.Context) error {
+	switch {
+	case o.Name == "":
+		return cmd.ErrMissingArgument("name")
+
+	case o.Namespace == "":
+		return cmd.ErrMissingArgument("namespace")
+	}
+
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	name := o.Name
+
+	// @step: a cloudresource is resolved to the configuration it manages
+	if o.Kind == terraformv1alpha1.CloudResourceKind {
+		cloudresource := &terraformv1alpha1.CloudResource{}
+		cloudresource.Namespace = o.Namespace
+		cloudresource.Name = o.Name
+
+		if found, err := kubernetes.GetIfExists(ctx, cc, cloudresource); err != nil {
+			return err
+		} else if !found {
+			return fmt.Errorf("cloudresource (%s/%s) does not exist", o.Namespace, o.Name)
+		}
+		if cloudresource.Status.ConfigurationName == "" {
+			return fmt.Errorf("cloudresource (%s/%s) has no configuration yet", o.Namespace, o.Name)
+		}
+		name = cloudresource.Status.ConfigurationName
+	}
+
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration (%s/%s) does not exist", o.Namespace, name)
+	}
+
+	history := configuration.Status.History
+
+	// @step: show the logs for the selected run
+	if o.Logs > 0 {
+		if o.Logs > len(history) {
+			return fmt.Errorf("run %d does not exist, resource has %d run(s) in the history", o.Logs, len(history))
+		}
+		run := history[o.Logs-1]
+
+		return (&logs.Command{
+			ControllerNamespace: o.ControllerNamespace,
+			Factory:             o.Factory,
+			Generation:          run.Generation,
+			Name:                configuration.Name,
+			Namespace:           configuration.Namespace,
+			Stage:               run.Stage,
+			WaitInterval:        o.WaitInterval,
+		}).Run(ctx)
+	}
+
+	if len(history) == 0 {
+		o.Println("No runs found for resource %q", o.Name)
+
+		return nil
+	}
+
+	// @step: lets build the rows
+	var data [][]string
+	for i, run := range history {
+		data = append(data, []string{
+			fmt.Sprintf("%d", i+1),
+			run.Stage,
+			fmt.Sprintf("%d", run.Generation),
+			formatReason(run),
+			string(run.Result),
+			formatChanges(run.Changes),
+			defaultValue(run.CostDelta, "-"),
+			formatAge(run),
+			formatDuration(run),
+		})
+	}
+
+	tw := cmd.NewTableWriter(o.Stdout())
+	tw.SetHeader([]string{
+		"Run",
+		"Stage",
+		"Generation",
+		"Reason",
+		"Result",
+		"Changes",
+		"Cost",
+		"Age",
+		"Duration",
+	})
+	tw.AppendBulk(data)
+	tw.Render()
+
+	return nil
+}
+
+// formatReason returns the reason for the run, along with the arguments of any operation
+func formatReason(run terraformv1alpha1.RunHistory) string {
+	if run.Arguments == "" {
+		return string(run.Reason)
+	}
+
+	return fmt.Sprintf("%s (%s)", run.Reason, run.Arguments)
+}
+
+// formatChanges returns a summary of the resource changes
+func formatChanges(changes *terraformv1alpha1.TerraformPlanChanges) string {
+	if changes == nil {
+		return "-"
+	}
+
+	summary := fmt.Sprintf("+%d ~%d -%d ±%d", changes.Create, changes.Update, changes.Delete, changes.Replace)
+	if changes.Import > 0 {
+		summary += fmt.Sprintf(" ←%d", changes.Import)
+	}
+
+	return summary
+}
+
+// formatAge returns the time since the run started
+func formatAge(run terraformv1alpha1.RunHistory) string {
+	if run.StartTime == nil {
+		return "-"
+	}
+
+	return duration.HumanDuration(time.Since(run.StartTime.Time))
+}
+
+// formatDuration returns the time taken by the run
+func formatDuration(run terraformv1alpha1.RunHistory) string {
+	if run.StartTime == nil || run.CompletionTime == nil {
+		return "-"
+	}
+
+	return duration.HumanDuration(run.CompletionTime.Sub(run.StartTime.Time))
+}
+
+// defaultValue returns the value or the default if empty
+func defaultValue(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
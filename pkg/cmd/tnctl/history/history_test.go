@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package history
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+// fakeProxy is a fake response from the controller service proxy
+type fakeProxy struct {
+	content []byte
+}
+
+func (f *fakeProxy) DoRaw(context.Context) ([]byte, error) {
+	return f.content, nil
+}
+
+func (f *fakeProxy) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f.content)), nil
+}
+
+func TestHistoryCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Running Test Suite")
+}
+
+var _ = Describe("History Command", func() {
+	var cc client.Client
+	var configuration *terraformv1alpha1.Configuration
+	var cloudresource *terraformv1alpha1.CloudResource
+	var command *cobra.Command
+	var stdout *bytes.Buffer
+	var err error
+
+	BeforeEach(func() {
+		var streams genericclioptions.IOStreams
+
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			WithStatusSubresource(&terraformv1alpha1.Configuration{}, &terraformv1alpha1.CloudResource{}).
+			Build()
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+
+		kc := k8sfake.NewSimpleClientset()
+		kc.PrependProxyReactor("services", func(action k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+			return true, &fakeProxy{content: []byte("archived logs\n")}, nil
+		})
+
+		factory, err := cmd.NewFactory(
+			cmd.WithClient(cc),
+			cmd.WithKubeClient(kc),
+			cmd.WithStreams(streams),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "bucket")
+		cloudresource = fixtures.NewCloudResource("default", "bucket")
+		Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+		Expect(cc.Create(context.Background(), cloudresource)).To(Succeed())
+
+		command = NewCommand(factory)
+	})
+
+	When("the configuration does not exist", func() {
+		BeforeEach(func() {
+			command.SetArgs([]string{"configuration", "missing"})
+
+			err = command.ExecuteContext(context.Background())
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("configuration (default/missing) does not exist"))
+		})
+	})
+
+	When("the configuration has no runs", func() {
+		BeforeEach(func() {
+			command.SetArgs([]string{"configuration", configuration.Name})
+
+			err = command.ExecuteContext(context.Background())
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should print a message", func() {
+			Expect(stdout.String()).To(ContainSubstring("No runs found for resource \"bucket\""))
+		})
+	})
+
+	When("the configuration has runs", func() {
+		BeforeEach(func() {
+			started := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+			completed := metav1.NewTime(started.Add(2 * time.Minute))
+
+			configuration.Status.History = []terraformv1alpha1.RunHistory{
+				{
+					Changes:        &terraformv1alpha1.TerraformPlanChanges{Create: 2, Replace: 1},
+					CompletionTime: &completed,
+					CostDelta:      "$12.50",
+					Generation:     3,
+					Job:            "bucket-plan-1234",
+					Reason:         terraformv1alpha1.RunReasonSpecChange,
+					Result:         terraformv1alpha1.RunResultSucceeded,
+					Stage:          terraformv1alpha1.StageTerraformPlan,
+					StartTime:      &started,
+				},
+				{
+					Arguments:  "-replace=aws_s3_bucket.this",
+					Generation: 3,
+					Job:        "bucket-apply-1234",
+					Reason:     terraformv1alpha1.RunReasonOperation,
+					Result:     terraformv1alpha1.RunResultFailed,
+					Stage:      terraformv1alpha1.StageTerraformApply,
+				},
+			}
+			Expect(cc.Status().Update(context.Background(), configuration)).To(Succeed())
+		})
+
+		Context("and we list the runs", func() {
+			BeforeEach(func() {
+				command.SetArgs([]string{"configuration", configuration.Name})
+
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should not error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should list the runs", func() {
+				Expect(stdout.String()).To(ContainSubstring("+2 ~0 -0 ±1"))
+				Expect(stdout.String()).To(ContainSubstring("$12.50"))
+				Expect(stdout.String()).To(ContainSubstring("SpecChange"))
+				Expect(stdout.String()).To(ContainSubstring("Operation (-replace=aws_s3_bucket.this)"))
+				Expect(stdout.String()).To(ContainSubstring("Succeeded"))
+				Expect(stdout.String()).To(ContainSubstring("Failed"))
+				Expect(stdout.String()).To(ContainSubstring("2m"))
+			})
+		})
+
+		Context("and we request the logs of a missing run", func() {
+			BeforeEach(func() {
+				command.SetArgs([]string{"configuration", configuration.Name, "--logs", "3"})
+
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("run 3 does not exist, resource has 2 run(s) in the history"))
+			})
+		})
+
+		Context("and we request the logs of a run", func() {
+			BeforeEach(func() {
+				command.SetArgs([]string{"configuration", configuration.Name, "--logs", "1", "--timeout", "10ms"})
+
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should not error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should show the archived logs of the run", func() {
+				Expect(stdout.String()).To(Equal("archived logs\n"))
+			})
+		})
+
+		Context("and we list the runs of the cloudresource", func() {
+			BeforeEach(func() {
+				cloudresource.Status.ConfigurationName = configuration.Name
+				Expect(cc.Status().Update(context.Background(), cloudresource)).To(Succeed())
+
+				command.SetArgs([]string{"cloudresource", cloudresource.Name})
+
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should list the runs of the configuration", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(ContainSubstring("$12.50"))
+			})
+		})
+	})
+
+	When("the cloudresource has no configuration", func() {
+		BeforeEach(func() {
+			command.SetArgs([]string{"cloudresource", cloudresource.Name})
+
+			err = command.ExecuteContext(context.Background())
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("cloudresource (default/bucket) has no configuration yet"))
+		})
+	})
+})
@@ -34,6 +34,8 @@ import (
 // CloudResourceLogsCommand defines the struct for running the command
 type CloudResourceLogsCommand struct {
 	cmd.Factory
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
 	// Name is the name of the cloudresource
 	Name string
 	// Namespace is the namespace of the cloudresource
@@ -68,6 +70,7 @@ func NewCloudResourceLogsCommand(factory cmd.Factory) *cobra.Command {
 
 	flags := c.Flags()
 	flags.BoolVarP(&o.Follow, "follow", "f", false, "Indicates we should follow the logs")
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in, used to retrieve the archived logs")
 	flags.DurationVar(&o.WaitInterval, "timeout", 3*time.Second, "The interval to wait for the logs")
 	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the resource")
 	flags.StringVar(&o.Stage, "stage", "", "Select the stage to show logs for, else defaults to the current resource state")
@@ -115,11 +118,12 @@ func (o *CloudResourceLogsCommand) Run(ctx context.Context) error {
 	}
 
 	return (&Command{
-		Factory:      o.Factory,
-		Follow:       o.Follow,
-		Name:         cloudresource.Status.ConfigurationName,
-		Namespace:    o.Namespace,
-		Stage:        o.Stage,
-		WaitInterval: o.WaitInterval,
+		ControllerNamespace: o.ControllerNamespace,
+		Factory:             o.Factory,
+		Follow:              o.Follow,
+		Name:                cloudresource.Status.ConfigurationName,
+		Namespace:           o.Namespace,
+		Stage:               o.Stage,
+		WaitInterval:        o.WaitInterval,
 	}).Run(ctx)
 }
@@ -47,6 +47,8 @@ func NewConfigurationLogsCommand(factory cmd.Factory) *cobra.Command {
 
 	flags := c.Flags()
 	flags.BoolVarP(&o.Follow, "follow", "f", false, "Indicates we should follow the logs")
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in, used to retrieve the archived logs")
+	flags.Int64Var(&o.Generation, "generation", 0, "Select the generation to show logs for, else defaults to the current generation")
 	flags.DurationVar(&o.WaitInterval, "timeout", 3*time.Second, "Indicates how long we should wait for logs to be available")
 	flags.StringVar(&o.Stage, "stage", "", "Select the stage to show logs for, else defaults to the current state")
 	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the resource")
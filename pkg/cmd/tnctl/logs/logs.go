@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -44,17 +45,25 @@ $ tnctl logs configuration NAME --follow
 
 Viewing the logs for a cloudresource
 $ tnctl logs cloudresource NAME --follow
+
+Viewing the logs for the plan of a previous generation, once the pods have been
+removed the logs are retrieved from the archive if enabled on the controller
+$ tnctl logs configuration NAME --stage plan --generation 2
 `
 
 // Command represents the options
 type Command struct {
 	cmd.Factory
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
 	// Name is the name of the resource
 	Name string
 	// Namespace is the namespace of the resource
 	Namespace string
 	// Follow indicates we should follow the logs
 	Follow bool
+	// Generation overrides the generation of the configuration to show logs for
+	Generation int64
 	// Stage override the stage to look for
 	Stage string
 	// WaitInterval is the interval to wait for the logs
@@ -149,8 +158,13 @@ func (o *Command) showLogs(ctx context.Context, stage string, configuration *ter
 		return err
 	}
 
+	generation := configuration.GetGeneration()
+	if o.Generation > 0 {
+		generation = o.Generation
+	}
+
 	labels := []string{
-		terraformv1alpha1.ConfigurationGenerationLabel + "=" + fmt.Sprintf("%d", configuration.GetGeneration()),
+		terraformv1alpha1.ConfigurationGenerationLabel + "=" + fmt.Sprintf("%d", generation),
 		terraformv1alpha1.ConfigurationNameLabel + "=" + configuration.Name,
 		terraformv1alpha1.ConfigurationStageLabel + "=" + stage,
 		terraformv1alpha1.ConfigurationUIDLabel + "=" + string(configuration.UID),
@@ -174,6 +188,11 @@ func (o *Command) showLogs(ctx context.Context, stage string, configuration *ter
 		return len(list.Items) > 0, nil
 	})
 	if err != nil {
+		// @step: the pods may have been removed, fall back to the archived logs
+		if found, err := o.showArchivedLogs(ctx, stage, generation, configuration); err != nil || found {
+			return err
+		}
+
 		return fmt.Errorf("no pods found for resource %q", configuration.Name)
 	}
 
@@ -199,3 +218,39 @@ func (o *Command) showLogs(ctx context.Context, stage string, configuration *ter
 
 	return nil
 }
+
+// showArchivedLogs retrieves the archived logs for the build from the controller, returning false
+// if no archived copy exists
+func (o *Command) showArchivedLogs(ctx context.Context, stage string, generation int64, configuration *terraformv1alpha1.Configuration) (bool, error) {
+	if o.ControllerNamespace == "" {
+		return false, nil
+	}
+
+	kc, err := o.GetKubeClient()
+	if err != nil {
+		return false, err
+	}
+
+	content, err := kc.CoreV1().Services(o.ControllerNamespace).ProxyGet("http", "controller", "80",
+		fmt.Sprintf("/v1/builds/%s/%s/logs", configuration.Namespace, configuration.Name),
+		map[string]string{
+			"archived":   "true",
+			"generation": fmt.Sprintf("%d", generation),
+			"name":       configuration.Name,
+			"namespace":  configuration.Namespace,
+			"stage":      stage,
+			"uid":        string(configuration.UID),
+		},
+	).DoRaw(ctx)
+	if err != nil {
+		log.WithError(err).Debug("no archived logs available for the build")
+
+		return false, nil
+	}
+
+	if _, err := o.Stdout().Write(content); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
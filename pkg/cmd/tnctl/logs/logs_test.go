@@ -29,9 +29,13 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -43,6 +47,20 @@ import (
 	"github.com/appvia/terranetes-controller/test/fixtures"
 )
 
+// fakeProxy is a fake response from the controller service proxy
+type fakeProxy struct {
+	content []byte
+	err     error
+}
+
+func (f *fakeProxy) DoRaw(context.Context) ([]byte, error) {
+	return f.content, f.err
+}
+
+func (f *fakeProxy) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f.content)), f.err
+}
+
 func TestLogsCommand(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Running Test Suite")
@@ -57,7 +75,9 @@ var _ = Describe("Logs Command", func() {
 	var configuration *terraformv1alpha1.Configuration
 	var cloudresource *terraformv1alpha1.CloudResource
 	var command *cobra.Command
+	var stdout *bytes.Buffer
 	var stderr *bytes.Buffer
+	var archive *fakeProxy
 	var err error
 
 	BeforeEach(func() {
@@ -66,7 +86,11 @@ var _ = Describe("Logs Command", func() {
 			WithStatusSubresource(&terraformv1alpha1.Configuration{}).
 			Build()
 		kc = k8sfake.NewSimpleClientset()
-		streams, _, _, stderr = genericclioptions.NewTestIOStreams()
+		streams, _, stdout, stderr = genericclioptions.NewTestIOStreams()
+		archive = &fakeProxy{err: kerrors.NewNotFound(k8sschema.GroupResource{Resource: "services"}, "controller")}
+		kc.PrependProxyReactor("services", func(action k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+			return true, archive, nil
+		})
 		configuration = fixtures.NewValidBucketConfiguration("default", "bucket")
 		cloudresource = fixtures.NewCloudResource("default", "bucket")
 		cloudresource.Status.ConfigurationName = configuration.Name
@@ -167,6 +191,25 @@ var _ = Describe("Logs Command", func() {
 					})
 				})
 
+				Context("but no pods exist and the logs have been archived", func() {
+					BeforeEach(func() {
+						pod := fixtures.NewConfigurationPodWatcher(configuration, string(stage))
+						Expect(cc.Delete(context.Background(), pod)).To(Succeed())
+						archive.content = []byte("archived logs\n")
+						archive.err = nil
+
+						err = command.ExecuteContext(context.Background())
+					})
+
+					It("should not error", func() {
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("should print the archived logs", func() {
+						Expect(stdout.String()).To(Equal("archived logs\n"))
+					})
+				})
+
 				Context("and pods exist", func() {
 					BeforeEach(func() {
 						err = command.ExecuteContext(context.Background())
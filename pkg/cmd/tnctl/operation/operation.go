@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// Command is the options for the replace and target commands
+type Command struct {
+	cmd.Factory
+	// Addresses is a collection of terraform addresses the operation applies to
+	Addresses []string
+	// Annotation is the annotation used to request the operation
+	Annotation string
+	// Cancel indicates we are cancelling a pending operation
+	Cancel bool
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+	// Operation is the name of the operation, used in the messages
+	Operation string
+}
+
+// Run implements the command
+func (o *Command) Run(ctx context.Context) error {
+	switch {
+	case o.Cancel && len(o.Addresses) > 0:
+		return errors.New("addresses cannot be used when cancelling an operation")
+	case !o.Cancel && len(o.Addresses) == 0:
+		return errors.New("at least one address is required")
+	}
+
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+	original := configuration.DeepCopy()
+
+	switch {
+	case o.Cancel:
+		if configuration.GetAnnotations()[o.Annotation] == "" {
+			o.Println("No %s operation is pending on configuration %s/%s", o.Operation, o.Namespace, o.Name)
+
+			return nil
+		}
+		delete(configuration.Annotations, o.Annotation)
+
+	default:
+		if configuration.Annotations == nil {
+			configuration.Annotations = map[string]string{}
+		}
+		configuration.Annotations[o.Annotation] = strings.Join(o.Addresses, ",")
+
+		// @step: ensure the addresses are valid before we request the operation
+		if _, err := configuration.GetOperationArguments(); err != nil {
+			return err
+		}
+	}
+
+	if err := cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+		return err
+	}
+
+	if o.Cancel {
+		o.Println("%s Cancelled the %s operation on configuration %s/%s", cmd.IconGood, o.Operation, o.Namespace, o.Name)
+
+		return nil
+	}
+	o.Println("%s Requested a %s of %s on configuration %s/%s, the next plan will include the operation",
+		cmd.IconGood, o.Operation, strings.Join(o.Addresses, ", "), o.Namespace, o.Name)
+
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package operation
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestOperationCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Running Test Suite")
+}
+
+var _ = Describe("Operation Commands", func() {
+	var cc client.Client
+	var factory cmd.Factory
+	var configuration *terraformv1alpha1.Configuration
+	var streams genericclioptions.IOStreams
+	var stdout *bytes.Buffer
+	var err error
+
+	BeforeEach(func() {
+		cc = fake.NewClientBuilder().WithScheme(schema.GetScheme()).Build()
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+		factory, _ = cmd.NewFactory(
+			cmd.WithClient(cc),
+			cmd.WithStreams(streams),
+		)
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "bucket")
+		Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+	})
+
+	When("requesting a replace", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			command = NewReplaceCommand(factory)
+		})
+
+		Context("and the configuration does not exist", func() {
+			BeforeEach(func() {
+				os.Args = []string{"replace", "missing", "aws_instance.this"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("configuration default/missing does not exist"))
+			})
+		})
+
+		Context("and no addresses are provided", func() {
+			BeforeEach(func() {
+				os.Args = []string{"replace", "bucket"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("at least one address is required"))
+			})
+		})
+
+		Context("and the address is invalid", func() {
+			BeforeEach(func() {
+				os.Args = []string{"replace", "bucket", "aws_instance"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`replace address "aws_instance" is not a valid resource address`))
+			})
+
+			It("should not have annotated the configuration", func() {
+				Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.ReplaceAnnotation))
+			})
+		})
+
+		Context("and the addresses are valid", func() {
+			BeforeEach(func() {
+				os.Args = []string{"replace", "bucket", "aws_instance.this", "module.db.aws_db_instance.this[0]"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should have annotated the configuration", func() {
+				Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+				Expect(configuration.Annotations).To(HaveKeyWithValue(terraformv1alpha1.ReplaceAnnotation,
+					"aws_instance.this,module.db.aws_db_instance.this[0]"))
+			})
+
+			It("should print a success message", func() {
+				Expect(stdout.String()).To(ContainSubstring("Requested a replace of aws_instance.this, module.db.aws_db_instance.this[0] on configuration default/bucket"))
+			})
+		})
+
+		Context("and cancelling the replace", func() {
+			BeforeEach(func() {
+				configuration.Annotations[terraformv1alpha1.ReplaceAnnotation] = "aws_instance.this"
+				Expect(cc.Update(context.Background(), configuration)).To(Succeed())
+
+				os.Args = []string{"replace", "bucket", "--cancel"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should have removed the annotation", func() {
+				Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.ReplaceAnnotation))
+			})
+
+			It("should print a cancelled message", func() {
+				Expect(stdout.String()).To(ContainSubstring("Cancelled the replace operation on configuration default/bucket"))
+			})
+		})
+	})
+
+	When("requesting a target", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			command = NewTargetCommand(factory)
+		})
+
+		Context("and the target is a module", func() {
+			BeforeEach(func() {
+				os.Args = []string{"target", "bucket", "module.network"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should have annotated the configuration", func() {
+				Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+				Expect(configuration.Annotations).To(HaveKeyWithValue(terraformv1alpha1.TargetAnnotation, "module.network"))
+			})
+		})
+
+		Context("and no target is pending when cancelling", func() {
+			BeforeEach(func() {
+				os.Args = []string{"target", "bucket", "--cancel"}
+				err = command.ExecuteContext(context.Background())
+			})
+
+			It("should not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should indicate nothing is pending", func() {
+				Expect(stdout.String()).To(Equal("No target operation is pending on configuration default/bucket\n"))
+			})
+		})
+	})
+})
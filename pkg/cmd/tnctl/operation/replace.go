@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package operation
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+var longReplaceHelp = `
+The replace command requests the resources of a configuration are
+destroyed and recreated on the next terraform run, i.e. terraform plan
+-replace=ADDRESS. The request is one-shot; once the plan has been applied
+the request is removed from the configuration.
+
+# Replace an instance within the configuration
+$ tnctl replace -n apps NAME aws_instance.this
+
+# Replace multiple resources
+$ tnctl replace -n apps NAME aws_instance.this 'module.db.aws_db_instance.this[0]'
+
+# Cancel a pending replace
+$ tnctl replace -n apps NAME --cancel
+`
+
+// NewReplaceCommand creates and returns a new replace command
+func NewReplaceCommand(factory cmd.Factory) *cobra.Command {
+	o := &Command{
+		Annotation: terraformv1alpha1.ReplaceAnnotation,
+		Factory:    factory,
+		Operation:  "replace",
+	}
+
+	c := &cobra.Command{
+		Use:     "replace [OPTIONS] NAME [ADDRESS...]",
+		Long:    strings.TrimPrefix(longReplaceHelp, "\n"),
+		Short:   "Requests resources within a configuration are replaced on the next run",
+		PreRunE: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			o.Addresses = args[1:]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	c.SetErr(factory.GetStreams().ErrOut)
+	c.SetOut(factory.GetStreams().Out)
+	c.SetIn(factory.GetStreams().In)
+
+	flags := c.Flags()
+	flags.BoolVar(&o.Cancel, "cancel", false, "Cancel the pending replace of the resources")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
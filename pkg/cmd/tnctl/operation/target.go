@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package operation
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+var longTargetHelp = `
+The target command limits the next terraform run of a configuration to the
+given resources or modules, i.e. terraform plan -target=ADDRESS. This is
+intended to unblock a stuck apply, not for routine use. The request is
+one-shot; once the plan has been applied the request is removed from the
+configuration.
+
+# Limit the next run to a single module
+$ tnctl target -n apps NAME module.network
+
+# Cancel a pending target
+$ tnctl target -n apps NAME --cancel
+`
+
+// NewTargetCommand creates and returns a new target command
+func NewTargetCommand(factory cmd.Factory) *cobra.Command {
+	o := &Command{
+		Annotation: terraformv1alpha1.TargetAnnotation,
+		Factory:    factory,
+		Operation:  "target",
+	}
+
+	c := &cobra.Command{
+		Use:     "target [OPTIONS] NAME [ADDRESS...]",
+		Long:    strings.TrimPrefix(longTargetHelp, "\n"),
+		Short:   "Requests the next run of a configuration is limited to the resources or modules",
+		PreRunE: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			o.Addresses = args[1:]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	c.SetErr(factory.GetStreams().ErrOut)
+	c.SetOut(factory.GetStreams().Out)
+	c.SetIn(factory.GetStreams().In)
+
+	flags := c.Flags()
+	flags.BoolVar(&o.Cancel, "cancel", false, "Cancel the pending target of the resources")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
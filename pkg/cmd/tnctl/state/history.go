@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
+)
+
+// HistoryCommand is the options for the history command
+type HistoryCommand struct {
+	cmd.Factory
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+}
+
+var longHistoryHelp = `
+The history command lists the versions of the terraform state retained
+by the controller for a configuration. A version is retained every time
+the state changes, up to the limit configured on the controller.
+
+# List the retained versions of the state for a configuration
+$ tnctl state history -n apps NAME
+`
+
+// NewHistoryCommand creates and returns a new history command
+func NewHistoryCommand(factory cmd.Factory) *cobra.Command {
+	o := &HistoryCommand{Factory: factory}
+
+	c := &cobra.Command{
+		Use:     "history [OPTIONS] NAME",
+		Long:    strings.TrimPrefix(longHistoryHelp, "\n"),
+		Short:   "Lists the retained versions of the terraform state for a configuration",
+		PreRunE: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+
+	flags := c.Flags()
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
+
+// Run implements the command
+func (o *HistoryCommand) Run(ctx context.Context) error {
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+
+	versions, err := terraform.ListStateVersions(ctx, cc, o.ControllerNamespace, configuration)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		o.Println("No versions of the state found for configuration %s/%s", o.Namespace, o.Name)
+
+		return nil
+	}
+
+	// @step: lets build the rows, newest first
+	var data [][]string
+	for i := len(versions) - 1; i >= 0; i-- {
+		labels := versions[i].GetLabels()
+
+		resources := "Unknown"
+		if state, err := terraform.DecodeState(versions[i].Data[terraformv1alpha1.TerraformStateSecretKey]); err == nil {
+			resources = fmt.Sprintf("%d", state.CountResources())
+		}
+
+		age := "Unknown"
+		if timestamp, err := strconv.ParseInt(labels[terraformv1alpha1.ConfigurationTimestampLabel], 10, 64); err == nil {
+			age = duration.HumanDuration(time.Since(time.Unix(timestamp, 0)))
+		}
+
+		data = append(data, []string{
+			fmt.Sprintf("%d", terraform.GetStateVersion(&versions[i])),
+			labels[terraformv1alpha1.ConfigurationGenerationLabel],
+			labels[terraformv1alpha1.ConfigurationStateSerialLabel],
+			resources,
+			age,
+		})
+	}
+
+	tw := cmd.NewTableWriter(o.Stdout())
+	tw.SetHeader([]string{
+		"Version",
+		"Generation",
+		"Serial",
+		"Resources",
+		"Age",
+	})
+	tw.AppendBulk(data)
+	tw.Render()
+
+	return nil
+}
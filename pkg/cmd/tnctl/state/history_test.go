@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package state
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+var _ = Describe("State history", func() {
+	logrus.SetOutput(io.Discard)
+
+	var cc client.Client
+	var factory cmd.Factory
+	var streams genericclioptions.IOStreams
+	var stdout *bytes.Buffer
+	var command *cobra.Command
+	var configuration *terraformv1alpha1.Configuration
+	var err error
+
+	BeforeEach(func() {
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			Build()
+
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+		factory = &fixtures.Factory{
+			RuntimeClient: cc,
+			KubeClient:    k8sfake.NewSimpleClientset(),
+			Streams:       streams,
+		}
+		command = NewCommand(factory)
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "test")
+	})
+
+	When("the configuration does not exist", func() {
+		BeforeEach(func() {
+			os.Args = []string{"state", "history", "test"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("configuration default/test does not exist"))
+		})
+	})
+
+	When("no versions have been retained", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "history", "test"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should indicate no versions were found", func() {
+			Expect(stdout.String()).To(Equal("No versions of the state found for configuration default/test"))
+		})
+	})
+
+	When("versions have been retained", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+			for i := 1; i <= 2; i++ {
+				version := fixtures.NewTerraformStateVersion(configuration, i)
+				version.Namespace = "terraform-system"
+				Expect(cc.Create(context.Background(), version)).To(Succeed())
+			}
+		})
+
+		Context("and we are listing the history", func() {
+			BeforeEach(func() {
+				os.Args = []string{"state", "history", "test"}
+				err = command.Execute()
+			})
+
+			It("should not error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should list the versions newest first", func() {
+				Expect(stdout.String()).To(HavePrefix("VERSION\tGENERATION\tSERIAL\tRESOURCES\tAGE"))
+				Expect(stdout.String()).To(MatchRegexp(`(?s)\n2 .*\n1 `))
+			})
+		})
+
+		Context("and we are showing the latest version", func() {
+			BeforeEach(func() {
+				os.Args = []string{"state", "show", "test"}
+				err = command.Execute()
+			})
+
+			It("should not error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should print the state", func() {
+				Expect(stdout.String()).To(ContainSubstring(`"serial": 4`))
+			})
+		})
+
+		Context("and we are showing a missing version", func() {
+			BeforeEach(func() {
+				os.Args = []string{"state", "show", "test", "--version", "3"}
+				err = command.Execute()
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("version 3 of the state does not exist for configuration default/test"))
+			})
+		})
+	})
+})
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// MigrateCommand is the options for the migrate command
+type MigrateCommand struct {
+	cmd.Factory
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+}
+
+var longMigrateHelp = `
+The migrate command approves moving the terraform state of a configuration
+into a changed backend. When the backend of a provider changes, the
+controller holds any configuration with an existing state until the
+migration has been approved; the state is then copied into the new
+backend by a migration job, under the state lock, before any further
+plans are run. The state in the previous backend is left untouched.
+
+# Approve the migration of the state for a configuration
+$ tnctl state migrate -n apps NAME
+`
+
+// NewMigrateCommand creates and returns a new migrate command
+func NewMigrateCommand(factory cmd.Factory) *cobra.Command {
+	o := &MigrateCommand{Factory: factory}
+
+	c := &cobra.Command{
+		Use:     "migrate [OPTIONS] NAME",
+		Long:    strings.TrimPrefix(longMigrateHelp, "\n"),
+		Short:   "Migrates the terraform state of a configuration into a changed backend",
+		PreRunE: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
+
+// Run implements the command
+func (o *MigrateCommand) Run(ctx context.Context) error {
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+
+	status := configuration.Status.Backend
+	if !status.HasPendingMigration() {
+		o.Println("No state migration is pending on configuration %s/%s", o.Namespace, o.Name)
+
+		return nil
+	}
+
+	// @step: approve the migration of the state to the pending backend
+	original := configuration.DeepCopy()
+	if configuration.Annotations == nil {
+		configuration.Annotations = map[string]string{}
+	}
+	configuration.Annotations[terraformv1alpha1.MigrateStateAnnotation] = status.PendingChecksum
+
+	if err := cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+		return err
+	}
+	o.Println("Approved the migration of the state for configuration %s/%s from the %s to the %s backend",
+		o.Namespace, o.Name, status.Type, status.PendingType)
+
+	return nil
+}
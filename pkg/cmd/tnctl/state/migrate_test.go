@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+var _ = Describe("Migrating the state", func() {
+	logrus.SetOutput(io.Discard)
+
+	var cc client.Client
+	var factory cmd.Factory
+	var streams genericclioptions.IOStreams
+	var stdout *bytes.Buffer
+	var command *cobra.Command
+	var configuration *terraformv1alpha1.Configuration
+	var err error
+
+	BeforeEach(func() {
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			Build()
+
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+		factory = &fixtures.Factory{
+			RuntimeClient: cc,
+			KubeClient:    k8sfake.NewSimpleClientset(),
+			Streams:       streams,
+		}
+		command = NewCommand(factory)
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "test")
+	})
+
+	When("the configuration does not exist", func() {
+		BeforeEach(func() {
+			os.Args = []string{"state", "migrate", "test"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("configuration default/test does not exist"))
+		})
+	})
+
+	When("no migration is pending", func() {
+		BeforeEach(func() {
+			configuration.Status.Backend = &terraformv1alpha1.BackendStatus{
+				Checksum: "0123456789abcdef",
+				Type:     terraformv1alpha1.BackendTypeKubernetes,
+			}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "migrate", "test"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should indicate no migration is pending", func() {
+			Expect(stdout.String()).To(Equal("No state migration is pending on configuration default/test"))
+		})
+
+		It("should not have approved anything", func() {
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.MigrateStateAnnotation))
+		})
+	})
+
+	When("a migration is pending", func() {
+		BeforeEach(func() {
+			configuration.Status.Backend = &terraformv1alpha1.BackendStatus{
+				Checksum:        "0123456789abcdef",
+				Type:            terraformv1alpha1.BackendTypeKubernetes,
+				PendingChecksum: "fedcba9876543210",
+				PendingType:     terraformv1alpha1.BackendTypeS3,
+			}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "migrate", "test"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should have approved the migration", func() {
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).To(HaveKeyWithValue(terraformv1alpha1.MigrateStateAnnotation, "fedcba9876543210"))
+		})
+
+		It("should indicate the migration has been approved", func() {
+			Expect(stdout.String()).To(Equal("Approved the migration of the state for configuration default/test from the kubernetes to the s3 backend"))
+		})
+	})
+})
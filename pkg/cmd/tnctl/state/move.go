@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+// MoveCommand is the options for the mv command
+type MoveCommand struct {
+	StateCommandOptions
+	// Destination is the address the resource or module is moved to
+	Destination string
+	// Source is the address of the resource or module to move
+	Source string
+}
+
+var longMoveHelp = `
+The mv command moves a resource or module to a different address within
+the terraform state of a configuration. The command is run by the
+controller as a job using the same credentials and backend as the
+configuration, the state is locked and a snapshot taken beforehand.
+
+# Move a resource to a new address
+$ tnctl state mv -n apps NAME aws_s3_bucket.this aws_s3_bucket.main
+`
+
+// NewMoveCommand creates and returns a new mv command
+func NewMoveCommand(factory cmd.Factory) *cobra.Command {
+	o := &MoveCommand{StateCommandOptions: StateCommandOptions{Factory: factory}}
+
+	c := &cobra.Command{
+		Use:     "mv [OPTIONS] NAME SOURCE DESTINATION",
+		Long:    strings.TrimPrefix(longMoveHelp, "\n"),
+		Short:   "Moves a resource or module to a different address in the terraform state",
+		PreRunE: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			o.Source = args[1]
+			o.Destination = args[2]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	o.addFlags(c)
+
+	return c
+}
+
+// Run implements the command
+func (o *MoveCommand) Run(ctx context.Context) error {
+	if _, err := o.run(ctx, terraformv1alpha1.StateCommandMove, []string{o.Source, o.Destination}, nil); err != nil {
+		return err
+	} else if !o.Wait {
+		return nil
+	}
+	o.Println("Moved %s to %s in the state of configuration %s/%s", o.Source, o.Destination, o.Namespace, o.Name)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+// PullCommand is the options for the pull command
+type PullCommand struct {
+	StateCommandOptions
+}
+
+var longPullHelp = `
+The pull command retrieves the current terraform state of a configuration
+from the backend and prints it. The command is run by the controller as
+a job using the same credentials and backend as the configuration.
+
+# Retrieve the state for a configuration
+$ tnctl state pull -n apps NAME > terraform.tfstate
+`
+
+// NewPullCommand creates and returns a new pull command
+func NewPullCommand(factory cmd.Factory) *cobra.Command {
+	o := &PullCommand{StateCommandOptions: StateCommandOptions{Factory: factory}}
+
+	c := &cobra.Command{
+		Use:     "pull [OPTIONS] NAME",
+		Long:    strings.TrimPrefix(longPullHelp, "\n"),
+		Short:   "Retrieves the current terraform state of a configuration",
+		PreRunE: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	o.addFlags(c)
+
+	return c
+}
+
+// Run implements the command
+func (o *PullCommand) Run(ctx context.Context) error {
+	if !o.Wait {
+		return errors.New("pull requires waiting for the state command to complete")
+	}
+
+	output, err := o.run(ctx, terraformv1alpha1.StateCommandPull, nil, nil)
+	if err != nil {
+		return err
+	}
+	o.Println("%s", strings.TrimSuffix(string(output), "\n"))
+
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+// PushCommand is the options for the push command
+type PushCommand struct {
+	StateCommandOptions
+	// Force indicates the state is pushed even when the lineage or serial do not match
+	Force bool
+	// Path is the path to the terraform state to push
+	Path string
+}
+
+var longPushHelp = `
+The push command replaces the terraform state of a configuration with a
+local state file. The command is run by the controller as a job using the
+same credentials and backend as the configuration, the state is locked and
+a snapshot taken beforehand.
+
+# Push a local state file to a configuration
+$ tnctl state push -n apps NAME terraform.tfstate
+
+# Push the state even when the lineage or serial do not match
+$ tnctl state push -n apps NAME terraform.tfstate --force
+`
+
+// NewPushCommand creates and returns a new push command
+func NewPushCommand(factory cmd.Factory) *cobra.Command {
+	o := &PushCommand{StateCommandOptions: StateCommandOptions{Factory: factory}}
+
+	c := &cobra.Command{
+		Use:     "push [OPTIONS] NAME PATH",
+		Long:    strings.TrimPrefix(longPushHelp, "\n"),
+		Short:   "Replaces the terraform state of a configuration with a local state file",
+		PreRunE: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			o.Path = args[1]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	o.addFlags(c)
+	c.Flags().BoolVar(&o.Force, "force", false, "Push the state even when the lineage or serial do not match")
+
+	return c
+}
+
+// Run implements the command
+func (o *PushCommand) Run(ctx context.Context) error {
+	state, err := os.ReadFile(o.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read the state file %q, %w", o.Path, err)
+	}
+	if !json.Valid(state) {
+		return fmt.Errorf("state file %q is not valid json", o.Path)
+	}
+
+	var arguments []string
+	if o.Force {
+		arguments = append(arguments, "-force")
+	}
+
+	if _, err := o.run(ctx, terraformv1alpha1.StateCommandPush, arguments, state); err != nil {
+		return err
+	} else if !o.Wait {
+		return nil
+	}
+	o.Println("Pushed %s to the state of configuration %s/%s", o.Path, o.Namespace, o.Name)
+
+	return nil
+}
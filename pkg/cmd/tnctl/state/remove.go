@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+)
+
+// RemoveCommand is the options for the rm command
+type RemoveCommand struct {
+	StateCommandOptions
+	// Addresses are the addresses of the resources or modules to remove
+	Addresses []string
+}
+
+var longRemoveHelp = `
+The rm command removes resources or modules from the terraform state of
+a configuration, the resources themselves are not destroyed. The command
+is run by the controller as a job using the same credentials and backend
+as the configuration, the state is locked and a snapshot taken beforehand.
+
+# Remove a resource from the state
+$ tnctl state rm -n apps NAME aws_s3_bucket.this
+`
+
+// NewRemoveCommand creates and returns a new rm command
+func NewRemoveCommand(factory cmd.Factory) *cobra.Command {
+	o := &RemoveCommand{StateCommandOptions: StateCommandOptions{Factory: factory}}
+
+	c := &cobra.Command{
+		Use:     "rm [OPTIONS] NAME ADDRESS...",
+		Long:    strings.TrimPrefix(longRemoveHelp, "\n"),
+		Short:   "Removes resources or modules from the terraform state",
+		PreRunE: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			o.Addresses = args[1:]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	o.addFlags(c)
+
+	return c
+}
+
+// Run implements the command
+func (o *RemoveCommand) Run(ctx context.Context) error {
+	if _, err := o.run(ctx, terraformv1alpha1.StateCommandRemove, o.Addresses, nil); err != nil {
+		return err
+	} else if !o.Wait {
+		return nil
+	}
+	o.Println("Removed %s from the state of configuration %s/%s", strings.Join(o.Addresses, ", "), o.Namespace, o.Name)
+
+	return nil
+}
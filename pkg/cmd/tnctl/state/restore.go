@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// RestoreCommand is the options for the restore command
+type RestoreCommand struct {
+	cmd.Factory
+	// Approve indicates we are approving a pending restore
+	Approve bool
+	// Cancel indicates we are cancelling a pending restore
+	Cancel bool
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+	// Version is the version of the state to restore
+	Version int
+}
+
+var longRestoreHelp = `
+The restore command rolls the terraform state of a configuration back to
+a retained version. Requesting a restore places the configuration into a
+guarded mode; no further plans or applies are run until the restore has
+been approved and the state has been pushed back by a restore job, or
+the request has been cancelled.
+
+# Request the state for a configuration is restored to version 2
+$ tnctl state restore -n apps NAME 2
+
+# Approve the pending restore
+$ tnctl state restore -n apps NAME --approve
+
+# Cancel the pending restore
+$ tnctl state restore -n apps NAME --cancel
+`
+
+// NewRestoreCommand creates and returns a new restore command
+func NewRestoreCommand(factory cmd.Factory) *cobra.Command {
+	o := &RestoreCommand{Factory: factory}
+
+	c := &cobra.Command{
+		Use:     "restore [OPTIONS] NAME [VERSION]",
+		Long:    strings.TrimPrefix(longRestoreHelp, "\n"),
+		Short:   "Restores the terraform state of a configuration to a retained version",
+		PreRunE: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			if len(args) > 1 {
+				version, err := strconv.Atoi(args[1])
+				if err != nil || version <= 0 {
+					return fmt.Errorf("version %q is invalid", args[1])
+				}
+				o.Version = version
+			}
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+
+	flags := c.Flags()
+	flags.BoolVar(&o.Approve, "approve", false, "Approve the pending restore of the state")
+	flags.BoolVar(&o.Cancel, "cancel", false, "Cancel the pending restore of the state")
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
+
+// Run implements the command
+func (o *RestoreCommand) Run(ctx context.Context) error {
+	switch {
+	case o.Approve && o.Cancel:
+		return errors.New("approve and cancel are mutually exclusive")
+	case (o.Approve || o.Cancel) && o.Version > 0:
+		return errors.New("version cannot be used when approving or cancelling a restore")
+	case !o.Approve && !o.Cancel && o.Version <= 0:
+		return errors.New("version of the state to restore is required")
+	}
+
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+	original := configuration.DeepCopy()
+	pending := configuration.GetAnnotations()[terraformv1alpha1.RestoreStateAnnotation]
+
+	switch {
+	case o.Cancel, o.Approve:
+		if pending == "" {
+			o.Println("No state restore is pending on configuration %s/%s", o.Namespace, o.Name)
+
+			return nil
+		}
+		if o.Cancel {
+			delete(configuration.Annotations, terraformv1alpha1.RestoreApprovalAnnotation)
+			delete(configuration.Annotations, terraformv1alpha1.RestoreStateAnnotation)
+		} else {
+			configuration.Annotations[terraformv1alpha1.RestoreApprovalAnnotation] = fmt.Sprintf("%d", time.Now().Unix())
+		}
+
+	default:
+		// @step: ensure the version of the state exists
+		secret := &v1.Secret{}
+		secret.Namespace = o.ControllerNamespace
+		secret.Name = configuration.GetTerraformStateVersionSecretName(o.Version)
+
+		if found, err := kubernetes.GetIfExists(ctx, cc, secret); err != nil {
+			return err
+		} else if !found {
+			return fmt.Errorf("version %d of the state does not exist for configuration %s/%s", o.Version, o.Namespace, o.Name)
+		}
+
+		if configuration.Annotations == nil {
+			configuration.Annotations = map[string]string{}
+		}
+		delete(configuration.Annotations, terraformv1alpha1.RestoreApprovalAnnotation)
+		configuration.Annotations[terraformv1alpha1.RestoreStateAnnotation] = fmt.Sprintf("%d", o.Version)
+	}
+
+	if err := cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+		return err
+	}
+
+	switch {
+	case o.Cancel:
+		o.Println("Cancelled the restore of the state for configuration %s/%s", o.Namespace, o.Name)
+	case o.Approve:
+		o.Println("Approved the restore of the state for configuration %s/%s to version %s", o.Namespace, o.Name, pending)
+	default:
+		o.Println("Requested the restore of the state for configuration %s/%s to version %d, use --approve to proceed",
+			o.Namespace, o.Name, o.Version)
+	}
+
+	return nil
+}
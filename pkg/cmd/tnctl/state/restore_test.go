@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package state
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+var _ = Describe("Restoring the state", func() {
+	logrus.SetOutput(io.Discard)
+
+	var cc client.Client
+	var factory cmd.Factory
+	var streams genericclioptions.IOStreams
+	var stdout *bytes.Buffer
+	var command *cobra.Command
+	var configuration *terraformv1alpha1.Configuration
+	var err error
+
+	BeforeEach(func() {
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			Build()
+
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+		factory = &fixtures.Factory{
+			RuntimeClient: cc,
+			KubeClient:    k8sfake.NewSimpleClientset(),
+			Streams:       streams,
+		}
+		command = NewCommand(factory)
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "test")
+	})
+
+	When("no version is provided", func() {
+		BeforeEach(func() {
+			os.Args = []string{"state", "restore", "test"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("version of the state to restore is required"))
+		})
+	})
+
+	When("the version does not exist", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "restore", "test", "2"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("version 2 of the state does not exist for configuration default/test"))
+		})
+	})
+
+	When("requesting a restore", func() {
+		BeforeEach(func() {
+			configuration.Annotations = map[string]string{terraformv1alpha1.RestoreApprovalAnnotation: "1600000000"}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			version := fixtures.NewTerraformStateVersion(configuration, 2)
+			version.Namespace = "terraform-system"
+			Expect(cc.Create(context.Background(), version)).To(Succeed())
+
+			os.Args = []string{"state", "restore", "test", "2"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should have requested the restore without approval", func() {
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).To(HaveKeyWithValue(terraformv1alpha1.RestoreStateAnnotation, "2"))
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.RestoreApprovalAnnotation))
+		})
+
+		It("should indicate the restore has been requested", func() {
+			Expect(stdout.String()).To(Equal("Requested the restore of the state for configuration default/test to version 2, use --approve to proceed"))
+		})
+	})
+
+	When("approving without a pending restore", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "restore", "test", "--approve"}
+			err = command.Execute()
+		})
+
+		It("should indicate no restore is pending", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("No state restore is pending on configuration default/test"))
+		})
+	})
+
+	When("approving a pending restore", func() {
+		BeforeEach(func() {
+			configuration.Annotations = map[string]string{terraformv1alpha1.RestoreStateAnnotation: "2"}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "restore", "test", "--approve"}
+			err = command.Execute()
+		})
+
+		It("should have approved the restore", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).To(HaveKey(terraformv1alpha1.RestoreApprovalAnnotation))
+			Expect(stdout.String()).To(Equal("Approved the restore of the state for configuration default/test to version 2"))
+		})
+	})
+
+	When("cancelling a pending restore", func() {
+		BeforeEach(func() {
+			configuration.Annotations = map[string]string{
+				terraformv1alpha1.RestoreStateAnnotation:    "2",
+				terraformv1alpha1.RestoreApprovalAnnotation: "1600000000",
+			}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "restore", "test", "--cancel"}
+			err = command.Execute()
+		})
+
+		It("should have removed the restore annotations", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.RestoreStateAnnotation))
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.RestoreApprovalAnnotation))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
+)
+
+// ShowCommand is the options for the show command
+type ShowCommand struct {
+	StateCommandOptions
+	// Address is the address of a resource in the state to show
+	Address string
+	// Version is the version of the state to show, defaults to the latest
+	Version int
+}
+
+var longShowHelp = `
+The show command prints a retained version of the terraform state for
+a configuration. When no version is given the latest version is shown.
+When a resource address is given, the attributes of the resource are
+retrieved from the current state by the controller, running a job with
+the same credentials and backend as the configuration.
+
+# Show the latest version of the state for a configuration
+$ tnctl state show -n apps NAME
+
+# Show a specific version of the state
+$ tnctl state show -n apps NAME --version 2
+
+# Show the attributes of a resource in the current state
+$ tnctl state show -n apps NAME aws_s3_bucket.this
+`
+
+// NewShowCommand creates and returns a new show command
+func NewShowCommand(factory cmd.Factory) *cobra.Command {
+	o := &ShowCommand{StateCommandOptions: StateCommandOptions{Factory: factory}}
+
+	c := &cobra.Command{
+		Use:     "show [OPTIONS] NAME [ADDRESS]",
+		Long:    strings.TrimPrefix(longShowHelp, "\n"),
+		Short:   "Shows the terraform state, or a resource within it, for a configuration",
+		PreRunE: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+			if len(args) > 1 {
+				o.Address = args[1]
+			}
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+	o.addFlags(c)
+	c.Flags().IntVar(&o.Version, "version", 0, "The retained version of the state to show, defaults to the latest")
+
+	return c
+}
+
+// Run implements the command
+func (o *ShowCommand) Run(ctx context.Context) error {
+	switch {
+	case o.Version < 0:
+		return fmt.Errorf("version %d is invalid", o.Version)
+	case o.Address != "" && o.Version > 0:
+		return errors.New("version cannot be used when showing a resource address")
+	case o.Address != "":
+		return o.showResource(ctx)
+	}
+
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+
+	versions, err := terraform.ListStateVersions(ctx, cc, o.ControllerNamespace, configuration)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions of the state found for configuration %s/%s", o.Namespace, o.Name)
+	}
+
+	// @step: find the version of the state to show
+	secret := &versions[len(versions)-1]
+	if o.Version > 0 {
+		secret = nil
+		for i := range versions {
+			if terraform.GetStateVersion(&versions[i]) == o.Version {
+				secret = &versions[i]
+			}
+		}
+		if secret == nil {
+			return fmt.Errorf("version %d of the state does not exist for configuration %s/%s", o.Version, o.Namespace, o.Name)
+		}
+	}
+
+	state, err := terraform.Decode(secret.Data[terraformv1alpha1.TerraformStateSecretKey])
+	if err != nil {
+		return fmt.Errorf("failed to decode the terraform state, %w", err)
+	}
+	o.Println("%s", state)
+
+	return nil
+}
+
+// showResource runs a state show against the current state for the resource address
+func (o *ShowCommand) showResource(ctx context.Context) error {
+	if !o.Wait {
+		return errors.New("show requires waiting for the state command to complete")
+	}
+
+	output, err := o.run(ctx, terraformv1alpha1.StateCommandShow, []string{o.Address}, nil)
+	if err != nil {
+		return err
+	}
+	o.Println("%s", strings.TrimSuffix(string(output), "\n"))
+
+	return nil
+}
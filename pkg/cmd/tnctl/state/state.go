@@ -31,7 +31,11 @@ type Command struct {
 var longDesc = `
 When using the kubernetes backend to store the terraform state, this
 command provides the ability to list, clean and match up state secrets
-against the Configuration CRD which are using them.
+against the Configuration CRD which are using them, as well as release
+any stuck locks on the state and migrate the state when the backend of
+a provider has changed. The versions of the state retained by the
+controller can be listed, shown and restored, and the show, mv, rm,
+pull and push state commands can be run as jobs by the controller.
 `
 
 // NewCommand returns a new instance of the command
@@ -45,6 +49,15 @@ func NewCommand(factory cmd.Factory) *cobra.Command {
 	c.AddCommand(
 		NewListCommand(factory),
 		NewCleanCommand(factory),
+		NewUnlockCommand(factory),
+		NewMigrateCommand(factory),
+		NewHistoryCommand(factory),
+		NewShowCommand(factory),
+		NewRestoreCommand(factory),
+		NewMoveCommand(factory),
+		NewRemoveCommand(factory),
+		NewPullCommand(factory),
+		NewPushCommand(factory),
 	)
 
 	return c
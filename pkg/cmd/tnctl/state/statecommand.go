@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+var (
+	// newStateCommandID returns the unique identifier for a state command request
+	newStateCommandID = func() string {
+		return strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	// stateCommandInterval is the interval we check for the outcome of a state command
+	stateCommandInterval = 2 * time.Second
+)
+
+// StateCommandOptions are the options shared by the state commands run by the controller
+type StateCommandOptions struct {
+	cmd.Factory
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+	// Timeout is the maximum time to wait for the state command to complete
+	Timeout time.Duration
+	// Wait indicates we should wait for the state command to complete
+	Wait bool
+}
+
+// addFlags adds the flags shared by the state commands
+func (o *StateCommandOptions) addFlags(c *cobra.Command) {
+	flags := c.Flags()
+	flags.BoolVar(&o.Wait, "wait", true, "Wait for the state command to complete")
+	flags.DurationVar(&o.Timeout, "timeout", 5*time.Minute, "The maximum time to wait for the state command to complete")
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(o.Factory))
+}
+
+// run requests the controller runs the state command against the configuration, and when
+// waiting, returns the output of the command once it has completed. The arguments are passed
+// as given, so addresses containing whitespace or quotes are kept intact
+func (o *StateCommandOptions) run(ctx context.Context, name string, arguments []string, push []byte) ([]byte, error) {
+	command, err := terraformv1alpha1.NewStateCommand(name, arguments...)
+	if err != nil {
+		return nil, err
+	}
+
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+	if pending, found := configuration.GetAnnotations()[terraformv1alpha1.StateCommandAnnotation]; found {
+		if parsed, err := terraformv1alpha1.ParseStateCommand(pending); err == nil {
+			pending = parsed.String()
+		}
+
+		return nil, fmt.Errorf("state command %q is already pending on configuration %s/%s", pending, o.Namespace, o.Name)
+	}
+
+	// @step: provide the state to push to the controller
+	if command.Name == terraformv1alpha1.StateCommandPush {
+		if err := o.uploadState(ctx, cc, configuration, push); err != nil {
+			return nil, err
+		}
+	}
+
+	// @step: request the state command
+	id := newStateCommandID()
+	original := configuration.DeepCopy()
+	if configuration.Annotations == nil {
+		configuration.Annotations = map[string]string{}
+	}
+	configuration.Annotations[terraformv1alpha1.StateCommandAnnotation] = command.Encode()
+	configuration.Annotations[terraformv1alpha1.StateCommandIDAnnotation] = id
+
+	if err := cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+		return nil, err
+	}
+	if !o.Wait {
+		o.Println("Requested the state command %q on configuration %s/%s", command.String(), o.Namespace, o.Name)
+
+		return nil, nil
+	}
+
+	// @step: wait for the controller to record the outcome of the state command
+	err = wait.PollUntilContextTimeout(ctx, stateCommandInterval, o.Timeout, true, func(ctx context.Context) (bool, error) {
+		if err := cc.Get(ctx, configuration.GetNamespacedName(), configuration); err != nil {
+			return false, err
+		}
+		status := configuration.Status.StateCommand
+
+		return status != nil && status.ID == id && status.Result != "", nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for the state command %q to complete, %w", command.String(), err)
+	}
+
+	status := configuration.Status.StateCommand
+	if status.Result != terraformv1alpha1.RunResultSucceeded {
+		return nil, fmt.Errorf("state command %q has failed, check the logs of job %s/%s",
+			command.String(), o.ControllerNamespace, status.Job)
+	}
+
+	// @step: retrieve the output of the state command
+	secret, found, err := kubernetes.GetSecretIfExists(ctx, cc, o.ControllerNamespace, configuration.GetTerraformStateCommandSecretName())
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("output of the state command %q does not exist", command.String())
+	}
+
+	return secret.Data[terraformv1alpha1.TerraformStateOutputSecretKey], nil
+}
+
+// uploadState places the state to be pushed into the state command secret
+func (o *StateCommandOptions) uploadState(ctx context.Context, cc client.Client, configuration *terraformv1alpha1.Configuration, state []byte) error {
+	secret := &v1.Secret{}
+	secret.Namespace = o.ControllerNamespace
+	secret.Name = configuration.GetTerraformStateCommandSecretName()
+
+	found, err := kubernetes.GetIfExists(ctx, cc, secret)
+	if err != nil {
+		return err
+	}
+	original := secret.DeepCopy()
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[terraformv1alpha1.TerraformStatePushSecretKey] = state
+
+	if !found {
+		return cc.Create(ctx, secret)
+	}
+
+	return cc.Patch(ctx, secret, client.MergeFrom(original))
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+var _ = Describe("Running state commands", func() {
+	logrus.SetOutput(io.Discard)
+
+	var cc client.Client
+	var factory cmd.Factory
+	var streams genericclioptions.IOStreams
+	var stdout *bytes.Buffer
+	var command *cobra.Command
+	var configuration *terraformv1alpha1.Configuration
+	var err error
+
+	newStateCommandID = func() string { return "1700000000" }
+
+	BeforeEach(func() {
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			WithStatusSubresource(&terraformv1alpha1.Configuration{}).
+			Build()
+
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+		factory = &fixtures.Factory{
+			RuntimeClient: cc,
+			KubeClient:    k8sfake.NewSimpleClientset(),
+			Streams:       streams,
+		}
+		command = NewCommand(factory)
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "test")
+	})
+
+	When("the configuration does not exist", func() {
+		BeforeEach(func() {
+			os.Args = []string{"state", "mv", "test", "aws_s3_bucket.this", "aws_s3_bucket.main"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("configuration default/test does not exist"))
+		})
+	})
+
+	When("the address is invalid", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "rm", "test", "not an address"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not a valid resource or module address"))
+		})
+	})
+
+	When("a state command is already pending", func() {
+		BeforeEach(func() {
+			configuration.Annotations = map[string]string{terraformv1alpha1.StateCommandAnnotation: `["pull"]`}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "rm", "test", "aws_s3_bucket.this"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`state command "pull" is already pending on configuration default/test`))
+		})
+	})
+
+	When("requesting a state command without waiting", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "mv", "test", "aws_s3_bucket.this", "aws_s3_bucket.main", "--wait=false"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should have requested the state command", func() {
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).To(HaveKeyWithValue(terraformv1alpha1.StateCommandAnnotation, `["mv","aws_s3_bucket.this","aws_s3_bucket.main"]`))
+			Expect(configuration.GetAnnotations()).To(HaveKeyWithValue(terraformv1alpha1.StateCommandIDAnnotation, "1700000000"))
+		})
+
+		It("should indicate the state command has been requested", func() {
+			Expect(stdout.String()).To(Equal(`Requested the state command "mv aws_s3_bucket.this aws_s3_bucket.main" on configuration default/test`))
+		})
+	})
+
+	When("requesting a state command with an address containing whitespace and quotes", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "rm", "test", `aws_s3_bucket.this["it's a"]`, "--wait=false"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should have requested the state command with the arguments intact", func() {
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+			requested, err := terraformv1alpha1.ParseStateCommand(configuration.GetAnnotations()[terraformv1alpha1.StateCommandAnnotation])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requested.Name).To(Equal(terraformv1alpha1.StateCommandRemove))
+			Expect(requested.Arguments).To(Equal([]string{`aws_s3_bucket.this["it's a"]`}))
+		})
+	})
+
+	When("the state command has completed", func() {
+		BeforeEach(func() {
+			configuration.Status.StateCommand = &terraformv1alpha1.StateCommandStatus{
+				Command: "show aws_s3_bucket.this",
+				ID:      "1700000000",
+				Job:     "test-state-1234",
+				Result:  terraformv1alpha1.RunResultSucceeded,
+			}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			secret := &v1.Secret{}
+			secret.Namespace = "terraform-system"
+			secret.Name = configuration.GetTerraformStateCommandSecretName()
+			secret.Data = map[string][]byte{terraformv1alpha1.TerraformStateOutputSecretKey: []byte("# aws_s3_bucket.this:\n")}
+			Expect(cc.Create(context.Background(), secret)).To(Succeed())
+
+			os.Args = []string{"state", "show", "test", "aws_s3_bucket.this"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should print the output of the state command", func() {
+			Expect(stdout.String()).To(Equal("# aws_s3_bucket.this:"))
+		})
+	})
+
+	When("the state command has failed", func() {
+		BeforeEach(func() {
+			configuration.Status.StateCommand = &terraformv1alpha1.StateCommandStatus{
+				Command: "rm aws_s3_bucket.this",
+				ID:      "1700000000",
+				Job:     "test-state-1234",
+				Result:  terraformv1alpha1.RunResultFailed,
+			}
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "rm", "test", "aws_s3_bucket.this"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`state command "rm aws_s3_bucket.this" has failed, check the logs of job terraform-system/test-state-1234`))
+		})
+	})
+
+	When("pushing a state file", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			path := filepath.Join(GinkgoT().TempDir(), "terraform.tfstate")
+			Expect(os.WriteFile(path, []byte(`{"version": 4}`), 0600)).To(Succeed())
+
+			os.Args = []string{"state", "push", "test", path, "--force", "--wait=false"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should have uploaded the state to push", func() {
+			secret := &v1.Secret{}
+			secret.Namespace = "terraform-system"
+			secret.Name = configuration.GetTerraformStateCommandSecretName()
+
+			Expect(cc.Get(context.Background(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue(terraformv1alpha1.TerraformStatePushSecretKey, []byte(`{"version": 4}`)))
+		})
+
+		It("should have requested the push", func() {
+			Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).To(HaveKeyWithValue(terraformv1alpha1.StateCommandAnnotation, `["push","-force"]`))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/utils/ptr"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// UnlockCommand is the options for the unlock command
+type UnlockCommand struct {
+	cmd.Factory
+	// ControllerNamespace is the namespace the controller is running in
+	ControllerNamespace string
+	// Force will release the lock even if the holder is still renewing it
+	Force bool
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+}
+
+var longUnlockHelp = `
+The unlock command releases the lock held on the terraform state of a
+configuration. Locks are taken by the plan, apply and destroy jobs and
+are released when the job finishes; a lock which is no longer being
+renewed is considered stale and is taken over automatically. This
+command is for when a lock is stuck and you need to release it now.
+
+# Release a stale lock on a configuration
+$ tnctl state unlock -n apps NAME
+
+# Release a lock which is still being renewed by a running job
+$ tnctl state unlock -n apps NAME --force
+`
+
+// NewUnlockCommand creates and returns a new unlock command
+func NewUnlockCommand(factory cmd.Factory) *cobra.Command {
+	o := &UnlockCommand{Factory: factory}
+
+	c := &cobra.Command{
+		Use:     "unlock [OPTIONS] NAME",
+		Long:    strings.TrimPrefix(longUnlockHelp, "\n"),
+		Short:   "Releases the lock held on the terraform state of a configuration",
+		PreRunE: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run(cmd.Context())
+		},
+		ValidArgsFunction: cmd.AutoCompleteConfigurations(factory),
+	}
+
+	flags := c.Flags()
+	flags.StringVar(&o.ControllerNamespace, "controller-namespace", "terraform-system", "The namespace the controller is running in")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "default", "The namespace of the configuration")
+	flags.BoolVar(&o.Force, "force", false, "Release the lock even if it is still being renewed")
+
+	cmd.RegisterFlagCompletionFunc(c, "namespace", cmd.AutoCompleteNamespaces(factory))
+
+	return c
+}
+
+// Run implements the command
+func (o *UnlockCommand) Run(ctx context.Context) error {
+	// @step: retrieve a kubernetes client
+	cc, err := o.GetClient()
+	if err != nil {
+		return err
+	}
+
+	// @step: retrieve the configuration
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.Namespace = o.Namespace
+	configuration.Name = o.Name
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, configuration); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("configuration %s/%s does not exist", o.Namespace, o.Name)
+	}
+
+	// @step: retrieve the lock
+	lease := &coordinationv1.Lease{}
+	lease.Namespace = o.ControllerNamespace
+	lease.Name = configuration.GetTerraformLockName()
+
+	if found, err := kubernetes.GetIfExists(ctx, cc, lease); err != nil {
+		return err
+	} else if !found {
+		o.Println("No lock is held on configuration %s/%s", o.Namespace, o.Name)
+
+		return nil
+	}
+
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "Unknown")
+	if !kubernetes.IsLeaseExpired(lease, time.Now()) && !o.Force {
+		return fmt.Errorf("lock is still being renewed by %s, use --force to release it", holder)
+	}
+
+	if err := kubernetes.DeleteIfExists(ctx, cc, lease); err != nil {
+		return err
+	}
+
+	age := "Unknown"
+	if lease.Spec.RenewTime != nil {
+		age = duration.HumanDuration(time.Since(lease.Spec.RenewTime.Time))
+	}
+	o.Println("Released the lock held by %s on configuration %s/%s (last renewed %s ago)", holder, o.Namespace, o.Name, age)
+
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/cmd"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+var _ = Describe("Unlocking the state", func() {
+	logrus.SetOutput(io.Discard)
+	testUID := "4845842d-f29b-4d12-8f6a-b73c7bf82836"
+
+	var cc client.Client
+	var factory cmd.Factory
+	var streams genericclioptions.IOStreams
+	var stdout *bytes.Buffer
+	var command *cobra.Command
+	var configuration *terraformv1alpha1.Configuration
+	var err error
+
+	newLease := func(renewed time.Time) *coordinationv1.Lease {
+		lease := &coordinationv1.Lease{}
+		lease.Namespace = "terraform-system"
+		lease.Name = configuration.GetTerraformLockName()
+		lease.Spec.HolderIdentity = ptr.To("bucket-apply-1234")
+		lease.Spec.LeaseDurationSeconds = ptr.To(int32(30))
+		lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(renewed))
+
+		return lease
+	}
+
+	leaseExists := func() bool {
+		found, err := kubernetes.GetIfExists(context.Background(), cc, newLease(time.Now()))
+		Expect(err).ToNot(HaveOccurred())
+
+		return found
+	}
+
+	BeforeEach(func() {
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			Build()
+
+		streams, _, stdout, _ = genericclioptions.NewTestIOStreams()
+		factory = &fixtures.Factory{
+			RuntimeClient: cc,
+			KubeClient:    k8sfake.NewSimpleClientset(),
+			Streams:       streams,
+		}
+		command = NewCommand(factory)
+
+		configuration = fixtures.NewValidBucketConfiguration("default", "test")
+		configuration.UID = types.UID(testUID)
+	})
+
+	When("the configuration does not exist", func() {
+		BeforeEach(func() {
+			os.Args = []string{"state", "unlock", "test"}
+			err = command.Execute()
+		})
+
+		It("should error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("configuration default/test does not exist"))
+		})
+	})
+
+	When("no lock is held", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+			os.Args = []string{"state", "unlock", "test"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should indicate no lock is held", func() {
+			Expect(stdout.String()).To(Equal("No lock is held on configuration default/test"))
+		})
+	})
+
+	When("the lock is stale", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+			Expect(cc.Create(context.Background(), newLease(time.Now().Add(-5*time.Minute)))).To(Succeed())
+
+			os.Args = []string{"state", "unlock", "test"}
+			err = command.Execute()
+		})
+
+		It("should not error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should have released the lock", func() {
+			Expect(leaseExists()).To(BeFalse())
+			Expect(stdout.String()).To(ContainSubstring("Released the lock held by bucket-apply-1234 on configuration default/test"))
+		})
+	})
+
+	When("the lock is still being renewed", func() {
+		BeforeEach(func() {
+			Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+			Expect(cc.Create(context.Background(), newLease(time.Now()))).To(Succeed())
+		})
+
+		Context("and we are not forcing the release", func() {
+			BeforeEach(func() {
+				os.Args = []string{"state", "unlock", "test"}
+				err = command.Execute()
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("lock is still being renewed by bucket-apply-1234, use --force to release it"))
+			})
+
+			It("should not have released the lock", func() {
+				Expect(leaseExists()).To(BeTrue())
+			})
+		})
+
+		Context("and we are forcing the release", func() {
+			BeforeEach(func() {
+				os.Args = []string{"state", "unlock", "test", "--force"}
+				err = command.Execute()
+			})
+
+			It("should not error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should have released the lock", func() {
+				Expect(leaseExists()).To(BeFalse())
+			})
+		})
+	})
+})
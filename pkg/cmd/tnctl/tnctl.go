@@ -37,8 +37,10 @@ import (
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/describe"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/generate"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/get"
+	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/history"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/kubectl"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/logs"
+	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/operation"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/retry"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/search"
 	"github.com/appvia/terranetes-controller/pkg/cmd/tnctl/state"
@@ -90,7 +92,10 @@ func New(factory cmd.Factory) *cobra.Command {
 		state.NewCommand(factory),
 		verify.NewCommand(factory),
 		retry.NewCommand(factory),
+		operation.NewReplaceCommand(factory),
+		operation.NewTargetCommand(factory),
 		logs.NewCommand(factory),
+		history.NewCommand(factory),
 	)
 
 	flags := command.PersistentFlags()
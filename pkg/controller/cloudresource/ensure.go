@@ -171,8 +171,11 @@ func (c *Controller) ensureConfigurationExists(cloudresource *terraformv1alpha1.
 		}
 
 		configuration.Spec.Module = revision.Spec.Configuration.Module
+		configuration.Spec.DependsOn = cloudresource.Spec.DependsOn
 		configuration.Spec.EnableAutoApproval = cloudresource.Spec.EnableAutoApproval
 		configuration.Spec.EnableDriftDetection = cloudresource.Spec.EnableDriftDetection
+		configuration.Spec.DriftPolicy = cloudresource.Spec.DriftPolicy
+		configuration.Spec.Engine = cloudresource.Spec.Engine
 		configuration.Spec.Plan = &terraformv1alpha1.PlanReference{
 			Name:     cloudresource.Spec.Plan.Name,
 			Revision: cloudresource.Spec.Plan.Revision,
@@ -192,6 +195,10 @@ func (c *Controller) ensureConfigurationExists(cloudresource *terraformv1alpha1.
 		if cloudresource.Spec.ProviderRef != nil {
 			configuration.Spec.ProviderRef = cloudresource.Spec.ProviderRef
 		}
+		configuration.Spec.Providers = revision.Spec.Configuration.Providers
+		if len(cloudresource.Spec.Providers) > 0 {
+			configuration.Spec.Providers = cloudresource.Spec.Providers
+		}
 
 		// @step: copy in the values from the inputs which have a value
 		for _, input := range revision.Spec.Inputs {
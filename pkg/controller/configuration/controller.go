@@ -44,6 +44,7 @@ import (
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/handlers/configurations"
 	ksutils "github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
 	"github.com/appvia/terranetes-controller/pkg/utils/policies"
 )
 
@@ -53,6 +54,8 @@ const controllerName = "configuration.terraform.appvia.io"
 type Controller struct {
 	// cc is the kubernetes client to the cluster
 	cc client.Client
+	// logs is the store holding the archived job logs, if enabled
+	logs logstore.Store
 	// kc is a client kubernetes - this is required as the controller runtime client
 	// does not support subresources, check https://github.com/kubernetes-sigs/controller-runtime/pull/1922
 	kc kubernetes.Interface
@@ -74,6 +77,9 @@ type Controller struct {
 	EnableContextInjection bool
 	// EnableInfracosts enables the cost analytics via infracost
 	EnableInfracosts bool
+	// EnableSourceCache indicates the jobs should retrieve and store the module sources in
+	// the source cache served by the controller
+	EnableSourceCache bool
 	// EnableTerraformVersions enables the use of the configuration's Terraform version
 	EnableTerraformVersions bool
 	// EnableWatchers indicates we should create watcher jobs in the user namespace
@@ -94,10 +100,25 @@ type Controller struct {
 	ControllerJobLabels map[string]string
 	// JobTemplate is a custom override for the template to use
 	JobTemplate string
+	// LogStore are the options for archiving the logs of the jobs, archiving is disabled when nil
+	LogStore *logstore.Options
 	// PolicyImage is the image to use for all policy / checkov jobs
 	PolicyImage string
+	// RegoImage is the image to use when evaluating rego policies
+	RegoImage string
+	// StateVersions is the number of versions of the terraform state retained per configuration,
+	// versioning is disabled when zero
+	StateVersions int
+	// TerraformEngine is the default engine used to run the configurations, i.e. terraform or tofu
+	TerraformEngine string
 	// TerraformImage is the image to use for all terraform jobs
 	TerraformImage string
+	// TerraformVersions are the terraform versions permitted when resolving a version constraint
+	TerraformVersions []string
+	// TofuImage is the image to use for the jobs of configurations using the opentofu engine
+	TofuImage string
+	// TofuVersions are the opentofu versions permitted when resolving a version constraint
+	TofuVersions []string
 }
 
 // HasBackendTemplate returns true if the configuration has a backend template
@@ -114,7 +135,10 @@ func (c *Controller) Add(mgr manager.Manager) error {
 		"enable_watchers":    c.EnableWatchers,
 		"namespace":          c.ControllerNamespace,
 		"policy_image":       c.PolicyImage,
+		"rego_image":         c.RegoImage,
+		"terraform_engine":   c.TerraformEngine,
 		"terraform_image":    c.TerraformImage,
+		"tofu_image":         c.TofuImage,
 	}).Info("adding the configuration controller")
 
 	switch {
@@ -122,8 +146,14 @@ func (c *Controller) Add(mgr manager.Manager) error {
 		return errors.New("job namespace is required")
 	case c.TerraformImage == "":
 		return errors.New("terraform image is required")
+	case c.TofuImage == "":
+		return errors.New("tofu image is required")
+	case !terraformv1alpha1.IsEngineSupported(c.TerraformEngine):
+		return fmt.Errorf("terraform engine %q is not supported", c.TerraformEngine)
 	case c.PolicyImage == "":
 		return errors.New("policy image is required")
+	case c.RegoImage == "":
+		return errors.New("rego image is required")
 	case c.EnableInfracosts && c.InfracostsImage == "":
 		return errors.New("infracost image is required")
 	case c.EnableInfracosts && c.InfracostsSecretName == "":
@@ -140,10 +170,18 @@ func (c *Controller) Add(mgr manager.Manager) error {
 	}
 	c.kc = kc
 
+	if c.LogStore != nil {
+		store, err := logstore.New(c.cc, *c.LogStore)
+		if err != nil {
+			return fmt.Errorf("failed to create the log store: %w", err)
+		}
+		c.logs = store
+	}
+
 	if c.EnableWebhooks {
 		mgr.GetWebhookServer().Register(
 			fmt.Sprintf("/validate/%s/configurations", terraformv1alpha1.GroupName),
-			admission.WithCustomValidator(mgr.GetScheme(), &terraformv1alpha1.Configuration{}, configurations.NewValidator(c.cc, c.EnableTerraformVersions)),
+			admission.WithCustomValidator(mgr.GetScheme(), &terraformv1alpha1.Configuration{}, configurations.NewValidator(c.cc, c.EnableTerraformVersions, c.TerraformEngine)),
 		)
 		mgr.GetWebhookServer().Register(
 			fmt.Sprintf("/mutate/%s/configurations", terraformv1alpha1.GroupName),
@@ -219,20 +257,125 @@ func (c *Controller) findMatchingPolicy(
 		return nil, nil
 	}
 
+	namespace, err := c.findNamespace(ctx, configuration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.FindMatchingPolicy(ctx, configuration, namespace, list)
+}
+
+// findMatchingRegoPolicy is used to find the rego constraint for the configuration, as with the checkov
+// policies only a single constraint can match
+func (c *Controller) findMatchingRegoPolicy(
+	ctx context.Context,
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList) (*terraformv1alpha1.RegoConstraint, error) {
+
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace, err := c.findNamespace(ctx, configuration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.FindMatchingRegoPolicy(configuration, namespace, list)
+}
+
+// findMaintenanceWindows is used to find the maintenance windows from all the policies which
+// select the configuration
+func (c *Controller) findMaintenanceWindows(
+	ctx context.Context,
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList) ([]terraformv1alpha1.MaintenanceWindow, error) {
+
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace, err := c.findNamespace(ctx, configuration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.FindMaintenanceWindows(configuration, namespace, list)
+}
+
+// findSignatureKeys is used to find the keys trusted to sign the module from all the policies
+// which select the configuration
+func (c *Controller) findSignatureKeys(
+	ctx context.Context,
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList) ([]terraformv1alpha1.SignatureKey, error) {
+
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace, err := c.findNamespace(ctx, configuration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.FindSignatureKeys(configuration, namespace, list)
+}
+
+// findCostConstraints is used to find the cost constraints from all the policies which select
+// the configuration
+func (c *Controller) findCostConstraints(
+	ctx context.Context,
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList) ([]terraformv1alpha1.CostConstraint, error) {
+
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace, err := c.findNamespace(ctx, configuration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.FindCostConstraints(configuration, namespace, list)
+}
+
+// findVersionConstraints is used to find the terraform version constraints from all the policies
+// which select the configuration
+func (c *Controller) findVersionConstraints(
+	ctx context.Context,
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList) ([]string, error) {
+
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace, err := c.findNamespace(ctx, configuration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.FindVersionConstraints(configuration, namespace, list)
+}
+
+// findNamespace returns the namespace from the cache, falling back to the api
+func (c *Controller) findNamespace(ctx context.Context, name string) (client.Object, error) {
 	// @step: check the cache for the result
-	entry, found := c.cache.Get(configuration.Namespace)
+	entry, found := c.cache.Get(name)
 	if found {
-		return policies.FindMatchingPolicy(ctx, configuration, entry.(client.Object), list)
+		return entry.(client.Object), nil
 	}
 
 	namespace := &v1.Namespace{}
-	namespace.Name = configuration.Namespace
+	namespace.Name = name
 
 	if found, err := ksutils.GetIfExists(ctx, c.cc, namespace); err != nil {
 		return nil, err
 	} else if !found {
-		return nil, fmt.Errorf("namespace: %q was not found in the cache or api", configuration.Namespace)
+		return nil, fmt.Errorf("namespace: %q was not found in the cache or api", name)
 	}
 
-	return policies.FindMatchingPolicy(ctx, configuration, namespace, list)
+	return namespace, nil
 }
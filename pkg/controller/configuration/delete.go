@@ -20,10 +20,12 @@ package configuration
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,8 +38,40 @@ import (
 	"github.com/appvia/terranetes-controller/pkg/utils/filters"
 	"github.com/appvia/terranetes-controller/pkg/utils/jobs"
 	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
 )
 
+// ensureNoDependents is responsible for waiting on any configurations which depend on this
+// configuration to be deleted before we destroy the resources
+func (c *Controller) ensureNoDependents(configuration *terraformv1alpha1.Configuration) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		if configuration.GetAnnotations()[terraformv1alpha1.OrphanAnnotation] == "true" {
+			return reconcile.Result{}, nil
+		}
+
+		dependents, err := c.findDependents(ctx, configuration)
+		if err != nil {
+			cond.Failed(err, "Failed to list the configurations which depend on this configuration")
+
+			return reconcile.Result{}, err
+		}
+		if len(dependents) == 0 {
+			return reconcile.Result{}, nil
+		}
+
+		var names []string
+		for _, x := range dependents {
+			names = append(names, x.Name)
+		}
+		cond.InProgress("Waiting for dependent configuration(s) to be deleted: %s", strings.Join(names, ", "))
+
+		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+}
+
 // ensureTerraformDestroy is responsible for deleting any associated terraform configuration
 func (c *Controller) ensureTerraformDestroy(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
 	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
@@ -97,15 +131,19 @@ func (c *Controller) ensureTerraformDestroy(configuration *terraformv1alpha1.Con
 				map[string]string{
 					terraformv1alpha1.RetryAnnotation: configuration.GetAnnotations()[terraformv1alpha1.RetryAnnotation],
 				}),
-			BackoffLimit:     c.BackoffLimit,
-			EnableInfraCosts: c.EnableInfracosts,
-			ExecutorImage:    c.ExecutorImage,
-			ExecutorSecrets:  c.ExecutorSecrets,
-			InfracostsImage:  c.InfracostsImage,
-			InfracostsSecret: c.InfracostsSecretName,
-			Namespace:        c.ControllerNamespace,
-			Template:         state.jobTemplate,
-			TerraformImage:   GetTerraformImage(configuration, c.TerraformImage),
+			BackoffLimit:      c.BackoffLimit,
+			EnableInfraCosts:  c.EnableInfracosts,
+			EnableSourceCache: c.EnableSourceCache,
+			Engine:            state.engine,
+			Providers:         state.providers,
+			ExecutorImage:     c.ExecutorImage,
+			ExecutorSecrets:   c.ExecutorSecrets,
+			InfracostsImage:   c.InfracostsImage,
+			InfracostsSecret:  c.InfracostsSecretName,
+			LogStore:          c.LogStore,
+			Namespace:         c.ControllerNamespace,
+			Template:          state.jobTemplate,
+			TerraformImage:    c.GetEngineImage(state.engine, state.terraformVersion),
 		})
 		if err != nil {
 			cond.Failed(err, "Failed to create the terraform destroy job")
@@ -174,7 +212,11 @@ func (c *Controller) ensureConfigurationSecretsDeleted(configuration *terraformv
 		names := []string{
 			configuration.GetTerraformConfigSecretName(),
 			configuration.GetTerraformCostSecretName(),
+			configuration.GetTerraformMigrationSecretName(),
+			configuration.GetTerraformPlanSecretName(),
 			configuration.GetTerraformPolicySecretName(),
+			configuration.GetTerraformRegoSecretName(),
+			configuration.GetTerraformStateCommandSecretName(),
 			configuration.GetTerraformStateSecretName(),
 		}
 
@@ -190,6 +232,63 @@ func (c *Controller) ensureConfigurationSecretsDeleted(configuration *terraformv
 			}
 		}
 
+		// @step: delete any retained versions of the terraform state
+		versions, err := terraform.ListStateVersions(ctx, c.cc, c.ControllerNamespace, configuration)
+		if err != nil {
+			cond.Failed(err, "Failed to list the terraform state versions")
+
+			return reconcile.Result{}, err
+		}
+		for i := range versions {
+			if err := kubernetes.DeleteIfExists(ctx, c.cc, &versions[i]); err != nil {
+				cond.Failed(err, "Failed to delete the terraform state version (%s/%s)", versions[i].Namespace, versions[i].Name)
+
+				return reconcile.Result{}, err
+			}
+		}
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureConfigurationLogsDeleted is responsible for deleting any archived logs for the configuration
+func (c *Controller) ensureConfigurationLogsDeleted(configuration *terraformv1alpha1.Configuration) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		if c.logs == nil {
+			return reconcile.Result{}, nil
+		}
+
+		if err := c.logs.Delete(ctx, logstore.Key{
+			Name:      configuration.GetName(),
+			Namespace: configuration.GetNamespace(),
+			UID:       string(configuration.GetUID()),
+		}); err != nil {
+			cond.Failed(err, "Failed to delete the archived logs for the configuration")
+
+			return reconcile.Result{}, err
+		}
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureTerraformLockDeleted is responsible for deleting any lock left behind by the terraform jobs
+func (c *Controller) ensureTerraformLockDeleted(configuration *terraformv1alpha1.Configuration) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		lease := &coordinationv1.Lease{}
+		lease.Namespace = c.ControllerNamespace
+		lease.Name = configuration.GetTerraformLockName()
+
+		if err := kubernetes.DeleteIfExists(ctx, c.cc, lease); err != nil {
+			cond.Failed(err, "Failed to delete the terraform lock (%s/%s)", lease.Namespace, lease.Name)
+
+			return reconcile.Result{}, err
+		}
+
 		return reconcile.Result{}, nil
 	}
 }
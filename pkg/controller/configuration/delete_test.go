@@ -29,6 +29,7 @@ import (
 	cache "github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kfake "k8s.io/client-go/kubernetes/fake"
@@ -100,7 +101,10 @@ var _ = Describe("Configuration Controller with Contexts", func() {
 			InfracostsImage:     "infracosts/infracost:latest",
 			ControllerNamespace: "terraform-system",
 			PolicyImage:         "bridgecrew/checkov:2.0.1140",
+			RegoImage:           "openpolicyagent/conftest:v0.56.0",
+			TerraformEngine:     "terraform",
 			TerraformImage:      "hashicorp/terraform:1.1.9",
+			TofuImage:           "ghcr.io/opentofu/opentofu:1.6.2",
 		}
 
 		ctrl.cache.SetDefault("default", fixtures.NewNamespace("default"))
@@ -116,6 +120,15 @@ var _ = Describe("Configuration Controller with Contexts", func() {
 				)
 				Expect(cc.Update(context.Background(), configuration)).To(Succeed())
 
+				lease := &coordinationv1.Lease{}
+				lease.Namespace = ctrl.ControllerNamespace
+				lease.Name = configuration.GetTerraformLockName()
+				Expect(cc.Create(context.Background(), lease)).To(Succeed())
+
+				version := fixtures.NewTerraformStateVersion(configuration, 1)
+				version.Namespace = ctrl.ControllerNamespace
+				Expect(cc.Create(context.Background(), version)).To(Succeed())
+
 				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
 			})
 
@@ -129,6 +142,22 @@ var _ = Describe("Configuration Controller with Contexts", func() {
 				Expect(found).To(BeFalse())
 				Expect(secret).To(BeNil())
 			})
+
+			It("should have deleted the terraform state versions", func() {
+				_, found, err := kubernetes.GetSecretIfExists(context.TODO(), cc, ctrl.ControllerNamespace, configuration.GetTerraformStateVersionSecretName(1))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeFalse())
+			})
+
+			It("should have deleted the terraform lock", func() {
+				lease := &coordinationv1.Lease{}
+				lease.Namespace = ctrl.ControllerNamespace
+				lease.Name = configuration.GetTerraformLockName()
+
+				found, err := kubernetes.GetIfExists(context.TODO(), cc, lease)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeFalse())
+			})
 		})
 
 		Context("and we are checking for resources", func() {
@@ -187,6 +216,109 @@ var _ = Describe("Configuration Controller with Contexts", func() {
 			})
 		})
 
+		Context("and other configurations depend on it", func() {
+			BeforeEach(func() {
+				dependent := fixtures.NewValidBucketConfiguration("default", "dependent")
+				dependent.Spec.DependsOn = terraformv1alpha1.DependencyList{{Name: configuration.Name}}
+				Expect(cc.Create(context.Background(), dependent)).To(Succeed())
+
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+			})
+
+			It("should requeue", func() {
+				Expect(rerr).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(time.Second * 30))
+			})
+
+			It("should indicate the status in the conditions", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonInProgress))
+				Expect(cond.Message).To(Equal("Waiting for dependent configuration(s) to be deleted: dependent"))
+			})
+
+			It("should not create the destroy job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.Background(), list)).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+
+			It("should not delete the configuration", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("and a configuration it depends on is missing", func() {
+			Context("and no outputs are required from the dependency", func() {
+				BeforeEach(func() {
+					configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{
+						{
+							Name:    "missing",
+							Outputs: []terraformv1alpha1.DependencyOutput{{Key: "bucket_arn", Optional: true}},
+						},
+					}
+					Expect(cc.Update(context.Background(), configuration)).To(Succeed())
+
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+				})
+
+				It("should not error", func() {
+					Expect(rerr).ToNot(HaveOccurred())
+				})
+
+				It("should have created the destroy job", func() {
+					list := &batchv1.JobList{}
+					Expect(cc.List(context.Background(), list,
+						client.InNamespace(ctrl.ControllerNamespace),
+						client.MatchingLabels(map[string]string{
+							terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformDestroy,
+						},
+						))).ToNot(HaveOccurred())
+					Expect(list.Items).To(HaveLen(1))
+				})
+			})
+
+			Context("and an output is required from the dependency", func() {
+				BeforeEach(func() {
+					configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{
+						{
+							Name:    "missing",
+							Outputs: []terraformv1alpha1.DependencyOutput{{Key: "bucket_arn"}},
+						},
+					}
+					Expect(cc.Update(context.Background(), configuration)).To(Succeed())
+
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+				})
+
+				It("should requeue", func() {
+					Expect(rerr).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+				})
+
+				It("should indicate the status in the conditions", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonError))
+					Expect(cond.Message).To(Equal("spec.dependsOn[0] configuration (default/missing) does not exist, its outputs are required to destroy the configuration"))
+				})
+
+				It("should not create the destroy job", func() {
+					list := &batchv1.JobList{}
+					Expect(cc.List(context.Background(), list)).To(Succeed())
+					Expect(list.Items).To(BeEmpty())
+				})
+
+				It("should not delete the configuration", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				})
+			})
+		})
+
 		Context("and the provider is not ready", func() {
 			BeforeEach(func() {
 				provider.Status.GetCondition(corev1alpha1.ConditionReady).Status = metav1.ConditionFalse
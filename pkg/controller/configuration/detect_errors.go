@@ -21,6 +21,7 @@ import (
 	"context"
 	"io"
 	"regexp"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -79,6 +80,19 @@ func (c *Controller) ensureErrorDetection(configuration *terraformv1alpha1.Confi
 			return reconcile.Result{}, controller.ErrIgnore
 		}
 
+		// @step: check if the setup container rejected the module source, i.e. the signature of
+		// the module could not be verified
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name != jobs.SetupContainerName || status.State.Terminated == nil {
+				continue
+			}
+			if status.State.Terminated.ExitCode != 0 && status.State.Terminated.Message != "" {
+				cond.ActionRequired("%s", strings.TrimSpace(status.State.Terminated.Message))
+
+				return reconcile.Result{}, controller.ErrIgnore
+			}
+		}
+
 		// @step: find the terraform container and retrieve the logs
 		stream, err := c.kc.CoreV1().Pods(c.ControllerNamespace).GetLogs(pod.Name, &v1.PodLogOptions{
 			Container: jobs.TerraformContainerName,
@@ -44,6 +44,7 @@ import (
 	"github.com/appvia/terranetes-controller/pkg/utils/filters"
 	"github.com/appvia/terranetes-controller/pkg/utils/jobs"
 	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/policies"
 	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
 )
 
@@ -140,6 +141,47 @@ func (c *Controller) ensureCustomBackendTemplate(configuration *terraformv1alpha
 	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
 
 	return func(ctx context.Context) (reconcile.Result, error) {
+		// @step: is the provider using a typed backend
+		if state != nil && state.provider != nil && state.provider.HasBackend() {
+			backend := state.provider.Spec.Backend
+
+			template, err := terraform.GetBackendTemplate(backend)
+			if err != nil {
+				cond.ActionRequired("Provider %q backend is invalid: %s", state.provider.Name, err.Error())
+
+				return reconcile.Result{}, controller.ErrIgnore
+			}
+
+			if backend.HasSecretRef() {
+				secret := &v1.Secret{}
+				secret.Namespace = c.ControllerNamespace
+				secret.Name = backend.SecretRef.Name
+
+				found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+				if err != nil {
+					cond.Failed(err, "Failed to retrieve the backend secret")
+
+					return reconcile.Result{}, err
+				}
+				if !found {
+					cond.ActionRequired("Backend secret (%s/%s) not found, contact administrator", secret.Namespace, secret.Name)
+
+					return reconcile.Result{}, controller.ErrIgnore
+				}
+
+				state.backendCredentials = make(map[string]string)
+				for k, v := range secret.Data {
+					state.backendCredentials[k] = string(v)
+				}
+			}
+
+			state.backend = backend
+			state.backendTemplate = template
+			state.backendType = backend.GetType()
+
+			return reconcile.Result{}, nil
+		}
+
 		switch {
 		case c.HasBackendTemplate():
 			break
@@ -195,6 +237,7 @@ func (c *Controller) ensureCustomBackendTemplate(configuration *terraformv1alpha
 
 		// @step: update the template in the state
 		state.backendTemplate = string(backend)
+		state.backendType = terraformv1alpha1.BackendTypeCustom
 
 		return reconcile.Result{}, nil
 	}
@@ -248,6 +291,8 @@ func (c *Controller) ensureValueFromSecret(configuration *terraformv1alpha1.Conf
 			return reconcile.Result{}, nil
 		}
 
+		contexts := make(map[string]interface{})
+
 		for i, x := range configuration.Spec.ValueFrom {
 			switch {
 			case x.Secret != nil && x.Context != nil:
@@ -330,6 +375,7 @@ func (c *Controller) ensureValueFromSecret(configuration *terraformv1alpha1.Conf
 
 					return reconcile.Result{}, err
 				}
+				contexts[x.GetName()] = av["value"]
 				state.valueFrom[x.GetName()] = av["value"]
 
 			default:
@@ -337,6 +383,138 @@ func (c *Controller) ensureValueFromSecret(configuration *terraformv1alpha1.Conf
 			}
 		}
 
+		if len(contexts) == 0 {
+			return reconcile.Result{}, nil
+		}
+
+		// @step: record a checksum of the context values, a change to these requires a new plan
+		encoded, err := json.Marshal(contexts)
+		if err != nil {
+			cond.Failed(err, "Failed to encode the context values")
+
+			return reconcile.Result{}, err
+		}
+		state.contexts = utils.Sha256Sum(encoded)[0:16]
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureDependencies is responsible for ensuring the configurations we depend on are ready, and for
+// retrieving any outputs from the dependencies which are mapped to variables
+func (c *Controller) ensureDependencies(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		if len(configuration.Spec.DependsOn) == 0 {
+			return reconcile.Result{}, nil
+		}
+		outputs := make(map[string]interface{})
+
+		for i, x := range configuration.Spec.DependsOn {
+			dependency := &terraformv1alpha1.Configuration{}
+			dependency.Namespace = configuration.Namespace
+			dependency.Name = x.Name
+
+			found, err := kubernetes.GetIfExists(ctx, c.cc, dependency)
+			if err != nil {
+				cond.Failed(err, "Failed to retrieve the dependency spec.dependsOn[%d] (%s)", i, x.Name)
+
+				return reconcile.Result{}, err
+			}
+			if !found {
+				switch {
+				case configuration.GetDeletionTimestamp().IsZero():
+					cond.ActionRequired("spec.dependsOn[%d] configuration (%s/%s) does not exist", i, configuration.Namespace, x.Name)
+
+					return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+
+				case x.HasRequiredOutputs():
+					cond.Failed(nil, "spec.dependsOn[%d] configuration (%s/%s) does not exist, its outputs are required to destroy the configuration",
+						i, configuration.Namespace, x.Name)
+
+					return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+				}
+
+				// @note: the dependency has been removed before us, as we take no required outputs
+				// from it we can still be destroyed
+				continue
+			}
+
+			// @step: the dependency must be ready for its current generation, unless we are
+			// being deleted, in which case we only need the outputs for the destroy
+			if configuration.GetDeletionTimestamp().IsZero() {
+				ready := dependency.Status.GetCondition(corev1alpha1.ConditionReady)
+				if ready == nil || !ready.IsComplete(dependency.GetGeneration()) || !dependency.GetDeletionTimestamp().IsZero() {
+					cond.InProgress("Waiting for dependency spec.dependsOn[%d] (%s) to be ready", i, x.Name)
+
+					return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+				}
+			}
+
+			if len(x.Outputs) == 0 {
+				continue
+			}
+
+			// @step: retrieve the outputs from the terraform state of the dependency
+			secret, found, err := kubernetes.GetSecretIfExists(ctx, c.cc, c.ControllerNamespace, dependency.GetTerraformStateSecretName())
+			if err != nil {
+				cond.Failed(err, "Failed to retrieve the terraform state for dependency spec.dependsOn[%d] (%s)", i, x.Name)
+
+				return reconcile.Result{}, err
+			}
+			if !found {
+				switch {
+				case configuration.GetDeletionTimestamp().IsZero():
+					cond.InProgress("Waiting for the terraform state of dependency spec.dependsOn[%d] (%s)", i, x.Name)
+
+					return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+
+				case x.HasRequiredOutputs():
+					cond.Failed(nil, "Terraform state of dependency spec.dependsOn[%d] (%s) does not exist, its outputs are required to destroy the configuration",
+						i, x.Name)
+
+					return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+				}
+
+				continue
+			}
+
+			tfstate, err := terraform.DecodeState(secret.Data[terraformv1alpha1.TerraformStateSecretKey])
+			if err != nil {
+				cond.Failed(err, "Failed to decode the terraform state for dependency spec.dependsOn[%d] (%s)", i, x.Name)
+
+				return reconcile.Result{}, err
+			}
+
+			for j, output := range x.Outputs {
+				value, found := tfstate.Outputs[output.Key]
+				if !found {
+					if !output.Optional {
+						cond.ActionRequired("spec.dependsOn[%d].outputs[%d] dependency (%s) does not have output: %s", i, j, x.Name, output.Key)
+
+						return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+					}
+					continue
+				}
+				outputs[output.GetName()] = value.Value
+				state.valueFrom[output.GetName()] = value.Value
+			}
+		}
+
+		if len(outputs) == 0 {
+			return reconcile.Result{}, nil
+		}
+
+		// @step: record a checksum of the outputs, a change to these requires a new plan
+		encoded, err := json.Marshal(outputs)
+		if err != nil {
+			cond.Failed(err, "Failed to encode the dependency outputs")
+
+			return reconcile.Result{}, err
+		}
+		state.dependencies = utils.Sha256Sum(encoded)[0:16]
+
 		return reconcile.Result{}, nil
 	}
 }
@@ -441,29 +619,60 @@ func (c *Controller) ensureProviderReady(configuration *terraformv1alpha1.Config
 			return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 		}
 		state.provider = provider
+		state.engine = configuration.GetEngine(provider, c.TerraformEngine)
 
 		// @step: ensure we are permitted to use the provider
-		if provider.Spec.Selector != nil {
-			value, found := c.cache.Get(configuration.Namespace)
+		if permitted, err := c.isProviderPermitted(configuration, provider); err != nil {
+			cond.Failed(err, "Failed to check against the provider policy")
+
+			return reconcile.Result{}, err
+		} else if !permitted {
+			cond.ActionRequired("Provider policy does not permit the configuration to use it")
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+
+		// @step: retrieve and check any additional providers referenced by the configuration
+		state.providers = make([]*terraformv1alpha1.Provider, len(configuration.Spec.Providers))
+
+		for i, reference := range configuration.Spec.Providers {
+			provider := &terraformv1alpha1.Provider{}
+			provider.Name = reference.Name
+
+			found, err := kubernetes.GetIfExists(ctx, c.cc, provider)
+			if err != nil {
+				cond.Failed(err, "Failed to retrieve the provider for the configuration: %q", provider.Name)
+
+				return reconcile.Result{}, err
+			}
 			if !found {
-				cond.Failed(errors.New("namespace not found"), "Failed to retrieve the namespace from the cache")
+				cond.ActionRequired("Provider referenced %q does not exist", provider.Name)
+
+				return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
+			}
+			if provider.Status.GetCondition(corev1alpha1.ConditionReady).Status != metav1.ConditionTrue {
+				cond.Warning("Provider %q is not ready", provider.Name)
 
 				return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 			}
-			namespace := value.(*v1.Namespace)
 
-			// @step: ensure we have match the selector of the provider - i.e our namespace and resource labels must match
-			match, err := kubernetes.IsSelectorMatch(*provider.Spec.Selector, configuration.GetLabels(), namespace.GetLabels())
-			if err != nil {
+			if permitted, err := c.isProviderPermitted(configuration, provider); err != nil {
 				cond.Failed(err, "Failed to check against the provider policy")
 
 				return reconcile.Result{}, err
-			}
-			if !match {
-				cond.ActionRequired("Provider policy does not permit the configuration to use it")
+			} else if !permitted {
+				cond.ActionRequired("Provider policy for %q does not permit the configuration to use it", provider.Name)
 
 				return reconcile.Result{}, controller.ErrIgnore
 			}
+			state.providers[i] = provider
+		}
+
+		// @step: ensure the providers can be used together
+		if err := configuration.IsCompatibleProviders(state.provider, state.providers); err != nil {
+			cond.ActionRequired("Providers cannot be used together: %s", err)
+
+			return reconcile.Result{}, controller.ErrIgnore
 		}
 		cond.Success("Provider ready")
 
@@ -471,6 +680,22 @@ func (c *Controller) ensureProviderReady(configuration *terraformv1alpha1.Config
 	}
 }
 
+// isProviderPermitted checks the configuration is permitted to use the provider, i.e. the namespace
+// and resource labels match the selector on the provider
+func (c *Controller) isProviderPermitted(configuration *terraformv1alpha1.Configuration, provider *terraformv1alpha1.Provider) (bool, error) {
+	if provider.Spec.Selector == nil {
+		return true, nil
+	}
+
+	value, found := c.cache.Get(configuration.Namespace)
+	if !found {
+		return false, errors.New("namespace not found")
+	}
+	namespace := value.(*v1.Namespace)
+
+	return kubernetes.IsSelectorMatch(*provider.Spec.Selector, configuration.GetLabels(), namespace.GetLabels())
+}
+
 // ensurePolicyDefaultsExist is responsible for ensuring any default secrets which are being injected
 // are available for the this configuration
 func (c *Controller) ensurePolicyDefaultsExist(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
@@ -536,6 +761,32 @@ func (c *Controller) ensurePolicyDefaultsExist(configuration *terraformv1alpha1.
 	}
 }
 
+// ensureTerraformVersion is responsible for resolving the version of terraform used to run the
+// configuration, ensuring the version is permitted by any version constraints in the policies
+func (c *Controller) ensureTerraformVersion(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		constraints, err := c.findVersionConstraints(ctx, configuration, state.policies)
+		if err != nil {
+			cond.Failed(err, "Failed to retrieve the version constraints from the policies")
+
+			return reconcile.Result{}, err
+		}
+
+		version, err := terraform.ResolveVersion(configuration.Spec.TerraformVersion, c.GetEngineVersions(state.engine), constraints)
+		if err != nil {
+			cond.ActionRequired("Terraform version cannot be resolved, %s", err)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+		state.terraformVersion = version
+		configuration.Status.ResolvedTerraformVersion = version
+
+		return reconcile.Result{}, nil
+	}
+}
+
 // ensureJobConfigurationSecret is responsible in ensuring the terraform configuration is generated for this job. This
 // includes the backend configuration and the variables which have been included in the configuration
 func (c *Controller) ensureJobConfigurationSecret(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
@@ -563,7 +814,9 @@ func (c *Controller) ensureJobConfigurationSecret(configuration *terraformv1alph
 		// @step: generate the terraform backend configuration - this creates a kubernetes terraform
 		// backend pointing at a secret
 		cfg, err := terraform.NewKubernetesBackend(terraform.BackendOptions{
+			Backend:       state.backend,
 			Configuration: configuration,
+			Credentials:   state.backendCredentials,
 			Namespace:     c.ControllerNamespace,
 			Suffix:        suffix,
 			Template:      state.backendTemplate,
@@ -575,15 +828,72 @@ func (c *Controller) ensureJobConfigurationSecret(configuration *terraformv1alph
 		}
 		secret.Data = map[string][]byte{terraformv1alpha1.TerraformBackendSecretKey: cfg}
 
-		// @step: generate the provider for the terraform configuration
-		cfg, err = terraform.NewTerraformProvider(string(state.provider.Spec.Provider), state.provider.GetConfiguration())
+		// @step: generate the providers for the terraform configuration, aliasing the additional
+		// providers where required
+		providers := append([]*terraformv1alpha1.Provider{state.provider}, state.providers...)
+
+		cfg = nil
+		for i, provider := range providers {
+			if provider == nil {
+				continue
+			}
+			var alias string
+			if i > 0 {
+				alias = configuration.Spec.Providers[i-1].Alias
+			}
+
+			config, err := terraform.NewProviderConfiguration(provider)
+			if err != nil {
+				cond.Failed(err, "Failed to generate the terraform provider configuration for %q", provider.Name)
+
+				return reconcile.Result{}, err
+			}
+			block, err := terraform.NewTerraformProviderWithAlias(string(provider.Spec.Provider), alias, config)
+			if err != nil {
+				cond.Failed(err, "Failed to generate the terraform provider configuration for %q", provider.Name)
+
+				return reconcile.Result{}, err
+			}
+			if len(cfg) > 0 {
+				cfg = append(cfg, '\n')
+			}
+			cfg = append(cfg, block...)
+
+			// @step: add any credentials required to exchange the projected token
+			credentials, err := terraform.NewFederatedCredentials(provider)
+			if err != nil {
+				cond.Failed(err, "Failed to generate the federated credentials for %q", provider.Name)
+
+				return reconcile.Result{}, err
+			}
+			if len(credentials) > 0 {
+				secret.Data[provider.GetFederatedCredentialsKey()] = credentials
+			}
+		}
+
+		// @step: generate the required providers for any providers sourced from the registry
+		required, err := terraform.NewTerraformRequiredProviders(providers)
 		if err != nil {
-			cond.Failed(err, "Failed to generate the terraform provider configuration")
+			cond.Failed(err, "Failed to generate the terraform required providers configuration")
 
 			return reconcile.Result{}, err
 		}
+		if len(required) > 0 {
+			cfg = append(append(required, '\n'), cfg...)
+		}
 		secret.Data[terraformv1alpha1.TerraformProviderConfigMapKey] = cfg
 
+		// @step: generate the import blocks for any resources yet to be imported
+		if imports := configuration.GetPendingImports(); len(imports) > 0 {
+			cfg, err = terraform.NewTerraformImports(imports)
+			if err != nil {
+				cond.Failed(err, "Failed to generate the terraform import configuration")
+
+				return reconcile.Result{}, err
+			}
+			secret.Data[terraformv1alpha1.TerraformImportsSecretKey] = cfg
+		}
+
 		// @step: we need to generate the value from the variables
 		variables, err := configuration.Spec.GetVariables()
 		if err != nil {
@@ -650,6 +960,34 @@ func (c *Controller) ensureJobConfigurationSecret(configuration *terraformv1alph
 			}
 		}
 
+		// @step: find any rego constraint and write the inline modules into the secret
+		rego, err := c.findMatchingRegoPolicy(ctx, configuration, state.policies)
+		if err != nil {
+			policyCondition.Failed(err, "Failed to find matching rego policy constraints")
+
+			return reconcile.Result{}, err
+		}
+		if rego != nil {
+			state.regoConstraint = rego
+
+			for _, module := range rego.Modules {
+				secret.Data[module.GetKey()] = []byte(module.Content)
+			}
+		}
+
+		// @step: find any keys trusted to sign the module and write them into the secret
+		keys, err := c.findSignatureKeys(ctx, configuration, state.policies)
+		if err != nil {
+			policyCondition.Failed(err, "Failed to find matching signature policy constraints")
+
+			return reconcile.Result{}, err
+		}
+		state.signatureKeys = keys
+
+		for _, key := range keys {
+			secret.Data[key.GetKey()] = []byte(key.PublicKey)
+		}
+
 		if err := kubernetes.CreateOrPatch(ctx, c.cc, secret); err != nil {
 			cond.Failed(err, "Failed to create or update the configuration secret")
 
@@ -682,11 +1020,45 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 				"namespace": configuration.Namespace,
 			}).Info("retrying the configuration")
 
+		// @note: a replace or target operation has been requested which has not been planned yet
+		case state.operation != "" && configuration.Status.TerraformPlan.GetOperation() != state.operation:
+			log.WithFields(log.Fields{
+				"arguments": strings.Join(state.operationArguments, " "),
+				"name":      configuration.Name,
+				"namespace": configuration.Namespace,
+			}).Info("operation has been requested, running a new plan")
+
 		// @note: the last plan failed for this generation - we do not run it again
 		case cond.GetCondition().IsFailed(configuration.GetGeneration()):
 			return reconcile.Result{}, controller.ErrIgnore
 
 		case cond.GetCondition().IsComplete(configuration.GetGeneration()):
+			// @note: the outputs consumed from our dependencies have changed since the last plan
+			if configuration.Status.TerraformPlan.GetDependencies() != state.dependencies {
+				log.WithFields(log.Fields{
+					"name":      configuration.Name,
+					"namespace": configuration.Namespace,
+				}).Info("dependency outputs have changed, running a new plan")
+
+				break
+			}
+
+			// @note: plans produced before the context values were recorded carry no checksum, we adopt
+			// the current values rather than trigger a new plan on upgrade
+			if plan := configuration.Status.TerraformPlan; plan != nil && plan.Contexts == "" {
+				plan.Contexts = state.contexts
+			}
+
+			// @note: the values consumed from contexts have changed since the last plan
+			if plan := configuration.Status.TerraformPlan; plan != nil && plan.Contexts != state.contexts {
+				log.WithFields(log.Fields{
+					"name":      configuration.Name,
+					"namespace": configuration.Namespace,
+				}).Info("context values have changed, running a new plan")
+
+				break
+			}
+
 			if !configuration.Spec.EnableDriftDetection || configuration.GetAnnotations()[terraformv1alpha1.DriftAnnotation] == "" {
 				// @note: this is effectively checking the status of plan condition - if the condition is True
 				// for the given generation we can say the plan has already been run and can move on
@@ -695,10 +1067,7 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 		}
 
 		// @step: should we save the terraform state
-		saveState := c.HasBackendTemplate()
-		if state.provider != nil && state.provider.HasBackendTemplate() {
-			saveState = true
-		}
+		saveState := state.backendType != terraformv1alpha1.BackendTypeKubernetes
 
 		// @step: lets build the options to render the job
 		options := jobs.Options{
@@ -710,21 +1079,32 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 				state.provider.JobLabels(),
 				configuration.GetLabels(),
 				map[string]string{
-					terraformv1alpha1.DriftAnnotation: configuration.GetAnnotations()[terraformv1alpha1.DriftAnnotation],
-					terraformv1alpha1.RetryAnnotation: configuration.GetAnnotations()[terraformv1alpha1.RetryAnnotation],
+					terraformv1alpha1.ConfigurationContextsLabel:     state.contexts,
+					terraformv1alpha1.ConfigurationDependenciesLabel: state.dependencies,
+					terraformv1alpha1.ConfigurationOperationLabel:    state.operation,
+					terraformv1alpha1.DriftAnnotation:                configuration.GetAnnotations()[terraformv1alpha1.DriftAnnotation],
+					terraformv1alpha1.RetryAnnotation:                configuration.GetAnnotations()[terraformv1alpha1.RetryAnnotation],
 				}),
 			BackoffLimit:       c.BackoffLimit,
 			EnableInfraCosts:   c.EnableInfracosts,
+			EnableSourceCache:  c.EnableSourceCache,
+			Engine:             state.engine,
+			Providers:          state.providers,
 			ExecutorImage:      c.ExecutorImage,
 			ExecutorSecrets:    c.ExecutorSecrets,
 			InfracostsImage:    c.InfracostsImage,
 			InfracostsSecret:   c.InfracostsSecretName,
+			LogStore:           c.LogStore,
 			Namespace:          c.ControllerNamespace,
 			PolicyConstraint:   state.checkovConstraint,
 			PolicyImage:        c.PolicyImage,
+			RegoConstraint:     state.regoConstraint,
+			RegoImage:          c.RegoImage,
 			SaveTerraformState: saveState,
+			SignatureKeys:      state.signatureKeys,
 			Template:           state.jobTemplate,
-			TerraformImage:     GetTerraformImage(configuration, c.TerraformImage),
+			TerraformArguments: state.operationArguments,
+			TerraformImage:     c.GetEngineImage(state.engine, state.terraformVersion),
 		}
 
 		// @step: use the options to generate the job
@@ -738,6 +1118,9 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 		// @step: search for any current jobs
 		job, found := filters.Jobs(state.jobs).
 			WithGeneration(generation).
+			WithLabel(terraformv1alpha1.ConfigurationContextsLabel, state.contexts).
+			WithLabel(terraformv1alpha1.ConfigurationDependenciesLabel, state.dependencies).
+			WithLabel(terraformv1alpha1.ConfigurationOperationLabel, state.operation).
 			WithLabel(terraformv1alpha1.DriftAnnotation, configuration.GetAnnotations()[terraformv1alpha1.DriftAnnotation]).
 			WithLabel(terraformv1alpha1.RetryAnnotation, configuration.GetAnnotations()[terraformv1alpha1.RetryAnnotation]).
 			WithName(configuration.GetName()).
@@ -747,47 +1130,16 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 			Latest()
 
 		if !found {
-			// @step: if auto approval is not enabled we should annotate the configuration with the need to approve.
-			if !configuration.Spec.EnableAutoApproval && !configuration.NeedsApproval() {
-
-				original := configuration.DeepCopy()
-				if configuration.Annotations == nil {
-					configuration.Annotations = map[string]string{}
-				}
-				configuration.Annotations[terraformv1alpha1.ApplyAnnotation] = "false"
+			remediating := isDriftRemediation(configuration, configuration.GetAnnotations()[terraformv1alpha1.DriftAnnotation])
 
-				if err := c.cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
-					cond.Failed(err, "Failed to create or update the terraform configuration")
+			// @step: if auto approval is not enabled we should annotate the configuration with the need to approve.
+			if !configuration.Spec.EnableAutoApproval && !configuration.NeedsApproval() && !remediating {
+				if err := c.requestApproval(ctx, configuration); err != nil {
+					cond.Failed(err, "Failed to request approval for the terraform plan")
 
 					return reconcile.Result{}, err
 				}
 
-				// @step: if the configuration is part of a managed plan, we should update the cloudresource
-				// to reflect the need to approve
-				if configuration.IsManaged() {
-					cloudresource := &terraformv1alpha1.CloudResource{}
-					cloudresource.Namespace = configuration.Namespace
-					cloudresource.Name = configuration.GetLabels()[terraformv1alpha1.CloudResourceNameLabel]
-
-					if found, err := kubernetes.GetIfExists(ctx, c.cc, cloudresource); err != nil {
-						cond.Failed(err, "Failed to retrieve the cloudresource: %q which this configuration is part of", cloudresource.Name)
-
-						return reconcile.Result{}, err
-					} else if !found {
-						cond.Failed(err, "CloudResource: %q which this configuration is part of is missing", cloudresource.Name)
-
-						return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
-					}
-					original := cloudresource.DeepCopy()
-					cloudresource.Annotations[terraformv1alpha1.ApplyAnnotation] = "false"
-
-					if err := c.cc.Patch(ctx, cloudresource, client.MergeFrom(original)); err != nil {
-						cond.Failed(err, "Failed to create or update the cloudresource: %q", cloudresource.Name)
-
-						return reconcile.Result{}, err
-					}
-				}
-
 				return controller.RequeueImmediate, nil
 			}
 
@@ -809,18 +1161,30 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 			}
 			cond.InProgress("Terraform plan in progress")
 
+			if state.operation != "" {
+				c.recorder.Eventf(configuration, v1.EventTypeNormal, "OperationRequested",
+					"Terraform plan is running with the operation: %s", strings.Join(state.operationArguments, " "))
+			}
+
 			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 
 		// @step: we only shift out of this state of the job is complete
 		switch {
 		case jobs.IsComplete(job):
+			if err := c.recordTerraformPlan(ctx, configuration, job); err != nil {
+				cond.Failed(err, "Failed to record the terraform plan checksum")
+
+				return reconcile.Result{}, err
+			}
+			recordRunHistory(configuration, job, terraformv1alpha1.StageTerraformPlan)
 			cond.Success("Terraform plan is complete")
 
 			return reconcile.Result{}, nil
 
 		case jobs.IsFailed(job):
 			cond.Failed(nil, "Terraform plan is failed")
+			recordRunHistory(configuration, job, terraformv1alpha1.StageTerraformPlan)
 
 			return c.ensureErrorDetection(configuration, job, state)(ctx)
 
@@ -832,8 +1196,129 @@ func (c *Controller) ensureTerraformPlan(configuration *terraformv1alpha1.Config
 	}
 }
 
+// requestApproval annotates the configuration, and the cloudresource it is part of, with the need to
+// approve the terraform plan
+func (c *Controller) requestApproval(ctx context.Context, configuration *terraformv1alpha1.Configuration) error {
+	// @note: we patch a copy, as the patch would otherwise replace the status we are building
+	patched := configuration.DeepCopy()
+	if patched.Annotations == nil {
+		patched.Annotations = map[string]string{}
+	}
+	patched.Annotations[terraformv1alpha1.ApplyAnnotation] = "false"
+
+	if err := c.cc.Patch(ctx, patched, client.MergeFrom(configuration)); err != nil {
+		return err
+	}
+	configuration.Annotations = patched.Annotations
+
+	// @step: if the configuration is part of a managed plan, we should update the cloudresource
+	// to reflect the need to approve
+	if !configuration.IsManaged() {
+		return nil
+	}
+	cloudresource := &terraformv1alpha1.CloudResource{}
+	cloudresource.Namespace = configuration.Namespace
+	cloudresource.Name = configuration.GetLabels()[terraformv1alpha1.CloudResourceNameLabel]
+
+	found, err := kubernetes.GetIfExists(ctx, c.cc, cloudresource)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("cloudresource %q which this configuration is part of is missing", cloudresource.Name)
+	}
+	original := cloudresource.DeepCopy()
+	if cloudresource.Annotations == nil {
+		cloudresource.Annotations = map[string]string{}
+	}
+	cloudresource.Annotations[terraformv1alpha1.ApplyAnnotation] = "false"
+
+	return c.cc.Patch(ctx, cloudresource, client.MergeFrom(original))
+}
+
+// recordTerraformPlan is responsible for recording the checksum of the terraform plan produced by
+// the plan job. The checksum is used by the apply stage to ensure we only apply the plan which was
+// approved
+func (c *Controller) recordTerraformPlan(ctx context.Context, configuration *terraformv1alpha1.Configuration, job *batchv1.Job) error {
+	// @step: we only need to record the plan once per job
+	if configuration.Status.TerraformPlan != nil && configuration.Status.TerraformPlan.Job == job.GetName() {
+		return nil
+	}
+
+	secret := &v1.Secret{}
+	secret.Namespace = c.ControllerNamespace
+	secret.Name = configuration.GetTerraformPlanSecretName()
+
+	found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+	if err != nil {
+		return err
+	}
+	if !found || len(secret.Data[terraformv1alpha1.TerraformPlanSecretKey]) == 0 {
+		log.WithFields(log.Fields{
+			"job":       job.GetName(),
+			"name":      configuration.GetName(),
+			"namespace": configuration.GetNamespace(),
+		}).Warn("no terraform plan found for the completed plan job")
+
+		return nil
+	}
+
+	status := &terraformv1alpha1.TerraformPlanStatus{
+		Checksum:     utils.Sha256Sum(secret.Data[terraformv1alpha1.TerraformPlanSecretKey]),
+		Contexts:     job.GetLabels()[terraformv1alpha1.ConfigurationContextsLabel],
+		Dependencies: job.GetLabels()[terraformv1alpha1.ConfigurationDependenciesLabel],
+		Generation:   configuration.GetGeneration(),
+		Job:          job.GetName(),
+		Operation:    job.GetLabels()[terraformv1alpha1.ConfigurationOperationLabel],
+	}
+
+	// @step: record the summary of the resource changes produced by the plan job
+	if encoded, found := secret.Data[terraformv1alpha1.TerraformPlanChangesSecretKey]; found {
+		changes := &terraformv1alpha1.TerraformPlanChanges{}
+		if err := json.Unmarshal(encoded, changes); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"job":       job.GetName(),
+				"name":      configuration.GetName(),
+				"namespace": configuration.GetNamespace(),
+			}).Warn("failed to parse the resource changes from the terraform plan")
+		} else {
+			status.Changes = changes
+		}
+	}
+	previous := configuration.Status.TerraformPlan
+	configuration.Status.TerraformPlan = status
+
+	// @step: an approval given while a different plan was recorded is not an approval of this plan, i.e.
+	// approving while a retry or re-plan was running, so we must ask for the plan to be approved again
+	if previous != nil && previous.Checksum != "" && previous.Checksum != status.Checksum &&
+		configuration.IsApprovalRequired() && !configuration.NeedsApproval() &&
+		!isDriftRemediation(configuration, job.GetLabels()[terraformv1alpha1.DriftAnnotation]) {
+		if err := c.requestApproval(ctx, configuration); err != nil {
+			return err
+		}
+	}
+
+	// @step: record the revision of the module source the plan was produced from
+	if encoded, found := secret.Data[terraformv1alpha1.TerraformSourceSecretKey]; found {
+		source := &terraformv1alpha1.SourceStatus{}
+		if err := json.Unmarshal(encoded, source); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"job":       job.GetName(),
+				"name":      configuration.GetName(),
+				"namespace": configuration.GetNamespace(),
+			}).Warn("failed to parse the revision of the module source")
+
+			return nil
+		}
+		source.Generation = configuration.GetGeneration()
+		configuration.Status.Source = source
+	}
+
+	return nil
+}
+
 // ensureCostStatus is responsible for updating the cost status post a plan
-func (c *Controller) ensureCostStatus(configuration *terraformv1alpha1.Configuration) controller.EnsureFunc {
+func (c *Controller) ensureCostStatus(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
 	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
 	labels := []string{configuration.GetNamespace(), configuration.GetName()}
 
@@ -894,6 +1379,23 @@ func (c *Controller) ensureCostStatus(configuration *terraformv1alpha1.Configura
 			Monthly: fmt.Sprintf("$%v", values["totalMonthlyCost"]),
 		}
 
+		// @step: record the estimate for any cost constraints, the increase is taken from the
+		// diff when present, otherwise the entire cost is considered new
+		state.costs = &policies.CostEstimate{
+			Monthly:         values["totalMonthlyCost"],
+			MonthlyIncrease: values["totalMonthlyCost"],
+		}
+		if diff := gjson.GetBytes(input, "diffTotalMonthlyCost"); diff.Exists() {
+			if value, err := strconv.ParseFloat(diff.String(), 64); err == nil {
+				state.costs.MonthlyIncrease = value
+			}
+		}
+
+		// @step: record the cost delta against the plan which produced the estimate
+		if run, found := configuration.Status.GetRun(configuration.Status.TerraformPlan.GetJob()); found {
+			run.CostDelta = fmt.Sprintf("$%.2f", state.costs.MonthlyIncrease)
+		}
+
 		// @step: update the prometheus metrics
 		monthlyCostMetric.WithLabelValues(labels...).Set(values["totalMonthlyCost"])
 		hourlyCostMetric.WithLabelValues(labels...).Set(values["totalHourlyCost"])
@@ -926,99 +1428,236 @@ func (c *Controller) ensureCostStatus(configuration *terraformv1alpha1.Configura
 	}
 }
 
-// ensurePolicyStatus is responsible for checking the checkov results and refusing to continue if failed
+// ensurePolicyStatus is responsible for checking the checkov and rego results and refusing to continue if failed
 func (c *Controller) ensurePolicyStatus(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
 	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformPolicy, c.recorder)
-	key := "results_json.json"
 
 	return func(ctx context.Context) (reconcile.Result, error) {
 		switch {
-		case state.checkovConstraint == nil:
+		case state.checkovConstraint == nil && state.regoConstraint == nil:
 			cond.Success("Security policy is not configured")
 
 			return reconcile.Result{}, nil
 		}
 
-		// @step: retrieve the uploaded scan
-		secret := &v1.Secret{}
-		secret.Namespace = c.ControllerNamespace
-		secret.Name = configuration.GetTerraformPolicySecretName()
-
-		found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
-		if err != nil {
-			cond.Failed(err, "Failed to retrieve the secret containing the checkov scan")
+		if state.checkovConstraint != nil {
+			if result, err := c.ensureCheckovStatus(ctx, configuration); err != nil || result.RequeueAfter > 0 {
+				return result, err
+			}
+		}
 
-			return reconcile.Result{}, err
+		if state.regoConstraint != nil {
+			if result, err := c.ensureRegoStatus(ctx, configuration); err != nil || result.RequeueAfter > 0 {
+				return result, err
+			}
 		}
-		if !found {
-			cond.Warning("Failed to find the secret: (%s/%s) containing checkov scan", c.ControllerNamespace, configuration.GetTerraformPolicySecretName())
 
-			return reconcile.Result{RequeueAfter: 10 * time.Minute}, nil
+		cond.Success("Passed security checks")
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureCheckovStatus is responsible for checking the checkov scan uploaded by the plan
+func (c *Controller) ensureCheckovStatus(ctx context.Context, configuration *terraformv1alpha1.Configuration) (reconcile.Result, error) {
+	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformPolicy, c.recorder)
+	key := "results_json.json"
+
+	// @step: retrieve the uploaded scan
+	secret := &v1.Secret{}
+	secret.Namespace = c.ControllerNamespace
+	secret.Name = configuration.GetTerraformPolicySecretName()
+
+	found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+	if err != nil {
+		cond.Failed(err, "Failed to retrieve the secret containing the checkov scan")
+
+		return reconcile.Result{}, err
+	}
+	if !found {
+		cond.Warning("Failed to find the secret: (%s/%s) containing checkov scan", c.ControllerNamespace, configuration.GetTerraformPolicySecretName())
+
+		return reconcile.Result{RequeueAfter: 10 * time.Minute}, nil
+	}
+
+	var failed gjson.Result
+
+	// @step: retrieve summary from the report
+	if gjson.GetBytes(secret.Data[key], "summary").Exists() {
+		failed = gjson.GetBytes(secret.Data[key], "summary.failed")
+		if !failed.Exists() {
+			cond.Failed(errors.New("missing report"), "Security report does not contain a summary of finding, please contact platform administrator")
+
+			return reconcile.Result{}, controller.ErrIgnore
 		}
 
-		var failed gjson.Result
+		if failed.Type != gjson.Number {
+			cond.Failed(errors.New("invalid resport"), "Security report failed summary is not numerical as expected, please contact platform administrator")
 
-		// @step: retrieve summary from the report
-		if gjson.GetBytes(secret.Data[key], "summary").Exists() {
-			failed = gjson.GetBytes(secret.Data[key], "summary.failed")
-			if !failed.Exists() {
-				cond.Failed(errors.New("missing report"), "Security report does not contain a summary of finding, please contact platform administrator")
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+	} else {
+		for _, x := range []string{"passed", "failed"} {
+			if !gjson.GetBytes(secret.Data[key], x).Exists() {
+				cond.Failed(errors.New("invalid policy report"), "Security report is missing %s field", x)
 
 				return reconcile.Result{}, controller.ErrIgnore
 			}
-
-			if failed.Type != gjson.Number {
-				cond.Failed(errors.New("invalid resport"), "Security report failed summary is not numerical as expected, please contact platform administrator")
+			if gjson.GetBytes(secret.Data[key], "zero").Int() != 0 {
+				cond.Failed(errors.New("invalid policy report"), "Security report is field %s is non-zero", x)
 
 				return reconcile.Result{}, controller.ErrIgnore
 			}
-		} else {
-			for _, x := range []string{"passed", "failed"} {
-				if !gjson.GetBytes(secret.Data[key], x).Exists() {
-					cond.Failed(errors.New("invalid policy report"), "Security report is missing %s field", x)
+		}
+	}
 
-					return reconcile.Result{}, controller.ErrIgnore
-				}
-				if gjson.GetBytes(secret.Data[key], "zero").Int() != 0 {
-					cond.Failed(errors.New("invalid policy report"), "Security report is field %s is non-zero", x)
+	// @step: copy the report into the configuration namespace
+	if err := c.copyPolicyReport(ctx, configuration, secret); err != nil {
+		cond.Failed(err, "Failed to create or update the terraform policy secret")
 
-					return reconcile.Result{}, controller.ErrIgnore
-				}
-			}
+		return reconcile.Result{}, err
+	}
+
+	if failed.Int() > 0 {
+		cond.ActionRequired("Configuration has failed security policy, refusing to continue")
+
+		return reconcile.Result{}, controller.ErrIgnore
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// ensureRegoStatus is responsible for checking the rego results uploaded by the plan. Any deny
+// rules block the configuration, while warnings are raised as events
+func (c *Controller) ensureRegoStatus(ctx context.Context, configuration *terraformv1alpha1.Configuration) (reconcile.Result, error) {
+	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformPolicy, c.recorder)
+
+	// @step: retrieve the uploaded results
+	secret := &v1.Secret{}
+	secret.Namespace = c.ControllerNamespace
+	secret.Name = configuration.GetTerraformRegoSecretName()
+
+	found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+	if err != nil {
+		cond.Failed(err, "Failed to retrieve the secret containing the rego results")
+
+		return reconcile.Result{}, err
+	}
+	if !found {
+		cond.Warning("Failed to find the secret: (%s/%s) containing rego results", c.ControllerNamespace, configuration.GetTerraformRegoSecretName())
+
+		return reconcile.Result{RequeueAfter: 10 * time.Minute}, nil
+	}
+
+	report, err := policies.ParseRegoReport(secret.Data["rego_results.json"])
+	if err != nil {
+		cond.Failed(err, "Rego report is invalid, please contact platform administrator")
+
+		return reconcile.Result{}, controller.ErrIgnore
+	}
+
+	// @step: copy the report into the configuration namespace
+	if err := c.copyPolicyReport(ctx, configuration, secret); err != nil {
+		cond.Failed(err, "Failed to create or update the rego policy secret")
+
+		return reconcile.Result{}, err
+	}
+
+	for _, x := range report.Warnings {
+		c.recorder.Event(configuration, v1.EventTypeWarning, "PolicyWarning", x)
+	}
+
+	if report.HasFailures() {
+		messages := report.Failures
+		if len(messages) > 5 {
+			messages = append(messages[:5:5], fmt.Sprintf("and %d more", len(report.Failures)-5))
 		}
+		cond.ActionRequired("Configuration has failed %d rego policy rule(s), refusing to continue: %s",
+			len(report.Failures), strings.Join(messages, "; "))
 
-		// @step: copy the report into the configuration namespace
-		copied := &v1.Secret{}
-		copied.Namespace = configuration.GetNamespace()
-		copied.Name = configuration.GetTerraformPolicySecretName()
-		copied.Labels = map[string]string{
-			terraformv1alpha1.ConfigurationNameLabel: configuration.GetName(),
-			terraformv1alpha1.ConfigurationUIDLabel:  string(configuration.GetUID()),
+		return reconcile.Result{}, controller.ErrIgnore
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// copyPolicyReport is used to copy a policy report from the controller namespace into the
+// namespace of the configuration
+func (c *Controller) copyPolicyReport(ctx context.Context, configuration *terraformv1alpha1.Configuration, secret *v1.Secret) error {
+	copied := &v1.Secret{}
+	copied.Namespace = configuration.GetNamespace()
+	copied.Name = secret.Name
+	copied.Labels = map[string]string{
+		terraformv1alpha1.ConfigurationNameLabel: configuration.GetName(),
+		terraformv1alpha1.ConfigurationUIDLabel:  string(configuration.GetUID()),
+	}
+	copied.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: terraformv1alpha1.SchemeGroupVersion.String(),
+			Kind:       terraformv1alpha1.ConfigurationKind,
+			Name:       configuration.GetName(),
+			UID:        configuration.GetUID(),
+		},
+	}
+	copied.Data = secret.Data
+
+	return kubernetes.CreateOrForceUpdate(ctx, c.cc, copied)
+}
+
+// ensureCostBudget is responsible for checking the predicted costs against any cost constraints and
+// refusing to continue if a budget has been breached
+func (c *Controller) ensureCostBudget(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformPolicy, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		// @note: the changes have already been applied, there is nothing left to block
+		if isTerraformPlanApplied(configuration) {
+			return reconcile.Result{}, nil
 		}
-		copied.OwnerReferences = []metav1.OwnerReference{
-			{
-				APIVersion: terraformv1alpha1.SchemeGroupVersion.String(),
-				Kind:       terraformv1alpha1.ConfigurationKind,
-				Name:       configuration.GetName(),
-				UID:        configuration.GetUID(),
-			},
+
+		constraints, err := c.findCostConstraints(ctx, configuration, state.policies)
+		if err != nil {
+			cond.Failed(err, "Failed to find matching cost constraints")
+
+			return reconcile.Result{}, err
+		}
+		if len(constraints) == 0 {
+			return reconcile.Result{}, nil
 		}
-		copied.Data = secret.Data
 
-		if err := kubernetes.CreateOrForceUpdate(ctx, c.cc, copied); err != nil {
-			cond.Failed(err, "Failed to create or update the terraform policy secret")
+		if state.costs == nil {
+			cond.Warning("Configuration is selected by a cost budget, but no cost estimate is available to check against")
+
+			return reconcile.Result{}, nil
+		}
+
+		// @step: add up the predicted costs of the other configurations in the namespace
+		list := &terraformv1alpha1.ConfigurationList{}
+		if err := c.cc.List(ctx, list, client.InNamespace(configuration.Namespace)); err != nil {
+			cond.Failed(err, "Failed to list the configurations in the namespace")
 
 			return reconcile.Result{}, err
 		}
 
-		if failed.Int() > 0 {
-			cond.ActionRequired("Configuration has failed security policy, refusing to continue")
+		estimate := *state.costs
+		estimate.NamespaceMonthly = estimate.Monthly
+		for _, x := range list.Items {
+			if x.GetUID() == configuration.GetUID() || x.Status.Costs == nil {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimPrefix(x.Status.Costs.Monthly, "$"), 64)
+			if err != nil {
+				continue
+			}
+			estimate.NamespaceMonthly += value
+		}
+
+		if breaches := policies.CheckCostConstraints(constraints, estimate); len(breaches) > 0 {
+			cond.ActionRequired("Configuration has breached the cost budget, refusing to continue: %s", strings.Join(breaches, ", "))
 
 			return reconcile.Result{}, controller.ErrIgnore
 		}
 
-		cond.Success("Passed security checks")
-
 		return reconcile.Result{}, nil
 	}
 }
@@ -1100,9 +1739,20 @@ func (c *Controller) ensureDriftDetection(configuration *terraformv1alpha1.Confi
 		// @step: handle the update to the status
 		if !state.hasDrift {
 			configuration.Status.ResourceStatus = terraformv1alpha1.ResourcesInSync
-		} else {
-			configuration.Status.ResourceStatus = terraformv1alpha1.ResourcesOutOfSync
 
+			return controller.RequeueImmediate, nil
+		}
+		configuration.Status.ResourceStatus = terraformv1alpha1.ResourcesOutOfSync
+
+		switch configuration.GetDriftMode() {
+		case terraformv1alpha1.DriftModeRemediate:
+			cond.InProgress("Drift has been detected in the resource, remediating")
+
+		case terraformv1alpha1.DriftModeRequireApproval:
+			cond.ActionRequired("Drift has been detected in the resource, set the annotation %s=%s to approve the remediation",
+				terraformv1alpha1.DriftApprovalAnnotation, configuration.Status.DriftTimestamp)
+
+		default:
 			cond.ActionRequired("Drift has been detected in the resource")
 		}
 
@@ -1118,6 +1768,12 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 	readyCond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
 
 	return func(ctx context.Context) (reconcile.Result, error) {
+		// remediation is the drift timestamp being remediated by this apply, if any
+		var remediation string
+		if configuration.IsDriftRemediationPending() && configuration.IsDriftRemediationApproved() {
+			remediation = configuration.Status.DriftTimestamp
+		}
+
 		switch {
 		case configuration.NeedsApproval() && !configuration.Spec.EnableAutoApproval:
 			cond.ActionRequired("Waiting for terraform apply annotation to be set to true")
@@ -1132,16 +1788,45 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 		case configuration.HasRetryableAnnotation() && configuration.IsRetryable():
 			break
 
+			// if a replace or target operation has been planned and is waiting to be applied
+		case state.operation != "" && configuration.Status.TerraformPlan.GetOperation() == state.operation:
+			break
+
 		case cond.GetCondition().IsComplete(configuration.GetGeneration()):
-			return reconcile.Result{}, nil
+			// @note: unless drift has been detected and the drift policy requires it to be remediated
+			if configuration.IsDriftRemediationPending() {
+				if !configuration.IsDriftRemediationApproved() {
+					readyCond.ActionRequired("Drift has been detected in the resource, set the annotation %s=%s to approve the remediation",
+						terraformv1alpha1.DriftApprovalAnnotation, configuration.Status.DriftTimestamp)
+
+					return reconcile.Result{}, controller.ErrIgnore
+				}
+
+				break
+			}
+
+			// @note: configurations applied before the context values were recorded carry no checksum,
+			// we adopt the current values rather than trigger a new apply on upgrade
+			if configuration.Status.Contexts == "" {
+				configuration.Status.Contexts = state.contexts
+			}
+
+			// @note: unless the outputs consumed from our dependencies or contexts have changed since
+			// the last apply
+			if configuration.Status.Contexts == state.contexts && configuration.Status.Dependencies == state.dependencies {
+				return reconcile.Result{}, nil
+			}
 		}
 
-		// @step: check if we need to save the terraform state
-		saveState := c.HasBackendTemplate()
-		if state.provider != nil && state.provider.HasBackendTemplate() {
-			saveState = true
+		// @step: bind the approval to the plan it was given for, any later plan must be approved again
+		if plan := configuration.Status.TerraformPlan; plan != nil && plan.ApprovedChecksum == "" &&
+			configuration.IsApprovalRequired() && !configuration.NeedsApproval() && remediation == "" {
+			plan.ApprovedChecksum = plan.Checksum
 		}
 
+		// @step: check if we need to save the terraform state
+		saveState := state.backendType != terraformv1alpha1.BackendTypeKubernetes
+
 		// @step: create the terraform job
 		runner, err := jobs.New(configuration, state.provider).NewTerraformApply(jobs.Options{
 			AdditionalJobAnnotations: state.provider.JobAnnotations(),
@@ -1150,17 +1835,28 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 				c.ControllerJobLabels,
 				state.provider.JobLabels(),
 				configuration.GetLabels(),
+				map[string]string{
+					terraformv1alpha1.ConfigurationContextsLabel:     state.contexts,
+					terraformv1alpha1.ConfigurationDependenciesLabel: state.dependencies,
+					terraformv1alpha1.ConfigurationOperationLabel:    state.operation,
+					terraformv1alpha1.DriftAnnotation:                remediation,
+				},
 			),
 			BackoffLimit:       c.BackoffLimit,
 			EnableInfraCosts:   c.EnableInfracosts,
+			EnableSourceCache:  c.EnableSourceCache,
+			Engine:             state.engine,
+			Providers:          state.providers,
 			ExecutorImage:      c.ExecutorImage,
 			ExecutorSecrets:    c.ExecutorSecrets,
 			InfracostsImage:    c.InfracostsImage,
 			InfracostsSecret:   c.InfracostsSecretName,
+			LogStore:           c.LogStore,
 			Namespace:          c.ControllerNamespace,
 			SaveTerraformState: saveState,
+			SignatureKeys:      state.signatureKeys,
 			Template:           state.jobTemplate,
-			TerraformImage:     GetTerraformImage(configuration, c.TerraformImage),
+			TerraformImage:     c.GetEngineImage(state.engine, state.terraformVersion),
 		})
 		if err != nil {
 			cond.Failed(err, "Failed to create the terraform apply job")
@@ -1171,6 +1867,10 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 		// @step: find the job which is implementing this stage if any
 		job, found := filters.Jobs(state.jobs).
 			WithGeneration(generation).
+			WithLabel(terraformv1alpha1.ConfigurationContextsLabel, state.contexts).
+			WithLabel(terraformv1alpha1.ConfigurationDependenciesLabel, state.dependencies).
+			WithLabel(terraformv1alpha1.ConfigurationOperationLabel, state.operation).
+			WithLabel(terraformv1alpha1.DriftAnnotation, remediation).
 			WithNamespace(configuration.GetNamespace()).
 			WithName(configuration.GetName()).
 			WithStage(terraformv1alpha1.StageTerraformApply).
@@ -1181,6 +1881,15 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 		if !found {
 			configuration.Status.ResourceStatus = terraformv1alpha1.ResourcesOutOfSync
 
+			// @step: ensure we are applying the plan which was produced for this generation
+			if result, err := c.ensureTerraformPlanValid(ctx, configuration, state); err != nil || result.RequeueAfter > 0 {
+				return result, err
+			}
+			// @step: ensure we are permitted to apply changes at this time
+			if result, err := c.ensureMaintenanceWindow(ctx, configuration, state); err != nil || result.RequeueAfter > 0 {
+				return result, err
+			}
+
 			if c.EnableWatchers {
 				if err := c.CreateWatcher(ctx, configuration, terraformv1alpha1.StageTerraformApply); err != nil {
 					cond.Failed(err, "Failed to create the terraform apply watcher")
@@ -1197,19 +1906,46 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 			}
 			cond.InProgress("Terraform apply is running")
 
+			if remediation != "" {
+				c.recorder.Eventf(configuration, v1.EventTypeNormal, "DriftRemediation", "Remediating the drift detected at %s", remediation)
+				driftRemediationMetric.WithLabelValues(configuration.Namespace, configuration.Name).Inc()
+			}
+
 			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 
 		// @step: we only shift out of this state of the job is complete
 		switch {
 		case jobs.IsComplete(job):
+			configuration.Status.Contexts = state.contexts
+			configuration.Status.Dependencies = state.dependencies
+			configuration.Status.Imported = configuration.Spec.Imports
 			configuration.Status.ResourceStatus = terraformv1alpha1.ResourcesInSync
 
+			// @step: let any configurations depending on us know our outputs may have changed
+			if err := c.notifyDependents(ctx, configuration); err != nil {
+				cond.Failed(err, "Failed to notify the configurations which depend on this configuration")
+
+				return reconcile.Result{}, err
+			}
+
+			recordRunHistory(configuration, job, terraformv1alpha1.StageTerraformApply)
+
+			// @step: the operation is one-shot, so we remove the annotations once it has been applied
+			if state.operation != "" {
+				if err := c.completeOperation(ctx, configuration, state); err != nil {
+					cond.Failed(err, "Failed to remove the operation annotations from the configuration")
+
+					return reconcile.Result{}, err
+				}
+			}
+
 			cond.Success("Terraform apply is complete")
 			return reconcile.Result{}, nil
 
 		case jobs.IsFailed(job):
 			cond.Failed(nil, "Terraform apply has failed")
+			recordRunHistory(configuration, job, terraformv1alpha1.StageTerraformApply)
 
 			return c.ensureErrorDetection(configuration, job, state)(ctx)
 
@@ -1221,6 +1957,104 @@ func (c *Controller) ensureTerraformApply(configuration *terraformv1alpha1.Confi
 	}
 }
 
+// ensureTerraformPlanValid is responsible for ensuring the terraform plan we are about to apply is present
+// and is the plan which was produced for the current generation of the configuration
+func (c *Controller) ensureTerraformPlanValid(ctx context.Context, configuration *terraformv1alpha1.Configuration, state *state) (reconcile.Result, error) {
+	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformApply, c.recorder)
+	status := configuration.Status.TerraformPlan
+
+	switch {
+	case status == nil, status.Checksum == "":
+		cond.ActionRequired("Terraform plan for generation %d is missing, refusing to apply", configuration.GetGeneration())
+
+		return reconcile.Result{}, controller.ErrIgnore
+
+	case status.IsStale(configuration.GetGeneration()):
+		cond.ActionRequired("Terraform plan was produced for generation %d, not %d, refusing to apply",
+			status.Generation, configuration.GetGeneration())
+
+		return reconcile.Result{}, controller.ErrIgnore
+
+	case status.Contexts != state.contexts:
+		cond.ActionRequired("Terraform plan was produced with different context values, refusing to apply")
+
+		return reconcile.Result{}, controller.ErrIgnore
+
+	case status.Dependencies != state.dependencies:
+		cond.ActionRequired("Terraform plan was produced with different dependency outputs, refusing to apply")
+
+		return reconcile.Result{}, controller.ErrIgnore
+
+	case status.Operation != state.operation:
+		cond.ActionRequired("Terraform plan was produced for a different replace or target operation, refusing to apply")
+
+		return reconcile.Result{}, controller.ErrIgnore
+
+	case status.ApprovedChecksum != "" && status.ApprovedChecksum != status.Checksum:
+		cond.ActionRequired("Terraform plan has changed since it was approved, refusing to apply")
+
+		return reconcile.Result{}, controller.ErrIgnore
+	}
+
+	secret := &v1.Secret{}
+	secret.Namespace = c.ControllerNamespace
+	secret.Name = configuration.GetTerraformPlanSecretName()
+
+	found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+	if err != nil {
+		cond.Failed(err, "Failed to retrieve the terraform plan secret")
+
+		return reconcile.Result{}, err
+	}
+	if !found {
+		cond.ActionRequired("Terraform plan secret (%s/%s) is missing, refusing to apply", secret.Namespace, secret.Name)
+
+		return reconcile.Result{}, controller.ErrIgnore
+	}
+
+	if utils.Sha256Sum(secret.Data[terraformv1alpha1.TerraformPlanSecretKey]) != status.Checksum {
+		cond.ActionRequired("Terraform plan does not match the checksum of the approved plan, refusing to apply")
+
+		return reconcile.Result{}, controller.ErrIgnore
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// ensureMaintenanceWindow is responsible for deferring the terraform apply when the policies selecting
+// the configuration define maintenance windows, and none of them are currently open
+func (c *Controller) ensureMaintenanceWindow(ctx context.Context, configuration *terraformv1alpha1.Configuration, state *state) (reconcile.Result, error) {
+	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformApply, c.recorder)
+
+	windows, err := c.findMaintenanceWindows(ctx, configuration, state.policies)
+	if err != nil {
+		cond.Failed(err, "Failed to find the maintenance windows for the configuration")
+
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+
+	open, next, err := policies.IsMaintenanceWindowOpen(windows, now)
+	if err != nil {
+		cond.Failed(err, "Failed to check the maintenance windows for the configuration")
+
+		return reconcile.Result{}, err
+	}
+	if open {
+		return reconcile.Result{}, nil
+	}
+
+	if next.IsZero() {
+		cond.Warning("Terraform apply deferred, outside of the maintenance windows and no window is scheduled to open")
+
+		return reconcile.Result{RequeueAfter: time.Hour}, nil
+	}
+	cond.Warning("Terraform apply deferred, outside of the maintenance windows, next window opens at %s", next.UTC().Format(time.RFC3339))
+
+	return reconcile.Result{RequeueAfter: next.Sub(now) + time.Second}, nil
+}
+
 // ensureConnectionSecret is responsible for ensuring the jobs ran successfully
 func (c *Controller) ensureConnectionSecret(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
 	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
@@ -1315,6 +2149,7 @@ func (c *Controller) ensureTerraformStatus(configuration *terraformv1alpha1.Conf
 			return reconcile.Result{}, err
 		}
 		configuration.Status.Resources = ptr.To(tfstate.CountResources())
+		configuration.Status.Engine = state.engine
 		configuration.Status.TerraformVersion = tfstate.TerraformVersion
 
 		switch configuration.Status.ResourceStatus {
@@ -21,6 +21,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/utils/jobs"
@@ -29,13 +33,31 @@ import (
 
 // GetTerraformImage is called to return the terraform image to use, or the image plus version
 // override
-func GetTerraformImage(configuration *terraformv1alpha1.Configuration, image string) string {
-	if configuration.Spec.TerraformVersion == "" {
+func GetTerraformImage(image, version string) string {
+	if version == "" {
 		return image
 	}
 	e := strings.Split(image, ":")
 
-	return fmt.Sprintf("%s:%s", e[0], configuration.Spec.TerraformVersion)
+	return fmt.Sprintf("%s:%s", e[0], version)
+}
+
+// GetEngineImage returns the image used to run the jobs for the engine, plus any version override
+func (c Controller) GetEngineImage(engine, version string) string {
+	if engine == terraformv1alpha1.EngineOpenTofu {
+		return GetTerraformImage(c.TofuImage, version)
+	}
+
+	return GetTerraformImage(c.TerraformImage, version)
+}
+
+// GetEngineVersions returns the versions permitted to be resolved for the engine
+func (c Controller) GetEngineVersions(engine string) []string {
+	if engine == terraformv1alpha1.EngineOpenTofu {
+		return c.TofuVersions
+	}
+
+	return c.TerraformVersions
 }
 
 // CreateWatcher is responsible for ensuring the logger is running in the application namespace
@@ -53,3 +75,157 @@ func (c Controller) CreateWatcher(ctx context.Context, configuration *terraformv
 
 	return c.cc.Create(ctx, watcher)
 }
+
+// findDependents returns the configurations in the same namespace which depend on the configuration
+func (c Controller) findDependents(ctx context.Context, configuration *terraformv1alpha1.Configuration) ([]terraformv1alpha1.Configuration, error) {
+	list := &terraformv1alpha1.ConfigurationList{}
+	if err := c.cc.List(ctx, list, client.InNamespace(configuration.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var dependents []terraformv1alpha1.Configuration
+	for _, x := range list.Items {
+		if x.Spec.DependsOn.Has(configuration.Name) {
+			dependents = append(dependents, x)
+		}
+	}
+
+	return dependents, nil
+}
+
+// notifyDependents is responsible for annotating the configurations which depend on the
+// configuration, triggering a reconcile so they can pick up any changes to our outputs
+func (c Controller) notifyDependents(ctx context.Context, configuration *terraformv1alpha1.Configuration) error {
+	dependents, err := c.findDependents(ctx, configuration)
+	if err != nil {
+		return err
+	}
+
+	for i := range dependents {
+		dependent := &dependents[i]
+		if dependent.DeletionTimestamp != nil {
+			continue
+		}
+
+		original := dependent.DeepCopy()
+		if dependent.Annotations == nil {
+			dependent.Annotations = map[string]string{}
+		}
+		dependent.Annotations[terraformv1alpha1.DependencyAnnotation] = fmt.Sprintf("%d", time.Now().UnixNano())
+
+		if err := c.cc.Patch(ctx, dependent, client.MergeFrom(original)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordRunHistory is responsible for recording the outcome of a terraform job in the run history
+// of the configuration
+func recordRunHistory(configuration *terraformv1alpha1.Configuration, job *batchv1.Job, stage string) {
+	if _, found := configuration.Status.GetRun(job.GetName()); found {
+		return
+	}
+
+	run := terraformv1alpha1.RunHistory{
+		CompletionTime: job.Status.CompletionTime,
+		Generation:     configuration.GetGeneration(),
+		Job:            job.GetName(),
+		Result:         terraformv1alpha1.RunResultSucceeded,
+		Stage:          stage,
+		StartTime:      job.Status.StartTime,
+	}
+
+	if jobs.IsFailed(job) {
+		run.Result = terraformv1alpha1.RunResultFailed
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed {
+				run.CompletionTime = condition.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+
+	// @step: the changes are taken from the plan which was produced or applied by the run
+	plan := configuration.Status.TerraformPlan
+	if run.Result == terraformv1alpha1.RunResultSucceeded && plan != nil && plan.Changes != nil {
+		if stage == terraformv1alpha1.StageTerraformApply || plan.Job == job.GetName() {
+			run.Changes = plan.Changes.DeepCopy()
+			run.Changes.Resources = nil
+		}
+	}
+
+	switch {
+	case job.GetLabels()[terraformv1alpha1.ConfigurationOperationLabel] != "":
+		run.Reason = terraformv1alpha1.RunReasonOperation
+
+		// @note: an apply inherits the arguments and cost of the plan it applied
+		if planned, found := configuration.Status.GetRun(plan.GetJob()); found && stage == terraformv1alpha1.StageTerraformApply {
+			run.Arguments = planned.Arguments
+			run.CostDelta = planned.CostDelta
+
+			break
+		}
+		if arguments, err := configuration.GetOperationArguments(); err == nil {
+			run.Arguments = strings.Join(arguments, " ")
+		}
+
+	case job.GetLabels()[terraformv1alpha1.RetryAnnotation] != "":
+		run.Reason = terraformv1alpha1.RunReasonRetry
+
+	case job.GetLabels()[terraformv1alpha1.DriftAnnotation] != "":
+		run.Reason = terraformv1alpha1.RunReasonDrift
+
+	default:
+		run.Reason = terraformv1alpha1.RunReasonSpecChange
+
+		// @note: an apply inherits the reason and cost of the plan it applied
+		if planned, found := configuration.Status.GetRun(plan.GetJob()); found && stage == terraformv1alpha1.StageTerraformApply {
+			run.CostDelta = planned.CostDelta
+			run.Reason = planned.Reason
+
+			break
+		}
+
+		// @note: a stage running again for the same generation has been triggered by the outputs of
+		// our dependencies or the values of our contexts changing
+		for _, x := range configuration.Status.History {
+			if x.Stage == stage && x.Generation == run.Generation {
+				run.Reason = terraformv1alpha1.RunReasonDependencies
+				if job.GetLabels()[terraformv1alpha1.ConfigurationContextsLabel] != configuration.Status.Contexts {
+					run.Reason = terraformv1alpha1.RunReasonContexts
+				}
+			}
+		}
+	}
+
+	configuration.Status.AddRun(run)
+}
+
+// isTerraformPlanApplied returns true if the changes for the current generation have been applied,
+// and no terraform plan has been produced since the last successful apply
+func isTerraformPlanApplied(configuration *terraformv1alpha1.Configuration) bool {
+	cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+	if cond == nil || !cond.IsComplete(configuration.GetGeneration()) {
+		return false
+	}
+	applied := true
+
+	for _, x := range configuration.Status.History {
+		switch {
+		case x.Job == configuration.Status.TerraformPlan.GetJob():
+			applied = false
+		case x.Stage == terraformv1alpha1.StageTerraformApply && x.Result == terraformv1alpha1.RunResultSucceeded:
+			applied = true
+		}
+	}
+
+	return applied
+}
+
+// isDriftRemediation returns true if a plan for the drift timestamp is remediating drift under the
+// drift policy, and as such does not require the changes to be approved again
+func isDriftRemediation(configuration *terraformv1alpha1.Configuration, drift string) bool {
+	return configuration.GetDriftMode() != terraformv1alpha1.DriftModeDetect &&
+		drift != "" && drift != configuration.Status.DriftTimestamp
+}
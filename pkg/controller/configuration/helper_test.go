@@ -21,8 +21,6 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-
-	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 )
 
 func TestGetTerraformImage(t *testing.T) {
@@ -43,9 +41,6 @@ func TestGetTerraformImage(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		config := &terraformv1alpha1.Configuration{}
-		config.Spec.TerraformVersion = c.Override
-
-		assert.Equal(t, c.Expected, GetTerraformImage(config, c.Default))
+		assert.Equal(t, c.Expected, GetTerraformImage(c.Default, c.Override))
 	}
 }
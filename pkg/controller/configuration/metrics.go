@@ -24,6 +24,7 @@ import (
 
 func init() {
 	metrics.Registry.MustRegister(
+		driftRemediationMetric,
 		hourlyCostMetric,
 		inSyncMetric,
 		monthlyCostMetric,
@@ -38,6 +39,12 @@ var (
 			Help: "Indicates the status of the configuration, 0 = OK, 1 = Error",
 		}, []string{"name", "namespace"},
 	)
+	driftRemediationMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "configuration_drift_remediations_total",
+			Help: "The number of times drift has been remediated on a configuration",
+		}, []string{"name", "namespace"},
+	)
 	hourlyCostMetric = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "configuration_hourly_cost_total",
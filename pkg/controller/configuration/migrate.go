@@ -0,0 +1,250 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/core/v1alpha1"
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/filters"
+	"github.com/appvia/terranetes-controller/pkg/utils/jobs"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// getBackendChecksum returns a checksum of the backend the terraform state is stored in
+func getBackendChecksum(state *state) (string, error) {
+	encoded, err := json.Marshal(state.backend)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.Sha256Sum(append([]byte(state.backendTemplate), encoded...))[0:16], nil
+}
+
+// ensureStateBackend is responsible for tracking the backend the terraform state is stored in. When the backend
+// has changed underneath an existing state we refuse to continue until the migration of the state has been
+// approved, else terraform would run against an empty state in the new backend
+func (c *Controller) ensureStateBackend(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		// @step: an orphaned configuration being deleted never touches the state
+		if !configuration.GetDeletionTimestamp().IsZero() &&
+			configuration.GetAnnotations()[terraformv1alpha1.OrphanAnnotation] == "true" {
+			return reconcile.Result{}, nil
+		}
+
+		checksum, err := getBackendChecksum(state)
+		if err != nil {
+			cond.Failed(err, "Failed to generate the checksum for the terraform backend")
+
+			return reconcile.Result{}, err
+		}
+		current := &terraformv1alpha1.BackendStatus{Checksum: checksum, Type: state.backendType}
+		status := configuration.Status.Backend
+
+		// @step: if the backend has not changed we can record and move on
+		if status == nil || status.Checksum == checksum {
+			configuration.Status.Backend = current
+
+			return reconcile.Result{}, nil
+		}
+
+		// @step: check we have a terraform state - else there is nothing to migrate
+		tfstate := &v1.Secret{}
+		tfstate.Namespace = c.ControllerNamespace
+		tfstate.Name = configuration.GetTerraformStateSecretName()
+
+		found, err := kubernetes.GetIfExists(ctx, c.cc, tfstate)
+		if err != nil {
+			cond.Failed(err, "Failed to check for the terraform state secret")
+
+			return reconcile.Result{}, err
+		}
+		if !found {
+			configuration.Status.Backend = current
+
+			return reconcile.Result{}, nil
+		}
+
+		// @step: ensure we have retained the configuration of the backend the state is currently in
+		migration := &v1.Secret{}
+		migration.Namespace = c.ControllerNamespace
+		migration.Name = configuration.GetTerraformMigrationSecretName()
+
+		found, err = kubernetes.GetIfExists(ctx, c.cc, migration)
+		if err != nil {
+			cond.Failed(err, "Failed to check for the terraform state migration secret")
+
+			return reconcile.Result{}, err
+		}
+		if !found {
+			// @note: the configuration secret has not been rendered with the changed backend yet
+			config := &v1.Secret{}
+			config.Namespace = c.ControllerNamespace
+			config.Name = configuration.GetTerraformConfigSecretName()
+
+			found, err := kubernetes.GetIfExists(ctx, c.cc, config)
+			if err != nil {
+				cond.Failed(err, "Failed to retrieve the configuration secret")
+
+				return reconcile.Result{}, err
+			}
+			if !found || len(config.Data[terraformv1alpha1.TerraformBackendSecretKey]) == 0 {
+				cond.Failed(nil, "Unable to find the previous backend configuration to migrate the state from")
+
+				return reconcile.Result{}, controller.ErrIgnore
+			}
+
+			migration.Labels = config.Labels
+			migration.Data = map[string][]byte{
+				terraformv1alpha1.TerraformBackendSecretKey: config.Data[terraformv1alpha1.TerraformBackendSecretKey],
+			}
+			if err := c.cc.Create(ctx, migration); err != nil {
+				cond.Failed(err, "Failed to create the terraform state migration secret")
+
+				return reconcile.Result{}, err
+			}
+		}
+		status.PendingChecksum = checksum
+		status.PendingType = state.backendType
+
+		// @step: we only migrate the state once approved
+		if configuration.GetAnnotations()[terraformv1alpha1.MigrateStateAnnotation] != checksum {
+			cond.ActionRequired("Terraform state backend has changed from %s to %s, the state must be migrated using `tnctl state migrate %s -n %s`",
+				status.Type, state.backendType, configuration.Name, configuration.Namespace)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+		state.migrateState = true
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureTerraformMigrate is responsible for running the job which migrates the terraform state from the previous
+// backend into the current one, once the migration has been approved
+func (c *Controller) ensureTerraformMigrate(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+	generation := fmt.Sprintf("%d", configuration.GetGeneration())
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		if !state.migrateState {
+			return reconcile.Result{}, nil
+		}
+		status := configuration.Status.Backend
+
+		// @step: find any current migration jobs
+		job, found := filters.Jobs(state.jobs).
+			WithGeneration(generation).
+			WithLabel(terraformv1alpha1.ConfigurationBackendLabel, status.PendingChecksum).
+			WithLabel(terraformv1alpha1.RetryAnnotation, configuration.GetAnnotations()[terraformv1alpha1.RetryAnnotation]).
+			WithName(configuration.GetName()).
+			WithNamespace(configuration.GetNamespace()).
+			WithStage(terraformv1alpha1.StageTerraformMigrate).
+			WithUID(string(configuration.GetUID())).
+			Latest()
+
+		if !found {
+			runner, err := jobs.New(configuration, state.provider).NewTerraformMigrate(jobs.Options{
+				AdditionalJobAnnotations: state.provider.JobAnnotations(),
+				AdditionalJobSecrets:     state.additionalJobSecrets,
+				AdditionalJobLabels: utils.MergeStringMaps(
+					c.ControllerJobLabels,
+					state.provider.JobLabels(),
+					map[string]string{
+						terraformv1alpha1.ConfigurationBackendLabel: status.PendingChecksum,
+						terraformv1alpha1.RetryAnnotation:           configuration.GetAnnotations()[terraformv1alpha1.RetryAnnotation],
+					}),
+				BackoffLimit:       c.BackoffLimit,
+				Engine:             state.engine,
+				Providers:          state.providers,
+				ExecutorImage:      c.ExecutorImage,
+				ExecutorSecrets:    c.ExecutorSecrets,
+				LogStore:           c.LogStore,
+				Namespace:          c.ControllerNamespace,
+				SaveTerraformState: state.backendType != terraformv1alpha1.BackendTypeKubernetes,
+				Template:           state.jobTemplate,
+				TerraformImage:     c.GetEngineImage(state.engine, state.terraformVersion),
+			})
+			if err != nil {
+				cond.Failed(err, "Failed to create the terraform state migration job")
+
+				return reconcile.Result{}, err
+			}
+
+			if c.EnableWatchers {
+				if err := c.CreateWatcher(ctx, configuration, terraformv1alpha1.StageTerraformMigrate); err != nil {
+					cond.Failed(err, "Failed to create the terraform state migration watcher")
+
+					return reconcile.Result{}, err
+				}
+			}
+
+			if err := c.cc.Create(ctx, runner); err != nil {
+				cond.Failed(err, "Failed to create the terraform state migration job")
+
+				return reconcile.Result{}, err
+			}
+			cond.InProgress("Terraform state migration is running")
+
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
+		switch {
+		case jobs.IsComplete(job):
+			migration := &v1.Secret{}
+			migration.Namespace = c.ControllerNamespace
+			migration.Name = configuration.GetTerraformMigrationSecretName()
+
+			if err := kubernetes.DeleteIfExists(ctx, c.cc, migration); err != nil {
+				cond.Failed(err, "Failed to delete the terraform state migration secret")
+
+				return reconcile.Result{}, err
+			}
+			c.recorder.Event(configuration, v1.EventTypeNormal, "StateMigrated",
+				fmt.Sprintf("Terraform state has been migrated from %s to %s backend", status.Type, status.PendingType))
+
+			configuration.Status.Backend = &terraformv1alpha1.BackendStatus{
+				Checksum: status.PendingChecksum,
+				Type:     status.PendingType,
+			}
+
+			return reconcile.Result{}, nil
+
+		case jobs.IsFailed(job):
+			cond.Failed(nil, "Terraform state migration has failed")
+
+			return reconcile.Result{}, controller.ErrIgnore
+
+		case jobs.IsActive(job):
+			cond.InProgress("Terraform state migration is running")
+		}
+
+		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package configuration
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils"
+)
+
+// ensureOperation is responsible for validating any one-shot replace or target operations requested
+// via the annotations, and computing the arguments the next terraform plan is run with
+func (c *Controller) ensureOperation(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, terraformv1alpha1.ConditionTerraformPlan, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		if !configuration.HasOperation() {
+			return reconcile.Result{}, nil
+		}
+
+		arguments, err := configuration.GetOperationArguments()
+		if err != nil {
+			cond.ActionRequired("Operation requested is invalid, %s", err)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+		state.operationArguments = arguments
+		state.operation = utils.Sha256Sum([]byte(strings.Join(arguments, " ")))[0:16]
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// completeOperation is responsible for removing the one-shot operation annotations once the terraform
+// run requested by them has been applied
+func (c *Controller) completeOperation(ctx context.Context, configuration *terraformv1alpha1.Configuration, state *state) error {
+	original := configuration.DeepCopy()
+	delete(configuration.Annotations, terraformv1alpha1.ReplaceAnnotation)
+	delete(configuration.Annotations, terraformv1alpha1.TargetAnnotation)
+
+	if err := c.cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+		return err
+	}
+	// @note: the patch returns the persisted status, so we restore the status being reconciled
+	configuration.Status = original.Status
+
+	log.WithFields(log.Fields{
+		"arguments": strings.Join(state.operationArguments, " "),
+		"name":      configuration.Name,
+		"namespace": configuration.Namespace,
+	}).Info("operation has been applied, removing the annotations")
+
+	c.recorder.Eventf(configuration, v1.EventTypeNormal, "OperationApplied",
+		"Terraform has applied the operation: %s", strings.Join(state.operationArguments, " "))
+
+	return nil
+}
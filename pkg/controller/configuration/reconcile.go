@@ -28,6 +28,7 @@ import (
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils/policies"
 	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
 )
 
@@ -36,15 +37,41 @@ type state struct {
 	auth *v1.Secret
 	// checkovConstraint is the policy constraint for this configuration
 	checkovConstraint *terraformv1alpha1.PolicyConstraint
+	// costs is the predicted costs taken from the cost report, nil when unavailable
+	costs *policies.CostEstimate
+	// regoConstraint is the rego constraint for this configuration
+	regoConstraint *terraformv1alpha1.RegoConstraint
+	// signatureKeys are the public keys trusted to sign the module, if any
+	signatureKeys []terraformv1alpha1.SignatureKey
+	// contexts is a checksum of the values consumed from contexts
+	contexts string
+	// dependencies is a checksum of the outputs consumed from the dependencies
+	dependencies string
+	// engine is the engine used to run the configuration, i.e. terraform or tofu
+	engine string
 	// hasDrift is a flag to indicate if the configuration has drift
 	hasDrift bool
 	// backendTemplate is the template to use for the terraform state backend.
 	// We always default this to the kubernetes backend
 	backendTemplate string
+	// backendType is the type of backend the terraform state is stored in
+	backendType string
+	// backend is the typed backend defined on the provider, if any
+	backend *terraformv1alpha1.Backend
+	// backendCredentials are the credentials taken from the typed backend secret
+	backendCredentials map[string]string
+	// operation is a checksum of the one-shot replace and target operations requested, if any
+	operation string
+	// operationArguments are the terraform arguments for the requested operations
+	operationArguments []string
+	// migrateState indicates the migration of the state to a changed backend has been approved
+	migrateState bool
 	// policies is a list of policies in the cluster
 	policies *terraformv1alpha1.PolicyList
 	// provider is the credentials provider to use
 	provider *terraformv1alpha1.Provider
+	// providers are the additional providers referenced by the configuration
+	providers []*terraformv1alpha1.Provider
 	// jobs is list of all jobs for this configuration and generation
 	jobs *batchv1.JobList
 	// jobTemplate is the template to use when rendering the job
@@ -54,6 +81,9 @@ type state struct {
 	additionalJobSecrets []string
 	// valueFrom is a map of keys to values
 	valueFrom map[string]interface{}
+	// terraformVersion is the resolved version of terraform used to run the configuration, an
+	// empty value indicates the default version of the controller
+	terraformVersion string
 	// tfstate is the secret containing the terraform state
 	tfstate *v1.Secret
 }
@@ -83,22 +113,33 @@ func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (
 		).Set(status)
 	}()
 
-	state := &state{valueFrom: make(map[string]interface{}), backendTemplate: terraform.KubernetesBackendTemplate}
+	state := &state{
+		valueFrom:       make(map[string]interface{}),
+		backendTemplate: terraform.KubernetesBackendTemplate,
+		backendType:     terraformv1alpha1.BackendTypeKubernetes,
+	}
 
 	finalizer := controller.NewFinalizer(c.cc, controllerName)
 	if finalizer.IsDeletionCandidate(configuration) {
 		result, err := controller.DefaultEnsureHandler.Run(ctx, c.cc, configuration,
 			[]controller.EnsureFunc{
 				c.ensureCapturedState(configuration, state),
-				c.ensureCustomBackendTemplate(configuration, state),
 				c.ensureProviderReady(configuration, state),
+				c.ensureCustomBackendTemplate(configuration, state),
 				c.ensurePolicyDefaultsExist(configuration, state),
+				c.ensureTerraformVersion(configuration, state),
 				c.ensureValueFromSecret(configuration, state),
+				c.ensureDependencies(configuration, state),
 				c.ensureAuthenticationSecret(configuration, state),
 				c.ensureCustomJobTemplate(configuration, state),
+				c.ensureStateBackend(configuration, state),
 				c.ensureJobConfigurationSecret(configuration, state),
+				c.ensureTerraformMigrate(configuration, state),
+				c.ensureNoDependents(configuration),
 				c.ensureTerraformDestroy(configuration, state),
 				c.ensureConfigurationSecretsDeleted(configuration),
+				c.ensureConfigurationLogsDeleted(configuration),
+				c.ensureTerraformLockDeleted(configuration),
 				c.ensureConfigurationJobsDeleted(configuration),
 				finalizer.EnsureRemoved(configuration),
 			})
@@ -122,18 +163,27 @@ func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (
 			c.ensureNoActivity(configuration, state),
 			c.ensureCostSecret(configuration),
 			c.ensureValueFromSecret(configuration, state),
+			c.ensureDependencies(configuration, state),
 			c.ensureAuthenticationSecret(configuration, state),
 			c.ensureCustomJobTemplate(configuration, state),
 			c.ensureProviderReady(configuration, state),
 			c.ensureCustomBackendTemplate(configuration, state),
 			c.ensurePolicyDefaultsExist(configuration, state),
+			c.ensureTerraformVersion(configuration, state),
+			c.ensureStateBackend(configuration, state),
 			c.ensureJobConfigurationSecret(configuration, state),
+			c.ensureTerraformMigrate(configuration, state),
+			c.ensureStateRestore(configuration, state),
+			c.ensureStateCommand(configuration, state),
+			c.ensureOperation(configuration, state),
 			c.ensureTerraformPlan(configuration, state),
-			c.ensureCostStatus(configuration),
+			c.ensureCostStatus(configuration, state),
 			c.ensurePolicyStatus(configuration, state),
+			c.ensureCostBudget(configuration, state),
 			c.ensureDriftDetection(configuration, state),
 			c.ensureTerraformApply(configuration, state),
 			c.ensureConnectionSecret(configuration, state),
+			c.ensureStateVersions(configuration, state),
 			c.ensureTerraformStatus(configuration, state),
 		})
 	if err != nil {
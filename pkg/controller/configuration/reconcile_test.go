@@ -30,6 +30,7 @@ import (
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,7 +44,9 @@ import (
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/controller"
 	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/pkg/utils"
 	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
 	controllertests "github.com/appvia/terranetes-controller/test"
 	"github.com/appvia/terranetes-controller/test/fixtures"
 )
@@ -67,7 +70,10 @@ func makeFakeController(cc client.Client) *Controller {
 		InfracostsImage:     "infracosts/infracost:latest",
 		ControllerNamespace: "terraform-system",
 		PolicyImage:         "bridgecrew/checkov:2.0.1140",
+		RegoImage:           "openpolicyagent/conftest:v0.56.0",
+		TerraformEngine:     "terraform",
 		TerraformImage:      "hashicorp/terraform:1.1.9",
+		TofuImage:           "ghcr.io/opentofu/opentofu:1.6.2",
 	}
 
 	return ctrl
@@ -414,6 +420,15 @@ var _ = Describe("Configuration Controller with Contexts", func() {
 					Expect(len(list.Items)).To(Equal(1))
 				})
 
+				It("should have labelled the terraform plan with the context checksum", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.Background(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(list.Items).To(HaveLen(1))
+					Expect(list.Items[0].GetLabels()).To(HaveKey(terraformv1alpha1.ConfigurationContextsLabel))
+					Expect(list.Items[0].GetLabels()[terraformv1alpha1.ConfigurationContextsLabel]).To(HaveLen(16))
+				})
+
 				It("should have the context variable in the job configuration secret", func() {
 					secret := &v1.Secret{}
 					secret.Namespace = ctrl.ControllerNamespace
@@ -428,6 +443,57 @@ var _ = Describe("Configuration Controller with Contexts", func() {
 					Expect(string(secret.Data[terraformv1alpha1.TerraformVariablesConfigMapKey])).To(Equal(expected))
 				})
 			})
+
+			Context("and the configuration was provisioned before the context values were recorded", func() {
+				BeforeEach(func() {
+					plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+					plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+					plan.Status.Succeeded = 1
+					apply := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformApply)
+					apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+					apply.Status.Succeeded = 1
+					tfstate := fixtures.NewTerraformState(configuration)
+					tfstate.Namespace = ctrl.ControllerNamespace
+
+					for _, x := range []client.Object{plan, apply, tfstate} {
+						Expect(cc.Create(context.Background(), x)).To(Succeed())
+					}
+
+					controller.EnsureConditionsRegistered(terraformv1alpha1.DefaultConfigurationConditions, configuration)
+					for _, x := range []corev1alpha1.ConditionType{terraformv1alpha1.ConditionTerraformPlan, terraformv1alpha1.ConditionTerraformApply} {
+						cond := configuration.Status.GetCondition(x)
+						cond.Status = metav1.ConditionTrue
+						cond.Reason = corev1alpha1.ReasonReady
+						cond.ObservedGeneration = configuration.GetGeneration()
+					}
+					configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{
+						Checksum: "checksum",
+						Job:      plan.Name,
+					}
+					Expect(cc.Create(context.Background(), configuration)).To(Succeed())
+
+					result, _, rerr = controllertests.Roll(context.Background(), ctrl, configuration, 0)
+				})
+
+				It("should not error", func() {
+					Expect(rerr).ToNot(HaveOccurred())
+					Expect(result.Requeue).To(BeFalse())
+				})
+
+				It("should not have run the terraform plan or apply again", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.Background(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(list.Items).To(HaveLen(2))
+				})
+
+				It("should have recorded the checksum of the context values", func() {
+					Expect(cc.Get(context.Background(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					Expect(configuration.Status.Contexts).To(HaveLen(16))
+					Expect(configuration.Status.TerraformPlan.Contexts).To(Equal(configuration.Status.Contexts))
+				})
+			})
 		})
 	})
 })
@@ -574,7 +640,10 @@ var _ = Describe("Configuration Controller", func() {
 			InfracostsImage:     "infracosts/infracost:latest",
 			ControllerNamespace: "default",
 			PolicyImage:         "bridgecrew/checkov:2.0.1140",
+			RegoImage:           "openpolicyagent/conftest:v0.56.0",
+			TerraformEngine:     "terraform",
 			TerraformImage:      "hashicorp/terraform:1.1.9",
+			TofuImage:           "ghcr.io/opentofu/opentofu:1.6.2",
 		}
 		ctrl.cache.SetDefault(cfgNamespace, fixtures.NewNamespace(cfgNamespace))
 	}
@@ -721,6 +790,241 @@ var _ = Describe("Configuration Controller", func() {
 				Expect(list.Items[0].Spec.Template.Spec.ServiceAccountName).To(Equal(serviceAccount))
 			})
 		})
+		When("using additional providers", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Alias: "east", Name: "east"}}
+			})
+
+			When("the additional provider does not exist", func() {
+				BeforeEach(func() {
+					Setup(configuration)
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should indicate the provider is missing", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionProviderReady)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal("Provider referenced \"east\" does not exist"))
+				})
+
+				It("should ask us to requeue", func() {
+					Expect(result).To(Equal(reconcile.Result{RequeueAfter: 5 * time.Minute}))
+					Expect(rerr).To(BeNil())
+				})
+			})
+
+			When("the additional provider is missing an alias", func() {
+				BeforeEach(func() {
+					configuration.Spec.Providers[0].Alias = ""
+					secret := fixtures.NewValidAWSProviderSecret("default", "east")
+
+					Setup(configuration, secret, fixtures.NewValidAWSReadyProvider("east", secret))
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should indicate the providers are incompatible", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionProviderReady)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal("Providers cannot be used together: spec.providers[0] requires a unique alias, provider type \"aws\" is already in use"))
+				})
+
+				It("should not create any jobs", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(list.Items).To(BeEmpty())
+				})
+			})
+
+			When("the additional provider is valid", func() {
+				BeforeEach(func() {
+					secret := fixtures.NewValidAWSProviderSecret("default", "east")
+
+					Setup(configuration, secret, fixtures.NewValidAWSReadyProvider("east", secret))
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should indicate the provider is ready", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionProviderReady)
+					Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				})
+
+				It("should have rendered the aliased provider", func() {
+					secret := &v1.Secret{}
+					secret.Namespace = ctrl.ControllerNamespace
+					secret.Name = configuration.GetTerraformConfigSecretName()
+
+					Expect(cc.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).ToNot(HaveOccurred())
+					Expect(string(secret.Data[terraformv1alpha1.TerraformProviderConfigMapKey])).To(ContainSubstring("provider \"aws\" {\n}\n"))
+					Expect(string(secret.Data[terraformv1alpha1.TerraformProviderConfigMapKey])).To(ContainSubstring("provider \"aws\" {\n  alias = \"east\"\n}\n"))
+				})
+
+				It("should have merged the provider credentials into the job", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(list.Items).To(HaveLen(1))
+
+					envFrom := list.Items[0].Spec.Template.Spec.Containers[0].EnvFrom
+					Expect(envFrom).To(HaveLen(2))
+					Expect(envFrom[0].SecretRef.Name).To(Equal("aws"))
+					Expect(envFrom[1].SecretRef.Name).To(Equal("east"))
+				})
+			})
+		})
+
+		When("using a provider sourced from the registry", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Spec.ProviderRef.Name = "cloudflare"
+
+				secret := fixtures.NewValidAWSProviderSecret("default", "cloudflare")
+				provider := fixtures.NewValidAWSReadyProvider("cloudflare", secret)
+				provider.Spec.Provider = "cloudflare"
+				provider.Spec.Registry = &terraformv1alpha1.ProviderRegistry{Source: "cloudflare/cloudflare", Version: "~> 4.0"}
+
+				Setup(configuration, secret, provider)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have rendered the required providers", func() {
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformConfigSecretName()
+
+				expected := "terraform {\n  required_providers {\n    cloudflare = {\n      source = \"cloudflare/cloudflare\"\n      version = \"~> 4.0\"\n    }\n  }\n}\n\nprovider \"cloudflare\" {\n}\n"
+
+				Expect(cc.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).ToNot(HaveOccurred())
+				Expect(string(secret.Data[terraformv1alpha1.TerraformProviderConfigMapKey])).To(Equal(expected))
+			})
+
+			It("should have created a plan job", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(1))
+			})
+		})
+
+		When("using a provider with federated credentials", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Spec.ProviderRef.Name = "federated"
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Name: "google"}}
+
+				provider := fixtures.NewValidAWSReadyProvider("federated", nil)
+				provider.Spec.Source = terraformv1alpha1.SourceFederated
+				provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{
+					RoleARN: "arn:aws:iam::123456789012:role/terraform",
+				}
+
+				google := fixtures.NewValidAWSReadyProvider("google", nil)
+				google.Spec.Provider = terraformv1alpha1.GCPProviderType
+				google.Spec.Source = terraformv1alpha1.SourceFederated
+				google.Spec.Federation = &terraformv1alpha1.ProviderFederation{
+					WorkloadIdentityProvider: "projects/1/locations/global/workloadIdentityPools/pool/providers/provider",
+				}
+
+				Setup(configuration, provider, google)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have rendered the federated providers", func() {
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformConfigSecretName()
+
+				Expect(cc.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).ToNot(HaveOccurred())
+				Expect(string(secret.Data[terraformv1alpha1.TerraformProviderConfigMapKey])).To(ContainSubstring("web_identity_token_file = \"/run/federated/federated/token\""))
+				Expect(string(secret.Data[terraformv1alpha1.TerraformProviderConfigMapKey])).To(ContainSubstring("credentials = \"/run/federated/google/credentials.json\""))
+				Expect(secret.Data).To(HaveKey("federated-google.json"))
+				Expect(secret.Data).ToNot(HaveKey("federated-federated.json"))
+			})
+
+			It("should have mounted the projected tokens into the job", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(1))
+
+				spec := list.Items[0].Spec.Template.Spec
+				Expect(spec.ServiceAccountName).To(Equal("terranetes-executor"))
+				Expect(spec.Containers[0].EnvFrom).To(BeEmpty())
+
+				var volumes []v1.Volume
+				for _, x := range spec.Volumes {
+					if x.Projected != nil {
+						volumes = append(volumes, x)
+					}
+				}
+				Expect(volumes).To(HaveLen(2))
+				Expect(volumes[0].Name).To(Equal("federated-0"))
+				Expect(volumes[0].Projected.Sources).To(HaveLen(1))
+				Expect(volumes[0].Projected.Sources[0].ServiceAccountToken.Audience).To(Equal("sts.amazonaws.com"))
+				Expect(*volumes[0].Projected.Sources[0].ServiceAccountToken.ExpirationSeconds).To(Equal(int64(3600)))
+				Expect(volumes[1].Name).To(Equal("federated-1"))
+				Expect(volumes[1].Projected.Sources).To(HaveLen(2))
+				Expect(volumes[1].Projected.Sources[0].ServiceAccountToken.Audience).To(Equal("//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider"))
+				Expect(volumes[1].Projected.Sources[1].Secret.Name).To(Equal(configuration.GetTerraformConfigSecretName()))
+				Expect(volumes[1].Projected.Sources[1].Secret.Items[0].Key).To(Equal("federated-google.json"))
+
+				Expect(spec.Containers[0].VolumeMounts).To(ContainElement(v1.VolumeMount{Name: "federated-0", MountPath: "/run/federated/federated", ReadOnly: true}))
+				Expect(spec.Containers[0].VolumeMounts).To(ContainElement(v1.VolumeMount{Name: "federated-1", MountPath: "/run/federated/google", ReadOnly: true}))
+			})
+		})
+
+		When("the same federated provider is referenced more than once", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Spec.ProviderRef.Name = "federated"
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Alias: "secondary", Name: "federated"}}
+
+				provider := fixtures.NewValidAWSReadyProvider("federated", nil)
+				provider.Spec.Source = terraformv1alpha1.SourceFederated
+				provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{
+					RoleARN: "arn:aws:iam::123456789012:role/terraform",
+				}
+
+				Setup(configuration, provider)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have mounted the projected token once", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(1))
+
+				spec := list.Items[0].Spec.Template.Spec
+
+				var volumes []v1.Volume
+				for _, x := range spec.Volumes {
+					if x.Projected != nil {
+						volumes = append(volumes, x)
+					}
+				}
+				Expect(volumes).To(HaveLen(1))
+				Expect(volumes[0].Name).To(Equal("federated-0"))
+
+				for _, container := range append(spec.InitContainers, spec.Containers...) {
+					paths := map[string]bool{}
+					for _, mount := range container.VolumeMounts {
+						Expect(paths).ToNot(HaveKey(mount.MountPath), "container %s has duplicate mount %s", container.Name, mount.MountPath)
+						paths[mount.MountPath] = true
+					}
+				}
+				Expect(spec.Containers[0].VolumeMounts).To(ContainElement(v1.VolumeMount{Name: "federated-0", MountPath: "/run/federated/federated", ReadOnly: true}))
+			})
+		})
 	})
 
 	// RETRYABLE CONFIGURATION
@@ -1075,6 +1379,82 @@ var _ = Describe("Configuration Controller", func() {
 		})
 	})
 
+	// VERSION CONSTRAINTS
+	When("configuration has a terraform version constraint", func() {
+		When("the constraint can be resolved from the allowed versions", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Spec.TerraformVersion = "~> 1.6"
+				Setup(configuration, fixtures.NewVersionsPolicy("versions", "< 1.6.6"))
+				ctrl.TerraformVersions = []string{"1.5.7", "1.6.0", "1.6.5", "1.6.6", "1.7.0"}
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have created the job with the newest permitted version", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
+				Expect(list.Items[0].Spec.Template.Spec.Containers[0].Image).To(Equal("hashicorp/terraform:1.6.5"))
+			})
+
+			It("should have the resolved version on the status", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				Expect(configuration.Status.ResolvedTerraformVersion).To(Equal("1.6.5"))
+			})
+		})
+
+		When("the constraint cannot be resolved", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Spec.TerraformVersion = "~> 1.8"
+				Setup(configuration)
+				ctrl.TerraformVersions = []string{"1.6.6", "1.7.0"}
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate the version cannot be resolved", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal(`Terraform version cannot be resolved, none of the allowed terraform versions satisfy the constraint "~> 1.8"`))
+			})
+
+			It("should not have created any jobs", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+	})
+
+	// ENGINE
+	When("configuration is using the opentofu engine", func() {
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Spec.Engine = terraformv1alpha1.EngineOpenTofu
+			configuration.Spec.TerraformVersion = "1.6.1"
+			Setup(configuration)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+		})
+
+		It("should have created job using the opentofu image and binary", func() {
+			list := &batchv1.JobList{}
+
+			Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+			Expect(len(list.Items)).To(Equal(1))
+
+			container := list.Items[0].Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("ghcr.io/opentofu/opentofu:1.6.1"))
+			Expect(container.Args).To(ContainElement(HavePrefix("--command=/usr/local/bin/tofu plan")))
+			Expect(list.Items[0].Spec.Template.Spec.InitContainers).To(ContainElement(
+				HaveField("Command", Equal([]string{"/usr/local/bin/tofu"}))))
+		})
+	})
+
 	// COSTS
 	When("predicted costs is enabled", func() {
 		When("the costs token is missing", func() {
@@ -1234,60 +1614,189 @@ var _ = Describe("Configuration Controller", func() {
 				Expect(string(secret.Data["costs.json"])).To(Equal(expected))
 			})
 		})
-	})
+		When("the configuration is selected by a cost budget", func() {
+			var policy *terraformv1alpha1.Policy
+			var others []runtime.Object
+			var infracosts bool
 
-	// VALUEFROM FIELDS
-	When("the configuration has valueFrom definitions", func() {
-		When("using values from the valueFrom field", func() {
 			BeforeEach(func() {
 				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
-				Setup()
+				policy = fixtures.NewCostPolicy("budget", "500")
+				others = nil
+				infracosts = true
 			})
 
-			When("secret is missing and not optional", func() {
-				BeforeEach(func() {
-					configuration.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
-						{Secret: ptr.To("missing"), Key: "key"},
-					}
-					Expect(ctrl.cc.Create(context.TODO(), configuration)).ToNot(HaveOccurred())
-					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
-				})
+			JustBeforeEach(func() {
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				report := fixtures.NewCostsReport(configuration)
+				report.Namespace = ctrl.ControllerNamespace
+				token := fixtures.NewCostsSecret(ctrl.ControllerNamespace, "infracost")
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = ctrl.ControllerNamespace
 
-				It("should have the conditions", func() {
-					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
-					Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
-				})
+				Setup(append(others, configuration, policy, plan, report, token, tfplan)...)
+				ctrl.EnableInfracosts = infracosts
+				ctrl.InfracostsSecretName = "infracost"
 
-				It("should indicate the failure on the conditions", func() {
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
+			})
+
+			When("the predicted costs are within the budget", func() {
+				It("should not block the configuration", func() {
 					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
 
-					cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
-					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
-					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
-					Expect(cond.Message).To(Equal("spec.valueFrom[0].secret (apps/missing) does not exist"))
-					Expect(cond.Detail).To(Equal(""))
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+					Expect(cond.Status).To(Equal(metav1.ConditionTrue))
 				})
 
-				It("should not create any jobs", func() {
+				It("should have created an apply job", func() {
 					list := &batchv1.JobList{}
 
 					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
-					Expect(len(list.Items)).To(Equal(0))
+					Expect(len(list.Items)).To(Equal(2))
 				})
 			})
 
-			When("secret is missing but optional", func() {
+			When("the predicted monthly cost exceeds the budget", func() {
 				BeforeEach(func() {
-					configuration.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
-						{Secret: ptr.To("missing"), Key: "key", Optional: true},
-					}
-					Expect(ctrl.cc.Create(context.TODO(), configuration)).ToNot(HaveOccurred())
-					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+					policy = fixtures.NewCostPolicy("budget", "50")
 				})
 
-				It("should have created a job", func() {
-					list := &batchv1.JobList{}
-					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				It("should indicate the budget has been breached", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal("Configuration has breached the cost budget, refusing to continue: predicted monthly cost $100.00 exceeds the budget of $50.00"))
+				})
+
+				It("should have not create an apply job", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(len(list.Items)).To(Equal(1))
+				})
+			})
+
+			When("the predicted namespace cost exceeds the budget", func() {
+				BeforeEach(func() {
+					namespace := resource.MustParse("500")
+					policy.Spec.Constraints.Costs.MaxNamespaceMonthly = &namespace
+
+					other := fixtures.NewValidBucketConfiguration(cfgNamespace, "other")
+					other.UID = "other"
+					other.Status.Costs = &terraformv1alpha1.CostStatus{Enabled: true, Monthly: "$450"}
+					others = append(others, other)
+				})
+
+				It("should indicate the budget has been breached", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal("Configuration has breached the cost budget, refusing to continue: predicted namespace monthly cost $550.00 exceeds the budget of $500.00"))
+				})
+			})
+
+			When("the predicted costs are unavailable", func() {
+				BeforeEach(func() {
+					infracosts = false
+				})
+
+				It("should warn the budget could not be checked", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonWarning))
+					Expect(cond.Message).To(Equal("Configuration is selected by a cost budget, but no cost estimate is available to check against"))
+				})
+			})
+
+			When("the changes were applied and a new plan has been produced for the generation", func() {
+				BeforeEach(func() {
+					policy = fixtures.NewCostPolicy("budget", "50")
+
+					controller.EnsureConditionsRegistered(terraformv1alpha1.DefaultConfigurationConditions, configuration)
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+					cond.Status = metav1.ConditionTrue
+					cond.Reason = corev1alpha1.ReasonReady
+					cond.ObservedGeneration = configuration.GetGeneration()
+
+					configuration.Status.History = []terraformv1alpha1.RunHistory{
+						{Job: "bucket-plan-previous", Stage: terraformv1alpha1.StageTerraformPlan, Result: terraformv1alpha1.RunResultSucceeded},
+						{Job: "bucket-apply-previous", Stage: terraformv1alpha1.StageTerraformApply, Result: terraformv1alpha1.RunResultSucceeded},
+					}
+				})
+
+				It("should check the new plan against the budget", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal("Configuration has breached the cost budget, refusing to continue: predicted monthly cost $100.00 exceeds the budget of $50.00"))
+				})
+			})
+		})
+	})
+
+	// VALUEFROM FIELDS
+	When("the configuration has valueFrom definitions", func() {
+		When("using values from the valueFrom field", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				Setup()
+			})
+
+			When("secret is missing and not optional", func() {
+				BeforeEach(func() {
+					configuration.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+						{Secret: ptr.To("missing"), Key: "key"},
+					}
+					Expect(ctrl.cc.Create(context.TODO(), configuration)).ToNot(HaveOccurred())
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should have the conditions", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+					Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+				})
+
+				It("should indicate the failure on the conditions", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal("spec.valueFrom[0].secret (apps/missing) does not exist"))
+					Expect(cond.Detail).To(Equal(""))
+				})
+
+				It("should not create any jobs", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(len(list.Items)).To(Equal(0))
+				})
+			})
+
+			When("secret is missing but optional", func() {
+				BeforeEach(func() {
+					configuration.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+						{Secret: ptr.To("missing"), Key: "key", Optional: true},
+					}
+					Expect(ctrl.cc.Create(context.TODO(), configuration)).ToNot(HaveOccurred())
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should have created a job", func() {
+					list := &batchv1.JobList{}
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
 
 					Expect(len(list.Items)).To(Equal(1))
 				})
@@ -1462,6 +1971,142 @@ var _ = Describe("Configuration Controller", func() {
 	})
 
 	// ADDITIONAL SECRETS
+	When("the configuration has dependencies", func() {
+		var network *terraformv1alpha1.Configuration
+
+		BeforeEach(func() {
+			network = fixtures.NewValidBucketConfiguration(cfgNamespace, "network")
+			network.UID = "network-uid"
+
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{
+				{
+					Name: "network",
+					Outputs: []terraformv1alpha1.DependencyOutput{
+						{Key: "test_output", Name: "bucket_name"},
+					},
+				},
+			}
+		})
+
+		When("the dependency does not exist", func() {
+			BeforeEach(func() {
+				Setup(configuration)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate the dependency is missing", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("spec.dependsOn[0] configuration (apps/network) does not exist"))
+			})
+
+			It("should requeue", func() {
+				Expect(rerr).ToNot(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{RequeueAfter: 30 * time.Second}))
+			})
+
+			It("should not create any jobs", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+
+		When("the dependency is not ready", func() {
+			BeforeEach(func() {
+				Setup(configuration, network)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate we are waiting on the dependency", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonInProgress))
+				Expect(cond.Message).To(Equal("Waiting for dependency spec.dependsOn[0] (network) to be ready"))
+			})
+
+			It("should not create any jobs", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+
+		When("the dependency is ready", func() {
+			BeforeEach(func() {
+				network.Status.Conditions = []corev1alpha1.Condition{
+					{
+						Type:               corev1alpha1.ConditionReady,
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: network.GetGeneration(),
+					},
+				}
+				state := fixtures.NewTerraformState(network)
+				state.Namespace = "default"
+
+				Setup(configuration, network, state)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have added the dependency outputs to the configuration variables", func() {
+				expected := "{\"bucket_name\":\"test\",\"name\":\"test\"}\n"
+
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformConfigSecretName()
+
+				found, err := kubernetes.GetIfExists(context.TODO(), ctrl.cc, secret)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(string(secret.Data[terraformv1alpha1.TerraformVariablesConfigMapKey])).To(Equal(expected))
+			})
+
+			It("should have labelled the terraform plan with the dependency checksum", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(1))
+				Expect(list.Items[0].GetLabels()).To(HaveKey(terraformv1alpha1.ConfigurationDependenciesLabel))
+				Expect(list.Items[0].GetLabels()[terraformv1alpha1.ConfigurationDependenciesLabel]).To(HaveLen(16))
+			})
+		})
+
+		When("the dependency is missing a required output", func() {
+			BeforeEach(func() {
+				configuration.Spec.DependsOn[0].Outputs[0].Key = "missing"
+				network.Status.Conditions = []corev1alpha1.Condition{
+					{
+						Type:               corev1alpha1.ConditionReady,
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: network.GetGeneration(),
+					},
+				}
+				state := fixtures.NewTerraformState(network)
+				state.Namespace = "default"
+
+				Setup(configuration, network, state)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate the output is missing", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("spec.dependsOn[0].outputs[0] dependency (network) does not have output: missing"))
+			})
+		})
+	})
+
 	When("the controller has been configured with additional secrets", func() {
 		BeforeEach(func() {
 			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
@@ -1634,8 +2279,14 @@ terraform {
 
 			expected := []string{
 				"--comment=Executing Terraform",
+				"--namespace=$(KUBE_NAMESPACE)",
+				"--lock=$(TERRAFORM_LOCK_NAME)",
 				"--command=/bin/terraform plan --var-file variables.tfvars.json -out=/run/plan.out -lock=false",
 				"--command=/bin/terraform show -json /run/plan.out > /run/plan.json",
+				"--command=/run/bin/step plan-summary --plan=/run/plan.json --output=/run/plan-changes.json",
+				"--upload=$(TERRAFORM_PLAN_NAME)=/run/plan.out",
+				"--upload=$(TERRAFORM_PLAN_NAME)=/run/plan-changes.json",
+				"--upload=$(TERRAFORM_PLAN_NAME)=/run/source.json",
 				"--on-error=/run/steps/terraform.failed",
 				"--on-success=/run/steps/terraform.complete",
 			}
@@ -1650,14 +2301,30 @@ terraform {
 			Expect(container.EnvFrom[0].SecretRef).ToNot(BeNil())
 			Expect(container.EnvFrom[0].SecretRef.Name).To(Equal("aws"))
 
-			Expect(len(container.Env)).To(Equal(5))
-			Expect(container.Env[4].Name).To(Equal("TERRAFORM_STATE_NAME"))
-			Expect(container.Env[4].Value).To(Equal(configuration.GetTerraformStateSecretName()))
+			Expect(len(container.Env)).To(Equal(7))
+			Expect(container.Env[4].Name).To(Equal("TERRAFORM_LOCK_NAME"))
+			Expect(container.Env[4].Value).To(Equal(configuration.GetTerraformLockName()))
+			Expect(container.Env[5].Name).To(Equal("TERRAFORM_PLAN_NAME"))
+			Expect(container.Env[5].Value).To(Equal(configuration.GetTerraformPlanSecretName()))
+			Expect(container.Env[6].Name).To(Equal("TERRAFORM_STATE_NAME"))
+			Expect(container.Env[6].Value).To(Equal(configuration.GetTerraformStateSecretName()))
 
 			Expect(container.VolumeMounts[0].Name).To(Equal("run"))
 			Expect(container.VolumeMounts[1].Name).To(Equal("source"))
 		})
 
+		It("should resolve the revision of the module source", func() {
+			list := &batchv1.JobList{}
+			Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+			Expect(len(list.Items)).To(Equal(1))
+
+			setup := list.Items[0].Spec.Template.Spec.InitContainers[0]
+			Expect(setup.Name).To(Equal("setup"))
+			Expect(setup.Args).To(ContainElement(
+				"--command=/bin/source --dest=/data --source=" + configuration.Spec.Module + " --revision-file=/run/source.json",
+			))
+		})
+
 		It("it should have the configuration labels", func() {
 			list := &batchv1.JobList{}
 			Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
@@ -1839,58 +2506,287 @@ terraform {
 		})
 	})
 
-	When("using a custom backend template", func() {
-		When("the template is not present", func() {
+	// TYPED BACKEND
+	When("using a provider with a typed backend", func() {
+		var provider *terraformv1alpha1.Provider
+
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+		})
+
+		JustBeforeEach(func() {
+			provider.Spec.Backend = &terraformv1alpha1.Backend{
+				S3: &terraformv1alpha1.S3Backend{Bucket: "state", Region: "eu-west-2"},
+				SecretRef: &v1.SecretReference{
+					Name: "backend",
+				},
+			}
+			Expect(cc.Update(context.Background(), provider)).To(Succeed())
+
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+		})
+
+		Context("and the backend secret is missing", func() {
 			BeforeEach(func() {
-				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
 				Setup(configuration)
-				ctrl.BackendTemplate = "not_there"
-
-				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 10)
-			})
 
-			It("should have the conditions", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
-				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+				provider = &terraformv1alpha1.Provider{}
+				provider.Name = configuration.Spec.ProviderRef.Name
+				Expect(cc.Get(context.Background(), provider.GetNamespacedName(), provider)).To(Succeed())
 			})
 
-			It("should indicate the configuration failed", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			It("should indicate the error", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
 
 				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
 				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
 				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
-				Expect(cond.Message).To(Equal("Backend template secret \"default/not_there\" not found, contact administrator"))
-			})
-
-			It("should not create any jobs", func() {
-				list := &batchv1.JobList{}
-
-				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
-				Expect(len(list.Items)).To(Equal(0))
+				Expect(cond.Message).To(Equal("Backend secret (default/backend) not found, contact administrator"))
 			})
 		})
 
-		When("the template is present but missing required keys", func() {
+		Context("and the backend secret is present", func() {
 			BeforeEach(func() {
-				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
-				backend := fixtures.NewBackendTemplateSecret(ctrl.ControllerNamespace, "missing_key")
-				delete(backend.Data, "backend.tf")
-				Setup(configuration, backend)
-				ctrl.BackendTemplate = "missing_key"
-
-				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 10)
-			})
+				secret := &v1.Secret{}
+				secret.Namespace = "default"
+				secret.Name = "backend"
+				secret.Data = map[string][]byte{
+					terraformv1alpha1.BackendAccessKeyID:     []byte("access"),
+					terraformv1alpha1.BackendSecretAccessKey: []byte("secret"),
+				}
+				Setup(configuration, secret)
 
-			It("should have the conditions", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
-				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+				provider = &terraformv1alpha1.Provider{}
+				provider.Name = configuration.Spec.ProviderRef.Name
+				Expect(cc.Get(context.Background(), provider.GetNamespacedName(), provider)).To(Succeed())
 			})
 
-			It("should indicate the configuration failed", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			It("should have rendered the typed backend", func() {
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformConfigSecretName()
 
-				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				found, err := kubernetes.GetIfExists(context.Background(), cc, secret)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+
+				backend := string(secret.Data[terraformv1alpha1.TerraformBackendSecretKey])
+				Expect(backend).To(ContainSubstring(`backend "s3"`))
+				Expect(backend).To(ContainSubstring(`key            = "terranetes/apps/bucket/`))
+				Expect(backend).To(ContainSubstring(`access_key     = "access"`))
+			})
+
+			It("should have recorded the backend in the status", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Status.Backend).ToNot(BeNil())
+				Expect(configuration.Status.Backend.Type).To(Equal(terraformv1alpha1.BackendTypeS3))
+				Expect(configuration.Status.Backend.Checksum).ToNot(BeEmpty())
+				Expect(configuration.Status.Backend.HasPendingMigration()).To(BeFalse())
+			})
+		})
+
+		Context("and the backend has changed under an existing state", func() {
+			var tfstate *v1.Secret
+
+			BeforeEach(func() {
+				secret := &v1.Secret{}
+				secret.Namespace = "default"
+				secret.Name = "backend"
+
+				tfstate = fixtures.NewTerraformState(configuration)
+				tfstate.Namespace = "default"
+
+				config := &v1.Secret{}
+				config.Namespace = "default"
+				config.Name = configuration.GetTerraformConfigSecretName()
+				config.Data = map[string][]byte{
+					terraformv1alpha1.TerraformBackendSecretKey: []byte(`terraform { backend "kubernetes" {} }`),
+				}
+
+				configuration.Status.Backend = &terraformv1alpha1.BackendStatus{
+					Checksum: "0123456789abcdef",
+					Type:     terraformv1alpha1.BackendTypeKubernetes,
+				}
+				Setup(configuration, secret, tfstate, config)
+
+				provider = &terraformv1alpha1.Provider{}
+				provider.Name = configuration.Spec.ProviderRef.Name
+				Expect(cc.Get(context.Background(), provider.GetNamespacedName(), provider)).To(Succeed())
+			})
+
+			It("should require the state to be migrated", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform state backend has changed from kubernetes to s3, the state must be migrated using `tnctl state migrate bucket -n apps`"))
+			})
+
+			It("should have recorded the pending backend", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Status.Backend.Checksum).To(Equal("0123456789abcdef"))
+				Expect(configuration.Status.Backend.PendingChecksum).ToNot(BeEmpty())
+				Expect(configuration.Status.Backend.PendingType).To(Equal(terraformv1alpha1.BackendTypeS3))
+			})
+
+			It("should have retained the previous backend configuration", func() {
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformMigrationSecretName()
+
+				found, err := kubernetes.GetIfExists(context.Background(), cc, secret)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(string(secret.Data[terraformv1alpha1.TerraformBackendSecretKey])).To(ContainSubstring(`backend "kubernetes"`))
+			})
+
+			It("should not have created any jobs", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+
+			When("the migration has been approved", func() {
+				JustBeforeEach(func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+					configuration.Annotations = map[string]string{
+						terraformv1alpha1.MigrateStateAnnotation: configuration.Status.Backend.PendingChecksum,
+					}
+					Expect(cc.Update(context.TODO(), configuration)).To(Succeed())
+
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+				})
+
+				It("should have created a migration job", func() {
+					list := &batchv1.JobList{}
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+						client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformMigrate},
+					)).To(Succeed())
+					Expect(list.Items).To(HaveLen(1))
+
+					Expect(list.Items[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.ConfigurationBackendLabel, configuration.Status.Backend.PendingChecksum))
+					args := list.Items[0].Spec.Template.Spec.Containers[0].Args
+					Expect(args).To(ContainElement("--command=/bin/terraform init -migrate-state -force-copy -input=false"))
+					Expect(args).To(ContainElement("--upload=$(TERRAFORM_STATE_NAME)=/run/tfstate"))
+				})
+
+				It("should indicate the migration is running", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+					cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonInProgress))
+					Expect(cond.Message).To(Equal("Terraform state migration is running"))
+				})
+
+				It("should not have run a terraform plan", func() {
+					list := &batchv1.JobList{}
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+						client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformPlan},
+					)).To(Succeed())
+					Expect(list.Items).To(BeEmpty())
+				})
+			})
+
+			When("the migration has completed", func() {
+				JustBeforeEach(func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+					checksum := configuration.Status.Backend.PendingChecksum
+					configuration.Annotations = map[string]string{terraformv1alpha1.MigrateStateAnnotation: checksum}
+					Expect(cc.Update(context.TODO(), configuration)).To(Succeed())
+
+					job := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformMigrate)
+					job.Labels[terraformv1alpha1.ConfigurationBackendLabel] = checksum
+					job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+					job.Status.Succeeded = 1
+					Expect(cc.Create(context.TODO(), job)).To(Succeed())
+
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+				})
+
+				It("should have moved the state to the new backend", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+					Expect(configuration.Status.Backend.Type).To(Equal(terraformv1alpha1.BackendTypeS3))
+					Expect(configuration.Status.Backend.Checksum).ToNot(Equal("0123456789abcdef"))
+					Expect(configuration.Status.Backend.HasPendingMigration()).To(BeFalse())
+				})
+
+				It("should have removed the previous backend configuration", func() {
+					secret := &v1.Secret{}
+					secret.Namespace = ctrl.ControllerNamespace
+					secret.Name = configuration.GetTerraformMigrationSecretName()
+
+					found, err := kubernetes.GetIfExists(context.Background(), cc, secret)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(found).To(BeFalse())
+				})
+
+				It("should have continued onto the terraform plan", func() {
+					list := &batchv1.JobList{}
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+						client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformPlan},
+					)).To(Succeed())
+					Expect(list.Items).To(HaveLen(1))
+				})
+			})
+		})
+	})
+
+	When("using a custom backend template", func() {
+		When("the template is not present", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				Setup(configuration)
+				ctrl.BackendTemplate = "not_there"
+
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 10)
+			})
+
+			It("should have the conditions", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+			})
+
+			It("should indicate the configuration failed", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Backend template secret \"default/not_there\" not found, contact administrator"))
+			})
+
+			It("should not create any jobs", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(0))
+			})
+		})
+
+		When("the template is present but missing required keys", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				backend := fixtures.NewBackendTemplateSecret(ctrl.ControllerNamespace, "missing_key")
+				delete(backend.Data, "backend.tf")
+				Setup(configuration, backend)
+				ctrl.BackendTemplate = "missing_key"
+
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 10)
+			})
+
+			It("should have the conditions", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+			})
+
+			It("should indicate the configuration failed", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
 				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
 				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
 				Expect(cond.Message).To(Equal("Backend template secret \"default/missing_key\" does not contain the backend.tf key"))
@@ -2161,6 +3057,118 @@ terraform {
 		})
 	})
 
+	When("configuration has a drift policy", func() {
+		drift := "1700000000"
+
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Spec.EnableAutoApproval = true
+			configuration.Spec.EnableDriftDetection = true
+			configuration.Spec.DriftPolicy = &terraformv1alpha1.DriftPolicy{Mode: terraformv1alpha1.DriftModeRemediate}
+			configuration.Annotations = map[string]string{terraformv1alpha1.DriftAnnotation: drift}
+			configuration.Status.DriftTimestamp = drift
+			configuration.Status.ResourceStatus = terraformv1alpha1.ResourcesOutOfSync
+			configuration.Status.Conditions = []corev1alpha1.Condition{
+				{
+					Type:               terraformv1alpha1.ConditionTerraformPlan,
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: configuration.GetGeneration(),
+				},
+				{
+					Type:               terraformv1alpha1.ConditionTerraformApply,
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: configuration.GetGeneration(),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			// @note: the plan which detected the drift, and the apply for the original change
+			plan := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformPlan)
+			plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			plan.Status.Succeeded = 1
+
+			apply := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformApply)
+			delete(apply.Labels, terraformv1alpha1.DriftAnnotation)
+			apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			apply.Status.Succeeded = 1
+
+			tfplan := fixtures.NewTerraformPlan(configuration)
+			tfplan.Namespace = "default"
+
+			Setup(configuration, plan, apply, tfplan)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+		})
+
+		When("the drift policy is to remediate", func() {
+			It("should have created an apply job to remediate the drift", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.DriftAnnotation: drift})).ToNot(HaveOccurred())
+
+				Expect(list.Items).To(HaveLen(2))
+			})
+
+			It("should indicate the terraform apply is running", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonInProgress))
+			})
+
+			It("should have raised a remediation event", func() {
+				Expect(recorder.Events).To(ContainElement(ContainSubstring("Remediating the drift detected at " + drift)))
+			})
+		})
+
+		When("the drift policy requires approval", func() {
+			BeforeEach(func() {
+				configuration.Spec.DriftPolicy.Mode = terraformv1alpha1.DriftModeRequireApproval
+			})
+
+			It("should indicate the remediation is waiting for approval", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Drift has been detected in the resource, set the annotation terraform.appvia.io/drift-approval=1700000000 to approve the remediation"))
+			})
+
+			It("should not have created an apply job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(2))
+			})
+		})
+
+		When("the drift remediation has been approved", func() {
+			BeforeEach(func() {
+				configuration.Spec.DriftPolicy.Mode = terraformv1alpha1.DriftModeRequireApproval
+				configuration.Annotations[terraformv1alpha1.DriftApprovalAnnotation] = drift
+			})
+
+			It("should have created an apply job to remediate the drift", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(3))
+			})
+		})
+
+		When("the drift policy is to detect only", func() {
+			BeforeEach(func() {
+				configuration.Spec.DriftPolicy.Mode = terraformv1alpha1.DriftModeDetect
+			})
+
+			It("should not have created an apply job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(list.Items).To(HaveLen(2))
+			})
+		})
+	})
+
 	// CHECKOV
 	When("checkov with an external source", func() {
 		BeforeEach(func() {
@@ -2473,7 +3481,10 @@ terraform {
 				report.Name = configuration.GetTerraformPolicySecretName()
 				report.Data = map[string][]byte{"results_json.json": []byte(`{"summary":{"failed": 1}}`)}
 
-				Setup(configuration, policy, plan, report)
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = ctrl.ControllerNamespace
+
+				Setup(configuration, policy, plan, report, tfplan)
 			})
 
 			When("policy report is missing due to interval error", func() {
@@ -2709,55 +3720,278 @@ terraform {
 				})
 			})
 		})
-	})
+		When("configuration has matched a rego policy", func() {
+			module := "package main\n\ndeny[msg] {\n  msg := \"denied\"\n}\n"
 
-	When("using a custom job template", func() {
-		templateName := "template"
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				policy := fixtures.NewRegoPolicy("rego", module)
 
-		BeforeEach(func() {
-			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
-			Setup(configuration)
+				Setup(configuration, policy)
+			})
 
-			cm := fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName)
-			ctrl.JobTemplate = cm.Name
+			When("the plan has not been run", func() {
+				BeforeEach(func() {
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
 
-			Expect(ctrl.cc.Create(context.TODO(), cm)).ToNot(HaveOccurred())
-		})
+				It("should have the rego modules in the configuration secret", func() {
+					secret := &v1.Secret{}
+					secret.Namespace = ctrl.ControllerNamespace
+					secret.Name = configuration.GetTerraformConfigSecretName()
 
-		When("the template is missing", func() {
-			BeforeEach(func() {
-				ctrl.cc.Delete(context.TODO(), fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName))
-				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
-			})
+					found, err := kubernetes.GetIfExists(context.TODO(), ctrl.cc, secret)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(found).To(BeTrue())
+					Expect(secret.Data).To(HaveKey("rego-main.rego"))
+					Expect(string(secret.Data["rego-main.rego"])).To(Equal(module))
+				})
 
-			It("should have conditions", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
-				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
-			})
+				It("should have a rego verification container", func() {
+					list := &batchv1.JobList{}
 
-			It("should indicate action is required in the conditions", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(len(list.Items)).To(Equal(1))
+					job := list.Items[0]
 
-				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
-				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
-				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
-				Expect(cond.Message).To(Equal("Custom job template (default/template) does not exists"))
+					Expect(len(job.Spec.Template.Spec.Containers)).To(Equal(2))
+					container := job.Spec.Template.Spec.Containers[1]
+					Expect(container.Name).To(Equal("verify-rego"))
+					Expect(container.Image).To(Equal(ctrl.RegoImage))
+					Expect(container.Args).To(Equal([]string{
+						"--comment=Evaluating Against Rego Policies",
+						"--command=/usr/local/bin/conftest test --all-namespaces --no-fail --policy /run/rego /run/plan.json",
+						"--command=/usr/local/bin/conftest test --all-namespaces --no-fail --policy /run/rego --output json /run/plan.json > /run/rego_results.json",
+						"--namespace=$(KUBE_NAMESPACE)",
+						"--upload=$(REGO_REPORT_NAME)=/run/rego_results.json",
+						"--is-failure=/run/steps/terraform.failed",
+						"--wait-on=/run/steps/terraform.complete",
+					}))
+					Expect(container.VolumeMounts).To(ContainElement(v1.VolumeMount{Name: "rego", MountPath: "/run/rego/inline"}))
+				})
 			})
 
-			It("should have raised a event", func() {
-				Expect(recorder.Events).To(HaveLen(1))
-				Expect(recorder.Events[0]).To(ContainSubstring("Custom job template (default/template) does not exists"))
-			})
-		})
+			When("the plan has completed", func() {
+				var report *v1.Secret
 
-		When("the template does not have the correct key", func() {
-			BeforeEach(func() {
-				// @step: delete the old one and create a new one with missing keys
-				cm := fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName)
-				invalid := fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName)
-				invalid.Data = map[string]string{}
+				BeforeEach(func() {
+					plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+					plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+					plan.Status.Succeeded = 1
+					Expect(ctrl.cc.Create(context.TODO(), plan)).ToNot(HaveOccurred())
 
-				ctrl.cc.Delete(context.TODO(), cm)
+					tfplan := fixtures.NewTerraformPlan(configuration)
+					tfplan.Namespace = ctrl.ControllerNamespace
+					Expect(ctrl.cc.Create(context.TODO(), tfplan)).ToNot(HaveOccurred())
+
+					report = &v1.Secret{}
+					report.Namespace = ctrl.ControllerNamespace
+					report.Name = configuration.GetTerraformRegoSecretName()
+				})
+
+				When("the rego results are missing", func() {
+					BeforeEach(func() {
+						result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+					})
+
+					It("should indicate the results are missing", func() {
+						Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+						cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+						Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+						Expect(cond.Reason).To(Equal(corev1alpha1.ReasonWarning))
+						Expect(cond.Message).To(Equal("Failed to find the secret: (default/rego-1234-122-1234-1234) containing rego results"))
+					})
+				})
+
+				When("the rego results contain failures", func() {
+					BeforeEach(func() {
+						report.Data = map[string][]byte{"rego_results.json": []byte(`[{"namespace":"main","failures":[{"msg":"denied"}]}]`)}
+						Expect(ctrl.cc.Create(context.TODO(), report)).ToNot(HaveOccurred())
+
+						result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+					})
+
+					It("should indicate the configuration failed the policy", func() {
+						Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+						cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+						Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+						Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+						Expect(cond.Message).To(Equal("Configuration has failed 1 rego policy rule(s), refusing to continue: denied"))
+					})
+
+					It("should have copied the report into the configuration namespace", func() {
+						copied := &v1.Secret{}
+						copied.Namespace = configuration.Namespace
+						copied.Name = configuration.GetTerraformRegoSecretName()
+
+						found, err := kubernetes.GetIfExists(context.TODO(), ctrl.cc, copied)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(found).To(BeTrue())
+					})
+
+					It("should have not create an apply job", func() {
+						list := &batchv1.JobList{}
+
+						Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+						Expect(len(list.Items)).To(Equal(1))
+					})
+				})
+
+				When("the rego results only contain warnings", func() {
+					BeforeEach(func() {
+						report.Data = map[string][]byte{"rego_results.json": []byte(`[{"namespace":"main","warnings":[{"msg":"be careful"}]}]`)}
+						Expect(ctrl.cc.Create(context.TODO(), report)).ToNot(HaveOccurred())
+
+						result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+					})
+
+					It("should indicate the configuration passed the policy", func() {
+						Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+						cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPolicy)
+						Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+						Expect(cond.Message).To(Equal("Passed security checks"))
+					})
+
+					It("should have raised a warning event", func() {
+						Expect(recorder.Events).To(ContainElement(ContainSubstring("be careful")))
+					})
+				})
+			})
+		})
+		When("configuration has matched a signature policy", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				policy := fixtures.NewSignaturePolicy("signed")
+
+				Setup(configuration, policy)
+			})
+
+			When("the plan has not been run", func() {
+				BeforeEach(func() {
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should have the public keys in the configuration secret", func() {
+					secret := &v1.Secret{}
+					secret.Namespace = ctrl.ControllerNamespace
+					secret.Name = configuration.GetTerraformConfigSecretName()
+
+					found, err := kubernetes.GetIfExists(context.TODO(), ctrl.cc, secret)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(found).To(BeTrue())
+					Expect(secret.Data).To(HaveKey("signature-signed-release.ssh"))
+				})
+
+				It("should verify the signature of the module source", func() {
+					list := &batchv1.JobList{}
+
+					Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+					Expect(len(list.Items)).To(Equal(1))
+					job := list.Items[0]
+
+					setup := job.Spec.Template.Spec.InitContainers[0]
+					Expect(setup.Args).To(ContainElement(ContainSubstring("--signature-keys=/run/signatures")))
+					Expect(setup.VolumeMounts).To(ContainElement(v1.VolumeMount{Name: "signatures", MountPath: "/run/signatures", ReadOnly: true}))
+					Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", "signatures")))
+				})
+			})
+
+			When("the module source failed verification", func() {
+				message := "module signature verification failed: neither the commit nor any tag on it has a valid signature from the trusted keys"
+
+				BeforeEach(func() {
+					plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+					plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}
+					plan.Status.Failed = 1
+					Expect(ctrl.cc.Create(context.TODO(), plan)).ToNot(HaveOccurred())
+
+					pod := &v1.Pod{}
+					pod.Namespace = ctrl.ControllerNamespace
+					pod.Name = plan.Name + "-abcd"
+					pod.Labels = map[string]string{"job-name": plan.Name}
+					pod.Status.Phase = v1.PodFailed
+					pod.Status.InitContainerStatuses = []v1.ContainerStatus{{
+						Name: "setup",
+						State: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Message: message},
+						},
+					}}
+					_, err := ctrl.kc.CoreV1().Pods(ctrl.ControllerNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+					Expect(err).ToNot(HaveOccurred())
+
+					result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+				})
+
+				It("should indicate the plan failed", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPlan)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonError))
+				})
+
+				It("should surface the verification failure on the configuration", func() {
+					Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+					cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+					Expect(cond.Message).To(Equal(message))
+				})
+			})
+		})
+	})
+
+	When("using a custom job template", func() {
+		templateName := "template"
+
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			Setup(configuration)
+
+			cm := fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName)
+			ctrl.JobTemplate = cm.Name
+
+			Expect(ctrl.cc.Create(context.TODO(), cm)).ToNot(HaveOccurred())
+		})
+
+		When("the template is missing", func() {
+			BeforeEach(func() {
+				ctrl.cc.Delete(context.TODO(), fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName))
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have conditions", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+			})
+
+			It("should indicate action is required in the conditions", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Custom job template (default/template) does not exists"))
+			})
+
+			It("should have raised a event", func() {
+				Expect(recorder.Events).To(HaveLen(1))
+				Expect(recorder.Events[0]).To(ContainSubstring("Custom job template (default/template) does not exists"))
+			})
+		})
+
+		When("the template does not have the correct key", func() {
+			BeforeEach(func() {
+				// @step: delete the old one and create a new one with missing keys
+				cm := fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName)
+				invalid := fixtures.NewJobTemplateConfigmap(ctrl.ControllerNamespace, templateName)
+				invalid.Data = map[string]string{}
+
+				ctrl.cc.Delete(context.TODO(), cm)
 				ctrl.cc.Create(context.TODO(), invalid)
 
 				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
@@ -2865,7 +4099,7 @@ terraform {
 			})
 		})
 
-		When("the configuration is approved", func() {
+		When("the configuration is approved but the terraform plan is missing", func() {
 			BeforeEach(func() {
 				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
 				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
@@ -2875,110 +4109,393 @@ terraform {
 				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
 			})
 
-			It("should have the conditions", func() {
+			It("should indicate the terraform plan is missing", func() {
 				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
-				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform plan for generation 0 is missing, refusing to apply"))
 			})
 
-			It("should indicate the terraform apply is running", func() {
-				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			It("should not have created a job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
+			})
+		})
 
-				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPlan)
-				Expect(cond.Status).To(Equal(metav1.ConditionTrue))
-				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonReady))
-				Expect(cond.Message).To(Equal("Terraform plan is complete"))
+		When("the configuration is approved but the terraform plan is stale", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Generation = 2
+				configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{
+					Checksum:   "checksum",
+					Generation: 1,
+					Job:        configuration.Name + "-plan-1234",
+				}
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				Setup(configuration, plan)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
 			})
 
-			It("should have created job for the terraform apply", func() {
-				list := &batchv1.JobList{}
+			It("should indicate the terraform plan is stale", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
 
-				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
-				Expect(len(list.Items)).To(Equal(2))
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform plan was produced for generation 1, not 2, refusing to apply"))
 			})
 
-			It("should have a terraform container", func() {
+			It("should not have created a job", func() {
 				list := &batchv1.JobList{}
 				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
-				Expect(len(list.Items)).To(Equal(2))
+				Expect(len(list.Items)).To(Equal(1))
+			})
+		})
 
-				expected := []string{
-					"--comment=Executing Terraform",
-					"--command=/bin/terraform apply --var-file variables.tfvars.json -auto-approve -lock=false",
-					"--on-error=/run/steps/terraform.failed",
-					"--on-success=/run/steps/terraform.complete",
+		When("the configuration is approved but the terraform plan has changed", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{
+					Checksum: "checksum",
+					Job:      plan.Name,
 				}
-				job := list.Items[0]
-				container := job.Spec.Template.Spec.Containers[0]
-
-				Expect(container.Name).To(Equal("terraform"))
-				Expect(container.Command).To(Equal([]string{"/run/bin/step"}))
-				Expect(container.Args).To(Equal(expected))
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = "default"
 
-				Expect(len(container.EnvFrom)).To(Equal(1))
-				Expect(container.EnvFrom[0].SecretRef).ToNot(BeNil())
-				Expect(container.EnvFrom[0].SecretRef.Name).To(Equal("aws"))
+				Setup(configuration, plan, tfplan)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
+			})
 
-				Expect(len(container.Env)).To(Equal(5))
-				Expect(container.Env[4].Name).To(Equal("TERRAFORM_STATE_NAME"))
-				Expect(container.Env[4].Value).To(Equal(configuration.GetTerraformStateSecretName()))
+			It("should indicate the terraform plan does not match", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
 
-				Expect(container.VolumeMounts[0].Name).To(Equal("run"))
-				Expect(container.VolumeMounts[1].Name).To(Equal("source"))
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform plan does not match the checksum of the approved plan, refusing to apply"))
 			})
 
-			It("it should have the configuration labels", func() {
+			It("should not have created a job", func() {
 				list := &batchv1.JobList{}
 				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
+			})
+		})
 
-				labels := list.Items[0].GetLabels()
-				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationNameLabel))
-				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationNamespaceLabel))
-				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationGenerationLabel))
-				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationStageLabel))
-				Expect(labels[terraformv1alpha1.ConfigurationStageLabel]).To(Equal(terraformv1alpha1.StageTerraformApply))
-				Expect(labels[terraformv1alpha1.ConfigurationNameLabel]).To(Equal(configuration.Name))
-				Expect(labels[terraformv1alpha1.ConfigurationNamespaceLabel]).To(Equal(configuration.Namespace))
+		When("the configuration was approved before a different terraform plan was produced", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Annotations[terraformv1alpha1.ApplyAnnotation] = "true"
+				configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{
+					Checksum: "previous",
+					Job:      configuration.Name + "-plan-previous",
+				}
+				plan := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = "default"
+
+				Setup(configuration, plan, tfplan)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
 			})
 
-			It("should have created a watch job in the configuration namespace", func() {
-				list := &batchv1.JobList{}
-				Expect(cc.List(context.TODO(), list, client.InNamespace(configuration.Namespace))).ToNot(HaveOccurred())
-				Expect(len(list.Items)).To(Equal(1))
-				Expect(len(list.Items[0].Spec.Template.Spec.Containers)).To(Equal(1))
+			It("should require the new terraform plan to be approved", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				Expect(configuration.Annotations[terraformv1alpha1.ApplyAnnotation]).To(Equal("false"))
+				Expect(configuration.Status.TerraformPlan.Checksum).To(Equal(utils.Sha256Sum([]byte("fake-plan"))))
+				Expect(configuration.Status.TerraformPlan.ApprovedChecksum).To(BeEmpty())
 
-				container := list.Items[0].Spec.Template.Spec.Containers[0]
-				Expect(container.Name).To(Equal("watch"))
-				Expect(container.Command).To(Equal([]string{"/watch_logs.sh"}))
-				Expect(container.Args).To(Equal([]string{"-e", "http://controller.default.svc.cluster.local/v1/builds/apps/bucket/logs?generation=0&name=bucket&namespace=apps&stage=apply&uid=1234-122-1234-1234"}))
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Waiting for terraform apply annotation to be set to true"))
 			})
 
-			It("should ask us to requeue", func() {
-				Expect(result).To(Equal(reconcile.Result{RequeueAfter: 5 * time.Second}))
-				Expect(rerr).To(BeNil())
+			It("should not have created a job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
 			})
 		})
-	})
 
-	// AFTER SUCCESSFUL APPLY
-	When("terraform apply has been provisioned", func() {
-		BeforeEach(func() {
-			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
-			// create two successful jobs
-			plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
-			plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
-			plan.Status.Succeeded = 1
+		When("the terraform plan has changed since it was approved", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				configuration.Annotations[terraformv1alpha1.ApplyAnnotation] = "true"
+				plan := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{
+					ApprovedChecksum: "approved",
+					Checksum:         utils.Sha256Sum([]byte("fake-plan")),
+					Job:              plan.Name,
+				}
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = "default"
 
-			apply := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformApply)
-			apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
-			apply.Status.Succeeded = 1
+				Setup(configuration, plan, tfplan)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
+			})
 
-			// create a fake terraform state
-			state := fixtures.NewTerraformState(configuration)
-			state.Namespace = "default"
+			It("should refuse to apply the terraform plan", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
 
-			Setup(configuration, plan, apply, state)
-			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
-		})
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform plan has changed since it was approved, refusing to apply"))
+			})
+
+			It("should not have created a job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
+			})
+		})
+
+		When("the configuration is approved but outside of the maintenance window", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = "default"
+				policy := fixtures.NewMaintenancePolicy("maintenance", "0 0 1 1 *", time.Minute)
+
+				Setup(configuration, plan, tfplan, policy)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
+			})
+
+			It("should indicate the terraform apply has been deferred", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonWarning))
+				Expect(cond.Message).To(HavePrefix("Terraform apply deferred, outside of the maintenance windows, next window opens at "))
+			})
+
+			It("should requeue when the window opens", func() {
+				Expect(rerr).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			})
+
+			It("should not have created the apply job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
+			})
+		})
+
+		When("the configuration is approved", func() {
+			BeforeEach(func() {
+				configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = "default"
+
+				Setup(configuration, plan, tfplan)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 5)
+			})
+
+			It("should have the conditions", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+				Expect(configuration.Status.Conditions).To(HaveLen(defaultConditions))
+			})
+
+			It("should indicate the terraform apply is running", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPlan)
+				Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonReady))
+				Expect(cond.Message).To(Equal("Terraform plan is complete"))
+			})
+
+			It("should have recorded the terraform plan on the status", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				Expect(configuration.Status.TerraformPlan).ToNot(BeNil())
+				Expect(configuration.Status.TerraformPlan.Checksum).To(Equal(utils.Sha256Sum([]byte("fake-plan"))))
+				Expect(configuration.Status.TerraformPlan.Generation).To(Equal(int64(0)))
+				Expect(configuration.Status.TerraformPlan.Job).To(Equal("bucket-plan-1234"))
+			})
+
+			It("should have bound the approval to the terraform plan", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				Expect(configuration.Status.TerraformPlan.ApprovedChecksum).To(Equal(utils.Sha256Sum([]byte("fake-plan"))))
+			})
+
+			It("should have recorded the resource changes in the terraform plan", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				Expect(configuration.Status.TerraformPlan).ToNot(BeNil())
+				Expect(configuration.Status.TerraformPlan.Changes).To(Equal(&terraformv1alpha1.TerraformPlanChanges{
+					Create:  1,
+					Replace: 1,
+					Resources: []terraformv1alpha1.TerraformPlanResourceChange{
+						{Action: terraformv1alpha1.ResourceChangeCreate, Address: "aws_s3_bucket.bucket"},
+						{Action: terraformv1alpha1.ResourceChangeReplace, Address: "aws_s3_bucket_policy.policy"},
+					},
+				}))
+			})
+
+			It("should have recorded the revision of the module source", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				Expect(configuration.Status.Source).To(Equal(&terraformv1alpha1.SourceStatus{
+					Digest:     fixtures.FakeSourceDigest,
+					Generation: configuration.GetGeneration(),
+					Module:     configuration.Spec.Module,
+					Revision:   fixtures.FakeSourceRevision,
+				}))
+			})
+
+			It("should pin the terraform apply to the revision of the module source", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(2))
+
+				setup := list.Items[0].Spec.Template.Spec.InitContainers[0]
+				Expect(setup.Name).To(Equal("setup"))
+				Expect(setup.Args).To(ContainElement(fmt.Sprintf("--command=/bin/source --dest=/data --source=%s --digest=%s --revision=%s",
+					configuration.Spec.Module, fixtures.FakeSourceDigest, fixtures.FakeSourceRevision)))
+			})
+
+			It("should have recorded the terraform plan in the run history", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+				Expect(configuration.Status.History).To(HaveLen(1))
+				run := configuration.Status.History[0]
+				Expect(run.Job).To(Equal("bucket-plan-1234"))
+				Expect(run.Stage).To(Equal(terraformv1alpha1.StageTerraformPlan))
+				Expect(run.Result).To(Equal(terraformv1alpha1.RunResultSucceeded))
+				Expect(run.Reason).To(Equal(terraformv1alpha1.RunReasonSpecChange))
+				Expect(run.Changes).To(Equal(&terraformv1alpha1.TerraformPlanChanges{Create: 1, Replace: 1}))
+			})
+
+			It("should have created job for the terraform apply", func() {
+				list := &batchv1.JobList{}
+
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(2))
+			})
+
+			It("should have a terraform container", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(2))
+
+				expected := []string{
+					"--comment=Executing Terraform",
+					"--namespace=$(KUBE_NAMESPACE)",
+					"--lock=$(TERRAFORM_LOCK_NAME)",
+					"--command=/bin/echo \"" + utils.Sha256Sum([]byte("fake-plan")) + "  /run/plan/plan.out\" | /usr/bin/sha256sum -c",
+					"--command=/bin/terraform apply -auto-approve -lock=false /run/plan/plan.out",
+					"--on-error=/run/steps/terraform.failed",
+					"--on-success=/run/steps/terraform.complete",
+				}
+				job := list.Items[0]
+				container := job.Spec.Template.Spec.Containers[0]
+
+				Expect(container.Name).To(Equal("terraform"))
+				Expect(container.Command).To(Equal([]string{"/run/bin/step"}))
+				Expect(container.Args).To(Equal(expected))
+
+				Expect(len(container.EnvFrom)).To(Equal(1))
+				Expect(container.EnvFrom[0].SecretRef).ToNot(BeNil())
+				Expect(container.EnvFrom[0].SecretRef.Name).To(Equal("aws"))
+
+				Expect(len(container.Env)).To(Equal(7))
+				Expect(container.Env[6].Name).To(Equal("TERRAFORM_STATE_NAME"))
+				Expect(container.Env[6].Value).To(Equal(configuration.GetTerraformStateSecretName()))
+
+				Expect(container.VolumeMounts).To(HaveLen(3))
+				Expect(container.VolumeMounts[0].Name).To(Equal("run"))
+				Expect(container.VolumeMounts[1].Name).To(Equal("source"))
+				Expect(container.VolumeMounts[2].Name).To(Equal("plan"))
+				Expect(container.VolumeMounts[2].MountPath).To(Equal("/run/plan"))
+			})
+
+			It("should mount the terraform plan secret", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+
+				var found bool
+				for _, x := range list.Items[0].Spec.Template.Spec.Volumes {
+					if x.Name == "plan" {
+						found = true
+						Expect(x.Secret).ToNot(BeNil())
+						Expect(x.Secret.SecretName).To(Equal(configuration.GetTerraformPlanSecretName()))
+					}
+				}
+				Expect(found).To(BeTrue())
+			})
+
+			It("it should have the configuration labels", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).ToNot(HaveOccurred())
+
+				labels := list.Items[0].GetLabels()
+				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationNameLabel))
+				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationNamespaceLabel))
+				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationGenerationLabel))
+				Expect(labels).To(HaveKey(terraformv1alpha1.ConfigurationStageLabel))
+				Expect(labels[terraformv1alpha1.ConfigurationStageLabel]).To(Equal(terraformv1alpha1.StageTerraformApply))
+				Expect(labels[terraformv1alpha1.ConfigurationNameLabel]).To(Equal(configuration.Name))
+				Expect(labels[terraformv1alpha1.ConfigurationNamespaceLabel]).To(Equal(configuration.Namespace))
+			})
+
+			It("should have created a watch job in the configuration namespace", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(configuration.Namespace))).ToNot(HaveOccurred())
+				Expect(len(list.Items)).To(Equal(1))
+				Expect(len(list.Items[0].Spec.Template.Spec.Containers)).To(Equal(1))
+
+				container := list.Items[0].Spec.Template.Spec.Containers[0]
+				Expect(container.Name).To(Equal("watch"))
+				Expect(container.Command).To(Equal([]string{"/watch_logs.sh"}))
+				Expect(container.Args).To(Equal([]string{"-e", "http://controller.default.svc.cluster.local/v1/builds/apps/bucket/logs?generation=0&name=bucket&namespace=apps&stage=apply&uid=1234-122-1234-1234"}))
+			})
+
+			It("should ask us to requeue", func() {
+				Expect(result).To(Equal(reconcile.Result{RequeueAfter: 5 * time.Second}))
+				Expect(rerr).To(BeNil())
+			})
+		})
+	})
+
+	// AFTER SUCCESSFUL APPLY
+	When("terraform apply has been provisioned", func() {
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			// create two successful jobs
+			plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+			plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			plan.Status.Succeeded = 1
+
+			apply := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformApply)
+			apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			apply.Status.Succeeded = 1
+
+			// create a fake terraform state
+			state := fixtures.NewTerraformState(configuration)
+			state.Namespace = "default"
+
+			Setup(configuration, plan, apply, state)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+		})
 
 		It("should have the conditions", func() {
 			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
@@ -3015,12 +4532,28 @@ terraform {
 			Expect(configuration.Status.TerraformVersion).To(Equal("1.1.9"))
 		})
 
+		It("should have the engine on the status", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			Expect(configuration.Status.Engine).To(Equal(terraformv1alpha1.EngineTerraform))
+		})
+
 		It("should have a resource count on the status", func() {
 			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
 			Expect(configuration.Status.Resources).ToNot(BeNil())
 			Expect(*configuration.Status.Resources).To(Equal(1))
 		})
 
+		It("should have recorded the runs in the history", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+
+			Expect(configuration.Status.History).To(HaveLen(2))
+			Expect(configuration.Status.History[0].Stage).To(Equal(terraformv1alpha1.StageTerraformPlan))
+			Expect(configuration.Status.History[1].Stage).To(Equal(terraformv1alpha1.StageTerraformApply))
+			Expect(configuration.Status.History[1].Job).To(Equal("bucket-apply-1234"))
+			Expect(configuration.Status.History[1].Result).To(Equal(terraformv1alpha1.RunResultSucceeded))
+			Expect(configuration.Status.History[1].Reason).To(Equal(terraformv1alpha1.RunReasonSpecChange))
+		})
+
 		It("should have a in resource status", func() {
 			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
 
@@ -3043,6 +4576,631 @@ terraform {
 		})
 	})
 
+	When("terraform apply has been provisioned with a resolved terraform version", func() {
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Spec.TerraformVersion = "~> 1.6"
+
+			plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+			plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			plan.Status.Succeeded = 1
+
+			apply := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformApply)
+			apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			apply.Status.Succeeded = 1
+
+			state := fixtures.NewTerraformState(configuration)
+			state.Namespace = "default"
+
+			Setup(configuration, plan, apply, state)
+			ctrl.TerraformVersions = []string{"1.6.0", "1.6.5"}
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+		})
+
+		It("should have the version from the terraform state on the status", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			Expect(configuration.Status.TerraformVersion).To(Equal("1.1.9"))
+		})
+
+		It("should have the resolved version on the status", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).ToNot(HaveOccurred())
+			Expect(configuration.Status.ResolvedTerraformVersion).To(Equal("1.6.5"))
+		})
+	})
+
+	When("the controller is retaining versions of the terraform state", func() {
+		var versions []v1.Secret
+
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+		})
+
+		Setup := func(objects ...runtime.Object) {
+			plan := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformPlan)
+			plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			plan.Status.Succeeded = 1
+
+			apply := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformApply)
+			apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+			apply.Status.Succeeded = 1
+
+			tfstate := fixtures.NewTerraformState(configuration)
+			tfstate.Namespace = "default"
+
+			Setup(append([]runtime.Object{configuration, plan, apply, tfstate}, objects...)...)
+			ctrl.StateVersions = 2
+		}
+
+		JustBeforeEach(func() {
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+
+			var err error
+			versions, err = terraform.ListStateVersions(context.TODO(), cc, ctrl.ControllerNamespace, configuration)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("and no versions have been retained", func() {
+			BeforeEach(func() {
+				Setup()
+			})
+
+			It("should have retained a version of the state", func() {
+				Expect(versions).To(HaveLen(1))
+				Expect(versions[0].Name).To(Equal(configuration.GetTerraformStateVersionSecretName(1)))
+				Expect(versions[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.ConfigurationStateSerialLabel, "4"))
+				Expect(versions[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.ConfigurationGenerationLabel, "0"))
+				Expect(versions[0].Labels).To(HaveKey(terraformv1alpha1.ConfigurationTimestampLabel))
+				Expect(versions[0].Data).To(HaveKey(terraformv1alpha1.TerraformStateSecretKey))
+			})
+
+			It("should be ready", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				Expect(cond.Message).To(Equal("Resource ready"))
+			})
+		})
+
+		Context("and the latest version matches the current state", func() {
+			BeforeEach(func() {
+				version := fixtures.NewTerraformStateVersion(configuration, 1)
+				version.Namespace = "default"
+
+				Setup(version)
+			})
+
+			It("should not have retained another version", func() {
+				Expect(versions).To(HaveLen(1))
+				Expect(versions[0].Name).To(Equal(configuration.GetTerraformStateVersionSecretName(1)))
+			})
+		})
+
+		Context("and the versions have reached the limit", func() {
+			BeforeEach(func() {
+				var objects []runtime.Object
+				for i := 1; i <= 2; i++ {
+					version := fixtures.NewTerraformStateVersion(configuration, i)
+					version.Namespace = "default"
+					version.Labels[terraformv1alpha1.ConfigurationStateSerialLabel] = fmt.Sprintf("%d", i)
+					objects = append(objects, version)
+				}
+
+				Setup(objects...)
+			})
+
+			It("should have pruned the oldest version", func() {
+				Expect(versions).To(HaveLen(2))
+				Expect(versions[0].Name).To(Equal(configuration.GetTerraformStateVersionSecretName(2)))
+				Expect(versions[1].Name).To(Equal(configuration.GetTerraformStateVersionSecretName(3)))
+			})
+		})
+
+		Context("and a restore has been requested", func() {
+			BeforeEach(func() {
+				version := fixtures.NewTerraformStateVersion(configuration, 1)
+				version.Namespace = "default"
+
+				configuration.Annotations = map[string]string{
+					terraformv1alpha1.RestoreStateAnnotation: "1",
+				}
+				Setup(version)
+			})
+
+			It("should require approval", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Restore of the terraform state to version 1 (serial 4) is awaiting approval, use `tnctl state restore bucket -n apps --approve`"))
+			})
+
+			It("should not have created a restore job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformRestore},
+				)).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+
+		Context("and a restore of a missing version has been requested", func() {
+			BeforeEach(func() {
+				configuration.Annotations = map[string]string{
+					terraformv1alpha1.RestoreStateAnnotation:    "3",
+					terraformv1alpha1.RestoreApprovalAnnotation: "1700000000",
+				}
+				Setup()
+			})
+
+			It("should indicate the version does not exist", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform state version 3 requested for restore does not exist, use `tnctl state history bucket -n apps` to list the versions"))
+			})
+		})
+
+		Context("and a restore has been approved", func() {
+			BeforeEach(func() {
+				version := fixtures.NewTerraformStateVersion(configuration, 1)
+				version.Namespace = "default"
+
+				configuration.Annotations = map[string]string{
+					terraformv1alpha1.RestoreStateAnnotation:    "1",
+					terraformv1alpha1.RestoreApprovalAnnotation: "1700000000",
+				}
+				Setup(version)
+			})
+
+			It("should have created a restore job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformRestore},
+				)).To(Succeed())
+				Expect(list.Items).To(HaveLen(1))
+				Expect(list.Items[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.ConfigurationStateVersionLabel, "1"))
+				Expect(list.Items[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.RestoreApprovalAnnotation, "1700000000"))
+
+				var found bool
+				for _, x := range list.Items[0].Spec.Template.Spec.Volumes {
+					if x.Name == "restore" {
+						found = true
+						Expect(x.Secret.SecretName).To(Equal(configuration.GetTerraformStateVersionSecretName(1)))
+					}
+				}
+				Expect(found).To(BeTrue())
+			})
+
+			It("should indicate the restore is running", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonInProgress))
+				Expect(cond.Message).To(Equal("Terraform state restore to version 1 is running"))
+			})
+		})
+
+		Context("and the restore has completed", func() {
+			BeforeEach(func() {
+				version := fixtures.NewTerraformStateVersion(configuration, 1)
+				version.Namespace = "default"
+
+				configuration.Annotations = map[string]string{
+					terraformv1alpha1.RestoreStateAnnotation:    "1",
+					terraformv1alpha1.RestoreApprovalAnnotation: "1700000000",
+				}
+				job := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformRestore)
+				job.Labels[terraformv1alpha1.ConfigurationStateVersionLabel] = "1"
+				job.Labels[terraformv1alpha1.RestoreApprovalAnnotation] = "1700000000"
+				job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				job.Status.Succeeded = 1
+
+				Setup(version, job)
+			})
+
+			It("should have removed the restore annotations", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.RestoreStateAnnotation))
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.RestoreApprovalAnnotation))
+			})
+
+			It("should have raised an event", func() {
+				Expect(recorder.Events).To(ContainElement(ContainSubstring("Terraform state has been restored to version 1")))
+			})
+		})
+	})
+
+	When("configuration has a state command", func() {
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Annotations[terraformv1alpha1.StateCommandAnnotation] = `["mv", "aws_s3_bucket.this", "aws_s3_bucket.main[\"it's a\"]"]`
+			configuration.Annotations[terraformv1alpha1.StateCommandIDAnnotation] = "1700000000"
+		})
+
+		Context("and the state command is invalid", func() {
+			BeforeEach(func() {
+				configuration.Annotations[terraformv1alpha1.StateCommandAnnotation] = `["taint", "aws_s3_bucket.this"]`
+
+				Setup(configuration)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate the state command is invalid", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal(`Terraform state command "[\"taint\", \"aws_s3_bucket.this\"]" is invalid, state command "taint" is not supported`))
+			})
+
+			It("should not have created any jobs", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+
+		Context("and the state to push is missing", func() {
+			BeforeEach(func() {
+				configuration.Annotations[terraformv1alpha1.StateCommandAnnotation] = `["push"]`
+
+				Setup(configuration)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate the state is missing", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform state to push is missing from the secret (default/statecmd-1234-122-1234-1234)"))
+			})
+		})
+
+		Context("and the state command has been requested", func() {
+			BeforeEach(func() {
+				Setup(configuration)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have created a state command job", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformState},
+				)).To(Succeed())
+				Expect(list.Items).To(HaveLen(1))
+				Expect(list.Items[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.ConfigurationStateCommandLabel, "1700000000"))
+
+				container := list.Items[0].Spec.Template.Spec.Containers[0]
+				Expect(container.Args).To(ContainElements(
+					"--lock=$(TERRAFORM_LOCK_NAME)",
+					"--command=/bin/terraform state pull > /run/snapshot",
+					`--command=/bin/terraform state mv -lock=false 'aws_s3_bucket.this' 'aws_s3_bucket.main["it'"'"'s a"]' > /run/output`,
+					"--upload=$(TERRAFORM_STATE_COMMAND_NAME)=/run/output",
+					"--upload=$(TERRAFORM_STATE_COMMAND_NAME)=/run/snapshot",
+				))
+			})
+
+			It("should not have run a plan", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformPlan},
+				)).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+
+			It("should indicate the state command is running", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(corev1alpha1.ConditionReady)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonInProgress))
+				Expect(cond.Message).To(Equal(`Terraform state command "mv aws_s3_bucket.this \"aws_s3_bucket.main[\\\"it's a\\\"]\"" is running`))
+				Expect(configuration.Status.StateCommand).ToNot(BeNil())
+				Expect(configuration.Status.StateCommand.ID).To(Equal("1700000000"))
+				Expect(configuration.Status.StateCommand.Result).To(BeEmpty())
+			})
+		})
+
+		Context("and the state command has completed", func() {
+			BeforeEach(func() {
+				job := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformState)
+				job.Labels[terraformv1alpha1.ConfigurationStateCommandLabel] = "1700000000"
+				job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				job.Status.Succeeded = 1
+
+				Setup(configuration, job)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have removed the state command annotations", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.StateCommandAnnotation))
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.StateCommandIDAnnotation))
+			})
+
+			It("should have recorded the result on the status", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Status.StateCommand).To(Equal(&terraformv1alpha1.StateCommandStatus{
+					Command: `mv aws_s3_bucket.this "aws_s3_bucket.main[\"it's a\"]"`,
+					ID:      "1700000000",
+					Job:     "bucket-state-1234",
+					Result:  terraformv1alpha1.RunResultSucceeded,
+				}))
+			})
+
+			It("should have raised an event", func() {
+				Expect(recorder.Events).To(ContainElement(ContainSubstring("has completed")))
+			})
+		})
+
+		Context("and the state command has failed", func() {
+			BeforeEach(func() {
+				job := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformState)
+				job.Labels[terraformv1alpha1.ConfigurationStateCommandLabel] = "1700000000"
+				job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}
+				job.Status.Failed = 1
+
+				Setup(configuration, job)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have recorded the failure on the status", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Status.StateCommand).ToNot(BeNil())
+				Expect(configuration.Status.StateCommand.Result).To(Equal(terraformv1alpha1.RunResultFailed))
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.StateCommandAnnotation))
+			})
+
+			It("should have raised a warning event", func() {
+				Expect(recorder.Events).To(ContainElement(ContainSubstring("has failed")))
+			})
+		})
+	})
+
+	When("configuration has imports", func() {
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Spec.Imports = terraformv1alpha1.ImportList{
+				{Address: "aws_s3_bucket.this", ID: "existing-bucket"},
+			}
+		})
+
+		Context("and the imports have not been applied", func() {
+			BeforeEach(func() {
+				Setup(configuration)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have rendered the import blocks", func() {
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformConfigSecretName()
+
+				found, err := kubernetes.GetIfExists(context.TODO(), cc, secret)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(string(secret.Data[terraformv1alpha1.TerraformImportsSecretKey])).To(Equal("import {\n  to = aws_s3_bucket.this\n  id = \"existing-bucket\"\n}\n"))
+			})
+
+			It("should have mounted the import blocks into the plan", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformPlan},
+				)).To(Succeed())
+				Expect(list.Items).To(HaveLen(1))
+
+				var items []string
+				for _, x := range list.Items[0].Spec.Template.Spec.Volumes {
+					if x.Name == "config" {
+						for _, item := range x.Secret.Items {
+							items = append(items, item.Key)
+						}
+					}
+				}
+				Expect(items).To(ContainElement(terraformv1alpha1.TerraformImportsSecretKey))
+			})
+		})
+
+		Context("and the apply has completed", func() {
+			BeforeEach(func() {
+				plan := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+
+				apply := fixtures.NewTerraformJob(configuration, "default", terraformv1alpha1.StageTerraformApply)
+				apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				apply.Status.Succeeded = 1
+
+				tfstate := fixtures.NewTerraformState(configuration)
+				tfstate.Namespace = "default"
+
+				Setup(configuration, plan, apply, tfstate)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have recorded the imports as applied", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Status.Imported).To(Equal(configuration.Spec.Imports))
+				Expect(configuration.GetPendingImports()).To(BeEmpty())
+			})
+
+			It("should no longer render the import blocks", func() {
+				// @note: the configuration secret is regenerated on the next reconcile
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+
+				secret := &v1.Secret{}
+				secret.Namespace = ctrl.ControllerNamespace
+				secret.Name = configuration.GetTerraformConfigSecretName()
+
+				found, err := kubernetes.GetIfExists(context.TODO(), cc, secret)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(secret.Data).ToNot(HaveKey(terraformv1alpha1.TerraformImportsSecretKey))
+			})
+		})
+	})
+
+	When("configuration has a replace operation", func() {
+		operation := utils.Sha256Sum([]byte("-replace=aws_s3_bucket.this"))[0:16]
+
+		BeforeEach(func() {
+			configuration = fixtures.NewValidBucketConfiguration(cfgNamespace, "bucket")
+			configuration.Annotations[terraformv1alpha1.ReplaceAnnotation] = "aws_s3_bucket.this"
+		})
+
+		Context("and the address is invalid", func() {
+			BeforeEach(func() {
+				configuration.Annotations[terraformv1alpha1.ReplaceAnnotation] = "not valid"
+
+				Setup(configuration)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should indicate the operation is invalid", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPlan)
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal(`Operation requested is invalid, replace address "not valid" is not a valid resource address`))
+			})
+
+			It("should not have created a plan", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace))).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+
+		Context("and the configuration has already been applied", func() {
+			BeforeEach(func() {
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+
+				apply := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformApply)
+				apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				apply.Status.Succeeded = 1
+
+				tfstate := fixtures.NewTerraformState(configuration)
+				tfstate.Namespace = ctrl.ControllerNamespace
+
+				Setup(configuration, plan, apply, tfstate)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have created a plan for the operation", func() {
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationOperationLabel: operation},
+				)).To(Succeed())
+				Expect(list.Items).To(HaveLen(1))
+				Expect(list.Items[0].Labels).To(HaveKeyWithValue(terraformv1alpha1.ConfigurationStageLabel, terraformv1alpha1.StageTerraformPlan))
+
+				args := list.Items[0].Spec.Template.Spec.Containers[0].Args
+				Expect(args).To(ContainElement("--command=/bin/terraform plan --var-file variables.tfvars.json '-replace=aws_s3_bucket.this' -out=/run/plan.out -lock=false"))
+			})
+
+			It("should have raised an event for the operation", func() {
+				Expect(recorder.Events).To(ContainElement(ContainSubstring("Terraform plan is running with the operation: -replace=aws_s3_bucket.this")))
+			})
+
+			It("should have kept the annotation until the operation is applied", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+				Expect(configuration.Annotations).To(HaveKeyWithValue(terraformv1alpha1.ReplaceAnnotation, "aws_s3_bucket.this"))
+			})
+		})
+
+		Context("and the terraform plan was produced for a different operation", func() {
+			BeforeEach(func() {
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Labels[terraformv1alpha1.ConfigurationOperationLabel] = operation
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = ctrl.ControllerNamespace
+
+				// @note: the operation was removed before the plan was applied
+				delete(configuration.Annotations, terraformv1alpha1.ReplaceAnnotation)
+				configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{
+					Checksum:  utils.Sha256Sum([]byte("fake-plan")),
+					Job:       plan.Name,
+					Operation: operation,
+				}
+
+				Setup(configuration, plan, tfplan)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should refuse to apply the plan", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				cond := configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply)
+				Expect(cond.Reason).To(Equal(corev1alpha1.ReasonActionRequired))
+				Expect(cond.Message).To(Equal("Terraform plan was produced for a different replace or target operation, refusing to apply"))
+			})
+		})
+
+		Context("and the operation has been applied", func() {
+			BeforeEach(func() {
+				plan := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformPlan)
+				plan.Labels[terraformv1alpha1.ConfigurationOperationLabel] = operation
+				plan.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				plan.Status.Succeeded = 1
+
+				apply := fixtures.NewTerraformJob(configuration, ctrl.ControllerNamespace, terraformv1alpha1.StageTerraformApply)
+				apply.Labels[terraformv1alpha1.ConfigurationOperationLabel] = operation
+				apply.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				apply.Status.Succeeded = 1
+
+				tfplan := fixtures.NewTerraformPlan(configuration)
+				tfplan.Namespace = ctrl.ControllerNamespace
+				tfstate := fixtures.NewTerraformState(configuration)
+				tfstate.Namespace = ctrl.ControllerNamespace
+
+				Setup(configuration, plan, apply, tfplan, tfstate)
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 3)
+			})
+
+			It("should have removed the operation annotation", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+				Expect(configuration.Annotations).ToNot(HaveKey(terraformv1alpha1.ReplaceAnnotation))
+			})
+
+			It("should have recorded the operation in the history", func() {
+				Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+
+				Expect(configuration.Status.History).To(HaveLen(2))
+				for _, run := range configuration.Status.History {
+					Expect(run.Reason).To(Equal(terraformv1alpha1.RunReasonOperation))
+					Expect(run.Arguments).To(Equal("-replace=aws_s3_bucket.this"))
+				}
+			})
+
+			It("should have raised an event for the applied operation", func() {
+				Expect(recorder.Events).To(ContainElement(ContainSubstring("Terraform has applied the operation: -replace=aws_s3_bucket.this")))
+			})
+
+			It("should not run the plan again", func() {
+				result, _, rerr = controllertests.Roll(context.TODO(), ctrl, configuration, 0)
+
+				list := &batchv1.JobList{}
+				Expect(cc.List(context.TODO(), list, client.InNamespace(ctrl.ControllerNamespace),
+					client.MatchingLabels{terraformv1alpha1.ConfigurationStageLabel: terraformv1alpha1.StageTerraformPlan},
+				)).To(Succeed())
+				Expect(list.Items).To(HaveLen(1))
+			})
+		})
+	})
+
 	// SECRET KEY MAPPINGS
 	When("we have secret key mappings on the configuration", func() {
 		BeforeEach(func() {
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/core/v1alpha1"
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/filters"
+	"github.com/appvia/terranetes-controller/pkg/utils/jobs"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// ensureStateCommand is responsible for running any terraform state command, i.e. mv, rm, show, pull or push,
+// requested against the configuration. No terraform is run while the state command is in progress; the job
+// holds the state lock and takes a snapshot of the state before changing it
+func (c *Controller) ensureStateCommand(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+	generation := fmt.Sprintf("%d", configuration.GetGeneration())
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		requested := configuration.GetAnnotations()[terraformv1alpha1.StateCommandAnnotation]
+		if requested == "" {
+			return reconcile.Result{}, nil
+		}
+
+		id := configuration.GetAnnotations()[terraformv1alpha1.StateCommandIDAnnotation]
+		if id == "" {
+			cond.ActionRequired("Terraform state command requires the %s annotation", terraformv1alpha1.StateCommandIDAnnotation)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+
+		command, err := terraformv1alpha1.ParseStateCommand(requested)
+		if err != nil {
+			cond.ActionRequired("Terraform state command %q is invalid, %s", requested, err)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+
+		// @step: find any current state command jobs
+		job, found := filters.Jobs(state.jobs).
+			WithGeneration(generation).
+			WithLabel(terraformv1alpha1.ConfigurationStateCommandLabel, id).
+			WithName(configuration.GetName()).
+			WithNamespace(configuration.GetNamespace()).
+			WithStage(terraformv1alpha1.StageTerraformState).
+			WithUID(string(configuration.GetUID())).
+			Latest()
+
+		if !found {
+			// @step: ensure the state being pushed has been provided
+			if command.Name == terraformv1alpha1.StateCommandPush {
+				secret := &v1.Secret{}
+				secret.Namespace = c.ControllerNamespace
+				secret.Name = configuration.GetTerraformStateCommandSecretName()
+
+				found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+				if err != nil {
+					cond.Failed(err, "Failed to retrieve the terraform state command secret")
+
+					return reconcile.Result{}, err
+				}
+				if !found || len(secret.Data[terraformv1alpha1.TerraformStatePushSecretKey]) == 0 {
+					cond.ActionRequired("Terraform state to push is missing from the secret (%s/%s)", secret.Namespace, secret.Name)
+
+					return reconcile.Result{}, controller.ErrIgnore
+				}
+			}
+
+			runner, err := jobs.New(configuration, state.provider).NewTerraformState(jobs.Options{
+				AdditionalJobAnnotations: state.provider.JobAnnotations(),
+				AdditionalJobSecrets:     state.additionalJobSecrets,
+				AdditionalJobLabels: utils.MergeStringMaps(
+					c.ControllerJobLabels,
+					state.provider.JobLabels(),
+					map[string]string{
+						terraformv1alpha1.ConfigurationStateCommandLabel: id,
+					}),
+				BackoffLimit:       c.BackoffLimit,
+				Engine:             state.engine,
+				Providers:          state.providers,
+				ExecutorImage:      c.ExecutorImage,
+				ExecutorSecrets:    c.ExecutorSecrets,
+				LogStore:           c.LogStore,
+				Namespace:          c.ControllerNamespace,
+				SaveTerraformState: state.backendType != terraformv1alpha1.BackendTypeKubernetes,
+				StateCommand:       command,
+				Template:           state.jobTemplate,
+				TerraformImage:     c.GetEngineImage(state.engine, state.terraformVersion),
+			})
+			if err != nil {
+				cond.Failed(err, "Failed to create the terraform state command job")
+
+				return reconcile.Result{}, err
+			}
+
+			if c.EnableWatchers {
+				if err := c.CreateWatcher(ctx, configuration, terraformv1alpha1.StageTerraformState); err != nil {
+					cond.Failed(err, "Failed to create the terraform state command watcher")
+
+					return reconcile.Result{}, err
+				}
+			}
+
+			if err := c.cc.Create(ctx, runner); err != nil {
+				cond.Failed(err, "Failed to create the terraform state command job")
+
+				return reconcile.Result{}, err
+			}
+			configuration.Status.StateCommand = &terraformv1alpha1.StateCommandStatus{
+				Command: command.String(),
+				ID:      id,
+				Job:     runner.GetName(),
+			}
+			cond.InProgress("Terraform state command %q is running", command.String())
+
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
+		configuration.Status.StateCommand = &terraformv1alpha1.StateCommandStatus{
+			Command: command.String(),
+			ID:      id,
+			Job:     job.GetName(),
+		}
+
+		switch {
+		case jobs.IsComplete(job):
+			configuration.Status.StateCommand.Result = terraformv1alpha1.RunResultSucceeded
+			c.recorder.Event(configuration, v1.EventTypeNormal, "StateCommand",
+				fmt.Sprintf("Terraform state command %q has completed", command.String()))
+
+		case jobs.IsFailed(job):
+			configuration.Status.StateCommand.Result = terraformv1alpha1.RunResultFailed
+			c.recorder.Event(configuration, v1.EventTypeWarning, "StateCommand",
+				fmt.Sprintf("Terraform state command %q has failed", command.String()))
+
+		default:
+			cond.InProgress("Terraform state command %q is running", command.String())
+
+			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+
+		// @step: the state command is one-shot, so we remove the request once it has finished
+		original := configuration.DeepCopy()
+		delete(configuration.Annotations, terraformv1alpha1.StateCommandAnnotation)
+		delete(configuration.Annotations, terraformv1alpha1.StateCommandIDAnnotation)
+
+		if err := c.cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+			cond.Failed(err, "Failed to remove the state command annotations from the configuration")
+
+			return reconcile.Result{}, err
+		}
+		// @note: the patch returns the persisted status, so we restore the status being reconciled
+		configuration.Status = original.Status
+
+		// @note: we requeue so the result is persisted before any later stage patches the configuration
+		return controller.RequeueImmediate, nil
+	}
+}
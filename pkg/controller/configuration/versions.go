@@ -0,0 +1,244 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/core/v1alpha1"
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/filters"
+	"github.com/appvia/terranetes-controller/pkg/utils/jobs"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
+)
+
+// ensureStateVersions is responsible for retaining a version of the terraform state every time the state
+// changes, pruning the oldest versions beyond the configured limit
+func (c *Controller) ensureStateVersions(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		if c.StateVersions <= 0 {
+			return reconcile.Result{}, nil
+		}
+
+		tfstate, err := terraform.DecodeState(state.tfstate.Data[terraformv1alpha1.TerraformStateSecretKey])
+		if err != nil {
+			cond.Failed(err, "Failed to decode the terraform state")
+
+			return reconcile.Result{}, err
+		}
+		serial := fmt.Sprintf("%d", tfstate.Serial)
+
+		versions, err := terraform.ListStateVersions(ctx, c.cc, c.ControllerNamespace, configuration)
+		if err != nil {
+			cond.Failed(err, "Failed to list the terraform state versions")
+
+			return reconcile.Result{}, err
+		}
+
+		// @step: we only retain a version when the state has changed since the latest version
+		var latest int
+		if len(versions) > 0 {
+			last := versions[len(versions)-1]
+			if last.GetLabels()[terraformv1alpha1.ConfigurationStateSerialLabel] == serial {
+				return reconcile.Result{}, nil
+			}
+			latest = terraform.GetStateVersion(&last)
+		}
+
+		secret := &v1.Secret{}
+		secret.Namespace = c.ControllerNamespace
+		secret.Name = configuration.GetTerraformStateVersionSecretName(latest + 1)
+		secret.Labels = map[string]string{
+			terraformv1alpha1.ConfigurationGenerationLabel:   fmt.Sprintf("%d", configuration.GetGeneration()),
+			terraformv1alpha1.ConfigurationNameLabel:         configuration.Name,
+			terraformv1alpha1.ConfigurationNamespaceLabel:    configuration.Namespace,
+			terraformv1alpha1.ConfigurationStateSerialLabel:  serial,
+			terraformv1alpha1.ConfigurationStateVersionLabel: fmt.Sprintf("%d", latest+1),
+			terraformv1alpha1.ConfigurationTimestampLabel:    fmt.Sprintf("%d", time.Now().Unix()),
+			terraformv1alpha1.ConfigurationUIDLabel:          string(configuration.GetUID()),
+		}
+		secret.Data = map[string][]byte{
+			terraformv1alpha1.TerraformStateSecretKey: state.tfstate.Data[terraformv1alpha1.TerraformStateSecretKey],
+		}
+
+		if err := c.cc.Create(ctx, secret); err != nil {
+			cond.Failed(err, "Failed to create the terraform state version")
+
+			return reconcile.Result{}, err
+		}
+		versions = append(versions, *secret)
+
+		// @step: prune the oldest versions beyond the limit
+		for i := 0; i < len(versions)-c.StateVersions; i++ {
+			if err := kubernetes.DeleteIfExists(ctx, c.cc, &versions[i]); err != nil {
+				cond.Failed(err, "Failed to delete the terraform state version (%s/%s)", versions[i].Namespace, versions[i].Name)
+
+				return reconcile.Result{}, err
+			}
+		}
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureStateRestore is responsible for restoring a previous version of the terraform state. A requested restore
+// places the configuration into a guarded mode, where no terraform is run until the restore has been approved
+// and completed
+func (c *Controller) ensureStateRestore(configuration *terraformv1alpha1.Configuration, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(configuration, corev1alpha1.ConditionReady, c.recorder)
+	generation := fmt.Sprintf("%d", configuration.GetGeneration())
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		requested := configuration.GetAnnotations()[terraformv1alpha1.RestoreStateAnnotation]
+		if requested == "" {
+			return reconcile.Result{}, nil
+		}
+
+		// @step: ensure the version being restored exists
+		version, err := strconv.Atoi(requested)
+		if err != nil || version <= 0 {
+			cond.ActionRequired("Terraform state version %q requested for restore is invalid", requested)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+
+		secret := &v1.Secret{}
+		secret.Namespace = c.ControllerNamespace
+		secret.Name = configuration.GetTerraformStateVersionSecretName(version)
+
+		found, err := kubernetes.GetIfExists(ctx, c.cc, secret)
+		if err != nil {
+			cond.Failed(err, "Failed to retrieve the terraform state version")
+
+			return reconcile.Result{}, err
+		}
+		if !found {
+			cond.ActionRequired("Terraform state version %d requested for restore does not exist, use `tnctl state history %s -n %s` to list the versions",
+				version, configuration.Name, configuration.Namespace)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+
+		// @step: the restore must be explicitly approved
+		approval := configuration.GetAnnotations()[terraformv1alpha1.RestoreApprovalAnnotation]
+		if approval == "" {
+			cond.ActionRequired("Restore of the terraform state to version %d (serial %s) is awaiting approval, use `tnctl state restore %s -n %s --approve`",
+				version, secret.GetLabels()[terraformv1alpha1.ConfigurationStateSerialLabel], configuration.Name, configuration.Namespace)
+
+			return reconcile.Result{}, controller.ErrIgnore
+		}
+
+		// @step: find any current restore jobs
+		job, found := filters.Jobs(state.jobs).
+			WithGeneration(generation).
+			WithLabel(terraformv1alpha1.ConfigurationStateVersionLabel, requested).
+			WithLabel(terraformv1alpha1.RestoreApprovalAnnotation, approval).
+			WithName(configuration.GetName()).
+			WithNamespace(configuration.GetNamespace()).
+			WithStage(terraformv1alpha1.StageTerraformRestore).
+			WithUID(string(configuration.GetUID())).
+			Latest()
+
+		if !found {
+			runner, err := jobs.New(configuration, state.provider).NewTerraformRestore(jobs.Options{
+				AdditionalJobAnnotations: state.provider.JobAnnotations(),
+				AdditionalJobSecrets:     state.additionalJobSecrets,
+				AdditionalJobLabels: utils.MergeStringMaps(
+					c.ControllerJobLabels,
+					state.provider.JobLabels(),
+					map[string]string{
+						terraformv1alpha1.ConfigurationStateVersionLabel: requested,
+						terraformv1alpha1.RestoreApprovalAnnotation:      approval,
+					}),
+				BackoffLimit:       c.BackoffLimit,
+				Engine:             state.engine,
+				Providers:          state.providers,
+				ExecutorImage:      c.ExecutorImage,
+				ExecutorSecrets:    c.ExecutorSecrets,
+				LogStore:           c.LogStore,
+				Namespace:          c.ControllerNamespace,
+				SaveTerraformState: state.backendType != terraformv1alpha1.BackendTypeKubernetes,
+				StateVersion:       version,
+				Template:           state.jobTemplate,
+				TerraformImage:     c.GetEngineImage(state.engine, state.terraformVersion),
+			})
+			if err != nil {
+				cond.Failed(err, "Failed to create the terraform state restore job")
+
+				return reconcile.Result{}, err
+			}
+
+			if c.EnableWatchers {
+				if err := c.CreateWatcher(ctx, configuration, terraformv1alpha1.StageTerraformRestore); err != nil {
+					cond.Failed(err, "Failed to create the terraform state restore watcher")
+
+					return reconcile.Result{}, err
+				}
+			}
+
+			if err := c.cc.Create(ctx, runner); err != nil {
+				cond.Failed(err, "Failed to create the terraform state restore job")
+
+				return reconcile.Result{}, err
+			}
+			cond.InProgress("Terraform state restore to version %d is running", version)
+
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
+		switch {
+		case jobs.IsComplete(job):
+			// @step: the restore is complete, we can leave the guarded mode
+			original := configuration.DeepCopy()
+			delete(configuration.Annotations, terraformv1alpha1.RestoreApprovalAnnotation)
+			delete(configuration.Annotations, terraformv1alpha1.RestoreStateAnnotation)
+
+			if err := c.cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+				cond.Failed(err, "Failed to remove the restore annotations from the configuration")
+
+				return reconcile.Result{}, err
+			}
+			c.recorder.Event(configuration, v1.EventTypeNormal, "StateRestored",
+				fmt.Sprintf("Terraform state has been restored to version %d", version))
+
+			return reconcile.Result{}, nil
+
+		case jobs.IsFailed(job):
+			cond.Failed(nil, "Terraform state restore to version %d has failed", version)
+
+			return reconcile.Result{}, controller.ErrIgnore
+
+		case jobs.IsActive(job):
+			cond.InProgress("Terraform state restore to version %d is running", version)
+		}
+
+		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+}
@@ -18,26 +18,47 @@
 package context
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/handlers/contexts"
+	"github.com/appvia/terranetes-controller/pkg/utils"
 )
 
 // Controller handles the reconciliation of the resource
 type Controller struct {
+	// cc is the kubernetes client to the cluster
+	cc client.Client
+	// recorder is a event recorder
+	recorder record.EventRecorder
 	// EnableWebhooks indicates if the webhooks should be enabled
 	EnableWebhooks bool
 }
 
+const controllerName = "context.terraform.appvia.io"
+
+// contextIndex is the field index holding the contexts referenced by a configuration or cloudresource
+const contextIndex = "spec.valueFrom.context"
+
 // Add is called to setup the manager for the controller
 func (c *Controller) Add(mgr manager.Manager) error {
 	log.Info("adding the contexts controller")
 
+	c.cc = mgr.GetClient()
+	c.recorder = mgr.GetEventRecorderFor(controllerName)
+
 	if c.EnableWebhooks {
 		mgr.GetWebhookServer().Register(
 			fmt.Sprintf("/validate/%s/contexts", terraformv1alpha1.GroupName),
@@ -45,5 +66,54 @@ func (c *Controller) Add(mgr manager.Manager) error {
 		)
 	}
 
-	return nil
+	// @step: index the configurations and cloudresources by the contexts they consume values from
+	for _, o := range []client.Object{&terraformv1alpha1.Configuration{}, &terraformv1alpha1.CloudResource{}} {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), o, contextIndex, indexContexts); err != nil {
+			return fmt.Errorf("failed to index the %T by context: %w", o, err)
+		}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&terraformv1alpha1.Context{}).
+		Named(controllerName).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 5}).
+		WithEventFilter(&predicate.GenerationChangedPredicate{}).
+		// @note: we requeue the contexts referenced by a consumer so the usage is kept up to date
+		Watches(&terraformv1alpha1.Configuration{}, handler.EnqueueRequestsFromMapFunc(enqueueContexts)).
+		Watches(&terraformv1alpha1.CloudResource{}, handler.EnqueueRequestsFromMapFunc(enqueueContexts)).
+		Complete(c)
+}
+
+// enqueueContexts returns a request for each of the contexts referenced by the resource
+func enqueueContexts(_ context.Context, o client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	for _, name := range indexContexts(o) {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: name}})
+	}
+
+	return requests
+}
+
+// indexContexts returns the names of the contexts referenced by a configuration or cloudresource
+func indexContexts(o client.Object) []string {
+	var valueFrom terraformv1alpha1.ValueFromList
+
+	switch resource := o.(type) {
+	case *terraformv1alpha1.Configuration:
+		valueFrom = resource.Spec.ValueFrom
+	case *terraformv1alpha1.CloudResource:
+		valueFrom = resource.Spec.ValueFrom
+	default:
+		return nil
+	}
+
+	var list []string
+	for _, x := range valueFrom {
+		if x.Context != nil && *x.Context != "" {
+			list = append(list, *x.Context)
+		}
+	}
+
+	return utils.Unique(list)
 }
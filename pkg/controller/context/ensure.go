@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2023  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package context
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/core/v1alpha1"
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// ensureConsumers is responsible for retrieving the configurations and cloudresources which
+// consume values from the context
+func (c *Controller) ensureConsumers(txt *terraformv1alpha1.Context, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(txt, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		configurations := &terraformv1alpha1.ConfigurationList{}
+		if err := c.cc.List(ctx, configurations, client.MatchingFields{contextIndex: txt.Name}); err != nil {
+			cond.Failed(err, "Failed to list the configurations consuming the context")
+
+			return reconcile.Result{}, err
+		}
+		state.configurations = configurations.Items
+
+		cloudresources := &terraformv1alpha1.CloudResourceList{}
+		if err := c.cc.List(ctx, cloudresources, client.MatchingFields{contextIndex: txt.Name}); err != nil {
+			cond.Failed(err, "Failed to list the cloudresources consuming the context")
+
+			return reconcile.Result{}, err
+		}
+		state.cloudresources = cloudresources.Items
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureUsageStatus is responsible for recording the consumers of each variable in the context,
+// and the variables which are not consumed by any resource
+func (c *Controller) ensureUsageStatus(txt *terraformv1alpha1.Context, state *state) controller.EnsureFunc {
+	return func(ctx context.Context) (reconcile.Result, error) {
+		consumers := make(map[string][]terraformv1alpha1.ContextConsumer)
+
+		add := func(kind string, o client.Object, valueFrom terraformv1alpha1.ValueFromList) {
+			if o.GetDeletionTimestamp() != nil {
+				return
+			}
+			for _, x := range valueFrom.GetContextReferences(txt.Name) {
+				consumers[x.Key] = append(consumers[x.Key], terraformv1alpha1.ContextConsumer{
+					Kind:      kind,
+					Name:      o.GetName(),
+					Namespace: o.GetNamespace(),
+					Optional:  x.Optional,
+				})
+			}
+		}
+		for i := range state.cloudresources {
+			add(terraformv1alpha1.CloudResourceKind, &state.cloudresources[i], state.cloudresources[i].Spec.ValueFrom)
+		}
+		for i := range state.configurations {
+			add(terraformv1alpha1.ConfigurationKind, &state.configurations[i], state.configurations[i].Spec.ValueFrom)
+		}
+
+		txt.Status.Unused = nil
+		txt.Status.Variables = nil
+
+		var names []string
+		for name := range consumers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			list := consumers[name]
+			sort.SliceStable(list, func(i, j int) bool {
+				if list[i].Kind != list[j].Kind {
+					return list[i].Kind < list[j].Kind
+				}
+				if list[i].Namespace != list[j].Namespace {
+					return list[i].Namespace < list[j].Namespace
+				}
+
+				return list[i].Name < list[j].Name
+			})
+			txt.Status.Variables = append(txt.Status.Variables, terraformv1alpha1.ContextVariableStatus{
+				Consumers: list,
+				Name:      name,
+			})
+		}
+		for name := range txt.Spec.Variables {
+			if _, found := consumers[name]; !found {
+				txt.Status.Unused = append(txt.Status.Unused, name)
+			}
+		}
+		sort.Strings(txt.Status.Unused)
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// ensureConfigurationsNotified is responsible for annotating the configurations whose consumed
+// context values have changed since their last plan, triggering a reconcile and a new plan
+func (c *Controller) ensureConfigurationsNotified(txt *terraformv1alpha1.Context, state *state) controller.EnsureFunc {
+	cond := controller.ConditionMgr(txt, corev1alpha1.ConditionReady, c.recorder)
+
+	return func(ctx context.Context) (reconcile.Result, error) {
+		for i := range state.configurations {
+			configuration := &state.configurations[i]
+
+			switch {
+			case configuration.DeletionTimestamp != nil:
+				continue
+			// @note: a configuration which has never been planned will pick up the values on its first plan
+			case configuration.Status.TerraformPlan == nil:
+				continue
+			}
+
+			checksum, err := c.contextChecksum(ctx, configuration, txt)
+			if err != nil {
+				cond.Failed(err, "Failed to compute the context values for configuration %s/%s",
+					configuration.Namespace, configuration.Name)
+
+				return reconcile.Result{}, err
+			}
+			if checksum == configuration.Status.TerraformPlan.GetContexts() {
+				continue
+			}
+
+			log.WithFields(log.Fields{
+				"context":   txt.Name,
+				"name":      configuration.Name,
+				"namespace": configuration.Namespace,
+			}).Info("context values have changed, triggering a reconcile of the configuration")
+
+			original := configuration.DeepCopy()
+			if configuration.Annotations == nil {
+				configuration.Annotations = map[string]string{}
+			}
+			configuration.Annotations[terraformv1alpha1.ContextAnnotation] = fmt.Sprintf("%d", time.Now().UnixNano())
+
+			if err := c.cc.Patch(ctx, configuration, client.MergeFrom(original)); err != nil {
+				cond.Failed(err, "Failed to notify configuration %s/%s of the context change",
+					configuration.Namespace, configuration.Name)
+
+				return reconcile.Result{}, err
+			}
+		}
+
+		return reconcile.Result{}, nil
+	}
+}
+
+// contextChecksum returns the checksum of the values the configuration consumes from contexts,
+// computed in the same manner as the configuration controller records on the terraform plan
+func (c *Controller) contextChecksum(ctx context.Context, configuration *terraformv1alpha1.Configuration, txt *terraformv1alpha1.Context) (string, error) {
+	values := make(map[string]interface{})
+
+	for _, x := range configuration.Spec.ValueFrom {
+		if x.Context == nil {
+			continue
+		}
+
+		source := txt
+		if *x.Context != txt.Name {
+			source = &terraformv1alpha1.Context{}
+			source.Name = *x.Context
+
+			found, err := kubernetes.GetIfExists(ctx, c.cc, source)
+			if err != nil {
+				return "", err
+			}
+			if !found {
+				continue
+			}
+		}
+		if !source.Spec.HasVariable(x.Key) {
+			continue
+		}
+
+		value, _, err := source.Spec.GetVariable(x.Key)
+		if err != nil {
+			return "", err
+		}
+		values[x.GetName()] = value
+	}
+
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.Sha256Sum(encoded)[0:16], nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2023  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package context
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/controller"
+)
+
+type state struct {
+	// cloudresources is a list of cloudresources consuming values from the context
+	cloudresources []terraformv1alpha1.CloudResource
+	// configurations is a list of configurations consuming values from the context
+	configurations []terraformv1alpha1.Configuration
+}
+
+// Reconcile is called to handle the reconciliation of the context resource
+func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	txt := &terraformv1alpha1.Context{}
+
+	// @step: retrieve the context resource
+	if err := c.cc.Get(ctx, request.NamespacedName, txt); err != nil {
+		if kerrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.WithError(err).Error("failed to retrieve the context resource")
+
+		return reconcile.Result{}, err
+	}
+	// @step: ensure the context has all the condition registered
+	controller.EnsureConditionsRegistered(terraformv1alpha1.DefaultInputsConditions, txt)
+
+	state := &state{}
+
+	return controller.DefaultEnsureHandler.Run(ctx, c.cc, txt, []controller.EnsureFunc{
+		c.ensureConsumers(txt, state),
+		c.ensureUsageStatus(txt, state),
+		c.ensureConfigurationsNotified(txt, state),
+	})
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright (C) 2023  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package context
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/core/v1alpha1"
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	controllertests "github.com/appvia/terranetes-controller/test"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestReconcile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Running Test Suite")
+}
+
+var _ = Describe("Context Controller", func() {
+	logrus.SetOutput(io.Discard)
+
+	var cc client.Client
+	var result reconcile.Result
+	var rerr error
+	var ctrl *Controller
+	var txt *terraformv1alpha1.Context
+	var configuration *terraformv1alpha1.Configuration
+
+	namespace := "apps"
+
+	// Setup is responsible for creating the controller with the given objects
+	Setup := func(objects ...runtime.Object) {
+		cc = fake.NewClientBuilder().
+			WithScheme(schema.GetScheme()).
+			WithStatusSubresource(&terraformv1alpha1.Context{}, &terraformv1alpha1.Configuration{}).
+			WithIndex(&terraformv1alpha1.Configuration{}, contextIndex, indexContexts).
+			WithIndex(&terraformv1alpha1.CloudResource{}, contextIndex, indexContexts).
+			WithRuntimeObjects(objects...).
+			Build()
+
+		ctrl = &Controller{cc: cc}
+	}
+
+	BeforeEach(func() {
+		txt = fixtures.NewTerranettesContext("default")
+
+		configuration = fixtures.NewValidBucketConfiguration(namespace, "bucket")
+		configuration.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+			{Context: ptr.To("default"), Key: "vpc_id"},
+		}
+	})
+
+	When("the context has no consumers", func() {
+		BeforeEach(func() {
+			Setup(txt)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, txt, 0)
+		})
+
+		It("should not error", func() {
+			Expect(rerr).ToNot(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+		})
+
+		It("should have the conditions", func() {
+			Expect(cc.Get(context.TODO(), txt.GetNamespacedName(), txt)).To(Succeed())
+
+			cond := txt.Status.GetCondition(corev1alpha1.ConditionReady)
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(corev1alpha1.ReasonReady))
+		})
+
+		It("should report all the variables as unused", func() {
+			Expect(cc.Get(context.TODO(), txt.GetNamespacedName(), txt)).To(Succeed())
+			Expect(txt.Status.Unused).To(Equal([]string{"public_subnets", "vpc_id"}))
+			Expect(txt.Status.Variables).To(BeEmpty())
+		})
+	})
+
+	When("the context has consumers", func() {
+		BeforeEach(func() {
+			configuration.Spec.ValueFrom = append(configuration.Spec.ValueFrom, terraformv1alpha1.ValueFromSource{
+				Context: ptr.To("default"), Key: "missing", Optional: true,
+			})
+			other := fixtures.NewValidBucketConfiguration(namespace, "other")
+			other.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+				{Context: ptr.To("other"), Key: "public_subnets"},
+			}
+			cloudresource := fixtures.NewCloudResource(namespace, "database")
+			cloudresource.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+				{Context: ptr.To("default"), Key: "vpc_id"},
+			}
+
+			Setup(txt, configuration, other, cloudresource)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, txt, 0)
+		})
+
+		It("should not error", func() {
+			Expect(rerr).ToNot(HaveOccurred())
+		})
+
+		It("should record the consumers of each variable", func() {
+			Expect(cc.Get(context.TODO(), txt.GetNamespacedName(), txt)).To(Succeed())
+			Expect(txt.Status.Variables).To(Equal([]terraformv1alpha1.ContextVariableStatus{
+				{
+					Name: "missing",
+					Consumers: []terraformv1alpha1.ContextConsumer{
+						{Kind: terraformv1alpha1.ConfigurationKind, Name: "bucket", Namespace: namespace, Optional: true},
+					},
+				},
+				{
+					Name: "vpc_id",
+					Consumers: []terraformv1alpha1.ContextConsumer{
+						{Kind: terraformv1alpha1.CloudResourceKind, Name: "database", Namespace: namespace},
+						{Kind: terraformv1alpha1.ConfigurationKind, Name: "bucket", Namespace: namespace},
+					},
+				},
+			}))
+		})
+
+		It("should report the unused variables", func() {
+			Expect(cc.Get(context.TODO(), txt.GetNamespacedName(), txt)).To(Succeed())
+			Expect(txt.Status.Unused).To(Equal([]string{"public_subnets"}))
+		})
+	})
+
+	When("a consumer has not been planned", func() {
+		BeforeEach(func() {
+			Setup(txt, configuration)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, txt, 0)
+		})
+
+		It("should not error", func() {
+			Expect(rerr).ToNot(HaveOccurred())
+		})
+
+		It("should not have annotated the configuration", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.ContextAnnotation))
+		})
+	})
+
+	When("a consumer was planned with the current context values", func() {
+		BeforeEach(func() {
+			Setup(txt)
+			checksum, err := ctrl.contextChecksum(context.TODO(), configuration, txt)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checksum).To(HaveLen(16))
+
+			configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{Contexts: checksum}
+			Setup(txt, configuration)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, txt, 0)
+		})
+
+		It("should not error", func() {
+			Expect(rerr).ToNot(HaveOccurred())
+		})
+
+		It("should not have annotated the configuration", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.ContextAnnotation))
+		})
+	})
+
+	When("a consumer was planned with different context values", func() {
+		var other *terraformv1alpha1.Configuration
+
+		BeforeEach(func() {
+			configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{Contexts: "stale"}
+
+			other = fixtures.NewValidBucketConfiguration(namespace, "other")
+			other.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{Contexts: "stale"}
+
+			Setup(txt, configuration, other)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, txt, 0)
+		})
+
+		It("should not error", func() {
+			Expect(rerr).ToNot(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+		})
+
+		It("should have annotated the configuration", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).To(HaveKey(terraformv1alpha1.ContextAnnotation))
+		})
+
+		It("should not have annotated configurations not consuming the context", func() {
+			Expect(cc.Get(context.TODO(), other.GetNamespacedName(), other)).To(Succeed())
+			Expect(other.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.ContextAnnotation))
+		})
+	})
+
+	When("a consumer only references unchanged keys", func() {
+		BeforeEach(func() {
+			Setup(txt)
+			checksum, err := ctrl.contextChecksum(context.TODO(), configuration, txt)
+			Expect(err).ToNot(HaveOccurred())
+			configuration.Status.TerraformPlan = &terraformv1alpha1.TerraformPlanStatus{Contexts: checksum}
+
+			txt.Spec.Variables["public_subnets"] = runtime.RawExtension{
+				Raw: []byte(`{"description": "netwrk", "value": ["subnet-654321"]}`),
+			}
+			Setup(txt, configuration)
+			result, _, rerr = controllertests.Roll(context.TODO(), ctrl, txt, 0)
+		})
+
+		It("should not have annotated the configuration", func() {
+			Expect(cc.Get(context.TODO(), configuration.GetNamespacedName(), configuration)).To(Succeed())
+			Expect(configuration.GetAnnotations()).ToNot(HaveKey(terraformv1alpha1.ContextAnnotation))
+		})
+	})
+})
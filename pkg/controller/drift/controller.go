@@ -18,29 +18,41 @@
 package drift
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"time"
 
+	cache "github.com/patrickmn/go-cache"
 	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	ksutils "github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
 )
 
 const controllerName = "drift.terraform.appvia.io"
 
+// maintenanceIndex is the field index marking the policies which define maintenance windows
+const maintenanceIndex = "spec.constraints.maintenance"
+
 // Controller handles the reconciliation of the configuration resource
 type Controller struct {
 	// cc is the kubernetes client to the cluster
 	cc client.Client
+	// cache is a local cache of the namespaces
+	cache *cache.Cache
 	// recorder is the kubernetes event recorder
 	recorder record.EventRecorder
 	// CheckInterval is the interval the controller checks to trigger drift on the configurations
@@ -69,13 +81,16 @@ func (c *Controller) Add(mgr manager.Manager) error {
 	}
 
 	c.cc = mgr.GetClient()
+	c.cache = cache.New(12*time.Hour, 10*time.Minute)
 	c.recorder = mgr.GetEventRecorderFor(controllerName)
 
+	// @step: index the policies defining maintenance windows, so the others are never retrieved
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &terraformv1alpha1.Policy{}, maintenanceIndex, indexMaintenance); err != nil {
+		return fmt.Errorf("failed to index the policies by maintenance windows: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&terraformv1alpha1.Configuration{}).
-		Named(controllerName).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
-		WithEventFilter(&predicate.Funcs{
+		For(&terraformv1alpha1.Configuration{}, builder.WithPredicates(&predicate.Funcs{
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				switch {
 				case !e.ObjectNew.(*terraformv1alpha1.Configuration).Spec.EnableDriftDetection:
@@ -94,6 +109,53 @@ func (c *Controller) Add(mgr manager.Manager) error {
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				return false
 			},
-		}).
+		})).
+		Named(controllerName).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Watches(
+			// We use this to keep a local cache of all namespaces in the cluster
+			&v1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+				switch {
+				case !o.GetDeletionTimestamp().IsZero():
+					c.cache.Delete(o.GetName())
+				default:
+					c.cache.SetDefault(o.GetName(), o)
+				}
+
+				return nil
+			}),
+		).
 		Complete(c)
 }
+
+// indexMaintenance returns a value when the policy defines maintenance windows
+func indexMaintenance(o client.Object) []string {
+	policy, ok := o.(*terraformv1alpha1.Policy)
+	switch {
+	case !ok, policy.Spec.Constraints == nil, policy.Spec.Constraints.Maintenance == nil:
+		return nil
+	}
+
+	return []string{"true"}
+}
+
+// findNamespace returns the namespace from the cache, falling back to the api
+func (c *Controller) findNamespace(ctx context.Context, name string) (client.Object, error) {
+	// @step: check the cache for the result
+	entry, found := c.cache.Get(name)
+	if found {
+		return entry.(client.Object), nil
+	}
+
+	namespace := &v1.Namespace{}
+	namespace.Name = name
+
+	if found, err := ksutils.GetIfExists(ctx, c.cc, namespace); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("namespace: %q was not found in the cache or api", name)
+	}
+
+	return namespace, nil
+}
@@ -29,6 +29,7 @@ import (
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/controller"
+	"github.com/appvia/terranetes-controller/pkg/utils/policies"
 )
 
 // ensureConfigurationReadyForDrift is responsible for checking the configuration is ready for drift
@@ -64,6 +65,8 @@ func (c *Controller) ensureConfigurationReadyForDrift(configuration *terraformv1
 		// totalAllowed is the max configurations permitted to run at any one
 		// time, based on the threshold
 		totalAllowed := int(math.Ceil(float64(len(list.Items)) * c.DriftThreshold))
+		// interval is the drift interval for the configuration, which can be overridden by the drift policy
+		interval := configuration.GetDriftInterval(c.DriftInterval)
 
 		switch {
 		// can't really happen due the predicate - but better safe than sorry; if not enabled or deleting, we ignore
@@ -106,11 +109,11 @@ func (c *Controller) ensureConfigurationReadyForDrift(configuration *terraformv1
 			return reconcile.Result{RequeueAfter: c.CheckInterval}, nil
 
 		// if the last transition on a plan was less than the interval, i.e we've had activity, we ignore
-		case configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPlan).LastTransitionTime.Add(c.DriftInterval).After(time.Now()):
+		case configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformPlan).LastTransitionTime.Add(interval).After(time.Now()):
 			return reconcile.Result{RequeueAfter: c.CheckInterval}, nil
 
 		// if the last transition on a apply was less than the interval, i.e we've had activity, we ignore
-		case configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply).LastTransitionTime.Add(c.DriftInterval).After(time.Now()):
+		case configuration.Status.GetCondition(terraformv1alpha1.ConditionTerraformApply).LastTransitionTime.Add(interval).After(time.Now()):
 			return reconcile.Result{RequeueAfter: c.CheckInterval}, nil
 
 		// if the number of active configuration running a drift exceeds the max percentage, we ignore
@@ -122,6 +125,54 @@ func (c *Controller) ensureConfigurationReadyForDrift(configuration *terraformv1
 	}
 }
 
+// ensureWithinMaintenanceWindow is responsible for deferring drift detection when the configuration is
+// outside of the maintenance windows defined by the policies
+func (c *Controller) ensureWithinMaintenanceWindow(configuration *terraformv1alpha1.Configuration) controller.EnsureFunc {
+	return func(ctx context.Context) (reconcile.Result, error) {
+		// @step: retrieve only the policies which define maintenance windows
+		list := &terraformv1alpha1.PolicyList{}
+		if err := c.cc.List(ctx, list, client.MatchingFields{maintenanceIndex: "true"}); err != nil {
+			log.WithError(err).Error("failed to retrieve a list of maintenance policies in cluster")
+
+			return reconcile.Result{}, err
+		}
+		if len(list.Items) == 0 {
+			return reconcile.Result{}, nil
+		}
+
+		namespace, err := c.findNamespace(ctx, configuration.Namespace)
+		if err != nil {
+			log.WithError(err).Error("failed to retrieve the configuration namespace")
+
+			return reconcile.Result{}, err
+		}
+
+		windows, err := policies.FindMaintenanceWindows(configuration, namespace, list)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		now := time.Now()
+
+		open, next, err := policies.IsMaintenanceWindowOpen(windows, now)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if open {
+			return reconcile.Result{}, nil
+		}
+
+		if next.IsZero() {
+			c.recorder.Event(configuration, "Normal", "DriftDetection", "Drift detection deferred, outside of the maintenance windows and no window is scheduled to open")
+
+			return reconcile.Result{RequeueAfter: c.CheckInterval}, nil
+		}
+		c.recorder.Eventf(configuration, "Normal", "DriftDetection", "Drift detection deferred, outside of the maintenance windows, next window opens at %s", next.UTC().Format(time.RFC3339))
+
+		return reconcile.Result{RequeueAfter: next.Sub(now) + time.Second}, nil
+	}
+}
+
 // ensureDriftDetection is responsible for triggering off drift detection on the configuration
 func (c *Controller) ensureDriftDetection(configuration *terraformv1alpha1.Configuration) controller.EnsureFunc {
 	return func(ctx context.Context) (reconcile.Result, error) {
@@ -44,6 +44,7 @@ func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (
 	result, err := controller.DefaultEnsureHandler.Run(ctx, c.cc, configuration,
 		[]controller.EnsureFunc{
 			c.ensureConfigurationReadyForDrift(configuration),
+			c.ensureWithinMaintenanceWindow(configuration),
 			c.ensureDriftDetection(configuration),
 			controller.RequeueAfter(c.CheckInterval),
 		})
@@ -26,6 +26,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	cache "github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -54,6 +55,7 @@ var _ = Describe("Drift Controller", func() {
 			Name        string
 			Before      func(ctrl *Controller)
 			Check       func(configuration *terraformv1alpha1.Configuration)
+			Deferred    bool
 			ShouldDrift bool
 		}{
 			{
@@ -255,6 +257,61 @@ var _ = Describe("Drift Controller", func() {
 				},
 				ShouldDrift: false,
 			},
+			{
+				Name: "configuration is outside of the maintenance window",
+				Before: func(ctrl *Controller) {
+					ctrl.cc.Create(ctx, fixtures.NewNamespace(namespace))
+					ctrl.cc.Create(ctx, fixtures.NewMaintenancePolicy("maintenance", "0 0 1 1 *", time.Minute))
+				},
+				Deferred:    true,
+				ShouldDrift: false,
+			},
+			{
+				Name: "configuration is outside of the maintenance window and the namespace is cached",
+				Before: func(ctrl *Controller) {
+					ctrl.cache.SetDefault(namespace, fixtures.NewNamespace(namespace))
+					ctrl.cc.Create(ctx, fixtures.NewMaintenancePolicy("maintenance", "0 0 1 1 *", time.Minute))
+				},
+				Deferred:    true,
+				ShouldDrift: false,
+			},
+			{
+				Name: "no policy defines maintenance windows",
+				Before: func(ctrl *Controller) {
+					// @note: the namespace does not exist, so would fail if the policies were evaluated
+					ctrl.cc.Create(ctx, fixtures.NewVersionsPolicy("versions", ">= 1.0.0"))
+				},
+				ShouldDrift: true,
+			},
+			{
+				Name: "configuration is inside of the maintenance window",
+				Before: func(ctrl *Controller) {
+					ctrl.cc.Create(ctx, fixtures.NewNamespace(namespace))
+					ctrl.cc.Create(ctx, fixtures.NewMaintenancePolicy("maintenance", "* * * * *", time.Hour))
+				},
+				ShouldDrift: true,
+			},
+			{
+				Name: "drift policy interval has not elapsed",
+				Check: func(configuration *terraformv1alpha1.Configuration) {
+					configuration.Spec.DriftPolicy = &terraformv1alpha1.DriftPolicy{
+						Interval: &metav1.Duration{Duration: 10 * time.Hour},
+					}
+				},
+				ShouldDrift: false,
+			},
+			{
+				Name: "drift policy interval has elapsed before the controller interval",
+				Before: func(ctrl *Controller) {
+					ctrl.DriftInterval = 10 * time.Hour
+				},
+				Check: func(configuration *terraformv1alpha1.Configuration) {
+					configuration.Spec.DriftPolicy = &terraformv1alpha1.DriftPolicy{
+						Interval: &metav1.Duration{Duration: time.Hour},
+					}
+				},
+				ShouldDrift: true,
+			},
 			{
 				Name:        "configuration should trigger a drift detection",
 				ShouldDrift: true,
@@ -268,9 +325,11 @@ var _ = Describe("Drift Controller", func() {
 					CheckInterval:  5 * time.Minute,
 					DriftInterval:  2 * time.Hour,
 					DriftThreshold: 0.2,
+					cache:          cache.New(5*time.Minute, 10*time.Minute),
 					cc: fake.NewClientBuilder().
 						WithScheme(schema.GetScheme()).
 						WithStatusSubresource(&terraformv1alpha1.Configuration{}).
+						WithIndex(&terraformv1alpha1.Policy{}, maintenanceIndex, indexMaintenance).
 						Build(),
 					recorder: events,
 				}
@@ -306,13 +365,26 @@ var _ = Describe("Drift Controller", func() {
 				}
 				Expect(ctrl.cc.Create(ctx, configuration)).To(Succeed())
 
+				deferred := c.Deferred
+
 				It("should not return an error", func() {
 					result, _, rerr := controllertests.Roll(ctx, ctrl, configuration, 1)
 
 					Expect(rerr).To(BeNil())
-					Expect(result.RequeueAfter).To(Equal(ctrl.CheckInterval))
+					if deferred {
+						Expect(result.RequeueAfter).To(BeNumerically(">", ctrl.CheckInterval))
+					} else {
+						Expect(result.RequeueAfter).To(Equal(ctrl.CheckInterval))
+					}
 				})
 
+				if deferred {
+					It("should have raised a event indicating the deferral", func() {
+						Expect(events.Events).ToNot(BeEmpty())
+						Expect(events.Events[0]).To(HavePrefix("(default/test) Normal DriftDetection: Drift detection deferred, outside of the maintenance windows, next window opens at"))
+					})
+				}
+
 				switch c.ShouldDrift {
 				case true:
 					It("should have a drift detection annotation", func() {
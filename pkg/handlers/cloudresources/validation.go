@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -72,7 +73,34 @@ func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.O
 
 // ValidateDelete is called when a resource is being deleted
 func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	return admission.Warnings{}, nil
+	var warnings admission.Warnings
+	current := obj.(*terraformv1alpha1.CloudResource)
+
+	if current.GetAnnotations()[terraformv1alpha1.OrphanAnnotation] == "true" {
+		return warnings, nil
+	}
+	if current.Status.ConfigurationName == "" {
+		return warnings, nil
+	}
+
+	// @choice: unless the orphan annotation is present we should not be allowed to delete
+	// a cloud resource while other configurations depend on its configuration
+	list := &terraformv1alpha1.ConfigurationList{}
+	if err := v.cc.List(ctx, list, client.InNamespace(current.Namespace)); err != nil {
+		return warnings, err
+	}
+
+	var inuse []string
+	for _, x := range list.Items {
+		if x.Spec.DependsOn.Has(current.Status.ConfigurationName) && x.GetDeletionTimestamp().IsZero() {
+			inuse = append(inuse, x.GetNamespacedName().String())
+		}
+	}
+	if len(inuse) > 0 {
+		return warnings, fmt.Errorf("resource in use by configuration(s): %v", strings.Join(inuse, ", "))
+	}
+
+	return warnings, nil
 }
 
 // validate is responsible for validating the configuration plan
@@ -101,6 +129,17 @@ func validate(ctx context.Context, cc client.Client, o *terraformv1alpha1.CloudR
 			return err
 		}
 	}
+	if err := o.Spec.DependsOn.IsValid(); err != nil {
+		return err
+	}
+	if o.Spec.DriftPolicy != nil {
+		if !o.Spec.EnableDriftDetection {
+			return errors.New("spec.driftPolicy requires spec.enableDriftDetection to be enabled")
+		}
+		if err := o.Spec.DriftPolicy.IsValid(); err != nil {
+			return err
+		}
+	}
 
 	// @step: lets check the inputs are valid
 	plan := &terraformv1alpha1.Plan{}
@@ -175,6 +214,16 @@ func validate(ctx context.Context, cc client.Client, o *terraformv1alpha1.CloudR
 		}
 	}
 
+	// @step: and the same for any outputs mapped from the dependencies
+	for i, x := range o.Spec.DependsOn {
+		for j, output := range x.Outputs {
+			if !utils.Contains(output.GetName(), permitted) {
+				return fmt.Errorf("spec.dependsOn[%d].outputs[%d].%s input is not permitted by revision: %s",
+					i, j, output.GetName(), o.Spec.Plan.Revision)
+			}
+		}
+	}
+
 	// @step: now we need to ensure any variables defined in the revision are present
 	for _, input := range rv.Spec.Inputs {
 		if ptr.Deref(input.Required, false) && input.Default == nil {
@@ -190,6 +239,13 @@ func validate(ctx context.Context, cc client.Client, o *terraformv1alpha1.CloudR
 					found = true
 				}
 			}
+			for _, x := range o.Spec.DependsOn {
+				for _, output := range x.Outputs {
+					if output.GetName() == input.Key {
+						found = true
+					}
+				}
+			}
 
 			if !found {
 				return fmt.Errorf("spec.variables.%s is required variable for revision: %s", input.Key, o.Spec.Plan.Revision)
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,19 +29,28 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils"
 	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
 	"github.com/appvia/terranetes-controller/pkg/utils/policies"
+	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
 )
 
+// engineVersions are the version constraints placed on the terraform version of each engine
+var engineVersions = map[string]string{
+	terraformv1alpha1.EngineOpenTofu: ">= 1.6.0",
+}
+
 type validator struct {
 	cc client.Client
 	// enableVersions indicates the terraform version can be changed
 	enableVersions bool
+	// engine is the default engine used to run configurations
+	engine string
 }
 
 // NewValidator is validation handler
-func NewValidator(cc client.Client, versioning bool) admission.CustomValidator {
-	return &validator{cc: cc, enableVersions: versioning}
+func NewValidator(cc client.Client, versioning bool, engine string) admission.CustomValidator {
+	return &validator{cc: cc, enableVersions: versioning, engine: engine}
 }
 
 // ValidateCreate is called when a new resource is created
@@ -69,7 +79,24 @@ func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.O
 
 // ValidateDelete is called when a resource is being deleted
 func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	return admission.Warnings{}, nil
+	var warnings admission.Warnings
+	current := obj.(*terraformv1alpha1.Configuration)
+
+	if current.GetAnnotations()[terraformv1alpha1.OrphanAnnotation] == "true" {
+		return warnings, nil
+	}
+
+	// @choice: unless the orphan annotation is present we should not be allowed to delete
+	// a configuration while other configurations depend on it
+	inuse, err := findDependents(ctx, v.cc, current.Namespace, current.Name)
+	if err != nil {
+		return warnings, err
+	}
+	if len(inuse) > 0 {
+		return warnings, fmt.Errorf("resource in use by configuration(s): %v", strings.Join(inuse, ", "))
+	}
+
+	return warnings, nil
 }
 
 // validate is called to ensure the configuration is valid and incline with current policies
@@ -97,6 +124,9 @@ func (v *validator) validate(ctx context.Context, before, configuration *terrafo
 	if err := configuration.Spec.ProviderRef.IsValid(); err != nil {
 		return err
 	}
+	if err := configuration.Spec.IsValidProviders(); err != nil {
+		return err
+	}
 
 	// @step: perform some checks which are dependent on if the resource is being created or updated
 	switch creating {
@@ -118,6 +148,11 @@ func (v *validator) validate(ctx context.Context, before, configuration *terrafo
 		}
 	}
 
+	// @step: check the engine and the version are supported
+	if err := v.validateEngine(ctx, configuration); err != nil {
+		return err
+	}
+
 	// @step: check the configuration secret
 	if configuration.Spec.WriteConnectionSecretToRef != nil {
 		if err := configuration.Spec.WriteConnectionSecretToRef.IsValid(); err != nil {
@@ -128,6 +163,41 @@ func (v *validator) validate(ctx context.Context, before, configuration *terrafo
 	if err := configuration.Spec.ValueFrom.IsValid(); err != nil {
 		return err
 	}
+	// @step: check the drift policy is valid
+	if configuration.Spec.DriftPolicy != nil {
+		if !configuration.Spec.EnableDriftDetection {
+			return errors.New("spec.driftPolicy requires spec.enableDriftDetection to be enabled")
+		}
+		if err := configuration.Spec.DriftPolicy.IsValid(); err != nil {
+			return err
+		}
+	}
+	// @step: check the imports are valid
+	if err := configuration.Spec.Imports.IsValid(); err != nil {
+		return err
+	}
+	// @step: check any replace or target operations are valid
+	if _, err := configuration.GetOperationArguments(); err != nil {
+		return err
+	}
+	// @step: check any requested state command is valid
+	if value, found := configuration.GetAnnotations()[terraformv1alpha1.StateCommandAnnotation]; found {
+		if _, err := terraformv1alpha1.ParseStateCommand(value); err != nil {
+			return err
+		}
+	}
+	// @step: check the dependencies are valid and do not form a cycle
+	if len(configuration.Spec.DependsOn) > 0 {
+		if err := configuration.Spec.DependsOn.IsValid(); err != nil {
+			return err
+		}
+		if configuration.Spec.DependsOn.Has(configuration.Name) {
+			return errors.New("spec.dependsOn cannot reference the configuration itself")
+		}
+		if err := validateDependencyCycle(ctx, v.cc, configuration); err != nil {
+			return err
+		}
+	}
 
 	// @step: grab the namespace of the configuration
 	namespace := &v1.Namespace{}
@@ -155,34 +225,239 @@ func (v *validator) validate(ctx context.Context, before, configuration *terrafo
 			return err
 		}
 	}
+	// @step: validate the terraform version is permitted by the version constraints
+	if len(list.Items) > 0 && configuration.Spec.TerraformVersion != "" {
+		if err := validateVersionConstraints(configuration, list, namespace); err != nil {
+			return err
+		}
+	}
+	// @step: validate the user is permitted to request any replace or target operations
+	if len(list.Items) > 0 && hasOperationChanged(before, configuration) {
+		if err := validateOperations(ctx, configuration, list, namespace); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// validateProvider is called to ensure the configuration is valid and inline with current provider policy
-func validateProvider(ctx context.Context, cc client.Client, configuration *terraformv1alpha1.Configuration, namespace *v1.Namespace) error {
+// hasOperationChanged returns true if a replace or target operation has been added or changed
+func hasOperationChanged(before, configuration *terraformv1alpha1.Configuration) bool {
+	for _, key := range []string{terraformv1alpha1.ReplaceAnnotation, terraformv1alpha1.TargetAnnotation} {
+		value := configuration.GetAnnotations()[key]
+		switch {
+		case value == "":
+			continue
+		case before == nil, before.GetAnnotations()[key] != value:
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateOperations is called to ensure the user requesting the operation is permitted by the
+// operations constraints selecting the configuration
+func validateOperations(
+	ctx context.Context,
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList,
+	namespace *v1.Namespace) error {
+
+	constraints, err := policies.FindOperationsConstraints(configuration, namespace, list)
+	if err != nil {
+		return err
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	request, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return errors.New("unable to determine the user requesting the operation")
+	}
+	for _, constraint := range constraints {
+		if constraint.IsPermitted(request.UserInfo.Username, request.UserInfo.Groups) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("user %q is not permitted to request replace or target operations on this configuration by policy",
+		request.UserInfo.Username)
+}
+
+// validateDependencyCycle is called to ensure the dependencies of the configuration do not
+// lead back to the configuration itself
+func validateDependencyCycle(ctx context.Context, cc client.Client, configuration *terraformv1alpha1.Configuration) error {
+	list := &terraformv1alpha1.ConfigurationList{}
+	if err := cc.List(ctx, list, client.InNamespace(configuration.Namespace)); err != nil {
+		return err
+	}
+
+	graph := map[string]terraformv1alpha1.DependencyList{}
+	for _, x := range list.Items {
+		graph[x.Name] = x.Spec.DependsOn
+	}
+	graph[configuration.Name] = configuration.Spec.DependsOn
+
+	visited := map[string]bool{}
+	var visit func(name string, path []string) error
+
+	visit = func(name string, path []string) error {
+		for _, x := range graph[name] {
+			if x.Name == configuration.Name {
+				return fmt.Errorf("spec.dependsOn creates a dependency cycle: %s", strings.Join(append(path, x.Name), " -> "))
+			}
+			if visited[x.Name] {
+				continue
+			}
+			visited[x.Name] = true
+
+			if err := visit(x.Name, append(path, x.Name)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return visit(configuration.Name, []string{configuration.Name})
+}
+
+// findDependents returns the names of any configurations in the namespace which depend on the
+// named configuration and are not already being deleted
+func findDependents(ctx context.Context, cc client.Client, namespace, name string) ([]string, error) {
+	list := &terraformv1alpha1.ConfigurationList{}
+	if err := cc.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	var inuse []string
+	for _, x := range list.Items {
+		if x.Spec.DependsOn.Has(name) && x.GetDeletionTimestamp().IsZero() {
+			inuse = append(inuse, x.GetNamespacedName().String())
+		}
+	}
+
+	return inuse, nil
+}
+
+// validateEngine is called to ensure the engine is supported and the terraform version is
+// permitted by the engine
+func (v *validator) validateEngine(ctx context.Context, configuration *terraformv1alpha1.Configuration) error {
+	if configuration.Spec.Engine != "" && !terraformv1alpha1.IsEngineSupported(configuration.Spec.Engine) {
+		return fmt.Errorf("spec.engine %q is not supported", configuration.Spec.Engine)
+	}
+
+	// @note: version constraints are resolved by the controller against the allowed versions
+	version := configuration.Spec.TerraformVersion
+	if version == "" || version == "latest" || terraform.IsVersionConstraint(version) {
+		return nil
+	}
+
+	// @step: resolve the engine, the provider may override the default engine
 	provider := &terraformv1alpha1.Provider{}
 	provider.Name = configuration.Spec.ProviderRef.Name
 
-	found, err := kubernetes.GetIfExists(ctx, cc, provider)
+	found, err := kubernetes.GetIfExists(ctx, v.cc, provider)
 	if err != nil {
 		return err
 	}
-	if !found || provider.Spec.Selector == nil {
+	if !found {
+		provider = nil
+	}
+
+	engine := configuration.GetEngine(provider, v.engine)
+	constraint, found := engineVersions[engine]
+	if !found {
 		return nil
 	}
+	matched, err := utils.IsVersionConstraintMatch(constraint, version)
+	if err != nil {
+		return fmt.Errorf("spec.terraformVersion %q is not a valid version for the %s engine", version, engine)
+	}
+	if !matched {
+		return fmt.Errorf("spec.terraformVersion %q is not supported by the %s engine, must be %s", version, engine, constraint)
+	}
 
-	matched, err := kubernetes.IsSelectorMatch(*provider.Spec.Selector, configuration.GetLabels(), namespace.GetLabels())
+	return nil
+}
+
+// validateVersionConstraints is called to ensure the terraform version is permitted by the version
+// constraints selecting the configuration
+func validateVersionConstraints(
+	configuration *terraformv1alpha1.Configuration,
+	list *terraformv1alpha1.PolicyList,
+	namespace *v1.Namespace) error {
+
+	constraints, err := policies.FindVersionConstraints(configuration, namespace, list)
 	if err != nil {
 		return err
 	}
-	if !matched {
-		return errors.New("configuration has been denied by the provider policy")
+	// @note: constraints are resolved by the controller, which applies the policy constraints
+	if len(constraints) == 0 || terraform.IsVersionConstraint(configuration.Spec.TerraformVersion) {
+		return nil
+	}
+
+	if _, err := terraform.ResolveVersion(configuration.Spec.TerraformVersion, nil, constraints); err != nil {
+		return fmt.Errorf("spec.terraformVersion: %w", err)
 	}
 
 	return nil
 }
 
+// validateProvider is called to ensure the configuration is valid and inline with current provider policy
+func validateProvider(ctx context.Context, cc client.Client, configuration *terraformv1alpha1.Configuration, namespace *v1.Namespace) error {
+	provider, err := findPermittedProvider(ctx, cc, configuration, configuration.Spec.ProviderRef.Name, namespace)
+	if err != nil {
+		return err
+	}
+
+	// @step: check any additional providers referenced by the configuration
+	providers := make([]*terraformv1alpha1.Provider, len(configuration.Spec.Providers))
+	for i, x := range configuration.Spec.Providers {
+		providers[i], err = findPermittedProvider(ctx, cc, configuration, x.Name, namespace)
+		if err != nil {
+			return fmt.Errorf("spec.providers[%d]: %w", i, err)
+		}
+	}
+
+	return configuration.IsCompatibleProviders(provider, providers)
+}
+
+// findPermittedProvider retrieves the provider, if it exists, and checks the configuration is permitted to use it
+func findPermittedProvider(
+	ctx context.Context,
+	cc client.Client,
+	configuration *terraformv1alpha1.Configuration,
+	name string,
+	namespace *v1.Namespace,
+) (*terraformv1alpha1.Provider, error) {
+	provider := &terraformv1alpha1.Provider{}
+	provider.Name = name
+
+	found, err := kubernetes.GetIfExists(ctx, cc, provider)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	if provider.Spec.Selector == nil {
+		return provider, nil
+	}
+
+	matched, err := kubernetes.IsSelectorMatch(*provider.Spec.Selector, configuration.GetLabels(), namespace.GetLabels())
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return nil, errors.New("configuration has been denied by the provider policy")
+	}
+
+	return provider, nil
+}
+
 // validateModuleConstriants evaluates the module constraints and ensure the configuration passes all policies
 func validateModuleConstriants(
 	configuration *terraformv1alpha1.Configuration,
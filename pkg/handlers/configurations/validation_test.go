@@ -22,6 +22,8 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -53,7 +55,7 @@ var _ = Describe("Checking Configuration Validation", func() {
 
 	When("creating a validator", func() {
 		It("should not be nil", func() {
-			v := NewValidator(cc, true)
+			v := NewValidator(cc, true, terraformv1alpha1.EngineTerraform)
 			Expect(v).ToNot(BeNil())
 		})
 	})
@@ -238,6 +240,221 @@ var _ = Describe("Checking Configuration Validation", func() {
 				})
 			})
 		})
+
+		When("using the opentofu engine", func() {
+			It("should fail when the engine is not supported", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Engine = "pulumi"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`spec.engine "pulumi" is not supported`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the version is not supported by opentofu", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Engine = terraformv1alpha1.EngineOpenTofu
+				configuration.Spec.TerraformVersion = "1.5.7"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`spec.terraformVersion "1.5.7" is not supported by the tofu engine, must be >= 1.6.0`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the version is invalid", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Engine = terraformv1alpha1.EngineOpenTofu
+				configuration.Spec.TerraformVersion = "edge"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`spec.terraformVersion "edge" is not a valid version for the tofu engine`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should validate the version against the engine of the provider", func() {
+				provider := &terraformv1alpha1.Provider{}
+				Expect(cc.Get(ctx, client.ObjectKey{Name: name}, provider)).To(Succeed())
+				provider.Spec.Engine = terraformv1alpha1.EngineOpenTofu
+				Expect(cc.Update(ctx, provider)).To(Succeed())
+
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.TerraformVersion = "1.5.7"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("is not supported by the tofu engine"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should be permitted when the version is supported", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Engine = terraformv1alpha1.EngineOpenTofu
+				configuration.Spec.TerraformVersion = "1.6.2"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
+		When("a policy constrains the terraform version", func() {
+			BeforeEach(func() {
+				Expect(cc.Create(ctx, fixtures.NewVersionsPolicy("versions", ">= 1.5.0, < 1.7.0"))).To(Succeed())
+			})
+
+			It("should deny a version not permitted by the policy", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.TerraformVersion = "1.7.2"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`spec.terraformVersion: terraform version "1.7.2" is not permitted by the policy constraint ">= 1.5.0, < 1.7.0"`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should deny a tag which cannot be checked against the policy", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.TerraformVersion = "latest"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cannot be checked against the policy constraints"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should permit a version allowed by the policy", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.TerraformVersion = "1.6.6"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should permit a version constraint", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.TerraformVersion = "~> 1.6"
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
+		When("requesting a replace or target operation", func() {
+			var before, after *terraformv1alpha1.Configuration
+
+			BeforeEach(func() {
+				before = fixtures.NewValidBucketConfiguration(namespace, "test")
+				after = before.DeepCopy()
+				after.Annotations[terraformv1alpha1.ReplaceAnnotation] = "aws_s3_bucket.this"
+			})
+
+			requestBy := func(username string, groups ...string) context.Context {
+				return admission.NewContextWithRequest(ctx, admission.Request{
+					AdmissionRequest: admissionv1.AdmissionRequest{
+						UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+					},
+				})
+			}
+
+			It("should fail when the replace address is invalid", func() {
+				after.Annotations[terraformv1alpha1.ReplaceAnnotation] = "aws_s3_bucket"
+
+				warnings, err := v.ValidateUpdate(ctx, before, after)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`replace address "aws_s3_bucket" is not a valid resource address`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the target address is invalid", func() {
+				after.Annotations[terraformv1alpha1.TargetAnnotation] = "module."
+
+				warnings, err := v.ValidateUpdate(ctx, before, after)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`target address "module." is not a valid resource or module address`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the state command is invalid", func() {
+				after.Annotations[terraformv1alpha1.StateCommandAnnotation] = `["import", "aws_s3_bucket.this"]`
+
+				warnings, err := v.ValidateUpdate(ctx, before, after)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`state command "import" is not supported`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the state command is not a json list", func() {
+				after.Annotations[terraformv1alpha1.StateCommandAnnotation] = "mv aws_s3_bucket.this aws_s3_bucket.main"
+
+				warnings, err := v.ValidateUpdate(ctx, before, after)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("state command must be a json list of the command and arguments"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should permit a state command with an address containing whitespace and quotes", func() {
+				after.Annotations[terraformv1alpha1.StateCommandAnnotation] = `["rm", "aws_s3_bucket.this[\"it's a\"]"]`
+				after.Annotations[terraformv1alpha1.StateCommandIDAnnotation] = "1700000000"
+
+				warnings, err := v.ValidateUpdate(ctx, before, after)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should be permitted when no policy restricts the operations", func() {
+				warnings, err := v.ValidateUpdate(requestBy("jane"), before, after)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+
+			Context("and a policy restricts the operations", func() {
+				BeforeEach(func() {
+					policy := fixtures.NewOperationsPolicy("operations", "admin")
+					policy.Spec.Constraints.Operations.Groups = []string{"platform"}
+
+					Expect(cc.Create(ctx, policy)).To(Succeed())
+				})
+
+				It("should deny a user not permitted by the policy", func() {
+					warnings, err := v.ValidateUpdate(requestBy("jane", "developers"), before, after)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal(`user "jane" is not permitted to request replace or target operations on this configuration by policy`))
+					Expect(warnings).To(BeEmpty())
+				})
+
+				It("should permit a user named in the policy", func() {
+					warnings, err := v.ValidateUpdate(requestBy("admin"), before, after)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(warnings).To(BeEmpty())
+				})
+
+				It("should permit a member of a group in the policy", func() {
+					warnings, err := v.ValidateUpdate(requestBy("jane", "platform"), before, after)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(warnings).To(BeEmpty())
+				})
+
+				It("should permit the operation to be removed", func() {
+					warnings, err := v.ValidateUpdate(requestBy("controller"), after, before)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(warnings).To(BeEmpty())
+				})
+
+				It("should permit other changes while an operation is pending", func() {
+					changed := after.DeepCopy()
+					changed.Spec.EnableAutoApproval = true
+
+					warnings, err := v.ValidateUpdate(requestBy("jane"), after, changed)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(warnings).To(BeEmpty())
+				})
+			})
+		})
 	})
 
 	When("creating a configuration", func() {
@@ -297,6 +514,131 @@ var _ = Describe("Checking Configuration Validation", func() {
 			})
 		})
 
+		Context("specifying a drift policy", func() {
+			It("should fail when drift detection is not enabled", func() {
+				configuration.Spec.DriftPolicy = &terraformv1alpha1.DriftPolicy{Mode: terraformv1alpha1.DriftModeRemediate}
+
+				warnings, err = v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.driftPolicy requires spec.enableDriftDetection to be enabled"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the interval is not positive", func() {
+				configuration.Spec.EnableDriftDetection = true
+				configuration.Spec.DriftPolicy = &terraformv1alpha1.DriftPolicy{Interval: &metav1.Duration{}}
+
+				warnings, err = v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.driftPolicy.interval must be greater than zero"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the mode is invalid", func() {
+				configuration.Spec.EnableDriftDetection = true
+				configuration.Spec.DriftPolicy = &terraformv1alpha1.DriftPolicy{Mode: "Unknown"}
+
+				warnings, err = v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.driftPolicy.mode must be one of Detect, Remediate or RequireApproval"))
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
+		Context("specifying imports", func() {
+			It("should fail when the address is invalid", func() {
+				configuration.Spec.Imports = terraformv1alpha1.ImportList{{Address: "aws_s3_bucket", ID: "bucket"}}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`spec.imports[0].address "aws_s3_bucket" is not a valid resource address`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the id is missing", func() {
+				configuration.Spec.Imports = terraformv1alpha1.ImportList{{Address: "aws_s3_bucket.this"}}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.imports[0].id is required"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the address is duplicated", func() {
+				configuration.Spec.Imports = terraformv1alpha1.ImportList{
+					{Address: "aws_s3_bucket.this", ID: "a"},
+					{Address: "aws_s3_bucket.this", ID: "b"},
+				}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(`spec.imports[1].address "aws_s3_bucket.this" is duplicated`))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should not fail when the imports are valid", func() {
+				configuration.Spec.Imports = terraformv1alpha1.ImportList{
+					{Address: "aws_s3_bucket.this", ID: "bucket"},
+					{Address: `module.vpc.aws_vpc.this["main"]`, ID: "vpc-0123"},
+				}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
+		Context("specifying dependencies", func() {
+			It("should fail when the dependency has no name", func() {
+				configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{{}}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.dependsOn[0].name is required"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the configuration depends on itself", func() {
+				configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{{Name: name}}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.dependsOn cannot reference the configuration itself"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should fail when the dependencies form a cycle", func() {
+				network := fixtures.NewValidBucketConfiguration(namespace, "network")
+				network.Spec.DependsOn = terraformv1alpha1.DependencyList{{Name: "database"}}
+				database := fixtures.NewValidBucketConfiguration(namespace, "database")
+				database.Spec.DependsOn = terraformv1alpha1.DependencyList{{Name: name}}
+				Expect(cc.Create(ctx, network)).To(Succeed())
+				Expect(cc.Create(ctx, database)).To(Succeed())
+
+				configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{{Name: "network"}}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.dependsOn creates a dependency cycle: aws -> network -> database -> aws"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should not fail when the dependencies are valid", func() {
+				Expect(cc.Create(ctx, fixtures.NewValidBucketConfiguration(namespace, "network"))).To(Succeed())
+
+				configuration.Spec.DependsOn = terraformv1alpha1.DependencyList{
+					{
+						Name:    "network",
+						Outputs: []terraformv1alpha1.DependencyOutput{{Key: "vpc_id"}},
+					},
+				}
+				warnings, err = v.ValidateCreate(ctx, configuration)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
 		Context("we have a module constraint", func() {
 			BeforeEach(func() {
 				provider := fixtures.NewValidAWSProvider(name, fixtures.NewValidAWSProviderSecret(namespace, name))
@@ -496,6 +838,76 @@ var _ = Describe("Checking Configuration Validation", func() {
 			})
 		})
 
+		Context("additional providers are referenced", func() {
+			BeforeEach(func() {
+				Expect(cc.Create(ctx, fixtures.NewValidAWSReadyProvider(name, fixtures.NewValidAWSProviderSecret(namespace, name)))).To(Succeed())
+
+				provider := fixtures.NewValidAWSReadyProvider("east", fixtures.NewValidAWSProviderSecret(namespace, "east"))
+				provider.Spec.Selector = &terraformv1alpha1.Selector{
+					Resource: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"does_match": "true",
+						},
+					},
+				}
+				Expect(cc.Create(ctx, provider)).To(Succeed())
+			})
+
+			It("should deny a provider reference without a name", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Alias: "east"}}
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.providers[0].name is required"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should deny duplicate provider references", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{
+					{Alias: "east", Name: "east"},
+					{Alias: "east", Name: "east"},
+				}
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.providers[1] is a duplicate of another provider reference"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should deny when the additional provider policy does not match", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Alias: "east", Name: "east"}}
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.providers[0]: configuration has been denied by the provider policy"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should deny when the provider type is used without an alias", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Labels = map[string]string{"does_match": "true"}
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Name: "east"}}
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("spec.providers[0] requires a unique alias, provider type \"aws\" is already in use"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should allow aliased additional providers", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Labels = map[string]string{"does_match": "true"}
+				configuration.Spec.Providers = []terraformv1alpha1.ProviderReference{{Alias: "east", Name: "east"}}
+
+				warnings, err := v.ValidateCreate(ctx, configuration)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
 		Context("versioning is disabled on configurations", func() {
 			BeforeEach(func() {
 				v.enableVersions = false
@@ -520,5 +932,29 @@ var _ = Describe("Checking Configuration Validation", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(warnings).To(BeEmpty())
 		})
+
+		Context("and another configuration depends on it", func() {
+			BeforeEach(func() {
+				dependent := fixtures.NewValidBucketConfiguration(namespace, "dependent")
+				dependent.Spec.DependsOn = terraformv1alpha1.DependencyList{{Name: "test"}}
+				Expect(cc.Create(ctx, dependent)).To(Succeed())
+			})
+
+			It("should deny the deletion", func() {
+				warnings, err := v.ValidateDelete(ctx, fixtures.NewValidBucketConfiguration(namespace, "test"))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("resource in use by configuration(s): default/dependent"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should allow the deletion when orphaned", func() {
+				configuration := fixtures.NewValidBucketConfiguration(namespace, "test")
+				configuration.Annotations = map[string]string{terraformv1alpha1.OrphanAnnotation: "true"}
+
+				warnings, err := v.ValidateDelete(ctx, configuration)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
 	})
 })
@@ -22,10 +22,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
@@ -57,7 +57,11 @@ func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.O
 		before = oldObj.(*terraformv1alpha1.Context)
 	}
 
-	return admission.Warnings{}, v.validate(ctx, before, after)
+	if err := v.validate(ctx, before, after); err != nil {
+		return admission.Warnings{}, err
+	}
+
+	return v.validateRemovedVariables(ctx, before, after)
 }
 
 // validate is called to ensure the configuration is valid and incline with current policies
@@ -88,6 +92,41 @@ func (v *validator) validate(_ context.Context, _, current *terraformv1alpha1.Co
 	return nil
 }
 
+// validateRemovedVariables is called to ensure any variables removed from the context are not
+// still being consumed
+func (v *validator) validateRemovedVariables(ctx context.Context, before, after *terraformv1alpha1.Context) (admission.Warnings, error) {
+	warnings := admission.Warnings{}
+	if before == nil {
+		return warnings, nil
+	}
+
+	var removed []string
+	for name := range before.Spec.Variables {
+		if !after.Spec.HasVariable(name) {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) == 0 {
+		return warnings, nil
+	}
+	sort.Strings(removed)
+
+	for _, name := range removed {
+		required, optional, err := v.findUsage(ctx, after.Name, name)
+		if err != nil {
+			return warnings, err
+		}
+		if !required.IsEmpty() {
+			return warnings, fmt.Errorf(`spec.variables["%s"] is in use by %s`, name, required)
+		}
+		if !optional.IsEmpty() {
+			warnings = append(warnings, fmt.Sprintf(`spec.variables["%s"] is optionally referenced by %s`, name, optional))
+		}
+	}
+
+	return warnings, nil
+}
+
 // ValidateDelete is called when a resource is being deleted
 func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	var warnings admission.Warnings
@@ -99,28 +138,98 @@ func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) (adm
 
 	// @choice: unless the zero validation annotation is present we should not be
 	// allowed to delete a context if it's being referenced
-	list := &terraformv1alpha1.ConfigurationList{}
-
-	if err := v.cc.List(ctx, list); err != nil {
+	required, optional, err := v.findUsage(ctx, current.Name, "")
+	if err != nil {
 		return warnings, err
 	}
-	if len(list.Items) == 0 {
-		return warnings, nil
+	if !required.IsEmpty() {
+		return warnings, fmt.Errorf("resource in use by %s", required)
+	}
+	if !optional.IsEmpty() {
+		warnings = append(warnings, fmt.Sprintf("resource is optionally referenced by %s", optional))
 	}
 
-	var inuse []string
+	return warnings, nil
+}
+
+// usage is a collection of resources referencing a context
+type usage struct {
+	// cloudresources is a list of cloudresources referencing the context
+	cloudresources []string
+	// configurations is a list of configurations referencing the context
+	configurations []string
+}
+
+// IsEmpty returns true if no resources are referencing the context
+func (u *usage) IsEmpty() bool {
+	return len(u.cloudresources) == 0 && len(u.configurations) == 0
+}
+
+// String returns a description of the resources referencing the context
+func (u *usage) String() string {
+	var list []string
+	if len(u.configurations) > 0 {
+		list = append(list, fmt.Sprintf("configuration(s): %s", strings.Join(u.configurations, ", ")))
+	}
+	if len(u.cloudresources) > 0 {
+		list = append(list, fmt.Sprintf("cloudresource(s): %s", strings.Join(u.cloudresources, ", ")))
+	}
+
+	return strings.Join(list, "; ")
+}
 
-	for i := 0; i < len(list.Items); i++ {
-		for _, x := range list.Items[i].Spec.ValueFrom {
-			if ptr.Deref(x.Context, "") == current.Name {
-				inuse = append(inuse, list.Items[i].GetNamespacedName().String())
+// findUsage returns the resources referencing the context, split by the required and optional
+// references. When a key is given only the references to that variable are included
+func (v *validator) findUsage(ctx context.Context, name, key string) (*usage, *usage, error) {
+	required, optional := &usage{}, &usage{}
+
+	// referenced returns if the context is referenced, and if any of the references are required
+	referenced := func(valueFrom terraformv1alpha1.ValueFromList) (bool, bool) {
+		var found, isRequired bool
+		for _, x := range valueFrom.GetContextReferences(name) {
+			if key != "" && x.Key != key {
+				continue
 			}
+			found = true
+			isRequired = isRequired || !x.Optional
 		}
+
+		return found, isRequired
 	}
 
-	if len(inuse) > 0 {
-		return warnings, fmt.Errorf("resource in use by configuration(s): %v", strings.Join(inuse, ", "))
+	configurations := &terraformv1alpha1.ConfigurationList{}
+	if err := v.cc.List(ctx, configurations); err != nil {
+		return nil, nil, err
 	}
+	for i := range configurations.Items {
+		x := &configurations.Items[i]
+		// @note: configurations managed by a cloudresource are reported via the cloudresource
+		if x.IsManaged() {
+			continue
+		}
 
-	return warnings, nil
+		switch found, isRequired := referenced(x.Spec.ValueFrom); {
+		case isRequired:
+			required.configurations = append(required.configurations, x.GetNamespacedName().String())
+		case found:
+			optional.configurations = append(optional.configurations, x.GetNamespacedName().String())
+		}
+	}
+
+	cloudresources := &terraformv1alpha1.CloudResourceList{}
+	if err := v.cc.List(ctx, cloudresources); err != nil {
+		return nil, nil, err
+	}
+	for i := range cloudresources.Items {
+		x := &cloudresources.Items[i]
+
+		switch found, isRequired := referenced(x.Spec.ValueFrom); {
+		case isRequired:
+			required.cloudresources = append(required.cloudresources, x.GetNamespacedName().String())
+		case found:
+			optional.cloudresources = append(optional.cloudresources, x.GetNamespacedName().String())
+		}
+	}
+
+	return required, optional, nil
 }
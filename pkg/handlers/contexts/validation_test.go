@@ -164,6 +164,82 @@ var _ = Describe("Context Validation", func() {
 		})
 	})
 
+	When("removing variables from a context", func() {
+		var before *terraformv1alpha1.Context
+
+		BeforeEach(func() {
+			before = c.DeepCopy()
+			delete(c.Spec.Variables, "vpc_id")
+		})
+
+		Context("and the variable is not referenced", func() {
+			BeforeEach(func() {
+				warnings, err = v.ValidateUpdate(context.Background(), before, c)
+			})
+
+			It("should not return an error", func() {
+				Expect(err).To(BeNil())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+
+		Context("and the variable is required by a configuration", func() {
+			BeforeEach(func() {
+				cr := fixtures.NewValidBucketConfiguration("default", "test")
+				cr.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+					{Context: pointer.String(c.Name), Key: "vpc_id"},
+				}
+				Expect(cc.Create(context.Background(), cr)).To(Succeed())
+
+				warnings, err = v.ValidateUpdate(context.Background(), before, c)
+			})
+
+			It("should return an error", func() {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal(`spec.variables["vpc_id"] is in use by configuration(s): default/test`))
+			})
+		})
+
+		Context("and the variable is optionally referenced by a cloudresource", func() {
+			BeforeEach(func() {
+				cr := fixtures.NewCloudResource("default", "test")
+				cr.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+					{Context: pointer.String(c.Name), Key: "vpc_id", Optional: true},
+				}
+				Expect(cc.Create(context.Background(), cr)).To(Succeed())
+
+				warnings, err = v.ValidateUpdate(context.Background(), before, c)
+			})
+
+			It("should not return an error", func() {
+				Expect(err).To(BeNil())
+			})
+
+			It("should return a warning", func() {
+				Expect(warnings).To(Equal(admission.Warnings{
+					`spec.variables["vpc_id"] is optionally referenced by cloudresource(s): default/test`,
+				}))
+			})
+		})
+
+		Context("and another variable is referenced", func() {
+			BeforeEach(func() {
+				cr := fixtures.NewValidBucketConfiguration("default", "test")
+				cr.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+					{Context: pointer.String(c.Name), Key: "public_subnets"},
+				}
+				Expect(cc.Create(context.Background(), cr)).To(Succeed())
+
+				warnings, err = v.ValidateUpdate(context.Background(), before, c)
+			})
+
+			It("should not return an error", func() {
+				Expect(err).To(BeNil())
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+	})
+
 	When("deleting a context", func() {
 		BeforeEach(func() {
 			for i := 0; i < 2; i++ {
@@ -213,6 +289,39 @@ var _ = Describe("Context Validation", func() {
 				})
 			})
 
+			Context("and we have cloudresources referencing the context", func() {
+				BeforeEach(func() {
+					cr := fixtures.NewCloudResource("default", "database")
+					cr.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+						{Context: pointer.String(c.Name), Key: "bar"},
+					}
+					Expect(cc.Create(context.Background(), cr)).To(Succeed())
+				})
+
+				It("should return an error", func() {
+					warnings, err = v.ValidateDelete(context.Background(), c)
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(Equal("resource in use by configuration(s): default/test-0, default/test-1; cloudresource(s): default/database"))
+				})
+			})
+
+			Context("and we only have optional references to the context", func() {
+				BeforeEach(func() {
+					c = fixtures.NewTerranettesContext("optional")
+					cr := fixtures.NewValidBucketConfiguration("default", "optional")
+					cr.Spec.ValueFrom = []terraformv1alpha1.ValueFromSource{
+						{Context: pointer.String(c.Name), Key: "bar", Optional: true},
+					}
+					Expect(cc.Create(context.Background(), cr)).To(Succeed())
+				})
+
+				It("should not return an error", func() {
+					warnings, err = v.ValidateDelete(context.Background(), c)
+					Expect(err).To(BeNil())
+					Expect(warnings).To(Equal(admission.Warnings{"resource is optionally referenced by configuration(s): default/optional"}))
+				})
+			})
+
 			Context("but we have configurations referencing the context", func() {
 				BeforeEach(func() {
 					c = fixtures.NewTerranettesContext("not_referenced")
@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,6 +32,7 @@ import (
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/schedule"
 )
 
 type validator struct {
@@ -97,6 +99,15 @@ func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.O
 	if err := validateModuleConstraint(o); err != nil {
 		return warnings, err
 	}
+	if err := validateRegoConstraint(o); err != nil {
+		return warnings, err
+	}
+	if err := validateCostConstraint(o); err != nil {
+		return warnings, err
+	}
+	if err := validateMaintenanceConstraint(o); err != nil {
+		return warnings, err
+	}
 
 	return warnings, nil
 }
@@ -152,6 +163,160 @@ func validateModuleConstraint(policy *terraformv1alpha1.Policy) error {
 	return nil
 }
 
+// validateMaintenanceConstraint ensures the maintenance windows are valid
+func validateMaintenanceConstraint(policy *terraformv1alpha1.Policy) error {
+	switch {
+	case policy.Spec.Constraints == nil, policy.Spec.Constraints.Maintenance == nil:
+		return nil
+	}
+
+	constraint := policy.Spec.Constraints.Maintenance
+
+	if constraint.Selector != nil {
+		if constraint.Selector.Namespace != nil {
+			if _, err := metav1.LabelSelectorAsSelector(constraint.Selector.Namespace); err != nil {
+				return fmt.Errorf("spec.constraints.maintenance.selector.namespace is invalid, %w", err)
+			}
+		}
+
+		if constraint.Selector.Resource != nil {
+			if _, err := metav1.LabelSelectorAsSelector(constraint.Selector.Resource); err != nil {
+				return fmt.Errorf("spec.constraints.maintenance.selector.resource is invalid, %w", err)
+			}
+		}
+	}
+
+	if len(constraint.Windows) == 0 {
+		return errors.New("spec.constraints.maintenance.windows requires at least one window")
+	}
+
+	for i, x := range constraint.Windows {
+		if _, err := schedule.Parse(x.Schedule); err != nil {
+			return fmt.Errorf("spec.constraints.maintenance.windows[%d].schedule is invalid, %w", i, err)
+		}
+		if x.Duration.Duration <= 0 {
+			return fmt.Errorf("spec.constraints.maintenance.windows[%d].duration must be greater than zero", i)
+		}
+		if _, err := x.GetLocation(); err != nil {
+			return fmt.Errorf("spec.constraints.maintenance.windows[%d].timeZone is invalid, %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCostConstraint ensures the cost budgets are valid
+func validateCostConstraint(policy *terraformv1alpha1.Policy) error {
+	switch {
+	case policy.Spec.Constraints == nil, policy.Spec.Constraints.Costs == nil:
+		return nil
+	}
+
+	constraint := policy.Spec.Constraints.Costs
+
+	if constraint.Selector != nil {
+		if constraint.Selector.Namespace != nil {
+			if _, err := metav1.LabelSelectorAsSelector(constraint.Selector.Namespace); err != nil {
+				return fmt.Errorf("spec.constraints.costs.selector.namespace is invalid, %w", err)
+			}
+		}
+
+		if constraint.Selector.Resource != nil {
+			if _, err := metav1.LabelSelectorAsSelector(constraint.Selector.Resource); err != nil {
+				return fmt.Errorf("spec.constraints.costs.selector.resource is invalid, %w", err)
+			}
+		}
+	}
+
+	budgets := []struct {
+		name  string
+		value *resource.Quantity
+	}{
+		{"maxMonthly", constraint.MaxMonthly},
+		{"maxMonthlyIncrease", constraint.MaxMonthlyIncrease},
+		{"maxNamespaceMonthly", constraint.MaxNamespaceMonthly},
+	}
+
+	var found bool
+	for _, x := range budgets {
+		if x.value == nil {
+			continue
+		}
+		if x.value.Sign() < 0 {
+			return fmt.Errorf("spec.constraints.costs.%s cannot be negative", x.name)
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("spec.constraints.costs requires at least one of maxMonthly, maxMonthlyIncrease or maxNamespaceMonthly")
+	}
+
+	return nil
+}
+
+// regoModuleName is the permitted format for a rego module name
+var regoModuleName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// validateRegoConstraint ensures the rego constraint is valid
+func validateRegoConstraint(policy *terraformv1alpha1.Policy) error {
+	switch {
+	case policy.Spec.Constraints == nil, policy.Spec.Constraints.Rego == nil:
+		return nil
+	}
+
+	constraint := policy.Spec.Constraints.Rego
+
+	if constraint.Selector != nil {
+		if constraint.Selector.Namespace != nil {
+			if _, err := metav1.LabelSelectorAsSelector(constraint.Selector.Namespace); err != nil {
+				return fmt.Errorf("spec.constraints.rego.selector.namespace is invalid, %w", err)
+			}
+		}
+
+		if constraint.Selector.Resource != nil {
+			if _, err := metav1.LabelSelectorAsSelector(constraint.Selector.Resource); err != nil {
+				return fmt.Errorf("spec.constraints.rego.selector.resource is invalid, %w", err)
+			}
+		}
+	}
+
+	if len(constraint.Modules) == 0 && len(constraint.External) == 0 {
+		return errors.New("spec.constraints.rego requires at least one module or external source")
+	}
+
+	names := make(map[string]bool)
+	for i, module := range constraint.Modules {
+		switch {
+		case module.Name == "":
+			return fmt.Errorf("spec.constraints.rego.modules[%d].name cannot be empty", i)
+		case !regoModuleName.MatchString(module.Name):
+			return fmt.Errorf("spec.constraints.rego.modules[%d].name must match %s", i, regoModuleName.String())
+		case names[module.Name]:
+			return fmt.Errorf("spec.constraints.rego.modules[%d].name %q is duplicated", i, module.Name)
+		case module.Content == "":
+			return fmt.Errorf("spec.constraints.rego.modules[%d].content cannot be empty", i)
+		}
+		names[module.Name] = true
+	}
+
+	for i, external := range constraint.External {
+		switch {
+		case external.Name == "":
+			return fmt.Errorf("spec.constraints.rego.external[%d].name cannot be empty", i)
+		case external.Name == "inline":
+			return fmt.Errorf("spec.constraints.rego.external[%d].name cannot be inline, the name is reserved", i)
+		case external.URL == "":
+			return fmt.Errorf("spec.constraints.rego.external[%d].url cannot be empty", i)
+		case external.SecretRef != nil && external.SecretRef.Name == "":
+			return fmt.Errorf("spec.constraints.rego.external[%d].secretRef.name cannot be empty", i)
+		case external.SecretRef != nil && external.SecretRef.Namespace != "":
+			return fmt.Errorf("spec.constraints.rego.external[%d].secretRef.namespace should not be set", i)
+		}
+	}
+
+	return nil
+}
+
 // validateCheckovConstraints ensures the constraints are valid
 func validateCheckovConstraints(policy *terraformv1alpha1.Policy) error {
 	switch {
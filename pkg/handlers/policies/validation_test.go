@@ -21,10 +21,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -115,6 +117,168 @@ var _ = Describe("Module Constraints", func() {
 	})
 })
 
+var _ = Describe("Maintenance Constraints", func() {
+	var err error
+	var v *validator
+	var policy *terraformv1alpha1.Policy
+	var warnings admission.Warnings
+
+	BeforeEach(func() {
+		v = &validator{cc: fake.NewClientBuilder().WithScheme(schema.GetScheme()).Build()}
+		policy = fixtures.NewMaintenancePolicy("maintenance", "0 22 * * 1-5", 4*time.Hour)
+	})
+
+	When("creating a policy with maintenance windows", func() {
+		It("should not error on a valid window", func() {
+			policy.Spec.Constraints.Maintenance.Windows[0].TimeZone = "Europe/London"
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error when no windows are defined", func() {
+			policy.Spec.Constraints.Maintenance.Windows = nil
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.maintenance.windows requires at least one window"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on an invalid schedule", func() {
+			policy.Spec.Constraints.Maintenance.Windows[0].Schedule = "0 25 * * *"
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.maintenance.windows[0].schedule is invalid, hour field must be between 0 and 23: \"25\""))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on a missing duration", func() {
+			policy.Spec.Constraints.Maintenance.Windows[0].Duration.Duration = 0
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.maintenance.windows[0].duration must be greater than zero"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on an invalid time zone", func() {
+			policy.Spec.Constraints.Maintenance.Windows[0].TimeZone = "Nowhere/Land"
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.maintenance.windows[0].timeZone is invalid, unknown time zone Nowhere/Land"))
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Rego Constraints", func() {
+	var err error
+	var v *validator
+	var policy *terraformv1alpha1.Policy
+	var warnings admission.Warnings
+
+	BeforeEach(func() {
+		v = &validator{cc: fake.NewClientBuilder().WithScheme(schema.GetScheme()).Build()}
+		policy = fixtures.NewRegoPolicy("rego", "package main\n")
+	})
+
+	When("creating a policy with rego modules", func() {
+		It("should not error on a valid module", func() {
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error when no modules or external sources are defined", func() {
+			policy.Spec.Constraints.Rego.Modules = nil
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.rego requires at least one module or external source"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on an invalid module name", func() {
+			policy.Spec.Constraints.Rego.Modules[0].Name = "bad/name"
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.rego.modules[0].name must match ^[a-zA-Z0-9._-]+$"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on duplicate module names", func() {
+			policy.Spec.Constraints.Rego.Modules = append(policy.Spec.Constraints.Rego.Modules, policy.Spec.Constraints.Rego.Modules[0])
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.rego.modules[1].name \"main\" is duplicated"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on empty module content", func() {
+			policy.Spec.Constraints.Rego.Modules[0].Content = ""
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.rego.modules[0].content cannot be empty"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on an external source without a url", func() {
+			policy.Spec.Constraints.Rego.External = []terraformv1alpha1.ExternalCheck{{Name: "policies"}}
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.rego.external[0].url cannot be empty"))
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Cost Constraints", func() {
+	var err error
+	var v *validator
+	var policy *terraformv1alpha1.Policy
+	var warnings admission.Warnings
+
+	BeforeEach(func() {
+		v = &validator{cc: fake.NewClientBuilder().WithScheme(schema.GetScheme()).Build()}
+		policy = fixtures.NewCostPolicy("budget", "100")
+	})
+
+	When("creating a policy with cost budgets", func() {
+		It("should not error on a valid budget", func() {
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error when no budgets are defined", func() {
+			policy.Spec.Constraints.Costs.MaxMonthly = nil
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.costs requires at least one of maxMonthly, maxMonthlyIncrease or maxNamespaceMonthly"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should error on a negative budget", func() {
+			increase := resource.MustParse("-10")
+			policy.Spec.Constraints.Costs.MaxMonthlyIncrease = &increase
+
+			warnings, err = v.ValidateCreate(context.TODO(), policy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.constraints.costs.maxMonthlyIncrease cannot be negative"))
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+})
+
 var _ = Describe("Policy Validation", func() {
 	var err error
 	var v *validator
@@ -21,7 +21,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
+	"github.com/Masterminds/semver"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -29,6 +32,9 @@ import (
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 )
 
+// providerTypeRegex is the format of a terraform provider local name
+var providerTypeRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
 type validator struct {
 	cc client.Client
 	// jobNamespace is the namespace where static credentials should be provision.
@@ -78,9 +84,32 @@ func (v *validator) validate(ctx context.Context, provider *terraformv1alpha1.Pr
 			return errors.New("spec.serviceAccount: serviceAccount is required when source is injected")
 		}
 
+	case terraformv1alpha1.SourceFederated:
+		if err := validateFederation(provider); err != nil {
+			return err
+		}
+
 	default:
 		return fmt.Errorf("spec.source: %s is not supported", provider.Spec.Source)
 	}
+	if provider.Spec.Federation != nil && provider.Spec.Source != terraformv1alpha1.SourceFederated {
+		return errors.New("spec.federation: can only be used when source is federated")
+	}
+
+	// @step: validate the provider type and any registry source
+	if !providerTypeRegex.MatchString(string(provider.Spec.Provider)) {
+		return errors.New("spec.provider: must be a valid terraform provider name, i.e. lowercase alphanumeric and hyphens")
+	}
+	if provider.Spec.Registry != nil {
+		if err := provider.Spec.Registry.IsValid(); err != nil {
+			return err
+		}
+		if provider.Spec.Registry.Version != "" {
+			if _, err := semver.NewConstraint(provider.Spec.Registry.Version); err != nil {
+				return fmt.Errorf("spec.registry.version: invalid version constraint, %w", err)
+			}
+		}
+	}
 
 	// @step: are we trying to set provider as a default provider
 	annotations := provider.GetAnnotations()
@@ -101,9 +130,42 @@ func (v *validator) validate(ctx context.Context, provider *terraformv1alpha1.Pr
 		}
 	}
 
+	// @step: validate any typed backend configuration
+	if backend := provider.Spec.Backend; backend != nil {
+		var count int
+		for _, x := range []bool{backend.HTTP != nil, backend.Postgres != nil, backend.S3 != nil} {
+			if x {
+				count++
+			}
+		}
+
+		switch {
+		case provider.Spec.BackendTemplate != nil:
+			return errors.New("spec.backend: cannot be used alongside spec.backendTemplate")
+		case count == 0:
+			return errors.New("spec.backend: one of http, postgres or s3 must be defined")
+		case count > 1:
+			return errors.New("spec.backend: only one of http, postgres or s3 can be defined")
+		case backend.HTTP != nil && backend.HTTP.Address == "":
+			return errors.New("spec.backend.http.address: address is required")
+		case backend.S3 != nil && backend.S3.Bucket == "":
+			return errors.New("spec.backend.s3.bucket: bucket is required")
+		case backend.S3 != nil && backend.S3.Region == "":
+			return errors.New("spec.backend.s3.region: region is required")
+		case backend.Postgres != nil && !backend.HasSecretRef():
+			return errors.New("spec.backend.secretRef: secret is required for the postgres backend")
+		case backend.SecretRef != nil && backend.SecretRef.Name == "":
+			return errors.New("spec.backend.secretRef.name: name is required")
+		case backend.SecretRef != nil && backend.SecretRef.Namespace != "" && backend.SecretRef.Namespace != v.jobNamespace:
+			return errors.New("spec.backend.secretRef.namespace: must be in same namespace as the controller")
+		}
+	}
+
 	// @step: validate any preloading configuration
 	if provider.Spec.Preload != nil {
 		switch {
+		case provider.IsPreloadingEnabled() && provider.Spec.Source == terraformv1alpha1.SourceFederated:
+			return errors.New("spec.preload: preloading is not supported with federated credentials")
 		case provider.Spec.Preload.Context == "":
 			return errors.New("spec.preload.context: is required")
 		case provider.Spec.Preload.Cluster == "":
@@ -115,3 +177,46 @@ func (v *validator) validate(ctx context.Context, provider *terraformv1alpha1.Pr
 
 	return nil
 }
+
+// validateFederation checks the workload identity federation required by the provider type is defined
+func validateFederation(provider *terraformv1alpha1.Provider) error {
+	federation := provider.Spec.Federation
+
+	switch {
+	case federation == nil:
+		return errors.New("spec.federation: federation is required when source is federated")
+	case provider.Spec.ServiceAccount != nil && *provider.Spec.ServiceAccount == "":
+		return errors.New("spec.serviceAccount: serviceAccount cannot be empty")
+	}
+
+	switch provider.Spec.Provider {
+	case terraformv1alpha1.AWSProviderType:
+		switch {
+		case federation.RoleARN == "":
+			return errors.New("spec.federation.roleARN: roleARN is required for aws providers")
+		case !strings.HasPrefix(federation.RoleARN, "arn:"):
+			return errors.New("spec.federation.roleARN: must be a valid role arn")
+		}
+
+	case terraformv1alpha1.AzureProviderType, terraformv1alpha1.AzureActiveDirectoryProviderType:
+		switch {
+		case federation.ClientID == "":
+			return errors.New("spec.federation.clientID: clientID is required for azure providers")
+		case federation.TenantID == "":
+			return errors.New("spec.federation.tenantID: tenantID is required for azure providers")
+		}
+
+	case terraformv1alpha1.GCPProviderType:
+		switch {
+		case federation.WorkloadIdentityProvider == "":
+			return errors.New("spec.federation.workloadIdentityProvider: workloadIdentityProvider is required for google providers")
+		case !strings.HasPrefix(federation.WorkloadIdentityProvider, "projects/"):
+			return errors.New("spec.federation.workloadIdentityProvider: must be in the format projects/NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER")
+		}
+
+	default:
+		return errors.New("spec.federation: federated credentials are only supported for aws, azurerm, azuread and google providers")
+	}
+
+	return nil
+}
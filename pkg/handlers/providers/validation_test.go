@@ -23,6 +23,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -257,6 +258,205 @@ var _ = Describe("Provider Validation", func() {
 		})
 	})
 
+	When("creating a provider with a typed backend", func() {
+		var provider *terraformv1alpha1.Provider
+
+		BeforeEach(func() {
+			provider = fixtures.NewValidAWSProvider(name, fixtures.NewValidAWSProviderSecret(namespace, name))
+			provider.Spec.Backend = &terraformv1alpha1.Backend{
+				S3: &terraformv1alpha1.S3Backend{Bucket: "state", Region: "eu-west-2"},
+			}
+		})
+
+		It("should not throw an error when the backend is valid", func() {
+			warnings, err := v.ValidateCreate(ctx, provider)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should throw an error when a backend template is also defined", func() {
+			provider.Spec.BackendTemplate = &v1.SecretReference{Name: "backend"}
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.backend: cannot be used alongside spec.backendTemplate"))
+		})
+
+		It("should throw an error when no backend type is defined", func() {
+			provider.Spec.Backend.S3 = nil
+
+			_, err := v.ValidateUpdate(ctx, nil, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.backend: one of http, postgres or s3 must be defined"))
+		})
+
+		It("should throw an error when multiple backend types are defined", func() {
+			provider.Spec.Backend.HTTP = &terraformv1alpha1.HTTPBackend{Address: "https://state.example.com"}
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.backend: only one of http, postgres or s3 can be defined"))
+		})
+
+		It("should throw an error when the s3 bucket is missing", func() {
+			provider.Spec.Backend.S3.Bucket = ""
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.backend.s3.bucket: bucket is required"))
+		})
+
+		It("should throw an error when the postgres backend has no secret", func() {
+			provider.Spec.Backend = &terraformv1alpha1.Backend{Postgres: &terraformv1alpha1.PostgresBackend{}}
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.backend.secretRef: secret is required for the postgres backend"))
+		})
+
+		It("should throw an error when the secret is outside the controller namespace", func() {
+			provider.Spec.Backend.SecretRef = &v1.SecretReference{Name: "backend", Namespace: "other"}
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.backend.secretRef.namespace: must be in same namespace as the controller"))
+		})
+	})
+
+	When("creating a provider sourced from the registry", func() {
+		var provider *terraformv1alpha1.Provider
+
+		BeforeEach(func() {
+			provider = fixtures.NewValidAWSProvider(name, fixtures.NewValidAWSProviderSecret(namespace, name))
+			provider.Spec.Provider = "cloudflare"
+			provider.Spec.Registry = &terraformv1alpha1.ProviderRegistry{Source: "cloudflare/cloudflare", Version: "~> 4.0"}
+		})
+
+		It("should not throw an error when the registry is valid", func() {
+			warnings, err := v.ValidateCreate(ctx, provider)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should not throw an error when the source includes a hostname", func() {
+			provider.Spec.Registry.Source = "registry.terraform.io/cloudflare/cloudflare"
+
+			warnings, err := v.ValidateCreate(ctx, provider)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should throw an error when the provider name is invalid", func() {
+			provider.Spec.Provider = "Cloudflare"
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.provider: must be a valid terraform provider name, i.e. lowercase alphanumeric and hyphens"))
+		})
+
+		It("should throw an error when the source is missing", func() {
+			provider.Spec.Registry.Source = ""
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.registry.source: source is required"))
+		})
+
+		It("should throw an error when the source is malformed", func() {
+			provider.Spec.Registry.Source = "cloudflare"
+
+			_, err := v.ValidateUpdate(ctx, nil, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.registry.source: must be in the format [hostname/]namespace/type"))
+		})
+
+		It("should throw an error when the version constraint is invalid", func() {
+			provider.Spec.Registry.Version = "not a version"
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.registry.version: invalid version constraint"))
+		})
+	})
+
+	When("creating a provider with federated credentials", func() {
+		var provider *terraformv1alpha1.Provider
+
+		BeforeEach(func() {
+			provider = fixtures.NewValidAWSProvider(name, nil)
+			provider.Spec.SecretRef = nil
+			provider.Spec.Source = terraformv1alpha1.SourceFederated
+			provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{
+				RoleARN: "arn:aws:iam::123456789012:role/terraform",
+			}
+		})
+
+		It("should not throw an error when the federation is valid", func() {
+			warnings, err := v.ValidateCreate(ctx, provider)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should throw an error when no federation is defined", func() {
+			provider.Spec.Federation = nil
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.federation: federation is required when source is federated"))
+		})
+
+		It("should throw an error when the aws role is missing", func() {
+			provider.Spec.Federation.RoleARN = ""
+
+			_, err := v.ValidateUpdate(ctx, nil, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.federation.roleARN: roleARN is required for aws providers"))
+		})
+
+		It("should throw an error when the aws role is invalid", func() {
+			provider.Spec.Federation.RoleARN = "terraform"
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.federation.roleARN: must be a valid role arn"))
+		})
+
+		It("should throw an error when the azure tenant is missing", func() {
+			provider.Spec.Provider = terraformv1alpha1.AzureProviderType
+			provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{ClientID: "client"}
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.federation.tenantID: tenantID is required for azure providers"))
+		})
+
+		It("should throw an error when the google workload identity provider is invalid", func() {
+			provider.Spec.Provider = terraformv1alpha1.GCPProviderType
+			provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{WorkloadIdentityProvider: "pool"}
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.federation.workloadIdentityProvider: must be in the format"))
+		})
+
+		It("should throw an error when the provider type is not supported", func() {
+			provider.Spec.Provider = terraformv1alpha1.VaultProviderType
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.federation: federated credentials are only supported for aws, azurerm, azuread and google providers"))
+		})
+
+		It("should throw an error when federation is used with another source", func() {
+			provider.Spec.Source = terraformv1alpha1.SourceInjected
+			provider.Spec.ServiceAccount = pointer.String("terranetes-executor")
+
+			_, err := v.ValidateCreate(ctx, provider)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("spec.federation: can only be used when source is federated"))
+		})
+	})
+
 	When("creating a provider with default annotation defined", func() {
 		var provider *terraformv1alpha1.Provider
 
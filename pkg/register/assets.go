@@ -143,6 +143,72 @@ spec:
                       type: string
                   type: object
                   x-kubernetes-map-type: atomic
+                dependsOn:
+                  description: |-
+                    DependsOn is a collection of configurations in the same namespace which must be ready
+                    before this cloud resource is planned. Outputs from the dependencies can be mapped to
+                    variables, and a change to those outputs will trigger a new plan.
+                  items:
+                    description: |-
+                      Dependency is a reference to a configuration which must be ready before this configuration
+                      is run, along with the outputs which should be passed in as variables
+                    properties:
+                      name:
+                        description: |-
+                          Name is the name of the configuration, in the same namespace, which this configuration
+                          depends on
+                        type: string
+                      outputs:
+                        description: |-
+                          Outputs is a collection of terraform outputs from the dependency which are passed into
+                          this configuration as variables
+                        items:
+                          description: DependencyOutput maps a terraform output of a dependency to a variable
+                          properties:
+                            key:
+                              description: Key is the name of the terraform output on the dependency
+                              type: string
+                            name:
+                              description: |-
+                                Name is the name of the variable the output is passed in as. If not set the key is
+                                used as the name of the variable
+                              type: string
+                            optional:
+                              description: |-
+                                Optional indicates the output may not exist on the dependency, in which case the
+                                variable is not set
+                              type: boolean
+                          required:
+                            - key
+                          type: object
+                        type: array
+                    required:
+                      - name
+                    type: object
+                  type: array
+                driftPolicy:
+                  description: |-
+                    DriftPolicy controls what happens when drift is detected and how often drift detection
+                    runs. Note this requires enableDriftDetection to be set.
+                  properties:
+                    interval:
+                      description: |-
+                        Interval is the minimum time between drift detection runs on this configuration,
+                        overriding the interval configured on the controller
+                      type: string
+                    mode:
+                      default: Detect
+                      description: |-
+                        Mode is the action taken when drift is detected. Detect only records the drift on the
+                        status, Remediate applies the plan to bring the resources back in line with the
+                        configuration, and RequireApproval waits for the drift approval annotation before
+                        applying the plan.
+                      enum:
+                        - Detect
+                        - Remediate
+                        - RequireApproval
+                      type: string
+                  type: object
                 enableAutoApproval:
                   description: |-
                     EnableAutoApproval when enabled indicates the configuration does not need to be
@@ -155,6 +221,15 @@ spec:
                     for any drift between the expected and current state. If any drift is detected the
                     status is changed and a kubernetes event raised.
                   type: boolean
+                engine:
+                  description: |-
+                    Engine is the engine used to run the terraform module, either terraform or tofu (OpenTofu).
+                    When not set the engine defined on the provider is used, falling back to the default
+                    engine of the controller.
+                  enum:
+                    - terraform
+                    - tofu
+                  type: string
                 plan:
                   description: |-
                     Plan is the reference to the plan which this cloud resource is associated with. This
@@ -175,6 +250,12 @@ spec:
                     ProviderRef is the reference to the provider which should be used to execute this
                     configuration.
                   properties:
+                    alias:
+                      description: |-
+                        Alias is an optional alias for the provider, rendered as the alias of the provider block
+                        in the terraform code. This is only used by the additional providers in spec.providers,
+                        and is required when more than one provider of the same type is used.
+                      type: string
                     name:
                       description: |-
                         Name is the name of the provider which contains the credentials to use for this
@@ -186,11 +267,40 @@ spec:
                   required:
                     - name
                   type: object
+                providers:
+                  description: |-
+                    Providers is a list of additional providers used by the terraform module. When defined
+                    these override the additional providers defined in the revision.
+                  items:
+                    description: |-
+                      ProviderReference is the reference to the provider which is used to create
+                      the configuration
+                    properties:
+                      alias:
+                        description: |-
+                          Alias is an optional alias for the provider, rendered as the alias of the provider block
+                          in the terraform code. This is only used by the additional providers in spec.providers,
+                          and is required when more than one provider of the same type is used.
+                        type: string
+                      name:
+                        description: |-
+                          Name is the name of the provider which contains the credentials to use for this
+                          configuration.
+                        type: string
+                      namespace:
+                        description: Namespace is the namespace of the provider itself.
+                        type: string
+                    required:
+                      - name
+                    type: object
+                  type: array
                 terraformVersion:
                   description: |-
                     TerraformVersion provides the ability to override the default terraform version. Before
                     changing this field its best to consult with platform administrator. As the
-                    value of this field is used to change the tag of the terraform container image.
+                    value of this field is used to change the tag of the terraform container image. The
+                    value can also be a version constraint, i.e. "~> 1.6", which is resolved to the newest
+                    version permitted by the controller satisfying the constraint.
                   type: string
                 valueFrom:
                   description: |-
@@ -339,6 +449,24 @@ spec:
                 configurationStatus:
                   description: Configuration is the state taken from the underlying configuration
                   properties:
+                    backend:
+                      description: Backend is the status of the backend holding the terraform state
+                      properties:
+                        checksum:
+                          description: Checksum is a checksum of the backend configuration the state currently resides in
+                          type: string
+                        pendingChecksum:
+                          description: |-
+                            PendingChecksum is the checksum of a changed backend configuration the state has not
+                            yet been migrated to
+                          type: string
+                        pendingType:
+                          description: PendingType is the type of the backend the state is waiting to be migrated to
+                          type: string
+                        type:
+                          description: Type is the type of backend the state currently resides in
+                          type: string
+                      type: object
                     conditions:
                       description: Conditions represents the observations of the resource's current state.
                       items:
@@ -411,6 +539,11 @@ spec:
                       x-kubernetes-list-map-keys:
                         - type
                       x-kubernetes-list-type: map
+                    contexts:
+                      description: |-
+                        Contexts is a checksum of the values consumed from contexts when the configuration
+                        was last applied
+                      type: string
                     costs:
                       description: |-
                         Costs is the predicted costs of this configuration. Note this field is only populated
@@ -428,9 +561,125 @@ spec:
                           description: Monthly is the monthly estimated cost of the configuration
                           type: string
                       type: object
+                    dependencies:
+                      description: |-
+                        Dependencies is a checksum of the outputs consumed from the dependencies when the
+                        configuration was last applied
+                      type: string
                     driftTimestamp:
                       description: DriftTimestamp is the timestamp of the last drift detection
                       type: string
+                    engine:
+                      description: Engine is the engine, either terraform or tofu, which was last used to run this configuration
+                      type: string
+                    history:
+                      description: |-
+                        History is a record of the most recent terraform runs against the configuration, ordered
+                        from oldest to newest
+                      items:
+                        description: RunHistory is a record of a terraform run against the configuration
+                        properties:
+                          arguments:
+                            description: |-
+                              Arguments are the additional terraform arguments the run was executed with, i.e. the
+                              replace or target operations requested
+                            type: string
+                          changes:
+                            description: Changes is a summary of the resource changes planned or applied by the run
+                            properties:
+                              create:
+                                description: Create is the number of resources which will be created
+                                type: integer
+                              delete:
+                                description: Delete is the number of resources which will be deleted
+                                type: integer
+                              import:
+                                description: Import is the number of existing resources which will be imported
+                                type: integer
+                              replace:
+                                description: Replace is the number of resources which will be destroyed and recreated
+                                type: integer
+                              resources:
+                                description: |-
+                                  Resources is a list of the resources which will be changed by the plan, limited to the
+                                  first 100 resources by address
+                                items:
+                                  description: TerraformPlanResourceChange is a change to a single resource in the terraform plan
+                                  properties:
+                                    action:
+                                      description: |-
+                                        Action is the change which will be made to the resource, i.e. create, update, delete,
+                                        replace or import
+                                      type: string
+                                    address:
+                                      description: Address is the terraform address of the resource
+                                      type: string
+                                  required:
+                                    - action
+                                    - address
+                                  type: object
+                                type: array
+                              truncated:
+                                description: Truncated is the number of changed resources omitted from the list of resources
+                                type: integer
+                              update:
+                                description: Update is the number of resources which will be updated in place
+                                type: integer
+                            type: object
+                          completionTime:
+                            description: CompletionTime is the time the run finished
+                            format: date-time
+                            type: string
+                          costDelta:
+                            description: CostDelta is the predicted change to the monthly cost introduced by the run
+                            type: string
+                          generation:
+                            description: Generation is the generation of the configuration the run was executed for
+                            format: int64
+                            type: integer
+                          job:
+                            description: Job is the name of the job which executed the run
+                            type: string
+                          reason:
+                            description: Reason is the reason the run was triggered
+                            type: string
+                          result:
+                            description: Result is the outcome of the run
+                            type: string
+                          stage:
+                            description: Stage is the terraform stage executed by the run
+                            type: string
+                          startTime:
+                            description: StartTime is the time the run started
+                            format: date-time
+                            type: string
+                        required:
+                          - generation
+                          - job
+                          - result
+                          - stage
+                        type: object
+                      type: array
+                    imported:
+                      description: |-
+                        Imported is the collection of imports from the spec which have been applied to the
+                        terraform state
+                      items:
+                        description: Import is an existing resource which should be imported into the terraform state
+                        properties:
+                          address:
+                            description: |-
+                              Address is the terraform address of the resource within the module the existing
+                              resource is imported to, i.e. aws_s3_bucket.this
+                            type: string
+                          id:
+                            description: ID is the provider specific identifier of the existing resource
+                            type: string
+                        required:
+                          - address
+                          - id
+                        type: object
+                      type: array
                     lastReconcile:
                       description: LastReconcile describes the generation and time of the last reconciliation
                       properties:
@@ -457,6 +706,11 @@ spec:
                           format: date-time
                           type: string
                       type: object
+                    resolvedTerraformVersion:
+                      description: |-
+                        ResolvedTerraformVersion is the version of terraform, or opentofu, resolved from the
+                        spec.terraformVersion and the policy constraints, i.e. the image tag the jobs are run with
+                      type: string
                     resourceStatus:
                       description: |-
                         ResourceStatus indicates the status of the resources and if the resources are insync with the
@@ -467,10 +721,132 @@ spec:
                         Resources is the number of managed cloud resources which are currently under management.
                         This field is taken from the terraform state itself.
                       type: integer
+                    source:
+                      description: |-
+                        Source is the revision of the module source resolved during the last terraform plan. The
+                        later stages use this revision to ensure they run against the same module code
+                      properties:
+                        digest:
+                          description: |-
+                            Digest is the sha256 digest of the module content, used to verify the module and as
+                            the key within the source cache
+                          type: string
+                        generation:
+                          description: Generation is the generation of the configuration the source was resolved for
+                          format: int64
+                          type: integer
+                        module:
+                          description: Module is the module source which was resolved
+                          type: string
+                        revision:
+                          description: |-
+                            Revision is the commit the module source resolved to for git sources, or the digest of
+                            the manifest for oci sources
+                          type: string
+                      type: object
+                    stateCommand:
+                      description: StateCommand is the status of the last terraform state command run against the configuration
+                      properties:
+                        command:
+                          description: Command is the state command which was run, i.e. mv aws_instance.a aws_instance.b
+                          type: string
+                        id:
+                          description: ID is the unique identifier of the request for the state command
+                          type: string
+                        job:
+                          description: Job is the name of the job which ran the state command
+                          type: string
+                        result:
+                          description: Result is the outcome of the state command
+                          type: string
+                      required:
+                        - command
+                        - id
+                      type: object
+                    terraformPlan:
+                      description: |-
+                        TerraformPlan is the status of the last terraform plan produced for this configuration. This
+                        is the plan which is applied during the apply stage.
+                      properties:
+                        approvedChecksum:
+                          description: |-
+                            ApprovedChecksum is the checksum of the terraform plan which was approved to be applied. An
+                            approval is bound to the plan it was given for, any later plan must be approved again
+                          type: string
+                        changes:
+                          description: Changes is a summary of the resource changes contained in the terraform plan
+                          properties:
+                            create:
+                              description: Create is the number of resources which will be created
+                              type: integer
+                            delete:
+                              description: Delete is the number of resources which will be deleted
+                              type: integer
+                            import:
+                              description: Import is the number of existing resources which will be imported
+                              type: integer
+                            replace:
+                              description: Replace is the number of resources which will be destroyed and recreated
+                              type: integer
+                            resources:
+                              description: |-
+                                Resources is a list of the resources which will be changed by the plan, limited to the
+                                first 100 resources by address
+                              items:
+                                description: TerraformPlanResourceChange is a change to a single resource in the terraform plan
+                                properties:
+                                  action:
+                                    description: |-
+                                      Action is the change which will be made to the resource, i.e. create, update, delete,
+                                      replace or import
+                                    type: string
+                                  address:
+                                    description: Address is the terraform address of the resource
+                                    type: string
+                                required:
+                                  - action
+                                  - address
+                                type: object
+                              type: array
+                            truncated:
+                              description: Truncated is the number of changed resources omitted from the list of resources
+                              type: integer
+                            update:
+                              description: Update is the number of resources which will be updated in place
+                              type: integer
+                          type: object
+                        checksum:
+                          description: |-
+                            Checksum is the sha256 checksum of the terraform plan produced by the plan stage. The
+                            apply stage will refuse to apply a plan which does not match this checksum
+                          type: string
+                        contexts:
+                          description: |-
+                            Contexts is a checksum of the values consumed from contexts when the terraform plan
+                            was produced
+                          type: string
+                        dependencies:
+                          description: |-
+                            Dependencies is a checksum of the outputs consumed from the dependencies when the
+                            terraform plan was produced
+                          type: string
+                        generation:
+                          description: Generation is the generation of the configuration the plan was produced for
+                          format: int64
+                          type: integer
+                        job:
+                          description: Job is the name of the job which produced the terraform plan
+                          type: string
+                        operation:
+                          description: |-
+                            Operation is a checksum of the one-shot operation, i.e. the replace and target
+                            annotations, the terraform plan was produced with
+                          type: string
+                      type: object
                     terraformVersion:
                       description: |-
-                        TerraformVersion is the version of terraform which was last used to run this
-                        configuration
+                        TerraformVersion is the version of terraform, or opentofu, which last wrote the terraform
+                        state. This field is taken from the terraform state itself.
                       type: string
                   type: object
                 costs:
@@ -576,6 +952,10 @@ spec:
           name: Plan
           priority: 1
           type: string
+        - jsonPath: .status.engine
+          name: Engine
+          priority: 1
+          type: string
         - jsonPath: .spec.writeConnectionSecretToRef.name
           name: Secret
           type: string
@@ -620,7 +1000,8 @@ spec:
                   description: |-
                     Auth is used to configure any options required when the source of the terraform
                     module is private or requires credentials to retrieve. This could be SSH keys or git
-                    user/pass or AWS credentials for an s3 bucket.
+                    user/pass, AWS credentials for an s3 bucket or OCI_USERNAME and OCI_PASSWORD for an
+                    oci registry.
                   properties:
                     name:
                       description: name is unique within a namespace to reference a secret resource.
@@ -630,6 +1011,72 @@ spec:
                       type: string
                   type: object
                   x-kubernetes-map-type: atomic
+                dependsOn:
+                  description: |-
+                    DependsOn is a collection of configurations in the same namespace which must be ready
+                    before this configuration is planned. Outputs from the dependencies can be mapped to
+                    variables, and a change to those outputs will trigger a new plan.
+                  items:
+                    description: |-
+                      Dependency is a reference to a configuration which must be ready before this configuration
+                      is run, along with the outputs which should be passed in as variables
+                    properties:
+                      name:
+                        description: |-
+                          Name is the name of the configuration, in the same namespace, which this configuration
+                          depends on
+                        type: string
+                      outputs:
+                        description: |-
+                          Outputs is a collection of terraform outputs from the dependency which are passed into
+                          this configuration as variables
+                        items:
+                          description: DependencyOutput maps a terraform output of a dependency to a variable
+                          properties:
+                            key:
+                              description: Key is the name of the terraform output on the dependency
+                              type: string
+                            name:
+                              description: |-
+                                Name is the name of the variable the output is passed in as. If not set the key is
+                                used as the name of the variable
+                              type: string
+                            optional:
+                              description: |-
+                                Optional indicates the output may not exist on the dependency, in which case the
+                                variable is not set
+                              type: boolean
+                          required:
+                            - key
+                          type: object
+                        type: array
+                    required:
+                      - name
+                    type: object
+                  type: array
+                driftPolicy:
+                  description: |-
+                    DriftPolicy controls what happens when drift is detected and how often drift detection
+                    runs. Note this requires enableDriftDetection to be set.
+                  properties:
+                    interval:
+                      description: |-
+                        Interval is the minimum time between drift detection runs on this configuration,
+                        overriding the interval configured on the controller
+                      type: string
+                    mode:
+                      default: Detect
+                      description: |-
+                        Mode is the action taken when drift is detected. Detect only records the drift on the
+                        status, Remediate applies the plan to bring the resources back in line with the
+                        configuration, and RequireApproval waits for the drift approval annotation before
+                        applying the plan.
+                      enum:
+                        - Detect
+                        - Remediate
+                        - RequireApproval
+                      type: string
+                  type: object
                 enableAutoApproval:
                   description: |-
                     EnableAutoApproval when enabled indicates the configuration does not need to be
@@ -642,6 +1089,36 @@ spec:
                     for any drift between the expected and current state. If any drift is detected the
                     status is changed and a kubernetes event raised.
                   type: boolean
+                engine:
+                  description: |-
+                    Engine is the engine used to run the terraform module, either terraform or tofu (OpenTofu).
+                    When not set the engine defined on the provider is used, falling back to the default
+                    engine of the controller.
+                  enum:
+                    - terraform
+                    - tofu
+                  type: string
+                imports:
+                  description: |-
+                    Imports is a collection of existing resources which should be imported into the state
+                    of the configuration. The imports are rendered as terraform import blocks, shown in the
+                    plan and recorded on the status once applied. Note this requires terraform 1.5 or above.
+                  items:
+                    description: Import is an existing resource which should be imported into the terraform state
+                    properties:
+                      address:
+                        description: |-
+                          Address is the terraform address of the resource within the module the existing
+                          resource is imported to, i.e. aws_s3_bucket.this
+                        type: string
+                      id:
+                        description: ID is the provider specific identifier of the existing resource
+                        type: string
+                    required:
+                      - address
+                      - id
+                    type: object
+                  type: array
                 module:
                   description: |-
                     Module is the URL to the source of the terraform module. The format of the URL is
@@ -668,6 +1145,12 @@ spec:
                     ProviderRef is the reference to the provider which should be used to execute this
                     configuration.
                   properties:
+                    alias:
+                      description: |-
+                        Alias is an optional alias for the provider, rendered as the alias of the provider block
+                        in the terraform code. This is only used by the additional providers in spec.providers,
+                        and is required when more than one provider of the same type is used.
+                      type: string
                     name:
                       description: |-
                         Name is the name of the provider which contains the credentials to use for this
@@ -679,11 +1162,42 @@ spec:
                   required:
                     - name
                   type: object
+                providers:
+                  description: |-
+                    Providers is a list of additional providers used by the terraform module, i.e. a
+                    kubernetes or vault provider alongside the cloud provider, or the same provider in a
+                    different region via an alias. Each provider is rendered into the terraform code and the
+                    credentials of each are made available to the terraform jobs.
+                  items:
+                    description: |-
+                      ProviderReference is the reference to the provider which is used to create
+                      the configuration
+                    properties:
+                      alias:
+                        description: |-
+                          Alias is an optional alias for the provider, rendered as the alias of the provider block
+                          in the terraform code. This is only used by the additional providers in spec.providers,
+                          and is required when more than one provider of the same type is used.
+                        type: string
+                      name:
+                        description: |-
+                          Name is the name of the provider which contains the credentials to use for this
+                          configuration.
+                        type: string
+                      namespace:
+                        description: Namespace is the namespace of the provider itself.
+                        type: string
+                    required:
+                      - name
+                    type: object
+                  type: array
                 terraformVersion:
                   description: |-
                     TerraformVersion provides the ability to override the default terraform version. Before
                     changing this field its best to consult with platform administrator. As the
-                    value of this field is used to change the tag of the terraform container image.
+                    value of this field is used to change the tag of the terraform container image. The
+                    value can also be a version constraint, i.e. "~> 1.6", which is resolved to the newest
+                    version permitted by the controller satisfying the constraint.
                   type: string
                 valueFrom:
                   description: |-
@@ -754,6 +1268,24 @@ spec:
             status:
               description: ConfigurationStatus defines the observed state of a terraform
               properties:
+                backend:
+                  description: Backend is the status of the backend holding the terraform state
+                  properties:
+                    checksum:
+                      description: Checksum is a checksum of the backend configuration the state currently resides in
+                      type: string
+                    pendingChecksum:
+                      description: |-
+                        PendingChecksum is the checksum of a changed backend configuration the state has not
+                        yet been migrated to
+                      type: string
+                    pendingType:
+                      description: PendingType is the type of the backend the state is waiting to be migrated to
+                      type: string
+                    type:
+                      description: Type is the type of backend the state currently resides in
+                      type: string
+                  type: object
                 conditions:
                   description: Conditions represents the observations of the resource's current state.
                   items:
@@ -826,6 +1358,11 @@ spec:
                   x-kubernetes-list-map-keys:
                     - type
                   x-kubernetes-list-type: map
+                contexts:
+                  description: |-
+                    Contexts is a checksum of the values consumed from contexts when the configuration
+                    was last applied
+                  type: string
                 costs:
                   description: |-
                     Costs is the predicted costs of this configuration. Note this field is only populated
@@ -843,53 +1380,296 @@ spec:
                       description: Monthly is the monthly estimated cost of the configuration
                       type: string
                   type: object
+                dependencies:
+                  description: |-
+                    Dependencies is a checksum of the outputs consumed from the dependencies when the
+                    configuration was last applied
+                  type: string
                 driftTimestamp:
                   description: DriftTimestamp is the timestamp of the last drift detection
                   type: string
-                lastReconcile:
-                  description: LastReconcile describes the generation and time of the last reconciliation
-                  properties:
-                    generation:
-                      description: Generation is the generation reconciled on the last reconciliation
-                      format: int64
-                      type: integer
-                    time:
-                      description: Time is the last time the resource was reconciled
-                      format: date-time
-                      type: string
-                  type: object
-                lastSuccess:
-                  description: |-
-                    LastSuccess descibes the generation and time of the last reconciliation which resulted in
-                    a Success status
-                  properties:
-                    generation:
-                      description: Generation is the generation reconciled on the last reconciliation
-                      format: int64
-                      type: integer
-                    time:
-                      description: Time is the last time the resource was reconciled
-                      format: date-time
-                      type: string
-                  type: object
-                resourceStatus:
-                  description: |-
-                    ResourceStatus indicates the status of the resources and if the resources are insync with the
-                    configuration
+                engine:
+                  description: Engine is the engine, either terraform or tofu, which was last used to run this configuration
                   type: string
-                resources:
-                  description: |-
-                    Resources is the number of managed cloud resources which are currently under management.
-                    This field is taken from the terraform state itself.
-                  type: integer
-                terraformVersion:
+                history:
                   description: |-
-                    TerraformVersion is the version of terraform which was last used to run this
-                    configuration
-                  type: string
-              type: object
-          type: object
-      served: true
+                    History is a record of the most recent terraform runs against the configuration, ordered
+                    from oldest to newest
+                  items:
+                    description: RunHistory is a record of a terraform run against the configuration
+                    properties:
+                      arguments:
+                        description: |-
+                          Arguments are the additional terraform arguments the run was executed with, i.e. the
+                          replace or target operations requested
+                        type: string
+                      changes:
+                        description: Changes is a summary of the resource changes planned or applied by the run
+                        properties:
+                          create:
+                            description: Create is the number of resources which will be created
+                            type: integer
+                          delete:
+                            description: Delete is the number of resources which will be deleted
+                            type: integer
+                          import:
+                            description: Import is the number of existing resources which will be imported
+                            type: integer
+                          replace:
+                            description: Replace is the number of resources which will be destroyed and recreated
+                            type: integer
+                          resources:
+                            description: |-
+                              Resources is a list of the resources which will be changed by the plan, limited to the
+                              first 100 resources by address
+                            items:
+                              description: TerraformPlanResourceChange is a change to a single resource in the terraform plan
+                              properties:
+                                action:
+                                  description: |-
+                                    Action is the change which will be made to the resource, i.e. create, update, delete,
+                                    replace or import
+                                  type: string
+                                address:
+                                  description: Address is the terraform address of the resource
+                                  type: string
+                              required:
+                                - action
+                                - address
+                              type: object
+                            type: array
+                          truncated:
+                            description: Truncated is the number of changed resources omitted from the list of resources
+                            type: integer
+                          update:
+                            description: Update is the number of resources which will be updated in place
+                            type: integer
+                        type: object
+                      completionTime:
+                        description: CompletionTime is the time the run finished
+                        format: date-time
+                        type: string
+                      costDelta:
+                        description: CostDelta is the predicted change to the monthly cost introduced by the run
+                        type: string
+                      generation:
+                        description: Generation is the generation of the configuration the run was executed for
+                        format: int64
+                        type: integer
+                      job:
+                        description: Job is the name of the job which executed the run
+                        type: string
+                      reason:
+                        description: Reason is the reason the run was triggered
+                        type: string
+                      result:
+                        description: Result is the outcome of the run
+                        type: string
+                      stage:
+                        description: Stage is the terraform stage executed by the run
+                        type: string
+                      startTime:
+                        description: StartTime is the time the run started
+                        format: date-time
+                        type: string
+                    required:
+                      - generation
+                      - job
+                      - result
+                      - stage
+                    type: object
+                  type: array
+                imported:
+                  description: |-
+                    Imported is the collection of imports from the spec which have been applied to the
+                    terraform state
+                  items:
+                    description: Import is an existing resource which should be imported into the terraform state
+                    properties:
+                      address:
+                        description: |-
+                          Address is the terraform address of the resource within the module the existing
+                          resource is imported to, i.e. aws_s3_bucket.this
+                        type: string
+                      id:
+                        description: ID is the provider specific identifier of the existing resource
+                        type: string
+                    required:
+                      - address
+                      - id
+                    type: object
+                  type: array
+                lastReconcile:
+                  description: LastReconcile describes the generation and time of the last reconciliation
+                  properties:
+                    generation:
+                      description: Generation is the generation reconciled on the last reconciliation
+                      format: int64
+                      type: integer
+                    time:
+                      description: Time is the last time the resource was reconciled
+                      format: date-time
+                      type: string
+                  type: object
+                lastSuccess:
+                  description: |-
+                    LastSuccess descibes the generation and time of the last reconciliation which resulted in
+                    a Success status
+                  properties:
+                    generation:
+                      description: Generation is the generation reconciled on the last reconciliation
+                      format: int64
+                      type: integer
+                    time:
+                      description: Time is the last time the resource was reconciled
+                      format: date-time
+                      type: string
+                  type: object
+                resolvedTerraformVersion:
+                  description: |-
+                    ResolvedTerraformVersion is the version of terraform, or opentofu, resolved from the
+                    spec.terraformVersion and the policy constraints, i.e. the image tag the jobs are run with
+                  type: string
+                resourceStatus:
+                  description: |-
+                    ResourceStatus indicates the status of the resources and if the resources are insync with the
+                    configuration
+                  type: string
+                resources:
+                  description: |-
+                    Resources is the number of managed cloud resources which are currently under management.
+                    This field is taken from the terraform state itself.
+                  type: integer
+                source:
+                  description: |-
+                    Source is the revision of the module source resolved during the last terraform plan. The
+                    later stages use this revision to ensure they run against the same module code
+                  properties:
+                    digest:
+                      description: |-
+                        Digest is the sha256 digest of the module content, used to verify the module and as
+                        the key within the source cache
+                      type: string
+                    generation:
+                      description: Generation is the generation of the configuration the source was resolved for
+                      format: int64
+                      type: integer
+                    module:
+                      description: Module is the module source which was resolved
+                      type: string
+                    revision:
+                      description: |-
+                        Revision is the commit the module source resolved to for git sources, or the digest of
+                        the manifest for oci sources
+                      type: string
+                  type: object
+                stateCommand:
+                  description: StateCommand is the status of the last terraform state command run against the configuration
+                  properties:
+                    command:
+                      description: Command is the state command which was run, i.e. mv aws_instance.a aws_instance.b
+                      type: string
+                    id:
+                      description: ID is the unique identifier of the request for the state command
+                      type: string
+                    job:
+                      description: Job is the name of the job which ran the state command
+                      type: string
+                    result:
+                      description: Result is the outcome of the state command
+                      type: string
+                  required:
+                    - command
+                    - id
+                  type: object
+                terraformPlan:
+                  description: |-
+                    TerraformPlan is the status of the last terraform plan produced for this configuration. This
+                    is the plan which is applied during the apply stage.
+                  properties:
+                    approvedChecksum:
+                      description: |-
+                        ApprovedChecksum is the checksum of the terraform plan which was approved to be applied. An
+                        approval is bound to the plan it was given for, any later plan must be approved again
+                      type: string
+                    changes:
+                      description: Changes is a summary of the resource changes contained in the terraform plan
+                      properties:
+                        create:
+                          description: Create is the number of resources which will be created
+                          type: integer
+                        delete:
+                          description: Delete is the number of resources which will be deleted
+                          type: integer
+                        import:
+                          description: Import is the number of existing resources which will be imported
+                          type: integer
+                        replace:
+                          description: Replace is the number of resources which will be destroyed and recreated
+                          type: integer
+                        resources:
+                          description: |-
+                            Resources is a list of the resources which will be changed by the plan, limited to the
+                            first 100 resources by address
+                          items:
+                            description: TerraformPlanResourceChange is a change to a single resource in the terraform plan
+                            properties:
+                              action:
+                                description: |-
+                                  Action is the change which will be made to the resource, i.e. create, update, delete,
+                                  replace or import
+                                type: string
+                              address:
+                                description: Address is the terraform address of the resource
+                                type: string
+                            required:
+                              - action
+                              - address
+                            type: object
+                          type: array
+                        truncated:
+                          description: Truncated is the number of changed resources omitted from the list of resources
+                          type: integer
+                        update:
+                          description: Update is the number of resources which will be updated in place
+                          type: integer
+                      type: object
+                    checksum:
+                      description: |-
+                        Checksum is the sha256 checksum of the terraform plan produced by the plan stage. The
+                        apply stage will refuse to apply a plan which does not match this checksum
+                      type: string
+                    contexts:
+                      description: |-
+                        Contexts is a checksum of the values consumed from contexts when the terraform plan
+                        was produced
+                      type: string
+                    dependencies:
+                      description: |-
+                        Dependencies is a checksum of the outputs consumed from the dependencies when the
+                        terraform plan was produced
+                      type: string
+                    generation:
+                      description: Generation is the generation of the configuration the plan was produced for
+                      format: int64
+                      type: integer
+                    job:
+                      description: Job is the name of the job which produced the terraform plan
+                      type: string
+                    operation:
+                      description: |-
+                        Operation is a checksum of the one-shot operation, i.e. the replace and target
+                        annotations, the terraform plan was produced with
+                      type: string
+                  type: object
+                terraformVersion:
+                  description: |-
+                    TerraformVersion is the version of terraform, or opentofu, which last wrote the terraform
+                    state. This field is taken from the terraform state itself.
+                  type: string
+              type: object
+          type: object
+      served: true
       storage: true
       subresources:
         status: {}
@@ -1070,6 +1850,46 @@ spec:
                       format: date-time
                       type: string
                   type: object
+                unused:
+                  description: Unused is a list of the variables in the context which are not consumed by any resource
+                  items:
+                    type: string
+                  type: array
+                variables:
+                  description: Variables is the usage of the variables consumed from the context
+                  items:
+                    description: ContextVariableStatus is the usage of a variable from the context
+                    properties:
+                      consumers:
+                        description: Consumers is a list of resources consuming the variable
+                        items:
+                          description: ContextConsumer is a resource consuming a variable from the context
+                          properties:
+                            kind:
+                              description: Kind is the kind of the resource, i.e. Configuration or CloudResource
+                              type: string
+                            name:
+                              description: Name is the name of the resource
+                              type: string
+                            namespace:
+                              description: Namespace is the namespace of the resource
+                              type: string
+                            optional:
+                              description: Optional indicates the resource does not require the variable to be present
+                              type: boolean
+                          required:
+                            - kind
+                            - name
+                            - namespace
+                          type: object
+                        type: array
+                      name:
+                        description: Name is the name of the variable
+                        type: string
+                    required:
+                      - name
+                    type: object
+                  type: array
               type: object
           type: object
       served: true
@@ -1394,9 +2214,666 @@ spec:
                                 x-kubernetes-map-type: atomic
                               url:
                                 description: |-
-                                  URL is the source external checks - this is usually a git repository. The notation
-                                  for this is https://github.com/hashicorp/go-getter
+                                  URL is the source external checks - this is usually a git repository. The notation
+                                  for this is https://github.com/hashicorp/go-getter
+                                type: string
+                            type: object
+                          type: array
+                        selector:
+                          description: |-
+                            Selector is the selector on the namespace or labels on the configuration. By leaving this
+                            fields empty you can implicitly selecting all configurations.
+                          properties:
+                            namespace:
+                              description: |-
+                                Namespace is used to filter a configuration based on the namespace labels of
+                                where it exists
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            resource:
+                              description: Resource provides the ability to filter a configuration based on it's labels
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                          type: object
+                        skipChecks:
+                          description: |-
+                            SkipChecks is a collection of checkov checks which you can defined as skipped. The security
+                            scan will ignore any failures on these checks.
+                          items:
+                            type: string
+                          type: array
+                        source:
+                          description: Source indicates an external source for the checkov configurations
+                          properties:
+                            configuration:
+                              description: Configuration is the configuration to use within the source directory
+                              type: string
+                            secretRef:
+                              description: |-
+                                SecretRef is reference to secret which contains environment variables used by the source
+                                command to retrieve the code. This could be cloud credentials, ssh keys, git username
+                                and password etc
+                              properties:
+                                name:
+                                  description: name is unique within a namespace to reference a secret resource.
+                                  type: string
+                                namespace:
+                                  description: namespace defines the space within which the secret name must be unique.
+                                  type: string
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            url:
+                              description: |-
+                                URL is the source external checks - this is usually a git repository. The notation
+                                for this is https://github.com/hashicorp/go-getter
+                              type: string
+                          type: object
+                      type: object
+                    costs:
+                      description: |-
+                        Costs provides the ability to place a budget on the predicted costs of the configurations.
+                        Configurations which breach the budget are blocked from applying. Note, this requires the
+                        cost integration to be enabled on the controller.
+                      properties:
+                        maxMonthly:
+                          anyOf:
+                            - type: integer
+                            - type: string
+                          description: MaxMonthly is the maximum predicted monthly cost permitted for a single configuration
+                          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                          x-kubernetes-int-or-string: true
+                        maxMonthlyIncrease:
+                          anyOf:
+                            - type: integer
+                            - type: string
+                          description: |-
+                            MaxMonthlyIncrease is the maximum increase in the predicted monthly cost which a single
+                            change to a configuration is permitted to introduce
+                          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                          x-kubernetes-int-or-string: true
+                        maxNamespaceMonthly:
+                          anyOf:
+                            - type: integer
+                            - type: string
+                          description: |-
+                            MaxNamespaceMonthly is the maximum predicted monthly cost of all the configurations
+                            within a namespace combined
+                          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                          x-kubernetes-int-or-string: true
+                        selector:
+                          description: |-
+                            Selector is the selector on the namespace or labels on the configuration. By leaving this
+                            fields empty you can implicitly selecting all configurations.
+                          properties:
+                            namespace:
+                              description: |-
+                                Namespace is used to filter a configuration based on the namespace labels of
+                                where it exists
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            resource:
+                              description: Resource provides the ability to filter a configuration based on it's labels
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                          type: object
+                      type: object
+                    maintenance:
+                      description: |-
+                        Maintenance provides the ability to restrict when the selected configurations are
+                        permitted to apply changes or run drift detection. Outside of the windows any applies
+                        are deferred until the next window opens.
+                      properties:
+                        selector:
+                          description: |-
+                            Selector is the selector on the namespace or labels on the configuration. By leaving this
+                            field empty you are implicitly selecting all configurations.
+                          properties:
+                            namespace:
+                              description: |-
+                                Namespace is used to filter a configuration based on the namespace labels of
+                                where it exists
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            resource:
+                              description: Resource provides the ability to filter a configuration based on it's labels
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                          type: object
+                        windows:
+                          description: |-
+                            Windows is a collection of maintenance windows. A configuration is permitted to apply
+                            changes when any of the windows are open.
+                          items:
+                            description: MaintenanceWindow defines a recurring period in which changes are permitted
+                            properties:
+                              duration:
+                                description: Duration is how long the window remains open once it has opened, i.e. 4h
+                                type: string
+                              name:
+                                description: Name is an optional name for the window, used when reporting on the status
+                                type: string
+                              schedule:
+                                description: |-
+                                  Schedule is a cron expression (minute hour day-of-month month day-of-week) defining when
+                                  the window opens, i.e. "0 22 * * 1-5" opens the window at 22:00 on weekdays. Only numeric
+                                  values, ranges, steps, lists and the @hourly, @daily, @weekly, @monthly and @yearly
+                                  macros are supported
+                                type: string
+                              timeZone:
+                                description: |-
+                                  TimeZone is the IANA time zone the schedule is evaluated in, i.e. Europe/London. Defaults
+                                  to UTC.
+                                type: string
+                            required:
+                              - duration
+                              - schedule
+                            type: object
+                          type: array
+                      required:
+                        - windows
+                      type: object
+                    modules:
+                      description: |-
+                        Modules provides the ability to control the source for all terraform modules. Allowing
+                        platform teams to control where the modules can be downloaded from.
+                      properties:
+                        allowed:
+                          description: |-
+                            Allowed is a collection of regexes which are applied to the source of the terraform
+                            configuration. The configuration MUST match one or more of the regexes in order to
+                            be allowed to run.
+                          items:
+                            type: string
+                          type: array
+                        selector:
+                          description: |-
+                            Selector is the selector on the namespace or labels on the configuration. By leaving
+                            this field empty you are implicitly selecting all configurations.
+                          properties:
+                            namespace:
+                              description: |-
+                                Namespace is used to filter a configuration based on the namespace labels of
+                                where it exists
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            resource:
+                              description: Resource provides the ability to filter a configuration based on it's labels
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                          type: object
+                      type: object
+                    operations:
+                      description: |-
+                        Operations provides the ability to restrict who may request one-shot operations, such
+                        as replacing or targeting resources, on the selected configurations
+                      properties:
+                        groups:
+                          description: Groups is a collection of groups whose members are permitted to request the operations
+                          items:
+                            type: string
+                          type: array
+                        selector:
+                          description: |-
+                            Selector is the selector on the namespace or labels on the configuration. By leaving
+                            this field empty you are implicitly selecting all configurations.
+                          properties:
+                            namespace:
+                              description: |-
+                                Namespace is used to filter a configuration based on the namespace labels of
+                                where it exists
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            resource:
+                              description: Resource provides the ability to filter a configuration based on it's labels
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                          type: object
+                        users:
+                          description: Users is a collection of users permitted to request the operations
+                          items:
+                            type: string
+                          type: array
+                      type: object
+                    rego:
+                      description: |-
+                        Rego provides the ability to evaluate the terraform plan against a collection of Open
+                        Policy Agent rego modules. Deny rules block the configuration, while warn rules are
+                        reported but permitted to continue.
+                      properties:
+                        external:
+                          description: |-
+                            External is a collection of external sources containing rego modules. Each of the sources
+                            is retrieved into /run/rego/NAME and evaluated alongside the inline modules.
+                          items:
+                            description: |-
+                              ExternalCheck defines the definition for an external check - this comprises of the
+                              source and any optional secret
+                            properties:
+                              name:
+                                description: |-
+                                  Name provides a arbitrary name to the checks - note, this name is used as the directory
+                                  name when we source the code
+                                type: string
+                              secretRef:
+                                description: |-
+                                  SecretRef is reference to secret which contains environment variables used by the source
+                                  command to retrieve the code. This could be cloud credentials, ssh keys, git username
+                                  and password etc
+                                properties:
+                                  name:
+                                    description: name is unique within a namespace to reference a secret resource.
+                                    type: string
+                                  namespace:
+                                    description: namespace defines the space within which the secret name must be unique.
+                                    type: string
+                                type: object
+                                x-kubernetes-map-type: atomic
+                              url:
+                                description: |-
+                                  URL is the source external checks - this is usually a git repository. The notation
+                                  for this is https://github.com/hashicorp/go-getter
+                                type: string
+                            type: object
+                          type: array
+                        modules:
+                          description: |-
+                            Modules is a collection of inline rego modules which are evaluated against the terraform
+                            plan. Rules named deny or violation block the configuration, rules named warn are reported
+                            on the status.
+                          items:
+                            description: RegoModule is an inline rego module
+                            properties:
+                              content:
+                                description: Content is the rego source of the module
+                                type: string
+                              name:
+                                description: |-
+                                  Name is the name of the module, this is used as the filename when the module is
+                                  evaluated and must be unique within the constraint
                                 type: string
+                            required:
+                              - content
+                              - name
                             type: object
                           type: array
                         selector:
@@ -1491,53 +2968,148 @@ spec:
                               type: object
                               x-kubernetes-map-type: atomic
                           type: object
-                        skipChecks:
+                      type: object
+                    signatures:
+                      description: |-
+                        Signatures provides the ability to require the module sources of the selected
+                        configurations are signed by one of the trusted keys. Modules held in an oci registry
+                        must carry a cosign signature, while git sources must have a signed commit or tag.
+                      properties:
+                        keys:
                           description: |-
-                            SkipChecks is a collection of checkov checks which you can defined as skipped. The security
-                            scan will ignore any failures on these checks.
+                            Keys is a collection of public keys trusted to sign the modules. A module must be
+                            signed by at least one of the keys in order to be allowed to run.
                           items:
-                            type: string
+                            description: SignatureKey is a public key trusted to sign modules
+                            properties:
+                              name:
+                                description: Name is the name of the key, this must be unique within the constraint
+                                pattern: ^[a-zA-Z0-9-_.]+$
+                                type: string
+                              publicKey:
+                                description: PublicKey is the content of the public key
+                                type: string
+                              type:
+                                description: |-
+                                  Type is the type of key, cosign keys are PEM encoded public keys used to verify oci
+                                  modules, while gpg (armored) and ssh keys are used to verify git commits and tags
+                                enum:
+                                  - cosign
+                                  - gpg
+                                  - ssh
+                                type: string
+                            required:
+                              - name
+                              - publicKey
+                              - type
+                            type: object
+                          minItems: 1
                           type: array
-                        source:
-                          description: Source indicates an external source for the checkov configurations
+                        selector:
+                          description: |-
+                            Selector is the selector on the namespace or labels on the configuration. By leaving
+                            this field empty you are implicitly selecting all configurations.
                           properties:
-                            configuration:
-                              description: Configuration is the configuration to use within the source directory
-                              type: string
-                            secretRef:
+                            namespace:
                               description: |-
-                                SecretRef is reference to secret which contains environment variables used by the source
-                                command to retrieve the code. This could be cloud credentials, ssh keys, git username
-                                and password etc
+                                Namespace is used to filter a configuration based on the namespace labels of
+                                where it exists
                               properties:
-                                name:
-                                  description: name is unique within a namespace to reference a secret resource.
-                                  type: string
-                                namespace:
-                                  description: namespace defines the space within which the secret name must be unique.
-                                  type: string
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
+                              type: object
+                              x-kubernetes-map-type: atomic
+                            resource:
+                              description: Resource provides the ability to filter a configuration based on it's labels
+                              properties:
+                                matchExpressions:
+                                  description: matchExpressions is a list of label selector requirements. The requirements are ANDed.
+                                  items:
+                                    description: |-
+                                      A label selector requirement is a selector that contains values, a key, and an operator that
+                                      relates the key and values.
+                                    properties:
+                                      key:
+                                        description: key is the label key that the selector applies to.
+                                        type: string
+                                      operator:
+                                        description: |-
+                                          operator represents a key's relationship to a set of values.
+                                          Valid operators are In, NotIn, Exists and DoesNotExist.
+                                        type: string
+                                      values:
+                                        description: |-
+                                          values is an array of string values. If the operator is In or NotIn,
+                                          the values array must be non-empty. If the operator is Exists or DoesNotExist,
+                                          the values array must be empty. This array is replaced during a strategic
+                                          merge patch.
+                                        items:
+                                          type: string
+                                        type: array
+                                    required:
+                                      - key
+                                      - operator
+                                    type: object
+                                  type: array
+                                matchLabels:
+                                  additionalProperties:
+                                    type: string
+                                  description: |-
+                                    matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+                                    map is equivalent to an element of matchExpressions, whose key field is "key", the
+                                    operator is "In", and the values array contains only "value". The requirements are ANDed.
+                                  type: object
                               type: object
                               x-kubernetes-map-type: atomic
-                            url:
-                              description: |-
-                                URL is the source external checks - this is usually a git repository. The notation
-                                for this is https://github.com/hashicorp/go-getter
-                              type: string
                           type: object
+                      required:
+                        - keys
                       type: object
-                    modules:
+                    versions:
                       description: |-
-                        Modules provides the ability to control the source for all terraform modules. Allowing
-                        platform teams to control where the modules can be downloaded from.
+                        Versions provides the ability to constrain the terraform versions the selected
+                        configurations are permitted to run with, i.e. ">= 1.5.0, < 2.0.0"
                       properties:
-                        allowed:
+                        constraint:
                           description: |-
-                            Allowed is a collection of regexes which are applied to the source of the terraform
-                            configuration. The configuration MUST match one or more of the regexes in order to
-                            be allowed to run.
-                          items:
-                            type: string
-                          type: array
+                            Constraint is the version constraint the terraform version of the configurations must
+                            satisfy, i.e. ">= 1.5.0, < 2.0.0"
+                          type: string
                         selector:
                           description: |-
                             Selector is the selector on the namespace or labels on the configuration. By leaving
@@ -1630,6 +3202,8 @@ spec:
                               type: object
                               x-kubernetes-map-type: atomic
                           type: object
+                      required:
+                        - constraint
                       type: object
                   type: object
                 defaults:
@@ -1892,6 +3466,89 @@ spec:
             spec:
               description: ProviderSpec defines the desired state of a provider
               properties:
+                backend:
+                  description: |-
+                    Backend is a typed terraform state backend used by all configurations using this provider.
+                    The field cannot be used alongside the backendTemplate. Changing the backend of a provider
+                    requires the state of existing configurations to be migrated via tnctl state migrate.
+                  properties:
+                    http:
+                      description: |-
+                        HTTP stores the terraform state behind a http endpoint which implements the terraform
+                        http backend protocol. The state for each configuration is stored under
+                        <address>/<namespace>/<name>/<uid>
+                      properties:
+                        address:
+                          description: Address is the base url of the http backend
+                          type: string
+                        enableLocking:
+                          description: EnableLocking indicates the endpoint supports locking via the LOCK and UNLOCK methods
+                          type: boolean
+                        skipCertVerification:
+                          description: SkipCertVerification disables the verification of the server certificate
+                          type: boolean
+                      required:
+                        - address
+                      type: object
+                    postgres:
+                      description: |-
+                        Postgres stores the terraform state in a postgres database. The state for each
+                        configuration is stored in a schema named <schemaPrefix>_<uid>. The connection string
+                        is read from the PG_CONN_STR key of the secret
+                      properties:
+                        schemaPrefix:
+                          default: terranetes
+                          description: SchemaPrefix is the prefix for the schema names created per configuration
+                          type: string
+                      type: object
+                    s3:
+                      description: |-
+                        S3 stores the terraform state in a s3 compatible bucket. The state for each configuration
+                        is stored under <keyPrefix>/<namespace>/<name>/<uid>.tfstate
+                      properties:
+                        bucket:
+                          description: Bucket is the name of the bucket the state is stored in
+                          type: string
+                        dynamodbTable:
+                          description: DynamoDBTable is an optional dynamodb table used for state locking
+                          type: string
+                        encrypt:
+                          description: Encrypt enables server side encryption of the state
+                          type: boolean
+                        endpoint:
+                          description: Endpoint is a custom endpoint for s3 compatible storage, i.e minio
+                          type: string
+                        forcePathStyle:
+                          description: |-
+                            ForcePathStyle enables path style addressing of the bucket, which is commonly required
+                            by s3 compatible storage
+                          type: boolean
+                        keyPrefix:
+                          default: terranetes
+                          description: KeyPrefix is the prefix for all the state keys in the bucket
+                          type: string
+                        region:
+                          description: Region is the region of the bucket
+                          type: string
+                      required:
+                        - bucket
+                        - region
+                      type: object
+                    secretRef:
+                      description: |-
+                        SecretRef is a reference to a secret in the controller namespace holding the credentials
+                        for the backend. Depending on the backend the keys are AWS_ACCESS_KEY_ID and
+                        AWS_SECRET_ACCESS_KEY, PG_CONN_STR or TF_HTTP_USERNAME and TF_HTTP_PASSWORD
+                      properties:
+                        name:
+                          description: name is unique within a namespace to reference a secret resource.
+                          type: string
+                        namespace:
+                          description: namespace defines the space within which the secret name must be unique.
+                          type: string
+                      type: object
+                      x-kubernetes-map-type: atomic
+                  type: object
                 backendTemplate:
                   description: |-
                     BackendTemplate is the reference to a backend template used for the terraform
@@ -1911,6 +3568,54 @@ spec:
                   description: Configuration is optional configuration to the provider. This is terraform provider specific.
                   type: object
                   x-kubernetes-preserve-unknown-fields: true
+                engine:
+                  description: |-
+                    Engine is the engine used to run the configurations using this provider, either terraform
+                    or tofu (OpenTofu). Configurations can override the engine, and when not set the default
+                    engine of the controller is used.
+                  enum:
+                    - terraform
+                    - tofu
+                  type: string
+                federation:
+                  description: |-
+                    Federation defines the workload identity federation used when the provider source is
+                    'federated'. A projected service account token is mounted into the jobs and exchanged for
+                    short-lived cloud credentials, removing the need for long-lived keys.
+                  properties:
+                    audience:
+                      description: |-
+                        Audience is the audience of the projected service account token. When not defined this
+                        defaults to sts.amazonaws.com for aws, api://AzureADTokenExchange for azure and the
+                        workload identity provider for google.
+                      type: string
+                    clientID:
+                      description: |-
+                        ClientID is the client id of the azure application or managed identity with the federated
+                        credential
+                      type: string
+                    expirationSeconds:
+                      description: ExpirationSeconds is the requested duration of the projected service account token
+                      format: int64
+                      minimum: 600
+                      type: integer
+                    roleARN:
+                      description: RoleARN is the aws role assumed via AssumeRoleWithWebIdentity
+                      type: string
+                    serviceAccountEmail:
+                      description: |-
+                        ServiceAccountEmail is an optional google service account impersonated using the federated
+                        credentials
+                      type: string
+                    tenantID:
+                      description: TenantID is the azure tenant of the application or managed identity
+                      type: string
+                    workloadIdentityProvider:
+                      description: |-
+                        WorkloadIdentityProvider is the full resource name of the google workload identity pool
+                        provider, i.e. projects/NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER
+                      type: string
+                  type: object
                 job:
                   description: |-
                     Job defined a custom collection of labels and annotations to be applied to all jobs
@@ -1950,9 +3655,27 @@ spec:
                   type: object
                 provider:
                   description: |-
-                    ProviderType defines the cloud provider which is being used, currently supported providers are
-                    aws, google or azurerm.
+                    ProviderType defines the terraform provider which is being used, i.e. aws, google, azurerm,
+                    or any other provider such as cloudflare when the registry source is defined.
                   type: string
+                registry:
+                  description: |-
+                    Registry defines the terraform registry source and version constraint of the provider. When
+                    defined a required_providers block is rendered for the provider, permitting the use of any
+                    provider from the registry. Note, the terraform module should not declare its own
+                    required_providers block when this is used.
+                  properties:
+                    source:
+                      description: |-
+                        Source is the address of the provider in the registry, i.e. cloudflare/cloudflare or
+                        registry.terraform.io/datadog/datadog
+                      type: string
+                    version:
+                      description: Version is an optional version constraint for the provider, i.e. ~> 4.0
+                      type: string
+                  required:
+                    - source
+                  type: object
                 secretRef:
                   description: |-
                     SecretRef is a reference to a kubernetes secret. This is required only when using the source: secret.
@@ -2063,12 +3786,13 @@ spec:
                   description: |-
                     ServiceAccount is the name of a service account to use when the provider source is 'injected'. The
                     service account should exist in the terraform controller namespace and be configure per cloud vendor
-                    requirements for pod identity.
+                    requirements for pod identity. When the source is 'federated' this optionally overrides the service
+                    account whose projected token is exchanged for the cloud credentials.
                   type: string
                 source:
                   description: |-
                     Source defines the type of credentials the provider is wrapper, this could be wrapping a static secret
-                    or using a managed identity. The currently supported values are secret and injected.
+                    or using a managed identity. The currently supported values are secret, injected and federated.
                   type: string
                 summary:
                   description: Summary provides a human readable description of the provider
@@ -2275,7 +3999,8 @@ spec:
                       description: |-
                         Auth is used to configure any options required when the source of the terraform
                         module is private or requires credentials to retrieve. This could be SSH keys or git
-                        user/pass or AWS credentials for an s3 bucket.
+                        user/pass, AWS credentials for an s3 bucket or OCI_USERNAME and OCI_PASSWORD for an
+                        oci registry.
                       properties:
                         name:
                           description: name is unique within a namespace to reference a secret resource.
@@ -2285,6 +4010,72 @@ spec:
                           type: string
                       type: object
                       x-kubernetes-map-type: atomic
+                    dependsOn:
+                      description: |-
+                        DependsOn is a collection of configurations in the same namespace which must be ready
+                        before this configuration is planned. Outputs from the dependencies can be mapped to
+                        variables, and a change to those outputs will trigger a new plan.
+                      items:
+                        description: |-
+                          Dependency is a reference to a configuration which must be ready before this configuration
+                          is run, along with the outputs which should be passed in as variables
+                        properties:
+                          name:
+                            description: |-
+                              Name is the name of the configuration, in the same namespace, which this configuration
+                              depends on
+                            type: string
+                          outputs:
+                            description: |-
+                              Outputs is a collection of terraform outputs from the dependency which are passed into
+                              this configuration as variables
+                            items:
+                              description: DependencyOutput maps a terraform output of a dependency to a variable
+                              properties:
+                                key:
+                                  description: Key is the name of the terraform output on the dependency
+                                  type: string
+                                name:
+                                  description: |-
+                                    Name is the name of the variable the output is passed in as. If not set the key is
+                                    used as the name of the variable
+                                  type: string
+                                optional:
+                                  description: |-
+                                    Optional indicates the output may not exist on the dependency, in which case the
+                                    variable is not set
+                                  type: boolean
+                              required:
+                                - key
+                              type: object
+                            type: array
+                        required:
+                          - name
+                        type: object
+                      type: array
+                    driftPolicy:
+                      description: |-
+                        DriftPolicy controls what happens when drift is detected and how often drift detection
+                        runs. Note this requires enableDriftDetection to be set.
+                      properties:
+                        interval:
+                          description: |-
+                            Interval is the minimum time between drift detection runs on this configuration,
+                            overriding the interval configured on the controller
+                          type: string
+                        mode:
+                          default: Detect
+                          description: |-
+                            Mode is the action taken when drift is detected. Detect only records the drift on the
+                            status, Remediate applies the plan to bring the resources back in line with the
+                            configuration, and RequireApproval waits for the drift approval annotation before
+                            applying the plan.
+                          enum:
+                            - Detect
+                            - Remediate
+                            - RequireApproval
+                          type: string
+                      type: object
                     enableAutoApproval:
                       description: |-
                         EnableAutoApproval when enabled indicates the configuration does not need to be
@@ -2297,6 +4088,36 @@ spec:
                         for any drift between the expected and current state. If any drift is detected the
                         status is changed and a kubernetes event raised.
                       type: boolean
+                    engine:
+                      description: |-
+                        Engine is the engine used to run the terraform module, either terraform or tofu (OpenTofu).
+                        When not set the engine defined on the provider is used, falling back to the default
+                        engine of the controller.
+                      enum:
+                        - terraform
+                        - tofu
+                      type: string
+                    imports:
+                      description: |-
+                        Imports is a collection of existing resources which should be imported into the state
+                        of the configuration. The imports are rendered as terraform import blocks, shown in the
+                        plan and recorded on the status once applied. Note this requires terraform 1.5 or above.
+                      items:
+                        description: Import is an existing resource which should be imported into the terraform state
+                        properties:
+                          address:
+                            description: |-
+                              Address is the terraform address of the resource within the module the existing
+                              resource is imported to, i.e. aws_s3_bucket.this
+                            type: string
+                          id:
+                            description: ID is the provider specific identifier of the existing resource
+                            type: string
+                        required:
+                          - address
+                          - id
+                        type: object
+                      type: array
                     module:
                       description: |-
                         Module is the URL to the source of the terraform module. The format of the URL is
@@ -2323,6 +4144,12 @@ spec:
                         ProviderRef is the reference to the provider which should be used to execute this
                         configuration.
                       properties:
+                        alias:
+                          description: |-
+                            Alias is an optional alias for the provider, rendered as the alias of the provider block
+                            in the terraform code. This is only used by the additional providers in spec.providers,
+                            and is required when more than one provider of the same type is used.
+                          type: string
                         name:
                           description: |-
                             Name is the name of the provider which contains the credentials to use for this
@@ -2334,11 +4161,42 @@ spec:
                       required:
                         - name
                       type: object
+                    providers:
+                      description: |-
+                        Providers is a list of additional providers used by the terraform module, i.e. a
+                        kubernetes or vault provider alongside the cloud provider, or the same provider in a
+                        different region via an alias. Each provider is rendered into the terraform code and the
+                        credentials of each are made available to the terraform jobs.
+                      items:
+                        description: |-
+                          ProviderReference is the reference to the provider which is used to create
+                          the configuration
+                        properties:
+                          alias:
+                            description: |-
+                              Alias is an optional alias for the provider, rendered as the alias of the provider block
+                              in the terraform code. This is only used by the additional providers in spec.providers,
+                              and is required when more than one provider of the same type is used.
+                            type: string
+                          name:
+                            description: |-
+                              Name is the name of the provider which contains the credentials to use for this
+                              configuration.
+                            type: string
+                          namespace:
+                            description: Namespace is the namespace of the provider itself.
+                            type: string
+                        required:
+                          - name
+                        type: object
+                      type: array
                     terraformVersion:
                       description: |-
                         TerraformVersion provides the ability to override the default terraform version. Before
                         changing this field its best to consult with platform administrator. As the
-                        value of this field is used to change the tag of the terraform container image.
+                        value of this field is used to change the tag of the terraform container image. The
+                        value can also be a version constraint, i.e. "~> 1.6", which is resolved to the newest
+                        version permitted by the controller satisfying the constraint.
                       type: string
                     valueFrom:
                       description: |-
@@ -2740,6 +4598,7 @@ webhooks:
     - v1alpha1
     operations:
     - CREATE
+    - DELETE
     - UPDATE
     resources:
     - cloudresources
@@ -2760,6 +4619,7 @@ webhooks:
     - v1alpha1
     operations:
     - CREATE
+    - DELETE
     - UPDATE
     resources:
     - configurations
@@ -19,6 +19,8 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
@@ -27,10 +29,15 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -50,6 +57,8 @@ import (
 	"github.com/appvia/terranetes-controller/pkg/schema"
 	"github.com/appvia/terranetes-controller/pkg/utils"
 	k8sutils "github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
 	"github.com/appvia/terranetes-controller/pkg/version"
 )
 
@@ -110,13 +119,51 @@ func New(cfg *rest.Config, config Config) (*Server, error) {
 		ns = "terraform-system"
 	}
 
+	// @step: create the store for the archived job logs if enabled
+	var logs logstore.Store
+	var logsOptions *logstore.Options
+
+	if config.LogStore.Type != "" {
+		logsOptions = &config.LogStore
+		logsOptions.Namespace = config.Namespace
+
+		rc, err := client.New(cfg, client.Options{Scheme: schema.GetScheme()})
+		if err != nil {
+			return nil, err
+		}
+		if logs, err = logstore.New(rc, *logsOptions); err != nil {
+			return nil, fmt.Errorf("failed to create the log store: %w", err)
+		}
+		log.WithField("store", config.LogStore.Type).Info("archiving the job logs")
+	}
+
+	// @step: create the module source cache if enabled
+	var sourceCache *sources.Cache
+	var sourceCacheToken string
+	if config.SourceCacheDir != "" {
+		size, err := resource.ParseQuantity(config.SourceCacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source cache size: %w", err)
+		}
+		if sourceCache, err = sources.NewCache(config.SourceCacheDir, size.Value()); err != nil {
+			return nil, err
+		}
+		if sourceCacheToken, err = ensureSourceCacheToken(context.Background(), cc, config.Namespace); err != nil {
+			return nil, fmt.Errorf("failed to provision the source cache token: %w", err)
+		}
+		log.WithField("directory", config.SourceCacheDir).Info("caching the module sources")
+	}
+
 	hs := &http.Server{
 		Addr:              listener.Addr().String(),
 		IdleTimeout:       30 * time.Second,
 		ReadHeaderTimeout: 5 * time.Second,
 		Handler: (&apiserver.Server{
-			Client:    cc,
-			Namespace: config.Namespace,
+			Client:           cc,
+			LogStore:         logs,
+			Namespace:        config.Namespace,
+			SourceCache:      sourceCache,
+			SourceCacheToken: sourceCacheToken,
 		}).Serve(),
 	}
 
@@ -175,6 +222,7 @@ func New(cfg *rest.Config, config Config) (*Server, error) {
 		ControllerJobLabels:     jobLabels,
 		ControllerNamespace:     config.Namespace,
 		EnableInfracosts:        (config.InfracostsSecretName != ""),
+		EnableSourceCache:       (sourceCache != nil),
 		EnableTerraformVersions: config.EnableTerraformVersions,
 		EnableWatchers:          config.EnableWatchers,
 		EnableWebhooks:          config.EnableWebhooks,
@@ -183,8 +231,15 @@ func New(cfg *rest.Config, config Config) (*Server, error) {
 		InfracostsImage:         config.InfracostsImage,
 		InfracostsSecretName:    config.InfracostsSecretName,
 		JobTemplate:             config.JobTemplate,
+		LogStore:                logsOptions,
 		PolicyImage:             config.PolicyImage,
+		RegoImage:               config.RegoImage,
+		StateVersions:           config.StateVersions,
+		TerraformEngine:         config.TerraformEngine,
 		TerraformImage:          config.TerraformImage,
+		TerraformVersions:       config.TerraformVersions,
+		TofuImage:               config.TofuImage,
+		TofuVersions:            config.TofuVersions,
 	}).Add(mgr); err != nil {
 		return nil, fmt.Errorf("failed to create the configuration controller, error: %w", err)
 	}
@@ -277,3 +332,41 @@ func (s *Server) Start(ctx context.Context) error {
 
 	return s.mgr.Start(ctrl.SetupSignalHandler())
 }
+
+// ensureSourceCacheToken returns the token the jobs present to the source cache, creating the
+// secret holding it if required. The secret is shared by all the replicas of the controller
+func ensureSourceCacheToken(ctx context.Context, cc kubernetes.Interface, namespace string) (string, error) {
+	secret, err := cc.CoreV1().Secrets(namespace).Get(ctx, sources.CacheTokenSecret, metav1.GetOptions{})
+	if err == nil {
+		if token := string(secret.Data[sources.CacheTokenKey]); token != "" {
+			return token, nil
+		}
+
+		return "", fmt.Errorf("secret %s/%s has no token", namespace, sources.CacheTokenSecret)
+	}
+	if !kerrors.IsNotFound(err) {
+		return "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(random)
+
+	secret = &v1.Secret{}
+	secret.Name = sources.CacheTokenSecret
+	secret.Namespace = namespace
+	secret.Data = map[string][]byte{sources.CacheTokenKey: []byte(token)}
+
+	if _, err := cc.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		if kerrors.IsAlreadyExists(err) {
+			// @note: another replica created the token first
+			return ensureSourceCacheToken(ctx, cc, namespace)
+		}
+
+		return "", err
+	}
+
+	return token, nil
+}
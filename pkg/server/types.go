@@ -17,7 +17,11 @@
 
 package server
 
-import "time"
+import (
+	"time"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+)
 
 // Config is the configuration for the controller
 type Config struct {
@@ -64,6 +68,9 @@ type Config struct {
 	JobLabels []string
 	// JobTemplate is the name of the configmap containing a template for the jobs
 	JobTemplate string
+	// LogStore are the options for archiving the logs of the jobs, archiving is disabled
+	// when the type is empty
+	LogStore logstore.Options
 	// MetricsPort is the port to listen on
 	MetricsPort int
 	// Namespace is namespace the controller is running
@@ -72,12 +79,29 @@ type Config struct {
 	PolicyImage string
 	// PreloadImage is the image to use for the preload job
 	PreloadImage string
+	// RegoImage is the image to use for evaluating rego policies
+	RegoImage string
 	// RegisterCRDs indicated we register our crds
 	RegisterCRDs bool
 	// ResyncPeriod is the period to resync the controller manager
 	ResyncPeriod time.Duration
+	// SourceCacheDir is the directory used to cache the module sources, the cache is disabled
+	// when empty
+	SourceCacheDir string
+	// SourceCacheSize is the maximum size of the source cache, i.e. 10Gi
+	SourceCacheSize string
+	// StateVersions is the number of versions of the terraform state retained per configuration
+	StateVersions int
+	// TerraformEngine is the default engine used to run configurations, i.e. terraform or tofu
+	TerraformEngine string
 	// TerraformImage is the image to use for terraform
 	TerraformImage string
+	// TerraformVersions are the terraform versions permitted when resolving version constraints
+	TerraformVersions []string
+	// TofuImage is the image to use for opentofu
+	TofuImage string
+	// TofuVersions are the opentofu versions permitted when resolving version constraints
+	TofuVersions []string
 	// TLSDir is the directory where the TLS certificates are stored
 	TLSDir string
 	// TLSAuthority is the path to the ca certificate
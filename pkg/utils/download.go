@@ -27,6 +27,8 @@ import (
 	"syscall"
 
 	"github.com/hashicorp/go-getter"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/oci"
 )
 
 // Download retrieves a source assets using the go-getter library
@@ -53,6 +55,7 @@ func Download(ctx context.Context, source, destination string) error {
 			new(getter.S3Detector),
 			new(getter.FileDetector),
 		},
+		Getters: oci.Getters(&oci.Getter{}),
 		Mode:    getter.ClientModeAny,
 		Options: []getter.ClientOption{},
 		Pwd:     pwd,
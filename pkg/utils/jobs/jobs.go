@@ -20,7 +20,9 @@ package jobs
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
 	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -31,6 +33,8 @@ import (
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/pkg/utils"
+	"github.com/appvia/terranetes-controller/pkg/utils/logstore"
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
 	"github.com/appvia/terranetes-controller/pkg/utils/terraform"
 )
 
@@ -40,6 +44,15 @@ const DefaultServiceAccount = "terranetes-executor"
 // TerraformContainerName is the default name for the main terraform container
 const TerraformContainerName = "terraform"
 
+// SetupContainerName is the name of the init container retrieving the module source
+const SetupContainerName = "setup"
+
+// EngineBinaries is the path of the binary within the image for each of the engines
+var EngineBinaries = map[string]string{
+	terraformv1alpha1.EngineOpenTofu:  "/usr/local/bin/tofu",
+	terraformv1alpha1.EngineTerraform: "/bin/terraform",
+}
+
 // Options is the configuration for the render
 type Options struct {
 	// AdditionalJobAnnotations are additional annotations added to the job
@@ -53,6 +66,11 @@ type Options struct {
 	BackoffLimit int
 	// EnableInfraCosts is the flag to enable cost analysis
 	EnableInfraCosts bool
+	// EnableSourceCache indicates the module sources should be retrieved from and stored in the
+	// source cache served by the controller
+	EnableSourceCache bool
+	// Engine is the engine used to run terraform, i.e. terraform or tofu, defaults to terraform
+	Engine string
 	// ExecutorImage is the image to use for the terraform jobs
 	ExecutorImage string
 	// ExecutorSecrets is a list of additional secrets to add to the job
@@ -61,16 +79,35 @@ type Options struct {
 	InfracostsImage string
 	// InfracostsSecret is the name of the secret contain the infracost token and url
 	InfracostsSecret string
+	// LogStore are the options for archiving the logs of the job, if any
+	LogStore *logstore.Options
 	// Namespace is the location of the jobs
 	Namespace string
 	// PolicyConstraint is a matching constraint for this policy
 	PolicyConstraint *terraformv1alpha1.PolicyConstraint
 	// PolicyImage is image to use for checkov
 	PolicyImage string
+	// Providers are the additional providers referenced by the configuration, whose credentials
+	// are merged into the job
+	Providers []*terraformv1alpha1.Provider
+	// RegoConstraint is the matching rego constraint for the configuration
+	RegoConstraint *terraformv1alpha1.RegoConstraint
+	// RegoImage is the image to use for evaluating rego policies
+	RegoImage string
 	// SaveTerraformState indicates we should save the terraform state in a secret
 	SaveTerraformState bool
+	// SignatureKeys are the public keys trusted to sign the module source. When provided the
+	// signature of the module is verified before it is used
+	SignatureKeys []terraformv1alpha1.SignatureKey
+	// StateCommand is the terraform state command being run, if any
+	StateCommand *terraformv1alpha1.StateCommand
+	// StateVersion is the version of the terraform state being restored
+	StateVersion int
 	// Template is the source for the job template if overridden by the controller
 	Template []byte
+	// TerraformArguments are additional arguments passed to the terraform plan for a single
+	// run, i.e. the replace and target operations
+	TerraformArguments []string
 	// TerraformImage is the image to use for the terraform jobs
 	TerraformImage string
 }
@@ -177,6 +214,25 @@ func (r *Render) NewTerraformApply(options Options) (*batchv1.Job, error) {
 	return r.createTerraformFromTemplate(options, terraformv1alpha1.StageTerraformApply)
 }
 
+// NewTerraformMigrate is responsible for creating a batch job to migrate the terraform state between backends
+func (r *Render) NewTerraformMigrate(options Options) (*batchv1.Job, error) {
+	return r.createTerraformFromTemplate(options, terraformv1alpha1.StageTerraformMigrate)
+}
+
+// NewTerraformRestore is responsible for creating a batch job to restore a version of the terraform state
+func (r *Render) NewTerraformRestore(options Options) (*batchv1.Job, error) {
+	return r.createTerraformFromTemplate(options, terraformv1alpha1.StageTerraformRestore)
+}
+
+// NewTerraformState is responsible for creating a batch job to run a terraform state command
+func (r *Render) NewTerraformState(options Options) (*batchv1.Job, error) {
+	if options.StateCommand == nil {
+		return nil, errors.New("state command is required")
+	}
+
+	return r.createTerraformFromTemplate(options, terraformv1alpha1.StageTerraformState)
+}
+
 // NewTerraformDestroy is responsible for creating a batch job to run terraform destroy
 func (r *Render) NewTerraformDestroy(options Options) (*batchv1.Job, error) {
 	return r.createTerraformFromTemplate(options, terraformv1alpha1.StageTerraformDestroy)
@@ -184,11 +240,76 @@ func (r *Render) NewTerraformDestroy(options Options) (*batchv1.Job, error) {
 
 // createTerraformFromTemplate is used to render the terraform job from the parameters and the template
 func (r *Render) createTerraformFromTemplate(options Options, stage string) (*batchv1.Job, error) {
-	var arguments string
+	var arguments, cache, checksum string
+	var source *terraformv1alpha1.SourceStatus
+	var command map[string]interface{}
 
 	if r.configuration.Spec.HasVariables() {
 		arguments = fmt.Sprintf("--var-file %s", terraformv1alpha1.TerraformVariablesConfigMapKey)
 	}
+	// @note: the operations are only passed to the plan, the apply stage applies the plan as produced
+	if stage == terraformv1alpha1.StageTerraformPlan {
+		if len(options.TerraformArguments) > 0 {
+			arguments = strings.TrimSpace(fmt.Sprintf("%s %s", arguments, utils.ShellJoin(options.TerraformArguments)))
+		}
+	}
+	if r.configuration.Status.TerraformPlan != nil {
+		checksum = r.configuration.Status.TerraformPlan.Checksum
+	}
+	// @note: the plan resolves the revision of the module source, the later stages are pinned to it
+	if stage != terraformv1alpha1.StageTerraformPlan {
+		source = r.configuration.Status.GetSource(r.configuration.Spec.Module)
+	}
+	// @note: the addresses are shell quoted as they may contain index keys, i.e. aws_instance.this["a b"]
+	if options.StateCommand != nil {
+		command = map[string]interface{}{
+			"Arguments": utils.ShellJoin(options.StateCommand.Arguments),
+			"Mutating":  options.StateCommand.IsMutating(),
+			"Name":      options.StateCommand.Name,
+		}
+	}
+	binary, found := EngineBinaries[options.Engine]
+	if !found {
+		binary = EngineBinaries[terraformv1alpha1.EngineTerraform]
+	}
+	if options.EnableSourceCache {
+		cache = fmt.Sprintf("http://controller.%s.svc.cluster.local/v1/sources", options.Namespace)
+	}
+	providers := []map[string]interface{}{}
+	for _, x := range options.Providers {
+		if x == nil {
+			continue
+		}
+		providers = append(providers, map[string]interface{}{
+			"Name":           x.Name,
+			"SecretRef":      x.Spec.SecretRef,
+			"ServiceAccount": ptr.Deref(x.Spec.ServiceAccount, ""),
+			"Source":         string(x.Spec.Source),
+		})
+	}
+	// @note: each federated provider has a projected token, and optionally credentials, mounted.
+	// The mount path is keyed by the provider name, so a provider referenced more than once, i.e.
+	// as the providerRef and again under an alias, is only mounted once
+	federated := []map[string]interface{}{}
+	mounted := map[string]bool{}
+	for _, x := range append([]*terraformv1alpha1.Provider{r.provider}, options.Providers...) {
+		if x == nil || !x.IsFederated() || mounted[x.Name] {
+			continue
+		}
+		mounted[x.Name] = true
+
+		var key string
+		if x.Spec.Provider == terraformv1alpha1.GCPProviderType {
+			key = x.GetFederatedCredentialsKey()
+		}
+		federated = append(federated, map[string]interface{}{
+			"Audience":          x.GetFederatedAudience(),
+			"CredentialsKey":    key,
+			"ExpirationSeconds": x.GetFederatedTokenExpiration(),
+			"Name":              x.Name,
+			"Path":              path.Dir(x.GetFederatedTokenFile()),
+		})
+	}
 
 	params := map[string]interface{}{
 		"GenerateName": fmt.Sprintf("%s-%s-", r.configuration.Name, stage),
@@ -208,15 +329,27 @@ func (r *Render) createTerraformFromTemplate(options Options, stage string) (*ba
 			"ServiceAccount": ptr.Deref(r.provider.Spec.ServiceAccount, ""),
 			"Source":         string(r.provider.Spec.Source),
 		},
+		"Providers":              providers,
+		"EnableImports":          len(r.configuration.GetPendingImports()) > 0,
 		"EnableInfraCosts":       options.EnableInfraCosts,
 		"EnableVariables":        r.configuration.Spec.HasVariables(),
 		"ExecutorSecrets":        options.ExecutorSecrets,
+		"Federated":              federated,
 		"ImagePullPolicy":        "IfNotPresent",
+		"Lock":                   r.configuration.GetTerraformLockName(),
+		"LogStore":               options.LogStore,
 		"Policy":                 options.PolicyConstraint,
+		"Rego":                   options.RegoConstraint,
 		"SaveTerraformState":     options.SaveTerraformState,
 		"ServiceAccount":         DefaultServiceAccount,
+		"SetupContainerName":     SetupContainerName,
+		"Signatures":             options.SignatureKeys,
+		"Source":                 source,
+		"SourceCache":            cache,
 		"Stage":                  stage,
+		"StateCommand":           command,
 		"TerraformArguments":     arguments,
+		"TerraformBinary":        binary,
 		"TerraformContainerName": TerraformContainerName,
 		"Configuration": map[string]interface{}{
 			"Generation": fmt.Sprintf("%d", r.configuration.GetGeneration()),
@@ -231,13 +364,24 @@ func (r *Render) createTerraformFromTemplate(options Options, stage string) (*ba
 			"Infracosts": options.InfracostsImage,
 			"Terraform":  options.TerraformImage,
 			"Policy":     options.PolicyImage,
+			"Rego":       options.RegoImage,
+		},
+		"Plan": map[string]interface{}{
+			"Checksum": checksum,
 		},
 		"Secrets": map[string]interface{}{
 			"AdditionalSecrets": options.AdditionalJobSecrets,
 			"Config":            r.configuration.GetTerraformConfigSecretName(),
 			"Infracosts":        options.InfracostsSecret,
 			"InfracostsReport":  r.configuration.GetTerraformCostSecretName(),
+			"Migration":         r.configuration.GetTerraformMigrationSecretName(),
 			"PolicyReport":      r.configuration.GetTerraformPolicySecretName(),
+			"RegoReport":        r.configuration.GetTerraformRegoSecretName(),
+			"SourceCache":       sources.CacheTokenSecret,
+			"SourceCacheKey":    sources.CacheTokenKey,
+			"TerraformPlan":     r.configuration.GetTerraformPlanSecretName(),
+			"StateCommand":      r.configuration.GetTerraformStateCommandSecretName(),
+			"StateVersion":      r.configuration.GetTerraformStateVersionSecretName(options.StateVersion),
 			"TerraformState":    r.configuration.GetTerraformStateSecretName(),
 		},
 	}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrLeaseLost is returned when the lease is held by another holder
+var ErrLeaseLost = errors.New("lease is held by another holder")
+
+// IsLeaseExpired returns true if the holder of the lease has stopped renewing it
+func IsLeaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	switch {
+	case ptr.Deref(lease.Spec.HolderIdentity, "") == "":
+		return true
+	case lease.Spec.RenewTime == nil, lease.Spec.LeaseDurationSeconds == nil:
+		return true
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+
+	return lease.Spec.RenewTime.Add(duration).Before(now)
+}
+
+// AcquireLease attempts to acquire the lease for the holder, returning true if the lease is now held.
+// A lease held by someone else is only taken over once it has expired
+func AcquireLease(ctx context.Context, cc client.Client, namespace, name, holder string, duration time.Duration) (bool, error) {
+	now := metav1.NewMicroTime(time.Now())
+
+	lease := &coordinationv1.Lease{}
+	lease.Namespace = namespace
+	lease.Name = name
+
+	found, err := GetIfExists(ctx, cc, lease)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		lease.Spec = coordinationv1.LeaseSpec{
+			AcquireTime:          &now,
+			HolderIdentity:       ptr.To(holder),
+			LeaseDurationSeconds: ptr.To(int32(duration.Seconds())),
+			RenewTime:            &now,
+		}
+		if err := cc.Create(ctx, lease); err != nil {
+			if kerrors.IsAlreadyExists(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != holder {
+		if !IsLeaseExpired(lease, now.Time) {
+			return false, nil
+		}
+		lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+	}
+	lease.Spec.AcquireTime = &now
+	lease.Spec.HolderIdentity = ptr.To(holder)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(duration.Seconds()))
+	lease.Spec.RenewTime = &now
+
+	// @note: the update is guarded by the resource version, so if two holders race
+	// for an expired lease only one of them wins
+	if err := cc.Update(ctx, lease); err != nil {
+		if kerrors.IsConflict(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RenewLease renews the lease for the holder, returning ErrLeaseLost if the lease is
+// no longer held by the holder
+func RenewLease(ctx context.Context, cc client.Client, namespace, name, holder string) error {
+	lease := &coordinationv1.Lease{}
+	lease.Namespace = namespace
+	lease.Name = name
+
+	found, err := GetIfExists(ctx, cc, lease)
+	if err != nil {
+		return err
+	}
+	if !found || ptr.Deref(lease.Spec.HolderIdentity, "") != holder {
+		return ErrLeaseLost
+	}
+	lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(time.Now()))
+
+	return cc.Update(ctx, lease)
+}
+
+// ReleaseLease releases the lease if it is held by the holder
+func ReleaseLease(ctx context.Context, cc client.Client, namespace, name, holder string) error {
+	lease := &coordinationv1.Lease{}
+	lease.Namespace = namespace
+	lease.Name = name
+
+	found, err := GetIfExists(ctx, cc, lease)
+	if err != nil {
+		return err
+	}
+	if !found || ptr.Deref(lease.Spec.HolderIdentity, "") != holder {
+		return nil
+	}
+
+	return DeleteIfExists(ctx, cc, lease)
+}
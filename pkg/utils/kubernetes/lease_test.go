@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestLease(holder string, renewed time.Time) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "lock", Namespace: "default"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(holder),
+			LeaseDurationSeconds: ptr.To(int32(60)),
+			RenewTime:            ptr.To(metav1.NewMicroTime(renewed)),
+		},
+	}
+}
+
+func TestIsLeaseExpired(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, IsLeaseExpired(&coordinationv1.Lease{}, now))
+	assert.True(t, IsLeaseExpired(newTestLease("", now), now))
+	assert.True(t, IsLeaseExpired(newTestLease("job", now.Add(-2*time.Minute)), now))
+	assert.False(t, IsLeaseExpired(newTestLease("job", now.Add(-30*time.Second)), now))
+}
+
+func TestAcquireLease(t *testing.T) {
+	cases := []struct {
+		Existing *coordinationv1.Lease
+		Expected bool
+	}{
+		{
+			Expected: true,
+		},
+		{
+			Existing: newTestLease("job", time.Now()),
+			Expected: true,
+		},
+		{
+			Existing: newTestLease("other", time.Now()),
+			Expected: false,
+		},
+		{
+			Existing: newTestLease("other", time.Now().Add(-5*time.Minute)),
+			Expected: true,
+		},
+	}
+	for _, c := range cases {
+		builder := fake.NewClientBuilder()
+		if c.Existing != nil {
+			builder.WithObjects(c.Existing)
+		}
+		cc := builder.Build()
+
+		acquired, err := AcquireLease(context.Background(), cc, "default", "lock", "job", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Expected, acquired)
+
+		lease := &coordinationv1.Lease{}
+		require.NoError(t, cc.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "lock"}, lease))
+		if c.Expected {
+			assert.Equal(t, "job", ptr.Deref(lease.Spec.HolderIdentity, ""))
+		} else {
+			assert.Equal(t, "other", ptr.Deref(lease.Spec.HolderIdentity, ""))
+		}
+	}
+}
+
+func TestRenewLease(t *testing.T) {
+	cc := fake.NewClientBuilder().WithObjects(newTestLease("job", time.Now().Add(-30*time.Second))).Build()
+
+	assert.NoError(t, RenewLease(context.Background(), cc, "default", "lock", "job"))
+	assert.Equal(t, ErrLeaseLost, RenewLease(context.Background(), cc, "default", "lock", "other"))
+	assert.Equal(t, ErrLeaseLost, RenewLease(context.Background(), cc, "default", "missing", "job"))
+
+	lease := &coordinationv1.Lease{}
+	require.NoError(t, cc.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "lock"}, lease))
+	assert.False(t, IsLeaseExpired(lease, time.Now().Add(45*time.Second)))
+}
+
+func TestReleaseLease(t *testing.T) {
+	cc := fake.NewClientBuilder().WithObjects(newTestLease("other", time.Now())).Build()
+
+	assert.NoError(t, ReleaseLease(context.Background(), cc, "default", "lock", "job"))
+	found, err := GetIfExists(context.Background(), cc, &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "lock", Namespace: "default"}})
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	assert.NoError(t, ReleaseLease(context.Background(), cc, "default", "lock", "other"))
+	found, err = GetIfExists(context.Background(), cc, &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "lock", Namespace: "default"}})
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+const (
+	// LogsLabel is the label used to identify the secrets holding archived logs
+	LogsLabel = "terraform.appvia.io/logs"
+	// LogsSecretKey is the key in the secret holding the compressed logs
+	LogsSecretKey = "logs.gz"
+	// MaxChunkSize is the maximum size of the compressed logs held in a single secret
+	MaxChunkSize = 512 * 1024
+)
+
+// kstore archives the logs as secrets
+type kstore struct {
+	// cc is the kubernetes client
+	cc client.Client
+	// namespace is the namespace to store the secrets
+	namespace string
+}
+
+// NewKubernetesStore returns a store which archives the logs as secrets in the namespace. The
+// compressed logs are split into chunks across multiple secrets to keep under the size limits
+func NewKubernetesStore(cc client.Client, namespace string) Store {
+	return &kstore{cc: cc, namespace: namespace}
+}
+
+// Put appends the logs to the archive of the build
+func (k *kstore) Put(ctx context.Context, key Key, content []byte) error {
+	compressed, err := Compress(content)
+	if err != nil {
+		return err
+	}
+	// @note: the timestamp orders the uploads for the build when they are read back
+	timestamp := time.Now().UnixNano()
+
+	for i := 0; i*MaxChunkSize < len(compressed); i++ {
+		end := (i + 1) * MaxChunkSize
+		if end > len(compressed) {
+			end = len(compressed)
+		}
+
+		secret := &v1.Secret{}
+		secret.Namespace = k.namespace
+		secret.Name = fmt.Sprintf("logs-%s-%s-%s-%d-%03d", key.UID, key.Generation, key.Stage, timestamp, i)
+		secret.Labels = k.labels(key)
+		secret.Labels[LogsLabel] = "true"
+		secret.Data = map[string][]byte{LogsSecretKey: compressed[i*MaxChunkSize : end]}
+
+		if err := k.cc.Create(ctx, secret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the archived logs for the build
+func (k *kstore) Get(ctx context.Context, key Key) ([]byte, bool, error) {
+	list, err := k.list(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(list.Items) == 0 {
+		return nil, false, nil
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	var compressed []byte
+	for _, x := range list.Items {
+		compressed = append(compressed, x.Data[LogsSecretKey]...)
+	}
+
+	content, err := Decompress(compressed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return content, true, nil
+}
+
+// Delete removes the archived logs matching the key
+func (k *kstore) Delete(ctx context.Context, key Key) error {
+	if key.UID == "" {
+		return errors.New("uid is required to delete the logs")
+	}
+
+	list, err := k.list(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(list.Items); i++ {
+		if err := client.IgnoreNotFound(k.cc.Delete(ctx, &list.Items[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// list returns the secrets matching the key
+func (k *kstore) list(ctx context.Context, key Key) (*v1.SecretList, error) {
+	labels := k.labels(key)
+	labels[LogsLabel] = "true"
+
+	list := &v1.SecretList{}
+	if err := k.cc.List(ctx, list, client.InNamespace(k.namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// labels returns the labels for the non empty elements of the key
+func (k *kstore) labels(key Key) map[string]string {
+	labels := map[string]string{}
+
+	for name, value := range map[string]string{
+		terraformv1alpha1.ConfigurationGenerationLabel: key.Generation,
+		terraformv1alpha1.ConfigurationNameLabel:       key.Name,
+		terraformv1alpha1.ConfigurationNamespaceLabel:  key.Namespace,
+		terraformv1alpha1.ConfigurationStageLabel:      key.Stage,
+		terraformv1alpha1.ConfigurationUIDLabel:        key.UID,
+	} {
+		if value != "" {
+			labels[name] = value
+		}
+	}
+
+	return labels
+}
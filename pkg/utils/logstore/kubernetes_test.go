@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/appvia/terranetes-controller/pkg/schema"
+	"github.com/appvia/terranetes-controller/pkg/utils"
+)
+
+func newTestKubernetesStore() (Store, client.Client) {
+	cc := fake.NewClientBuilder().WithScheme(schema.GetScheme()).Build()
+
+	return NewKubernetesStore(cc, "terraform-system"), cc
+}
+
+func TestKubernetesStoreGetMissing(t *testing.T) {
+	store, _ := newTestKubernetesStore()
+
+	content, found, err := store.Get(context.Background(), Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "plan"})
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, content)
+}
+
+func TestKubernetesStorePutGet(t *testing.T) {
+	store, _ := newTestKubernetesStore()
+	key := Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "plan"}
+
+	require.NoError(t, store.Put(context.Background(), key, []byte("first\n")))
+	require.NoError(t, store.Put(context.Background(), key, []byte("second\n")))
+
+	content, found, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "first\nsecond\n", string(content))
+
+	// @note: a different stage should not be found
+	_, found, err = store.Get(context.Background(), Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "apply"})
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestKubernetesStoreChunking(t *testing.T) {
+	store, cc := newTestKubernetesStore()
+	key := Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "plan"}
+
+	// @note: random content compresses poorly, forcing the logs across multiple secrets
+	expected := utils.Random(MaxChunkSize + MaxChunkSize/2)
+	require.NoError(t, store.Put(context.Background(), key, []byte(expected)))
+
+	list := &v1.SecretList{}
+	require.NoError(t, cc.List(context.Background(), list))
+	assert.Len(t, list.Items, 2)
+
+	content, found, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, expected, string(content))
+}
+
+func TestKubernetesStoreDelete(t *testing.T) {
+	store, cc := newTestKubernetesStore()
+
+	for _, stage := range []string{"plan", "apply"} {
+		key := Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: stage}
+		require.NoError(t, store.Put(context.Background(), key, []byte("logs")))
+	}
+	require.NoError(t, store.Put(context.Background(), Key{Namespace: "apps", Name: "other", UID: "5678", Generation: "1", Stage: "plan"}, []byte("logs")))
+
+	assert.Error(t, store.Delete(context.Background(), Key{}))
+	require.NoError(t, store.Delete(context.Background(), Key{Namespace: "apps", Name: "bucket", UID: "1234"}))
+
+	list := &v1.SecretList{}
+	require.NoError(t, cc.List(context.Background(), list))
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, "5678", list.Items[0].Labels["terraform.appvia.io/configuration-uid"])
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// StoreKubernetes archives the logs as secrets in the controller namespace
+	StoreKubernetes = "kubernetes"
+	// StoreS3 archives the logs in an s3 compatible bucket
+	StoreS3 = "s3"
+)
+
+// Store is the interface to an archive of the build logs
+type Store interface {
+	// Delete removes the archived logs matching the key, the generation and stage are optional
+	// and when empty match all the generations and stages of the configuration
+	Delete(ctx context.Context, key Key) error
+	// Get returns the archived logs for the build
+	Get(ctx context.Context, key Key) ([]byte, bool, error)
+	// Put appends the logs to the archive of the build
+	Put(ctx context.Context, key Key, content []byte) error
+}
+
+// Options are the options used to create a store
+type Options struct {
+	// Bucket is the name of the bucket to archive the logs into (s3 only)
+	Bucket string
+	// Endpoint is an optional endpoint for s3 compatible services (s3 only)
+	Endpoint string
+	// Namespace is the namespace to archive the logs into (kubernetes only)
+	Namespace string
+	// Region is the region of the bucket (s3 only)
+	Region string
+	// Type is the type of store, either kubernetes or s3
+	Type string
+}
+
+// IsValid checks the options are valid
+func (o Options) IsValid() error {
+	switch o.Type {
+	case StoreKubernetes:
+		if o.Namespace == "" {
+			return errors.New("namespace is required for the kubernetes log store")
+		}
+
+	case StoreS3:
+		if o.Bucket == "" {
+			return errors.New("bucket is required for the s3 log store")
+		}
+
+	default:
+		return fmt.Errorf("unknown log store %q, must be %s or %s", o.Type, StoreKubernetes, StoreS3)
+	}
+
+	return nil
+}
+
+// New returns a store for the options
+func New(cc client.Client, options Options) (Store, error) {
+	if err := options.IsValid(); err != nil {
+		return nil, err
+	}
+
+	switch options.Type {
+	case StoreS3:
+		return NewS3Store(options)
+	}
+
+	return NewKubernetesStore(cc, options.Namespace), nil
+}
+
+// Key identifies the logs of a build, i.e. a stage of a configuration at a given generation
+type Key struct {
+	// Generation is the generation of the configuration
+	Generation string
+	// Name is the name of the configuration
+	Name string
+	// Namespace is the namespace of the configuration
+	Namespace string
+	// Stage is the terraform stage
+	Stage string
+	// UID is the uid of the configuration
+	UID string
+}
+
+// ParseKey parses a key in the format namespace/name/uid/generation/stage
+func ParseKey(value string) (Key, error) {
+	e := strings.Split(value, "/")
+	if len(e) != 5 {
+		return Key{}, errors.New("key must be in the format namespace/name/uid/generation/stage")
+	}
+	for _, x := range e {
+		if x == "" {
+			return Key{}, errors.New("key must be in the format namespace/name/uid/generation/stage")
+		}
+	}
+
+	return Key{Namespace: e[0], Name: e[1], UID: e[2], Generation: e[3], Stage: e[4]}, nil
+}
+
+// String returns the key in the format namespace/name/uid/generation/stage
+func (k Key) String() string {
+	return strings.Join([]string{k.Namespace, k.Name, k.UID, k.Generation, k.Stage}, "/")
+}
+
+// Prefix returns the path prefix of the key, stopping at the first empty element
+func (k Key) Prefix() string {
+	var elements []string
+
+	for _, x := range []string{k.Namespace, k.Name, k.UID, k.Generation, k.Stage} {
+		if x == "" {
+			break
+		}
+		elements = append(elements, x)
+	}
+
+	return strings.Join(elements, "/") + "/"
+}
+
+// Compress returns the gzip compressed content
+func Compress(content []byte) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	w := gzip.NewWriter(b)
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Decompress returns the content of one or more concatenated gzip streams
+func Decompress(content []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// sensitiveRegex matches the names of environment variables which likely hold credentials
+var sensitiveRegex = regexp.MustCompile(`(?i)(secret|token|password|passwd|credential|private|access_key|api_key|_key$)`)
+
+// SensitiveValues returns the values of the environment variables which likely hold credentials,
+// the environment is expected in the format of os.Environ
+func SensitiveValues(environ []string) []string {
+	var list []string
+
+	for _, x := range environ {
+		e := strings.SplitN(x, "=", 2)
+		if len(e) != 2 || len(e[1]) < 6 {
+			continue
+		}
+		if sensitiveRegex.MatchString(e[0]) {
+			list = append(list, e[1])
+		}
+	}
+
+	return list
+}
+
+// Redact replaces any occurrence of the values in the content
+func Redact(content []byte, values []string) []byte {
+	// @note: replace the longest values first so a value containing another is fully redacted
+	sorted := append([]string{}, values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	for _, x := range sorted {
+		if x == "" {
+			continue
+		}
+		content = bytes.ReplaceAll(content, []byte(x), []byte("[REDACTED]"))
+	}
+
+	return content
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsIsValid(t *testing.T) {
+	cases := []struct {
+		Options  Options
+		Expected string
+	}{
+		{Options: Options{Type: "none"}, Expected: "unknown log store \"none\", must be kubernetes or s3"},
+		{Options: Options{Type: StoreKubernetes}, Expected: "namespace is required for the kubernetes log store"},
+		{Options: Options{Type: StoreKubernetes, Namespace: "terraform-system"}},
+		{Options: Options{Type: StoreS3}, Expected: "bucket is required for the s3 log store"},
+		{Options: Options{Type: StoreS3, Bucket: "logs"}},
+	}
+	for _, c := range cases {
+		err := c.Options.IsValid()
+		if c.Expected == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, c.Expected)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey("apps/bucket/1234/2/plan")
+	require.NoError(t, err)
+	assert.Equal(t, Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "2", Stage: "plan"}, key)
+	assert.Equal(t, "apps/bucket/1234/2/plan", key.String())
+
+	for _, x := range []string{"", "apps/bucket", "apps/bucket/1234//plan", "apps/bucket/1234/2/plan/extra"} {
+		_, err := ParseKey(x)
+		assert.Error(t, err, "expected an error for %q", x)
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	assert.Equal(t, "apps/bucket/1234/2/plan/", Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "2", Stage: "plan"}.Prefix())
+	assert.Equal(t, "apps/bucket/1234/", Key{Namespace: "apps", Name: "bucket", UID: "1234", Stage: "plan"}.Prefix())
+}
+
+func TestCompress(t *testing.T) {
+	compressed, err := Compress([]byte("hello "))
+	require.NoError(t, err)
+	other, err := Compress([]byte("world"))
+	require.NoError(t, err)
+
+	content, err := Decompress(append(compressed, other...))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+}
+
+func TestDecompressInvalid(t *testing.T) {
+	_, err := Decompress([]byte("not compressed"))
+	assert.Error(t, err)
+}
+
+func TestSensitiveValues(t *testing.T) {
+	environ := []string{
+		"AWS_ACCESS_KEY_ID=AKIAEXAMPLE",
+		"AWS_SECRET_ACCESS_KEY=supersecret",
+		"ARM_CLIENT_SECRET=azuresecret",
+		"GITHUB_TOKEN=ghp_example",
+		"HOME=/data",
+		"PASSWORD=short",
+		"SSH_KEY=ssh-rsa-example",
+	}
+	assert.Equal(t, []string{"AKIAEXAMPLE", "supersecret", "azuresecret", "ghp_example", "ssh-rsa-example"}, SensitiveValues(environ))
+}
+
+func TestRedact(t *testing.T) {
+	content := []byte("using key supersecret and supersecret-extended")
+
+	assert.Equal(t, "using key [REDACTED] and [REDACTED]", string(Redact(content, []string{"supersecret", "supersecret-extended", ""})))
+	assert.Equal(t, string(content), string(Redact(content, nil)))
+}
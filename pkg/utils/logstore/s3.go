@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// s3API is the subset of the s3 api used by the store
+type s3API interface {
+	DeleteObjectsWithContext(aws.Context, *s3.DeleteObjectsInput, ...request.Option) (*s3.DeleteObjectsOutput, error)
+	GetObjectWithContext(aws.Context, *s3.GetObjectInput, ...request.Option) (*s3.GetObjectOutput, error)
+	ListObjectsV2PagesWithContext(aws.Context, *s3.ListObjectsV2Input, func(*s3.ListObjectsV2Output, bool) bool, ...request.Option) error
+	PutObjectWithContext(aws.Context, *s3.PutObjectInput, ...request.Option) (*s3.PutObjectOutput, error)
+}
+
+// s3store archives the logs in an s3 compatible bucket
+type s3store struct {
+	// client is the s3 client
+	client s3API
+	// bucket is the name of the bucket
+	bucket string
+}
+
+// NewS3Store returns a store which archives the logs in an s3 compatible bucket. The credentials
+// are taken from the default aws credential chain, i.e. environment variables or web identity
+func NewS3Store(options Options) (Store, error) {
+	config := &aws.Config{}
+	if options.Region != "" {
+		config.Region = aws.String(options.Region)
+	}
+	if options.Endpoint != "" {
+		config.Endpoint = aws.String(options.Endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3store{client: s3.New(sess), bucket: options.Bucket}, nil
+}
+
+// Put appends the logs to the archive of the build
+func (s *s3store) Put(ctx context.Context, key Key, content []byte) error {
+	compressed, err := Compress(content)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Body:        bytes.NewReader(compressed),
+		Bucket:      aws.String(s.bucket),
+		ContentType: aws.String("application/gzip"),
+		Key:         aws.String(fmt.Sprintf("%s%d.log.gz", key.Prefix(), time.Now().UnixNano())),
+	})
+
+	return err
+}
+
+// Get returns the archived logs for the build
+func (s *s3store) Get(ctx context.Context, key Key) ([]byte, bool, error) {
+	keys, err := s.list(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(keys) == 0 {
+		return nil, false, nil
+	}
+
+	var compressed []byte
+	for _, name := range keys {
+		resp, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(name),
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		content, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, false, err
+		}
+		compressed = append(compressed, content...)
+	}
+
+	content, err := Decompress(compressed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return content, true, nil
+}
+
+// Delete removes the archived logs matching the key
+func (s *s3store) Delete(ctx context.Context, key Key) error {
+	if key.UID == "" {
+		return errors.New("uid is required to delete the logs")
+	}
+
+	keys, err := s.list(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// @note: the api permits a maximum of 1000 objects per request
+	for len(keys) > 0 {
+		size := len(keys)
+		if size > 1000 {
+			size = 1000
+		}
+
+		var objects []*s3.ObjectIdentifier
+		for _, name := range keys[:size] {
+			objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(name)})
+		}
+		keys = keys[size:]
+
+		if _, err := s.client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// list returns the sorted object keys under the prefix of the key
+func (s *s3store) list(ctx context.Context, key Key) ([]string, error) {
+	var keys []string
+
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(key.Prefix()),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, x := range page.Contents {
+			keys = append(keys, aws.StringValue(x.Key))
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is an in memory implementation of the s3 api
+type fakeS3 struct {
+	objects map[string][]byte
+}
+
+func (f *fakeS3) DeleteObjectsWithContext(_ aws.Context, input *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	for _, x := range input.Delete.Objects {
+		delete(f.objects, aws.StringValue(x.Key))
+	}
+
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(f.objects[aws.StringValue(input.Key)]))}, nil
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(_ aws.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	page := &s3.ListObjectsV2Output{}
+	for name := range f.objects {
+		if strings.HasPrefix(name, aws.StringValue(input.Prefix)) {
+			page.Contents = append(page.Contents, &s3.Object{Key: aws.String(name)})
+		}
+	}
+	fn(page, true)
+
+	return nil
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, input *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	content, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[aws.StringValue(input.Key)] = content
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+func newTestS3Store() (Store, *fakeS3) {
+	api := &fakeS3{objects: map[string][]byte{}}
+
+	return &s3store{client: api, bucket: "logs"}, api
+}
+
+func TestNewS3Store(t *testing.T) {
+	store, err := New(nil, Options{Type: StoreS3, Bucket: "logs", Endpoint: "http://minio:9000", Region: "eu-west-2"})
+	assert.NoError(t, err)
+	assert.NotNil(t, store)
+}
+
+func TestS3StorePutGet(t *testing.T) {
+	store, api := newTestS3Store()
+	key := Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "plan"}
+
+	_, found, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Put(context.Background(), key, []byte("first\n")))
+	require.NoError(t, store.Put(context.Background(), key, []byte("second\n")))
+
+	var names []string
+	for name := range api.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	require.Len(t, names, 2)
+	assert.True(t, strings.HasPrefix(names[0], "apps/bucket/1234/1/plan/"))
+	assert.True(t, strings.HasSuffix(names[0], ".log.gz"))
+
+	content, found, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "first\nsecond\n", string(content))
+}
+
+func TestS3StoreDelete(t *testing.T) {
+	store, api := newTestS3Store()
+
+	require.NoError(t, store.Put(context.Background(), Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "1", Stage: "plan"}, []byte("logs")))
+	require.NoError(t, store.Put(context.Background(), Key{Namespace: "apps", Name: "bucket", UID: "1234", Generation: "2", Stage: "apply"}, []byte("logs")))
+	require.NoError(t, store.Put(context.Background(), Key{Namespace: "apps", Name: "other", UID: "5678", Generation: "1", Stage: "plan"}, []byte("logs")))
+
+	assert.Error(t, store.Delete(context.Background(), Key{}))
+	require.NoError(t, store.Delete(context.Background(), Key{Namespace: "apps", Name: "bucket", UID: "1234"}))
+
+	assert.Len(t, api.objects, 1)
+	for name := range api.objects {
+		assert.True(t, strings.HasPrefix(name, "apps/other/5678/"))
+	}
+}
@@ -0,0 +1,408 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/appvia/terranetes-controller/pkg/utils/sources"
+)
+
+// MaxManifestSize is the maximum size of a manifest we are willing to read
+const MaxManifestSize = 4 * 1024 * 1024
+
+var challengeRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Client is a minimal client for the oci distribution api, used to pull and push terraform
+// modules
+type Client struct {
+	// HTTPClient is the http client used to talk to the registry
+	HTTPClient *http.Client
+	// Password is the password or token used to authenticate to the registry
+	Password string
+	// PlainHTTP indicates the registry should be contacted over http
+	PlainHTTP bool
+	// Username is the username used to authenticate to the registry
+	Username string
+	// authorizations is a cache of the authorization headers keyed by scope
+	authorizations sync.Map
+}
+
+// NewClient returns a client using the credentials from the environment
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: http.DefaultClient,
+		Password:   os.Getenv(EnvPassword),
+		Username:   os.Getenv(EnvUsername),
+	}
+}
+
+// Pull retrieves the module referenced and extracts it into the directory, returning the digest
+// of the manifest. The manifest and layer are verified against their digests
+func (c *Client) Pull(ctx context.Context, ref Reference, dir string) (string, error) {
+	manifest, digest, err := c.getManifest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	layer, err := findModuleLayer(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := c.getBlob(ctx, ref, "module layer", layer)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sources.Extract(bytes.NewReader(content), dir); err != nil {
+		return "", fmt.Errorf("failed to extract the module: %w", err)
+	}
+
+	return digest, nil
+}
+
+// Push archives the module in the directory and pushes it to the registry under the tag of
+// the reference, returning the digest of the manifest
+func (c *Client) Push(ctx context.Context, ref Reference, dir string) (string, error) {
+	if ref.Tag == "" {
+		return "", errors.New("a tag is required to push the module")
+	}
+
+	archive := &bytes.Buffer{}
+	if err := sources.Archive(dir, archive); err != nil {
+		return "", fmt.Errorf("failed to archive the module: %w", err)
+	}
+	config := []byte("{}")
+
+	manifest := &Manifest{
+		Config:        Descriptor{Digest: digestOf(config), MediaType: ConfigMediaType, Size: int64(len(config))},
+		Layers:        []Descriptor{{Digest: digestOf(archive.Bytes()), MediaType: LayerMediaType, Size: int64(archive.Len())}},
+		MediaType:     ManifestMediaType,
+		SchemaVersion: 2,
+	}
+	for _, blob := range [][]byte{config, archive.Bytes()} {
+		if err := c.pushBlob(ctx, ref, blob); err != nil {
+			return "", err
+		}
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, ref, "pull,push", http.MethodPut, "manifests/"+ref.Tag, encoded,
+		map[string]string{"Content-Type": ManifestMediaType})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to push the manifest, status: %d", resp.StatusCode)
+	}
+
+	return digestOf(encoded), nil
+}
+
+// getManifest retrieves and verifies the manifest for the reference
+func (c *Client) getManifest(ctx context.Context, ref Reference) (*Manifest, string, error) {
+	resp, err := c.do(ctx, ref, "pull", http.MethodGet, "manifests/"+ref.Reference(), nil,
+		map[string]string{"Accept": ManifestMediaType + ", " + DockerManifestMediaType})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", fmt.Errorf("module: %q not found in the registry", ref.String())
+	default:
+		return nil, "", fmt.Errorf("failed to retrieve the manifest for: %q, status: %d", ref.String(), resp.StatusCode)
+	}
+
+	encoded, err := io.ReadAll(io.LimitReader(resp.Body, MaxManifestSize))
+	if err != nil {
+		return nil, "", err
+	}
+	digest := digestOf(encoded)
+	if ref.Digest != "" && ref.Digest != digest {
+		return nil, "", fmt.Errorf("manifest digest: %q does not match: %q", digest, ref.Digest)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(encoded, manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to decode the manifest: %w", err)
+	}
+	switch manifest.MediaType {
+	case "", ManifestMediaType, DockerManifestMediaType:
+	default:
+		return nil, "", fmt.Errorf("unsupported manifest media type: %q", manifest.MediaType)
+	}
+
+	return manifest, digest, nil
+}
+
+// getBlob retrieves the blob described and verifies it matches the digest
+func (c *Client) getBlob(ctx context.Context, ref Reference, name string, blob Descriptor) ([]byte, error) {
+	resp, err := c.do(ctx, ref, "pull", http.MethodGet, "blobs/"+blob.Digest, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to retrieve the %s: %q, status: %d", name, blob.Digest, resp.StatusCode)
+	}
+
+	hash := sha256.New()
+	content := &bytes.Buffer{}
+	if _, err := io.Copy(io.MultiWriter(hash, content), resp.Body); err != nil {
+		return nil, err
+	}
+	if found := "sha256:" + hex.EncodeToString(hash.Sum(nil)); found != blob.Digest {
+		return nil, fmt.Errorf("%s digest: %q does not match: %q", name, found, blob.Digest)
+	}
+
+	return content.Bytes(), nil
+}
+
+// pushBlob uploads the blob to the registry if not already present
+func (c *Client) pushBlob(ctx context.Context, ref Reference, blob []byte) error {
+	digest := digestOf(blob)
+
+	resp, err := c.do(ctx, ref, "pull,push", http.MethodHead, "blobs/"+digest, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, ref, "pull,push", http.MethodPost, "blobs/uploads/", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to start the blob upload, status: %d", resp.StatusCode)
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %w", err)
+	}
+	values := location.Query()
+	values.Set("digest", digest)
+	location.RawQuery = values.Encode()
+
+	resp, err = c.doURL(ctx, ref, "pull,push", http.MethodPut, location.String(), blob,
+		map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to upload the blob: %q, status: %d", digest, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// do performs a request against the repository api of the reference
+func (c *Client) do(ctx context.Context, ref Reference, actions, method, path string, body []byte, headers map[string]string) (*http.Response, error) {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.Registry, ref.Repository, path)
+
+	return c.doURL(ctx, ref, actions, method, endpoint, body, headers)
+}
+
+// doURL performs the request, handling any authentication challenge from the registry
+func (c *Client) doURL(ctx context.Context, ref Reference, actions, method, endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:%s", ref.Repository, actions)
+
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		return c.httpClient().Do(req)
+	}
+
+	var authorization string
+	if value, found := c.authorizations.Load(scope); found {
+		authorization = value.(string)
+	}
+
+	resp, err := send(authorization)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	// @step: handle the authentication challenge and retry the request
+	authorization, err = c.authorize(ctx, resp.Header.Get("WWW-Authenticate"), scope)
+	if err != nil {
+		return nil, err
+	}
+	c.authorizations.Store(scope, authorization)
+
+	return send(authorization)
+}
+
+// authorize returns the authorization header for the challenge from the registry
+func (c *Client) authorize(ctx context.Context, challenge, scope string) (string, error) {
+	kind, _, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(kind) {
+	case "basic":
+		if c.Username == "" && c.Password == "" {
+			return "", errors.New("registry requires authentication, no credentials provided")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(c.Username, c.Password)
+
+		return req.Header.Get("Authorization"), nil
+
+	case "bearer":
+		params := map[string]string{}
+		for _, x := range challengeRegex.FindAllStringSubmatch(challenge, -1) {
+			params[x[1]] = x[2]
+		}
+		if params["realm"] == "" {
+			return "", errors.New("registry authentication challenge has no realm")
+		}
+
+		token, err := c.fetchToken(ctx, params["realm"], params["service"], scope)
+		if err != nil {
+			return "", err
+		}
+
+		return "Bearer " + token, nil
+	}
+
+	return "", fmt.Errorf("unsupported registry authentication challenge: %q", kind)
+}
+
+// fetchToken retrieves a bearer token for the scope from the token service
+func (c *Client) fetchToken(ctx context.Context, realm, service, scope string) (string, error) {
+	endpoint, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm: %w", err)
+	}
+	values := endpoint.Query()
+	if service != "" {
+		values.Set("service", service)
+	}
+	values.Set("scope", scope)
+	endpoint.RawQuery = values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to retrieve a registry token, status: %d", resp.StatusCode)
+	}
+
+	response := struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode the registry token: %w", err)
+	}
+	if response.Token != "" {
+		return response.Token, nil
+	}
+	if response.AccessToken != "" {
+		return response.AccessToken, nil
+	}
+
+	return "", errors.New("registry token response did not contain a token")
+}
+
+// httpClient returns the http client to use
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+
+	return http.DefaultClient
+}
+
+// findModuleLayer returns the layer holding the terraform module
+func findModuleLayer(manifest *Manifest) (Descriptor, error) {
+	for _, x := range manifest.Layers {
+		if x.MediaType == LayerMediaType {
+			return x, nil
+		}
+	}
+	for _, x := range manifest.Layers {
+		if x.MediaType == OCILayerMediaType {
+			return x, nil
+		}
+	}
+
+	return Descriptor{}, errors.New("manifest does not contain a terraform module layer")
+}
+
+// digestOf returns the sha256 digest of the content
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
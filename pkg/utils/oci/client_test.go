@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry is a minimal in-memory oci registry using bearer authentication
+type fakeRegistry struct {
+	sync.Mutex
+	// blobs are the blobs keyed by digest
+	blobs map[string][]byte
+	// manifests are the manifests keyed by tag and digest
+	manifests map[string][]byte
+	// server is the test server
+	server *httptest.Server
+}
+
+// newFakeRegistry returns a registry only accepting the user:pass credentials
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	registry := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	registry.server = httptest.NewServer(http.HandlerFunc(registry.serve))
+	t.Cleanup(registry.server.Close)
+
+	return registry
+}
+
+// reference returns a reference to the repository in the registry
+func (f *fakeRegistry) reference(tag string) Reference {
+	u, _ := url.Parse(f.server.URL)
+
+	return Reference{Registry: u.Host, Repository: "modules/bucket", Tag: tag}
+}
+
+func (f *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if req.URL.Path == "/token" {
+		username, password, _ := req.BasicAuth()
+		if username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+f.server.URL+`/token",service="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/modules/bucket/")
+	switch {
+	case strings.HasPrefix(path, "manifests/"):
+		reference := strings.TrimPrefix(path, "manifests/")
+		switch req.Method {
+		case http.MethodGet:
+			content, found := f.manifests[reference]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+			w.Header().Set("Content-Type", ManifestMediaType)
+			_, _ = w.Write(content)
+
+		case http.MethodPut:
+			content, _ := io.ReadAll(req.Body)
+			f.manifests[reference] = content
+			f.manifests[digestOf(content)] = content
+			w.WriteHeader(http.StatusCreated)
+		}
+
+	case path == "blobs/uploads/" && req.Method == http.MethodPost:
+		w.Header().Set("Location", "/v2/modules/bucket/blobs/uploads/1234?state=abc")
+		w.WriteHeader(http.StatusAccepted)
+
+	case strings.HasPrefix(path, "blobs/uploads/") && req.Method == http.MethodPut:
+		content, _ := io.ReadAll(req.Body)
+		if digestOf(content) != req.URL.Query().Get("digest") || req.URL.Query().Get("state") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+		f.blobs[req.URL.Query().Get("digest")] = content
+		w.WriteHeader(http.StatusCreated)
+
+	case strings.HasPrefix(path, "blobs/"):
+		content, found := f.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// makeModule creates a test module
+func makeModule(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# module\n"), 0600))
+
+	return dir
+}
+
+// newTestClient returns a client for the fake registry
+func newTestClient() *Client {
+	return &Client{PlainHTTP: true, Username: "user", Password: "pass"}
+}
+
+func TestPushAndPull(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(digest, "sha256:"))
+
+	dir := t.TempDir()
+	pulled, err := newTestClient().Pull(context.Background(), ref, dir)
+	require.NoError(t, err)
+	assert.Equal(t, digest, pulled)
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# module\n", string(content))
+}
+
+func TestPullByDigest(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+
+	ref.Digest = digest
+	pulled, err := newTestClient().Pull(context.Background(), ref, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, digest, pulled)
+}
+
+func TestPullDigestMismatch(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+
+	_, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+
+	// @note: point the digest at a manifest which has different content
+	registry.manifests[testDigest] = registry.manifests["v1"]
+	ref.Digest = testDigest
+
+	_, err = newTestClient().Pull(context.Background(), ref, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match")
+}
+
+func TestPullLayerMismatch(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+
+	_, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+
+	manifest := &Manifest{}
+	require.NoError(t, json.Unmarshal(registry.manifests["v1"], manifest))
+	registry.blobs[manifest.Layers[0].Digest] = []byte("tampered")
+
+	_, err = newTestClient().Pull(context.Background(), ref, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "module layer digest")
+}
+
+func TestPullNotFound(t *testing.T) {
+	registry := newFakeRegistry(t)
+
+	_, err := newTestClient().Pull(context.Background(), registry.reference("missing"), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found in the registry")
+}
+
+func TestPullBadCredentials(t *testing.T) {
+	registry := newFakeRegistry(t)
+	client := &Client{PlainHTTP: true, Username: "user", Password: "wrong"}
+
+	_, err := client.Pull(context.Background(), registry.reference("v1"), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to retrieve a registry token")
+}
+
+func TestPushRequiresTag(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("")
+	ref.Digest = testDigest
+
+	_, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	assert.EqualError(t, err, "a tag is required to push the module")
+}
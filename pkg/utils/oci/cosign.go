@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package oci
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// SignatureMediaType is the media type of the cosign simple signing payload
+	SignatureMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation is the annotation on the layer holding the base64 encoded signature
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// SignatureType is the type of the cosign simple signing payload
+	SignatureType = "cosign container image signature"
+)
+
+// SignaturePayload is the simple signing payload signed by cosign
+type SignaturePayload struct {
+	// Critical holds the claims which must be verified
+	Critical struct {
+		// Identity is the identity of the artifact signed
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		// Image is the image signed
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		// Type is the type of the payload
+		Type string `json:"type"`
+	} `json:"critical"`
+	// Optional holds any optional annotations added when signing
+	Optional map[string]interface{} `json:"optional,omitempty"`
+}
+
+// SignatureTag returns the tag cosign stores the signatures of the manifest digest under
+func SignatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// ParsePublicKeys parses one or more PEM encoded public keys
+func ParsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the public key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no public keys found")
+	}
+
+	return keys, nil
+}
+
+// VerifyPayload verifies the signature of the payload using the public key
+func VerifyPayload(key crypto.PublicKey, payload, signature []byte) error {
+	sum := sha256.Sum256(payload)
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, sum[:], signature) {
+			return errors.New("invalid ecdsa signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature); err != nil {
+			if err := rsa.VerifyPSS(key, crypto.SHA256, sum[:], signature, nil); err != nil {
+				return errors.New("invalid rsa signature")
+			}
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return errors.New("invalid ed25519 signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type: %T", key)
+	}
+
+	return nil
+}
+
+// VerifySource checks the manifest digest of the module source carries a cosign signature from
+// one of the public keys. As with the getter the credentials are taken from the environment
+func VerifySource(ctx context.Context, source, digest string, keys []crypto.PublicKey) error {
+	u, err := url.Parse(source)
+	if err != nil {
+		return fmt.Errorf("invalid module source: %w", err)
+	}
+
+	ref, client, err := newClientFromURL(u)
+	if err != nil {
+		return err
+	}
+
+	return client.Verify(ctx, ref, digest, keys)
+}
+
+// Verify checks the manifest digest in the repository has a cosign signature from one of the
+// public keys
+func (c *Client) Verify(ctx context.Context, ref Reference, digest string, keys []crypto.PublicKey) error {
+	if len(keys) == 0 {
+		return errors.New("no public keys provided to verify the signature")
+	}
+
+	signatures := Reference{Registry: ref.Registry, Repository: ref.Repository, Tag: SignatureTag(digest)}
+
+	manifest, _, err := c.getManifest(ctx, signatures)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the signatures for: %q, error: %w", digest, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != SignatureMediaType || layer.Annotations[SignatureAnnotation] == "" {
+			continue
+		}
+
+		signature, err := base64.StdEncoding.DecodeString(layer.Annotations[SignatureAnnotation])
+		if err != nil {
+			continue
+		}
+		payload, err := c.getBlob(ctx, signatures, "signature payload", layer)
+		if err != nil {
+			return err
+		}
+		if !isSignedBy(keys, payload, signature) {
+			continue
+		}
+
+		// @step: the signature is valid, ensure it was made for this manifest
+		decoded := &SignaturePayload{}
+		if err := json.Unmarshal(payload, decoded); err != nil {
+			continue
+		}
+		if decoded.Critical.Type != SignatureType || decoded.Critical.Image.DockerManifestDigest != digest {
+			continue
+		}
+		// @step: and for the repository, and tag if any, we are pulling
+		if isSignedFor(decoded.Critical.Identity.DockerReference, ref) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no valid signature found for: %q", ref.Registry+"/"+ref.Repository+"@"+digest)
+}
+
+// isSignedFor returns true if the docker reference the signature was made for identifies the
+// repository of the reference. Cosign records the repository alone, but when the identity also
+// carries a tag it must match the reference as well. The manifest digest is checked separately
+func isSignedFor(identity string, ref Reference) bool {
+	if identity == "" {
+		return false
+	}
+
+	var signed Reference
+	location := identity
+	if i := strings.Index(location, "@"); i >= 0 {
+		location = location[:i]
+	}
+	if i := strings.LastIndex(location, ":"); i > strings.LastIndex(location, "/") {
+		location, signed.Tag = location[:i], location[i+1:]
+	}
+	i := strings.Index(location, "/")
+	if i <= 0 {
+		return false
+	}
+	signed.Registry, signed.Repository = location[:i], location[i+1:]
+
+	switch {
+	case normalizeRegistry(signed.Registry) != normalizeRegistry(ref.Registry):
+		return false
+	case signed.Repository != ref.Repository:
+		return false
+	case signed.Tag != "" && signed.Tag != ref.Tag:
+		return false
+	}
+
+	return true
+}
+
+// normalizeRegistry returns the canonical name of the registry, docker hub is known by a
+// number of names
+func normalizeRegistry(registry string) string {
+	switch strings.ToLower(registry) {
+	case "docker.io", "registry-1.docker.io", "index.docker.io":
+		return "index.docker.io"
+	}
+
+	return strings.ToLower(registry)
+}
+
+// isSignedBy returns true if the signature of the payload was made by any of the keys
+func isSignedBy(keys []crypto.PublicKey, payload, signature []byte) bool {
+	for _, key := range keys {
+		if VerifyPayload(key, payload, signature) == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package oci
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signModule pushes a cosign signature for the manifest digest in the repository, signed by the key
+func signModule(t *testing.T, registry *fakeRegistry, digest, signed string, key *ecdsa.PrivateKey) {
+	ref := registry.reference("")
+
+	signModuleAs(t, registry, digest, signed, ref.Registry+"/"+ref.Repository, key)
+}
+
+// signModuleAs pushes a cosign signature for the manifest digest, made for the docker reference
+func signModuleAs(t *testing.T, registry *fakeRegistry, digest, signed, identity string, key *ecdsa.PrivateKey) {
+	payload := &SignaturePayload{}
+	payload.Critical.Identity.DockerReference = identity
+	payload.Critical.Image.DockerManifestDigest = signed
+	payload.Critical.Type = SignatureType
+	encoded, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(encoded)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(t, err)
+
+	config := []byte("{}")
+	manifest, err := json.Marshal(&Manifest{
+		Config: Descriptor{Digest: digestOf(config), MediaType: ConfigMediaType, Size: int64(len(config))},
+		Layers: []Descriptor{{
+			Annotations: map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+			Digest:      digestOf(encoded),
+			MediaType:   SignatureMediaType,
+			Size:        int64(len(encoded)),
+		}},
+		MediaType:     ManifestMediaType,
+		SchemaVersion: 2,
+	})
+	require.NoError(t, err)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.blobs[digestOf(encoded)] = encoded
+	registry.manifests[SignatureTag(digest)] = manifest
+}
+
+// newSigningKey returns a new ecdsa key and the pem encoded public key
+func newSigningKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encoded, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: encoded})
+}
+
+func TestSignatureTag(t *testing.T) {
+	assert.Equal(t, "sha256-1234.sig", SignatureTag("sha256:1234"))
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	_, first := newSigningKey(t)
+	_, second := newSigningKey(t)
+
+	keys, err := ParsePublicKeys(append(first, second...))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestParsePublicKeysNone(t *testing.T) {
+	_, err := ParsePublicKeys([]byte("not a key"))
+	require.Error(t, err)
+	assert.Equal(t, "no public keys found", err.Error())
+}
+
+func TestVerifyPayloadEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signature := ed25519.Sign(private, []byte("payload"))
+	assert.NoError(t, VerifyPayload(public, []byte("payload"), signature))
+	assert.Error(t, VerifyPayload(public, []byte("tampered"), signature))
+}
+
+func TestVerify(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	key, public := newSigningKey(t)
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+	signModule(t, registry, digest, digest, key)
+
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+	assert.NoError(t, newTestClient().Verify(context.Background(), ref, digest, keys))
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	_, public := newSigningKey(t)
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+	err = newTestClient().Verify(context.Background(), ref, digest, keys)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to retrieve the signatures")
+}
+
+func TestVerifyWrongKey(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	key, _ := newSigningKey(t)
+	_, public := newSigningKey(t)
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+	signModule(t, registry, digest, digest, key)
+
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+	err = newTestClient().Verify(context.Background(), ref, digest, keys)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no valid signature found")
+}
+
+func TestVerifyOtherDigest(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	key, public := newSigningKey(t)
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+	// @note: a valid signature copied from another manifest
+	signModule(t, registry, digest, testDigest, key)
+
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+	err = newTestClient().Verify(context.Background(), ref, digest, keys)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no valid signature found")
+}
+
+func TestVerifyIdentity(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	key, public := newSigningKey(t)
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+
+	cases := []struct {
+		Identity string
+		Expected bool
+	}{
+		{Identity: ref.Registry + "/modules/bucket", Expected: true},
+		{Identity: ref.Registry + "/modules/bucket:v1", Expected: true},
+		{Identity: ref.Registry + "/modules/bucket:v1@" + digest, Expected: true},
+		{Identity: ""},
+		{Identity: ref.Registry + "/modules/other"},
+		{Identity: ref.Registry + "/modules/bucket:v2"},
+		{Identity: "other.registry.io/modules/bucket"},
+		{Identity: "modules/bucket"},
+	}
+	for _, c := range cases {
+		signModuleAs(t, registry, digest, digest, c.Identity, key)
+
+		err := newTestClient().Verify(context.Background(), ref, digest, keys)
+		if c.Expected {
+			assert.NoError(t, err, "identity: %q", c.Identity)
+
+			continue
+		}
+		require.Error(t, err, "identity: %q", c.Identity)
+		assert.Contains(t, err.Error(), "no valid signature found")
+	}
+}
+
+func TestIsSignedFor(t *testing.T) {
+	ref := Reference{Registry: "docker.io", Repository: "appvia/modules", Tag: "v1"}
+
+	assert.True(t, isSignedFor("index.docker.io/appvia/modules", ref))
+	assert.True(t, isSignedFor("registry-1.docker.io/appvia/modules:v1", ref))
+	assert.False(t, isSignedFor("index.docker.io/appvia/modules:v2", ref))
+	assert.False(t, isSignedFor("index.docker.io/appvia/other", ref))
+	assert.False(t, isSignedFor("ghcr.io/appvia/modules", ref))
+}
+
+func TestVerifySource(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	key, public := newSigningKey(t)
+
+	t.Setenv(EnvUsername, "user")
+	t.Setenv(EnvPassword, "pass")
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+
+	source := ref.String() + "?insecure=true"
+	assert.Error(t, VerifySource(context.Background(), source, digest, keys))
+
+	signModule(t, registry, digest, digest, key)
+	assert.NoError(t, VerifySource(context.Background(), source, digest, keys))
+}
+
+func TestVerifySourceIdentity(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+	key, public := newSigningKey(t)
+
+	t.Setenv(EnvUsername, "user")
+	t.Setenv(EnvPassword, "pass")
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+	keys, err := ParsePublicKeys(public)
+	require.NoError(t, err)
+
+	source := ref.String() + "?insecure=true"
+
+	// @note: a signature made for another repository in the registry
+	signModuleAs(t, registry, digest, digest, ref.Registry+"/modules/other", key)
+	assert.Error(t, VerifySource(context.Background(), source, digest, keys))
+
+	// @note: a signature made for another tag of the repository
+	signModuleAs(t, registry, digest, digest, ref.Registry+"/modules/bucket:v2", key)
+	assert.Error(t, VerifySource(context.Background(), source, digest, keys))
+
+	signModuleAs(t, registry, digest, digest, ref.Registry+"/modules/bucket:v1", key)
+	assert.NoError(t, VerifySource(context.Background(), source, digest, keys))
+}
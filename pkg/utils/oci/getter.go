@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package oci
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/go-getter"
+)
+
+// Getter is a go-getter implementation retrieving terraform modules from an oci registry. The
+// credentials are taken from the OCI_USERNAME and OCI_PASSWORD environment variables, while
+// plain http registries can be used by adding insecure=true to the source
+type Getter struct {
+	// Digest is the digest of the manifest last retrieved
+	Digest string
+	// client is the go-getter client using the getter
+	client *getter.Client
+}
+
+// ClientMode returns the mode of the getter, modules are always directories
+func (g *Getter) ClientMode(_ *url.URL) (getter.ClientMode, error) {
+	return getter.ClientModeDir, nil
+}
+
+// SetClient sets the go-getter client
+func (g *Getter) SetClient(client *getter.Client) {
+	g.client = client
+}
+
+// Get retrieves the module into the directory
+func (g *Getter) Get(dst string, u *url.URL) error {
+	ctx := context.Background()
+	if g.client != nil && g.client.Ctx != nil {
+		ctx = g.client.Ctx
+	}
+
+	ref, client, err := newClientFromURL(u)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dst, 0750); err != nil {
+		return err
+	}
+
+	digest, err := client.Pull(ctx, ref, dst)
+	if err != nil {
+		return err
+	}
+	g.Digest = digest
+
+	return nil
+}
+
+// GetFile is not supported, modules are always directories
+func (g *Getter) GetFile(_ string, _ *url.URL) error {
+	return errors.New("retrieving a single file from an oci registry is not supported")
+}
+
+// newClientFromURL returns the reference and a client for the module source
+func newClientFromURL(u *url.URL) (Reference, *Client, error) {
+	ref, err := FromURL(u)
+	if err != nil {
+		return ref, nil, err
+	}
+
+	client := NewClient()
+	if insecure, err := strconv.ParseBool(u.Query().Get("insecure")); err == nil {
+		client.PlainHTTP = insecure
+	}
+
+	return ref, client, nil
+}
+
+// Getters returns the default go-getter getters along with the oci getter
+func Getters(oci *Getter) map[string]getter.Getter {
+	getters := map[string]getter.Getter{Scheme: oci}
+	for k, v := range getter.Getters {
+		getters[k] = v
+	}
+
+	return getters
+}
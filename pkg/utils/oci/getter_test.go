@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package oci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-getter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetter(t *testing.T) {
+	registry := newFakeRegistry(t)
+	ref := registry.reference("v1")
+
+	digest, err := newTestClient().Push(context.Background(), ref, makeModule(t))
+	require.NoError(t, err)
+
+	t.Setenv(EnvUsername, "user")
+	t.Setenv(EnvPassword, "pass")
+
+	oci := &Getter{}
+	dst := filepath.Join(t.TempDir(), "module")
+	client := &getter.Client{
+		Ctx:     context.Background(),
+		Dst:     dst,
+		Getters: Getters(oci),
+		Mode:    getter.ClientModeAny,
+		Src:     ref.String() + "?insecure=true",
+	}
+	require.NoError(t, client.Get())
+	assert.Equal(t, digest, oci.Digest)
+
+	content, err := os.ReadFile(filepath.Join(dst, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# module\n", string(content))
+}
+
+func TestGetterGetFile(t *testing.T) {
+	assert.Error(t, (&Getter{}).GetFile("/tmp/file", nil))
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// Scheme is the scheme used by module sources held in an oci registry
+	Scheme = "oci"
+	// ConfigMediaType is the media type of the config blob for a terraform module
+	ConfigMediaType = "application/vnd.terranetes.module.config.v1+json"
+	// LayerMediaType is the media type of the layer holding a terraform module
+	LayerMediaType = "application/vnd.terranetes.module.layer.v1.tar+gzip"
+	// ManifestMediaType is the media type of an oci image manifest
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// DockerManifestMediaType is the media type of a docker v2 image manifest
+	DockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	// OCILayerMediaType is the media type of a standard oci gzipped layer
+	OCILayerMediaType = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+const (
+	// EnvUsername is the environment variable holding the username for the registry
+	EnvUsername = "OCI_USERNAME"
+	// EnvPassword is the environment variable holding the password or token for the registry
+	EnvPassword = "OCI_PASSWORD"
+)
+
+// Descriptor describes a blob held in the registry
+type Descriptor struct {
+	// Annotations are optional annotations on the blob
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Digest is the digest of the blob
+	Digest string `json:"digest"`
+	// MediaType is the media type of the blob
+	MediaType string `json:"mediaType"`
+	// Size is the size of the blob in bytes
+	Size int64 `json:"size"`
+}
+
+// Manifest is an oci image manifest
+type Manifest struct {
+	// Annotations are optional annotations on the manifest
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Config is the config blob of the manifest
+	Config Descriptor `json:"config"`
+	// Layers are the layers of the manifest
+	Layers []Descriptor `json:"layers"`
+	// MediaType is the media type of the manifest
+	MediaType string `json:"mediaType"`
+	// SchemaVersion is the version of the manifest schema
+	SchemaVersion int `json:"schemaVersion"`
+}
+
+// Reference is a reference to an artifact in an oci registry
+type Reference struct {
+	// Digest is the optional digest of the manifest
+	Digest string
+	// Registry is the host (and port) of the registry
+	Registry string
+	// Repository is the name of the repository
+	Repository string
+	// Tag is the optional tag of the manifest
+	Tag string
+}
+
+// IsSource returns true if the module source is held in an oci registry
+func IsSource(source string) bool {
+	return strings.HasPrefix(source, Scheme+"://")
+}
+
+// ParseReference parses a reference of the form oci://registry/repository[:tag][@digest]
+func ParseReference(value string) (Reference, error) {
+	var ref Reference
+
+	location := strings.TrimPrefix(value, Scheme+"://")
+	if location == "" {
+		return ref, errors.New("reference is empty")
+	}
+	if i := strings.Index(location, "@"); i >= 0 {
+		location, ref.Digest = location[:i], location[i+1:]
+		if !strings.HasPrefix(ref.Digest, "sha256:") || len(ref.Digest) != 71 {
+			return ref, fmt.Errorf("invalid digest: %q in reference", ref.Digest)
+		}
+	}
+
+	i := strings.Index(location, "/")
+	if i <= 0 || i == len(location)-1 {
+		return ref, fmt.Errorf("reference: %q must include a registry and repository", value)
+	}
+	ref.Registry, ref.Repository = location[:i], location[i+1:]
+
+	if i := strings.LastIndex(ref.Repository, ":"); i >= 0 {
+		ref.Repository, ref.Tag = ref.Repository[:i], ref.Repository[i+1:]
+		if ref.Tag == "" {
+			return ref, fmt.Errorf("reference: %q has an empty tag", value)
+		}
+	}
+	if ref.Repository == "" || strings.ToLower(ref.Repository) != ref.Repository {
+		return ref, fmt.Errorf("reference: %q has an invalid repository", value)
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	return ref, nil
+}
+
+// FromURL returns the reference from a parsed source url
+func FromURL(u *url.URL) (Reference, error) {
+	return ParseReference(u.Host + u.Path)
+}
+
+// Reference returns the tag or digest used to retrieve the manifest, preferring the digest
+func (r Reference) Reference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+
+	return r.Tag
+}
+
+// String returns the reference as a module source
+func (r Reference) String() string {
+	value := Scheme + "://" + r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		value += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		value += "@" + r.Digest
+	}
+
+	return value
+}
+
+// PinDigest returns the module source pinned to the manifest digest. Any query parameters or
+// subdirectory on the source are retained
+func PinDigest(source, digest string) (string, error) {
+	if digest == "" || !IsSource(source) {
+		return source, nil
+	}
+
+	location, query := source, ""
+	if i := strings.Index(location, "?"); i >= 0 {
+		location, query = location[:i], location[i:]
+	}
+	var subdir string
+	if i := strings.Index(strings.TrimPrefix(location, Scheme+"://"), "//"); i >= 0 {
+		i += len(Scheme + "://")
+		location, subdir = location[:i], location[i:]
+	}
+
+	ref, err := ParseReference(location)
+	if err != nil {
+		return "", err
+	}
+	ref.Digest = digest
+
+	return ref.String() + subdir + query, nil
+}
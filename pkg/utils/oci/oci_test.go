@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:5d41402abc4b2a76b9719d911017c5925d41402abc4b2a76b9719d911017c592"
+
+func TestIsSource(t *testing.T) {
+	assert.True(t, IsSource("oci://ghcr.io/appvia/modules/bucket:v1.0.0"))
+	assert.False(t, IsSource("https://github.com/appvia/terranetes-controller.git"))
+	assert.False(t, IsSource("ghcr.io/appvia/modules/bucket:v1.0.0"))
+}
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		Value    string
+		Expected Reference
+		Error    bool
+	}{
+		{
+			Value:    "oci://ghcr.io/appvia/modules/bucket:v1.0.0",
+			Expected: Reference{Registry: "ghcr.io", Repository: "appvia/modules/bucket", Tag: "v1.0.0"},
+		},
+		{
+			Value:    "oci://localhost:5000/bucket",
+			Expected: Reference{Registry: "localhost:5000", Repository: "bucket", Tag: "latest"},
+		},
+		{
+			Value:    "oci://localhost:5000/bucket:v1@" + testDigest,
+			Expected: Reference{Registry: "localhost:5000", Repository: "bucket", Tag: "v1", Digest: testDigest},
+		},
+		{
+			Value:    "ghcr.io/bucket@" + testDigest,
+			Expected: Reference{Registry: "ghcr.io", Repository: "bucket", Digest: testDigest},
+		},
+		{Value: "", Error: true},
+		{Value: "oci://ghcr.io", Error: true},
+		{Value: "oci://ghcr.io/", Error: true},
+		{Value: "oci://ghcr.io/bucket:", Error: true},
+		{Value: "oci://ghcr.io/Bucket:v1", Error: true},
+		{Value: "oci://ghcr.io/bucket@sha256:abc", Error: true},
+	}
+	for _, c := range cases {
+		ref, err := ParseReference(c.Value)
+		if c.Error {
+			assert.Error(t, err, "expected an error for %q", c.Value)
+
+			continue
+		}
+		require.NoError(t, err, "value: %q", c.Value)
+		assert.Equal(t, c.Expected, ref, "value: %q", c.Value)
+	}
+}
+
+func TestReferenceString(t *testing.T) {
+	ref := Reference{Registry: "ghcr.io", Repository: "appvia/bucket", Tag: "v1"}
+	assert.Equal(t, "oci://ghcr.io/appvia/bucket:v1", ref.String())
+	assert.Equal(t, "v1", ref.Reference())
+
+	ref.Digest = testDigest
+	assert.Equal(t, "oci://ghcr.io/appvia/bucket:v1@"+testDigest, ref.String())
+	assert.Equal(t, testDigest, ref.Reference())
+}
+
+func TestPinDigest(t *testing.T) {
+	cases := []struct {
+		Source   string
+		Digest   string
+		Expected string
+	}{
+		{
+			Source:   "oci://ghcr.io/appvia/bucket:v1",
+			Digest:   testDigest,
+			Expected: "oci://ghcr.io/appvia/bucket:v1@" + testDigest,
+		},
+		{
+			Source:   "oci://localhost:5000/bucket:v1//modules/vpc?insecure=true",
+			Digest:   testDigest,
+			Expected: "oci://localhost:5000/bucket:v1@" + testDigest + "//modules/vpc?insecure=true",
+		},
+		{
+			Source:   "oci://ghcr.io/appvia/bucket:v1",
+			Expected: "oci://ghcr.io/appvia/bucket:v1",
+		},
+		{
+			Source:   "git::https://github.com/appvia/terranetes-controller.git",
+			Digest:   testDigest,
+			Expected: "git::https://github.com/appvia/terranetes-controller.git",
+		},
+	}
+	for _, c := range cases {
+		pinned, err := PinDigest(c.Source, c.Digest)
+		require.NoError(t, err)
+		assert.Equal(t, c.Expected, pinned, "source: %q", c.Source)
+	}
+}
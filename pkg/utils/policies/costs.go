@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// CostEstimate is the predicted monthly costs for a configuration
+type CostEstimate struct {
+	// Monthly is the predicted monthly cost of the configuration
+	Monthly float64
+	// MonthlyIncrease is the change in the predicted monthly cost introduced by the plan
+	MonthlyIncrease float64
+	// NamespaceMonthly is the predicted monthly cost of all configurations in the namespace,
+	// including this one
+	NamespaceMonthly float64
+}
+
+// FindCostConstraints returns the cost constraints from all policies which select the configuration
+func FindCostConstraints(
+	configuration *terraformv1alpha1.Configuration,
+	namespace client.Object,
+	list *terraformv1alpha1.PolicyList) ([]terraformv1alpha1.CostConstraint, error) {
+
+	var constraints []terraformv1alpha1.CostConstraint
+
+	for _, policy := range list.Items {
+		switch {
+		case policy.Spec.Constraints == nil:
+			continue
+		case policy.Spec.Constraints.Costs == nil:
+			continue
+		}
+
+		constraint := policy.Spec.Constraints.Costs
+		if constraint.Selector != nil {
+			matched, err := kubernetes.IsSelectorMatch(*constraint.Selector, configuration.GetLabels(), namespace.GetLabels())
+			if err != nil {
+				return nil, fmt.Errorf("failed to check cost selector on policy: %s, error: %w", policy.Name, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		constraints = append(constraints, *constraint)
+	}
+
+	return constraints, nil
+}
+
+// CheckCostConstraints returns a description of every budget the estimate breaches
+func CheckCostConstraints(constraints []terraformv1alpha1.CostConstraint, estimate CostEstimate) []string {
+	var breaches []string
+
+	for _, x := range constraints {
+		if x.MaxMonthly != nil && estimate.Monthly > x.MaxMonthly.AsApproximateFloat64() {
+			breaches = append(breaches, fmt.Sprintf("predicted monthly cost $%.2f exceeds the budget of $%.2f",
+				estimate.Monthly, x.MaxMonthly.AsApproximateFloat64()))
+		}
+		if x.MaxMonthlyIncrease != nil && estimate.MonthlyIncrease > x.MaxMonthlyIncrease.AsApproximateFloat64() {
+			breaches = append(breaches, fmt.Sprintf("predicted monthly increase $%.2f exceeds the budget of $%.2f",
+				estimate.MonthlyIncrease, x.MaxMonthlyIncrease.AsApproximateFloat64()))
+		}
+		if x.MaxNamespaceMonthly != nil && estimate.NamespaceMonthly > x.MaxNamespaceMonthly.AsApproximateFloat64() {
+			breaches = append(breaches, fmt.Sprintf("predicted namespace monthly cost $%.2f exceeds the budget of $%.2f",
+				estimate.NamespaceMonthly, x.MaxNamespaceMonthly.AsApproximateFloat64()))
+		}
+	}
+
+	return breaches
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestFindCostConstraintsEmpty(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+
+	constraints, err := FindCostConstraints(configuration, namespace, &terraformv1alpha1.PolicyList{})
+	assert.NoError(t, err)
+	assert.Empty(t, constraints)
+}
+
+func TestFindCostConstraints(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	configuration.Labels = map[string]string{"app": "test"}
+	namespace := fixtures.NewNamespace("default")
+
+	all := fixtures.NewCostPolicy("all", "100")
+	matched := fixtures.NewCostPolicy("matched", "50")
+	matched.Spec.Constraints.Costs.Selector = &terraformv1alpha1.Selector{
+		Resource: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+	}
+	other := fixtures.NewCostPolicy("other", "10")
+	other.Spec.Constraints.Costs.Selector = &terraformv1alpha1.Selector{
+		Resource: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+	}
+	list := &terraformv1alpha1.PolicyList{Items: []terraformv1alpha1.Policy{*all, *matched, *other}}
+
+	constraints, err := FindCostConstraints(configuration, namespace, list)
+	assert.NoError(t, err)
+	assert.Len(t, constraints, 2)
+}
+
+func TestCheckCostConstraintsWithinBudget(t *testing.T) {
+	budget := resource.MustParse("100")
+	constraints := []terraformv1alpha1.CostConstraint{{MaxMonthly: &budget}}
+
+	assert.Empty(t, CheckCostConstraints(constraints, CostEstimate{Monthly: 99.5}))
+	assert.Empty(t, CheckCostConstraints(nil, CostEstimate{Monthly: 1000}))
+}
+
+func TestCheckCostConstraints(t *testing.T) {
+	budget := resource.MustParse("100")
+	increase := resource.MustParse("10")
+	namespace := resource.MustParse("500")
+	constraints := []terraformv1alpha1.CostConstraint{
+		{MaxMonthly: &budget, MaxMonthlyIncrease: &increase, MaxNamespaceMonthly: &namespace},
+	}
+
+	breaches := CheckCostConstraints(constraints, CostEstimate{Monthly: 120, MonthlyIncrease: 20.5, NamespaceMonthly: 600})
+	assert.Equal(t, []string{
+		"predicted monthly cost $120.00 exceeds the budget of $100.00",
+		"predicted monthly increase $20.50 exceeds the budget of $10.00",
+		"predicted namespace monthly cost $600.00 exceeds the budget of $500.00",
+	}, breaches)
+}
@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
 	"github.com/appvia/terranetes-controller/pkg/utils/weights"
 )
 
@@ -66,6 +67,24 @@ func FindSecurityPolicyConstraints(list *terraformv1alpha1.PolicyList) []terrafo
 	return filtered
 }
 
+// FindRegoConstraints returns all policies related to rego constraints
+func FindRegoConstraints(list *terraformv1alpha1.PolicyList) []terraformv1alpha1.Policy {
+	var filtered []terraformv1alpha1.Policy
+
+	for _, policy := range list.Items {
+		switch {
+		case policy.Spec.Constraints == nil:
+			continue
+		case policy.Spec.Constraints.Rego == nil:
+			continue
+		}
+
+		filtered = append(filtered, policy)
+	}
+
+	return filtered
+}
+
 // FindMatchingPolicy is called to find a match of policy for a given configurations
 func FindMatchingPolicy(
 	ctx context.Context,
@@ -126,3 +145,59 @@ func FindMatchingPolicy(
 
 	return matches[0].(*terraformv1alpha1.Policy).Spec.Constraints.Checkov, nil
 }
+
+// FindMatchingRegoPolicy is called to find the rego constraint for a given configuration. As with
+// the checkov policies the most specific policy wins, and multiple policies of equal weight are an error
+func FindMatchingRegoPolicy(
+	configuration *terraformv1alpha1.Configuration,
+	namespace client.Object,
+	list *terraformv1alpha1.PolicyList) (*terraformv1alpha1.RegoConstraint, error) {
+
+	filtered := FindRegoConstraints(list)
+	if len(filtered) == 0 {
+		return nil, nil
+	}
+
+	priority := weights.New()
+
+	for i := 0; i < len(filtered); i++ {
+		weight := 0
+		selector := filtered[i].Spec.Constraints.Rego.Selector
+
+		if selector != nil {
+			if selector.Namespace != nil {
+				matched, err := kubernetes.IsLabelSelectorMatch(namespace.GetLabels(), *selector.Namespace)
+				if err != nil {
+					return nil, err
+				}
+				if !matched {
+					continue
+				}
+				weight += 10
+			}
+
+			if selector.Resource != nil {
+				matched, err := kubernetes.IsLabelSelectorMatch(configuration.GetLabels(), *selector.Resource)
+				if err != nil {
+					return nil, err
+				}
+				if !matched {
+					continue
+				}
+				weight += 20
+			}
+		}
+		priority.Add(&filtered[i], weight)
+	}
+
+	if priority.Size() == 0 {
+		return nil, nil
+	}
+
+	matches := priority.Highest()
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("multiple rego policies match configuration: %s", strings.Join(priority.HighestNames(), ", "))
+	}
+
+	return matches[0].(*terraformv1alpha1.Policy).Spec.Constraints.Rego, nil
+}
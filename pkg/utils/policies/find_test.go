@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
 	"github.com/appvia/terranetes-controller/test/fixtures"
@@ -85,3 +86,49 @@ func TestFindSecurityPolicyConstraints(t *testing.T) {
 	filtered := FindSecurityPolicyConstraints(list)
 	assert.Len(t, filtered, 1)
 }
+
+func TestFindMatchingRegoPolicyNone(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+
+	constraint, err := FindMatchingRegoPolicy(configuration, namespace, &terraformv1alpha1.PolicyList{})
+	assert.NoError(t, err)
+	assert.Nil(t, constraint)
+}
+
+func TestFindMatchingRegoPolicy(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	configuration.Labels = map[string]string{"app": "test"}
+	namespace := fixtures.NewNamespace("default")
+
+	all := fixtures.NewRegoPolicy("all", "package main")
+	matched := fixtures.NewRegoPolicy("matched", "package matched")
+	matched.Spec.Constraints.Rego.Selector = &terraformv1alpha1.Selector{
+		Resource: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+	}
+	other := fixtures.NewRegoPolicy("other", "package other")
+	other.Spec.Constraints.Rego.Selector = &terraformv1alpha1.Selector{
+		Resource: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+	}
+
+	list := &terraformv1alpha1.PolicyList{Items: []terraformv1alpha1.Policy{*all, *matched, *other}}
+
+	constraint, err := FindMatchingRegoPolicy(configuration, namespace, list)
+	assert.NoError(t, err)
+	assert.NotNil(t, constraint)
+	assert.Equal(t, "package matched", constraint.Modules[0].Content)
+}
+
+func TestFindMatchingRegoPolicyMultiple(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+
+	list := &terraformv1alpha1.PolicyList{Items: []terraformv1alpha1.Policy{
+		*fixtures.NewRegoPolicy("first", "package main"),
+		*fixtures.NewRegoPolicy("second", "package main"),
+	}}
+
+	constraint, err := FindMatchingRegoPolicy(configuration, namespace, list)
+	assert.Error(t, err)
+	assert.Nil(t, constraint)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+	"github.com/appvia/terranetes-controller/pkg/utils/schedule"
+)
+
+// FindMaintenanceWindows returns the maintenance windows from all policies which select the configuration
+func FindMaintenanceWindows(
+	configuration *terraformv1alpha1.Configuration,
+	namespace client.Object,
+	list *terraformv1alpha1.PolicyList) ([]terraformv1alpha1.MaintenanceWindow, error) {
+
+	var windows []terraformv1alpha1.MaintenanceWindow
+
+	for _, policy := range list.Items {
+		switch {
+		case policy.Spec.Constraints == nil:
+			continue
+		case policy.Spec.Constraints.Maintenance == nil:
+			continue
+		}
+
+		constraint := policy.Spec.Constraints.Maintenance
+		if constraint.Selector != nil {
+			matched, err := kubernetes.IsSelectorMatch(*constraint.Selector, configuration.GetLabels(), namespace.GetLabels())
+			if err != nil {
+				return nil, fmt.Errorf("failed to check maintenance selector on policy: %s, error: %w", policy.Name, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		windows = append(windows, constraint.Windows...)
+	}
+
+	return windows, nil
+}
+
+// IsMaintenanceWindowOpen returns true if the time falls within any of the maintenance windows, or
+// no windows have been defined. When closed, the time the next window opens is returned.
+func IsMaintenanceWindowOpen(windows []terraformv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	if len(windows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	var next time.Time
+
+	for i, x := range windows {
+		s, err := schedule.Parse(x.Schedule)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("maintenance window[%d] has an invalid schedule: %w", i, err)
+		}
+		location, err := x.GetLocation()
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("maintenance window[%d] has an invalid time zone: %w", i, err)
+		}
+
+		// @note: the window is open if it opened within the duration of now
+		opened := s.Next(now.In(location).Add(-x.Duration.Duration))
+		if opened.IsZero() {
+			continue
+		}
+		if !opened.After(now) {
+			return true, time.Time{}, nil
+		}
+
+		if next.IsZero() || opened.Before(next) {
+			next = opened
+		}
+	}
+
+	return false, next, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestFindMaintenanceWindowsEmpty(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+
+	windows, err := FindMaintenanceWindows(configuration, namespace, &terraformv1alpha1.PolicyList{})
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+}
+
+func TestFindMaintenanceWindows(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+	namespace.Labels = map[string]string{"env": "prod"}
+
+	prod := fixtures.NewMaintenancePolicy("prod", "0 22 * * *", time.Hour)
+	prod.Spec.Constraints.Maintenance.Selector = &terraformv1alpha1.Selector{
+		Namespace: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+	}
+	dev := fixtures.NewMaintenancePolicy("dev", "0 9 * * *", time.Hour)
+	dev.Spec.Constraints.Maintenance.Selector = &terraformv1alpha1.Selector{
+		Namespace: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}
+	list := &terraformv1alpha1.PolicyList{
+		Items: []terraformv1alpha1.Policy{
+			*fixtures.NewMatchAllPolicyConstraint("checkov"),
+			*prod,
+			*dev,
+			*fixtures.NewMaintenancePolicy("all", "0 1 * * *", time.Hour),
+		},
+	}
+
+	windows, err := FindMaintenanceWindows(configuration, namespace, list)
+	assert.NoError(t, err)
+	assert.Len(t, windows, 2)
+	assert.Equal(t, "0 22 * * *", windows[0].Schedule)
+	assert.Equal(t, "0 1 * * *", windows[1].Schedule)
+}
+
+func TestIsMaintenanceWindowOpen(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 22, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		Windows []terraformv1alpha1.MaintenanceWindow
+		Open    bool
+		Next    time.Time
+		Error   string
+	}{
+		{
+			Open: true,
+		},
+		{
+			Windows: []terraformv1alpha1.MaintenanceWindow{
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			Open: true,
+		},
+		{
+			Windows: []terraformv1alpha1.MaintenanceWindow{
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 30 * time.Minute}},
+			},
+			Next: time.Date(2022, time.June, 16, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			Windows: []terraformv1alpha1.MaintenanceWindow{
+				{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+				{Schedule: "0 23 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			Next: time.Date(2022, time.June, 15, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			Windows: []terraformv1alpha1.MaintenanceWindow{
+				{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/London"},
+			},
+			Next: time.Date(2022, time.June, 16, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			Windows: []terraformv1alpha1.MaintenanceWindow{
+				{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			Error: "maintenance window[0] has an invalid schedule: expected 5 fields in schedule, found 4",
+		},
+		{
+			Windows: []terraformv1alpha1.MaintenanceWindow{
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Nowhere/Land"},
+			},
+			Error: "maintenance window[0] has an invalid time zone: unknown time zone Nowhere/Land",
+		},
+	}
+	for i, c := range cases {
+		open, next, err := IsMaintenanceWindowOpen(c.Windows, now)
+		if c.Error != "" {
+			assert.Error(t, err, "case %d", i)
+			assert.Equal(t, c.Error, err.Error(), "case %d", i)
+
+			continue
+		}
+		assert.NoError(t, err, "case %d", i)
+		assert.Equal(t, c.Open, open, "case %d", i)
+		assert.True(t, c.Next.Equal(next), "case %d, expected %s, got %s", i, c.Next, next)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/pkg/utils/kubernetes"
+)
+
+// FindOperationsConstraints returns the operations constraints from all policies which select the configuration
+func FindOperationsConstraints(
+	configuration *terraformv1alpha1.Configuration,
+	namespace client.Object,
+	list *terraformv1alpha1.PolicyList) ([]terraformv1alpha1.OperationsConstraint, error) {
+
+	var constraints []terraformv1alpha1.OperationsConstraint
+
+	for _, policy := range list.Items {
+		switch {
+		case policy.Spec.Constraints == nil:
+			continue
+		case policy.Spec.Constraints.Operations == nil:
+			continue
+		}
+
+		constraint := policy.Spec.Constraints.Operations
+		if constraint.Selector != nil {
+			matched, err := kubernetes.IsSelectorMatch(*constraint.Selector, configuration.GetLabels(), namespace.GetLabels())
+			if err != nil {
+				return nil, fmt.Errorf("failed to check operations selector on policy: %s, error: %w", policy.Name, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		constraints = append(constraints, *constraint)
+	}
+
+	return constraints, nil
+}
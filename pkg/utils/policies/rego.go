@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RegoReport is a summary of the conftest results evaluated against the terraform plan
+type RegoReport struct {
+	// Failures is a collection of messages from the deny or violation rules
+	Failures []string
+	// Successes is the number of rules which passed
+	Successes int
+	// Warnings is a collection of messages from the warn rules
+	Warnings []string
+}
+
+// regoResult is a single result in the conftest json output
+type regoResult struct {
+	Filename  string        `json:"filename"`
+	Namespace string        `json:"namespace"`
+	Successes int           `json:"successes"`
+	Failures  []regoMessage `json:"failures"`
+	Warnings  []regoMessage `json:"warnings"`
+}
+
+// regoMessage is a message produced by a rule
+type regoMessage struct {
+	Msg string `json:"msg"`
+}
+
+// HasFailures returns true if any deny rules were triggered
+func (r *RegoReport) HasFailures() bool {
+	return len(r.Failures) > 0
+}
+
+// ParseRegoReport is used to parse the json output of conftest into a summary
+func ParseRegoReport(data []byte) (*RegoReport, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("rego report is empty")
+	}
+
+	var results []regoResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode the rego report, error: %w", err)
+	}
+
+	report := &RegoReport{}
+	for _, result := range results {
+		report.Successes += result.Successes
+
+		for _, x := range result.Failures {
+			report.Failures = append(report.Failures, x.Msg)
+		}
+		for _, x := range result.Warnings {
+			report.Warnings = append(report.Warnings, x.Msg)
+		}
+	}
+
+	return report, nil
+}
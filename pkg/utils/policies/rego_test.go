@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRegoReportEmpty(t *testing.T) {
+	report, err := ParseRegoReport(nil)
+	assert.Error(t, err)
+	assert.Nil(t, report)
+}
+
+func TestParseRegoReportInvalid(t *testing.T) {
+	report, err := ParseRegoReport([]byte("not json"))
+	assert.Error(t, err)
+	assert.Nil(t, report)
+}
+
+func TestParseRegoReportNoFindings(t *testing.T) {
+	report, err := ParseRegoReport([]byte(`[{"filename":"/run/plan.json","namespace":"main","successes":2}]`))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, 2, report.Successes)
+	assert.False(t, report.HasFailures())
+	assert.Empty(t, report.Warnings)
+}
+
+func TestParseRegoReport(t *testing.T) {
+	data := `[
+  {
+    "filename": "/run/plan.json",
+    "namespace": "main",
+    "successes": 1,
+    "failures": [{"msg": "bucket must be encrypted"}],
+    "warnings": [{"msg": "bucket should have versioning"}]
+  },
+  {
+    "filename": "/run/plan.json",
+    "namespace": "tags",
+    "successes": 3,
+    "failures": [{"msg": "missing owner tag"}]
+  }
+]`
+	report, err := ParseRegoReport([]byte(data))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, 4, report.Successes)
+	assert.True(t, report.HasFailures())
+	assert.Equal(t, []string{"bucket must be encrypted", "missing owner tag"}, report.Failures)
+	assert.Equal(t, []string{"bucket should have versioning"}, report.Warnings)
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestFindSignatureKeysEmpty(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+
+	keys, err := FindSignatureKeys(configuration, namespace, &terraformv1alpha1.PolicyList{})
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestFindSignatureKeys(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+	namespace.Labels = map[string]string{"env": "prod"}
+
+	prod := fixtures.NewSignaturePolicy("prod")
+	prod.Spec.Constraints.Signatures.Selector = &terraformv1alpha1.Selector{
+		Namespace: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+	}
+	dev := fixtures.NewSignaturePolicy("dev")
+	dev.Spec.Constraints.Signatures.Selector = &terraformv1alpha1.Selector{
+		Namespace: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}
+	list := &terraformv1alpha1.PolicyList{
+		Items: []terraformv1alpha1.Policy{
+			*fixtures.NewMatchAllPolicyConstraint("checkov"),
+			*prod,
+			*dev,
+			*fixtures.NewSignaturePolicy("all"),
+		},
+	}
+
+	keys, err := FindSignatureKeys(configuration, namespace, list)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, "prod-release", keys[0].Name)
+	assert.Equal(t, "all-release", keys[1].Name)
+	assert.Equal(t, "signature-prod-release.ssh", keys[0].GetKey())
+	assert.Equal(t, "release", prod.Spec.Constraints.Signatures.Keys[0].Name)
+}
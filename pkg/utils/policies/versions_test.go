@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+	"github.com/appvia/terranetes-controller/test/fixtures"
+)
+
+func TestFindVersionConstraintsEmpty(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+
+	constraints, err := FindVersionConstraints(configuration, namespace, &terraformv1alpha1.PolicyList{})
+	assert.NoError(t, err)
+	assert.Empty(t, constraints)
+}
+
+func TestFindVersionConstraints(t *testing.T) {
+	configuration := fixtures.NewValidBucketConfiguration("default", "test")
+	namespace := fixtures.NewNamespace("default")
+	namespace.Labels = map[string]string{"env": "prod"}
+
+	prod := fixtures.NewVersionsPolicy("prod", ">= 1.5.0")
+	prod.Spec.Constraints.Versions.Selector = &terraformv1alpha1.Selector{
+		Namespace: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+	}
+	dev := fixtures.NewVersionsPolicy("dev", ">= 1.7.0")
+	dev.Spec.Constraints.Versions.Selector = &terraformv1alpha1.Selector{
+		Namespace: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}
+	list := &terraformv1alpha1.PolicyList{
+		Items: []terraformv1alpha1.Policy{
+			*fixtures.NewMatchAllPolicyConstraint("checkov"),
+			*prod,
+			*dev,
+			*fixtures.NewVersionsPolicy("all", "< 2.0.0"),
+		},
+	}
+
+	constraints, err := FindVersionConstraints(configuration, namespace, list)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{">= 1.5.0", "< 2.0.0"}, constraints)
+}
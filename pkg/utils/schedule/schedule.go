@@ -0,0 +1,232 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package schedule implements the subset of the standard five field cron expression used by
+// the maintenance windows. The supported syntax is
+//
+//   - fields are minute (0-59), hour (0-23), day of month (1-31), month (1-12) and day of
+//     week (0-7, both 0 and 7 being sunday); names, seconds and years are not supported
+//   - a field is a comma separated list of a value (5), a range (1-5) or a wildcard (*), each
+//     optionally followed by a step (*/15, 1-10/2); a value with a step extends to the maximum
+//     of the field, i.e. 5/15 is 5-59/15 in the minute field. Ranges cannot wrap, i.e. 22-2
+//   - the @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly macros
+//   - as with cron, when both the day of month and day of week are restricted, i.e. neither
+//     starts with a wildcard, a day matching either field is matched
+//
+// Any other syntax, i.e. ?, L, W, #, @every or @reboot, is rejected. Schedules are evaluated
+// against the wall clock of the location of the time given, so a time skipped when daylight
+// saving starts does not occur on that day, and a time repeated when it ends is matched on
+// both occurrences.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// macros are the shorthand schedules we support
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field defines the permitted range for a field in the expression
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression, i.e. minute hour day-of-month month day-of-week
+type Schedule struct {
+	minute     map[int]bool
+	hour       map[int]bool
+	dom        map[int]bool
+	month      map[int]bool
+	dow        map[int]bool
+	domWild    bool
+	dowWild    bool
+	expression string
+}
+
+// Parse is used to parse a five field cron expression, rejecting any syntax not supported
+func Parse(expression string) (*Schedule, error) {
+	spec := strings.TrimSpace(expression)
+	if strings.HasPrefix(spec, "@") {
+		v, found := macros[spec]
+		if !found {
+			return nil, fmt.Errorf("unsupported schedule macro: %q", spec)
+		}
+		spec = v
+	}
+
+	items := strings.Fields(spec)
+	if len(items) != len(fields) {
+		return nil, fmt.Errorf("expected %d fields in schedule, found %d", len(fields), len(items))
+	}
+
+	values := make([]map[int]bool, len(fields))
+	for i, x := range items {
+		v, err := parseField(x, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	// @note: sunday can be expressed as 0 or 7
+	if values[4][7] {
+		values[4][0] = true
+		delete(values[4], 7)
+	}
+
+	return &Schedule{
+		minute:     values[0],
+		hour:       values[1],
+		dom:        values[2],
+		month:      values[3],
+		dow:        values[4],
+		domWild:    strings.HasPrefix(items[2], "*"),
+		dowWild:    strings.HasPrefix(items[4], "*"),
+		expression: expression,
+	}, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expression
+}
+
+// Next returns the next time after the given time which matches the schedule, in the location
+// of the given time. A zero time is returned if the schedule can never be satisfied.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !s.month[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+
+		case !s.isDayMatch(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+
+		case !s.hour[t.Hour()]:
+			// @note: stepping by duration rather than wall clock ensures an hour repeated when
+			// daylight saving ends is visited on both occurrences
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+
+		case !s.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// isDayMatch checks the day of month and day of week fields; as with cron if both are
+// restricted a match on either is sufficient, a field starting with a wildcard, i.e. */2,
+// is not considered restricted
+func (s *Schedule) isDayMatch(t time.Time) bool {
+	dom := s.dom[t.Day()]
+	dow := s.dow[int(t.Weekday())]
+
+	if s.domWild || s.dowWild {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// parseField parses a single field of the expression, i.e 1,2,5-10/2
+func parseField(value string, f field) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, x := range strings.Split(value, ",") {
+		step, hasStep := 1, false
+		if i := strings.Index(x, "/"); i >= 0 {
+			v, err := parseNumber(x[i+1:])
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("invalid step in %s field: %q", f.name, x)
+			}
+			step, hasStep = v, true
+			x = x[:i]
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case x == "*":
+		case strings.Contains(x, "-"):
+			e := strings.SplitN(x, "-", 2)
+			a, err := parseNumber(e[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid range in %s field: %q", f.name, x)
+			}
+			b, err := parseNumber(e[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid range in %s field: %q", f.name, x)
+			}
+			low, high = a, b
+		default:
+			v, err := parseNumber(x)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in %s field: %q", f.name, x)
+			}
+			low = v
+			if !hasStep {
+				high = v
+			}
+		}
+
+		if low < f.min || high > f.max || low > high {
+			return nil, fmt.Errorf("%s field must be between %d and %d: %q", f.name, f.min, f.max, value)
+		}
+		for i := low; i <= high; i += step {
+			values[i] = true
+		}
+	}
+
+	return values, nil
+}
+
+// parseNumber parses an unsigned decimal number, rejecting signs and anything else
+// strconv.Atoi would permit
+func parseNumber(value string) (int, error) {
+	if value == "" || strings.TrimLeft(value, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid number: %q", value)
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		Expression string
+		Error      string
+	}{
+		{Expression: "* * * * *"},
+		{Expression: "0 22 * * 1-5"},
+		{Expression: "*/15 0-6,22-23 1 */2 7"},
+		{Expression: "@daily"},
+		{Expression: "", Error: "expected 5 fields in schedule, found 0"},
+		{Expression: "* * * *", Error: "expected 5 fields in schedule, found 4"},
+		{Expression: "60 * * * *", Error: "minute field must be between 0 and 59: \"60\""},
+		{Expression: "* 5-2 * * *", Error: "hour field must be between 0 and 23: \"5-2\""},
+		{Expression: "* * 0 * *", Error: "day of month field must be between 1 and 31: \"0\""},
+		{Expression: "*/0 * * * *", Error: "invalid step in minute field: \"*/0\""},
+		{Expression: "* * * jan *", Error: "invalid value in month field: \"jan\""},
+		{Expression: "* * * * mon", Error: "invalid value in day of week field: \"mon\""},
+		{Expression: "0 0 0 * * *", Error: "expected 5 fields in schedule, found 6"},
+		{Expression: "0 0 ? * 1", Error: "invalid value in day of month field: \"?\""},
+		{Expression: "0 0 L * *", Error: "invalid value in day of month field: \"L\""},
+		{Expression: "0 0 15W * *", Error: "invalid value in day of month field: \"15W\""},
+		{Expression: "0 0 * * 1#2", Error: "invalid value in day of week field: \"1#2\""},
+		{Expression: "+5 * * * *", Error: "invalid value in minute field: \"+5\""},
+		{Expression: "-5 * * * *", Error: "invalid range in minute field: \"-5\""},
+		{Expression: "1-2-3 * * * *", Error: "invalid range in minute field: \"1-2-3\""},
+		{Expression: "*/-1 * * * *", Error: "invalid step in minute field: \"*/-1\""},
+		{Expression: "1,,2 * * * *", Error: "invalid value in minute field: \"\""},
+		{Expression: "0 22-2 * * *", Error: "hour field must be between 0 and 23: \"22-2\""},
+		{Expression: "* * * * 8", Error: "day of week field must be between 0 and 7: \"8\""},
+		{Expression: "@every 1h", Error: "unsupported schedule macro: \"@every 1h\""},
+		{Expression: "@reboot", Error: "unsupported schedule macro: \"@reboot\""},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.Expression)
+		if c.Error != "" {
+			assert.Error(t, err, c.Expression)
+			assert.Equal(t, c.Error, err.Error(), c.Expression)
+			assert.Nil(t, s)
+
+			continue
+		}
+		assert.NoError(t, err, c.Expression)
+		assert.NotNil(t, s)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// a wednesday
+	now := time.Date(2022, time.June, 15, 10, 30, 15, 0, time.UTC)
+
+	cases := []struct {
+		Expression string
+		Expected   time.Time
+	}{
+		{Expression: "* * * * *", Expected: time.Date(2022, time.June, 15, 10, 31, 0, 0, time.UTC)},
+		{Expression: "0 * * * *", Expected: time.Date(2022, time.June, 15, 11, 0, 0, 0, time.UTC)},
+		{Expression: "0 22 * * 1-5", Expected: time.Date(2022, time.June, 15, 22, 0, 0, 0, time.UTC)},
+		{Expression: "0 2 * * 6,0", Expected: time.Date(2022, time.June, 18, 2, 0, 0, 0, time.UTC)},
+		{Expression: "0 2 * * 7", Expected: time.Date(2022, time.June, 19, 2, 0, 0, 0, time.UTC)},
+		{Expression: "30 9 1 * *", Expected: time.Date(2022, time.July, 1, 9, 30, 0, 0, time.UTC)},
+		{Expression: "0 0 1 1 *", Expected: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{Expression: "0 0 1 * 1", Expected: time.Date(2022, time.June, 20, 0, 0, 0, 0, time.UTC)},
+		{Expression: "@hourly", Expected: time.Date(2022, time.June, 15, 11, 0, 0, 0, time.UTC)},
+		{Expression: "0 0 30 2 *", Expected: time.Time{}},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.Expression)
+		require.NoError(t, err, c.Expression)
+		assert.Equal(t, c.Expected, s.Next(now), c.Expression)
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	location, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	s, err := Parse("0 22 * * *")
+	require.NoError(t, err)
+
+	now := time.Date(2022, time.June, 15, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, time.June, 15, 21, 0, 0, 0, time.UTC), s.Next(now.In(location)).UTC())
+}
+
+func TestParseFieldValues(t *testing.T) {
+	cases := []struct {
+		Expression string
+		Field      int
+		Expected   []int
+	}{
+		{Expression: "*/15 * * * *", Field: 0, Expected: []int{0, 15, 30, 45}},
+		{Expression: "10-30/10 * * * *", Field: 0, Expected: []int{10, 20, 30}},
+		{Expression: "50/5 * * * *", Field: 0, Expected: []int{50, 55}},
+		{Expression: "1,5-7,20-23/2 * * * *", Field: 0, Expected: []int{1, 5, 6, 7, 20, 22}},
+		{Expression: "* 9-17/4 * * *", Field: 1, Expected: []int{9, 13, 17}},
+		{Expression: "* * * */5 *", Field: 3, Expected: []int{1, 6, 11}},
+		{Expression: "* * * * 5-7", Field: 4, Expected: []int{0, 5, 6}},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.Expression)
+		require.NoError(t, err, c.Expression)
+
+		values := []map[int]bool{s.minute, s.hour, s.dom, s.month, s.dow}[c.Field]
+		var list []int
+		for i := 0; i <= 59; i++ {
+			if values[i] {
+				list = append(list, i)
+			}
+		}
+		assert.Equal(t, c.Expected, list, c.Expression)
+	}
+}
+
+func TestNextDayOfMonthAndWeek(t *testing.T) {
+	// a wednesday
+	now := time.Date(2022, time.June, 15, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		Expression string
+		Expected   []time.Time
+	}{
+		{
+			// both restricted, either the 20th or a friday
+			Expression: "0 0 20 * 5",
+			Expected: []time.Time{
+				time.Date(2022, time.June, 17, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.June, 20, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.June, 24, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// the day of week starts with a wildcard, so both must match
+			Expression: "0 0 1-10 * */5",
+			Expected: []time.Time{
+				time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.July, 3, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.July, 8, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// the day of month starts with a wildcard, so both must match
+			Expression: "0 0 */10 * 2",
+			Expected: []time.Time{
+				time.Date(2022, time.June, 21, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.October, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.Expression)
+		require.NoError(t, err, c.Expression)
+
+		next := now
+		for _, expected := range c.Expected {
+			next = s.Next(next)
+			assert.Equal(t, expected, next, c.Expression)
+		}
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	s, err := Parse("30 1 * * *")
+	require.NoError(t, err)
+
+	// the clocks go forward at 01:00 on the 26th march, so 01:30 does not occur that day
+	now := time.Date(2023, time.March, 25, 12, 0, 0, 0, location)
+	assert.Equal(t, time.Date(2023, time.March, 27, 0, 30, 0, 0, time.UTC), s.Next(now).UTC())
+
+	// the clocks go back at 02:00 on the 29th october, so 01:30 occurs twice
+	now = time.Date(2023, time.October, 28, 12, 0, 0, 0, location)
+	first := s.Next(now)
+	assert.Equal(t, time.Date(2023, time.October, 29, 0, 30, 0, 0, time.UTC), first.UTC())
+	second := s.Next(first)
+	assert.Equal(t, time.Date(2023, time.October, 29, 1, 30, 0, 0, time.UTC), second.UTC())
+	assert.Equal(t, time.Date(2023, time.October, 30, 1, 30, 0, 0, time.UTC), s.Next(second).UTC())
+}
@@ -33,6 +33,20 @@ func LatestSemverVersion(versions []string) (string, error) {
 	return list[len(list)-1], nil
 }
 
+// IsVersionConstraintMatch returns true if the version satisfies the constraint, i.e. ">= 1.6.0"
+func IsVersionConstraintMatch(constraint, version string) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
+
 // SortSemverVersions sorts a list of semver versions in ascending order.
 func SortSemverVersions(versions []string) ([]string, error) {
 	vs := make([]*semver.Version, len(versions))
@@ -46,3 +46,28 @@ func TestSortSemverVersionsBad(t *testing.T) {
 	assert.Error(t, err)
 	assert.Empty(t, sorted)
 }
+
+func TestIsVersionConstraintMatch(t *testing.T) {
+	cases := []struct {
+		Constraint string
+		Version    string
+		Expected   bool
+		Error      bool
+	}{
+		{Constraint: ">= 1.6.0", Version: "1.6.0", Expected: true},
+		{Constraint: ">= 1.6.0", Version: "v1.7.1", Expected: true},
+		{Constraint: ">= 1.6.0", Version: "1.5.7"},
+		{Constraint: ">= 1.6.0", Version: "latest", Error: true},
+		{Constraint: "bad", Version: "1.6.0", Error: true},
+	}
+	for _, c := range cases {
+		matched, err := IsVersionConstraintMatch(c.Constraint, c.Version)
+		if c.Error {
+			assert.Error(t, err)
+
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.Expected, matched, "constraint: %s, version: %s", c.Constraint, c.Version)
+	}
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import "strings"
+
+// ShellQuote returns the value quoted as a single argument to a posix shell, i.e. the value is
+// wrapped in single quotes and any single quotes within are escaped
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+// ShellJoin returns the values quoted as individual arguments to a posix shell
+func ShellJoin(values []string) string {
+	quoted := make([]string, len(values))
+	for i, x := range values {
+		quoted[i] = ShellQuote(x)
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		"":                       `''`,
+		"aws_instance.a":         `'aws_instance.a'`,
+		`aws_instance.a["a b"]`:  `'aws_instance.a["a b"]'`,
+		`aws_instance.a["it's"]`: `'aws_instance.a["it'"'"'s"]'`,
+	}
+	for value, expected := range cases {
+		assert.Equal(t, expected, ShellQuote(value))
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	assert.Equal(t, "", ShellJoin(nil))
+	assert.Equal(t, `'a' 'b c'`, ShellJoin([]string{"a", "b c"}))
+}
+
+func TestShellJoinRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell available")
+	}
+	values := []string{`aws_instance.a["a b"]`, `aws_instance.a["it's"]`, `module.m["$(id)"].x.y`, "a\nb"}
+
+	output, err := exec.Command("sh", "-c", `printf '%s\0' `+ShellJoin(values)).Output()
+	require.NoError(t, err)
+	assert.Equal(t, values, strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00"))
+}
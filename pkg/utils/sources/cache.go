@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package sources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxArchiveSize is the maximum size of a module archive accepted by the cache
+	MaxArchiveSize = 256 * 1024 * 1024
+	// CacheTokenEnv is the environment variable holding the token used to access the cache
+	CacheTokenEnv = "SOURCE_CACHE_TOKEN"
+	// CacheTokenKey is the key in the secret holding the token used to access the cache
+	CacheTokenKey = "token"
+	// CacheTokenSecret is the name of the secret holding the token used to access the cache
+	CacheTokenSecret = "terranetes-source-cache"
+)
+
+// Cache is a directory holding the archived module sources, keyed by their digest
+type Cache struct {
+	// dir is the directory holding the archives
+	dir string
+	// maxSize is the maximum total size of the archives, the least recently used archives
+	// are evicted to make room for new ones
+	maxSize int64
+	// mutex guards the eviction of the archives
+	mutex sync.Mutex
+}
+
+// NewCache returns a cache backed by the directory, holding at most max size bytes of archives
+func NewCache(dir string, maxSize int64) (*Cache, error) {
+	switch {
+	case dir == "":
+		return nil, errors.New("no cache directory defined")
+	case maxSize <= 0:
+		return nil, errors.New("cache size must be greater than zero")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create the cache directory: %w", err)
+	}
+
+	return &Cache{dir: dir, maxSize: maxSize}, nil
+}
+
+// Get returns the archive for the digest if present in the cache
+func (c *Cache) Get(digest string) (io.ReadCloser, bool, error) {
+	if !IsDigest(digest) {
+		return nil, false, fmt.Errorf("invalid digest: %q", digest)
+	}
+
+	file, err := os.Open(c.filename(digest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+	// @note: the modification time records the last use of the archive for eviction
+	now := time.Now()
+	_ = os.Chtimes(file.Name(), now, now)
+
+	return file, true, nil
+}
+
+// Put adds the archive to the cache. The archive is only accepted when the content matches the digest
+func (c *Cache) Put(digest string, reader io.Reader) error {
+	if !IsDigest(digest) {
+		return fmt.Errorf("invalid digest: %q", digest)
+	}
+
+	tmpfile, err := os.CreateTemp(c.dir, ".upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	size, err := io.Copy(tmpfile, reader)
+	if err != nil {
+		return err
+	}
+	if _, err := tmpfile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// @step: verify the content against the digest, without unpacking the archive
+	found, err := ArchiveDigest(tmpfile)
+	if err != nil {
+		return fmt.Errorf("failed to read the archive: %w", err)
+	}
+	if found != digest {
+		return fmt.Errorf("archive digest: %q does not match: %q", found, digest)
+	}
+
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, err := os.Stat(c.filename(digest)); err == nil {
+		return nil
+	}
+	if err := c.evict(size); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpfile.Name(), c.filename(digest))
+}
+
+// evict removes the least recently used archives until there is room for the size
+func (c *Cache) evict(size int64) error {
+	if size > c.maxSize {
+		return fmt.Errorf("archive size: %d exceeds the cache size: %d", size, c.maxSize)
+	}
+
+	archives, err := filepath.Glob(filepath.Join(c.dir, "*.tar.gz"))
+	if err != nil {
+		return err
+	}
+
+	var files []os.FileInfo
+	total := size
+	for _, x := range archives {
+		info, err := os.Stat(x)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return err
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, x := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, x.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= x.Size()
+	}
+
+	return nil
+}
+
+// filename returns the path of the archive for the digest
+func (c *Cache) filename(digest string) string {
+	return filepath.Join(c.dir, strings.TrimPrefix(digest, DigestPrefix)+".tar.gz")
+}
+
+// Fetch retrieves the module from the cache endpoint and extracts it into the directory. The
+// content is verified against the digest, returning false if the module is not cached
+func Fetch(ctx context.Context, endpoint, token, digest, dir string) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpointURL(endpoint, digest), nil)
+	if err != nil {
+		return false, err
+	}
+	setToken(request, token)
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status code: %d from the cache", resp.StatusCode)
+	}
+
+	if err := Extract(resp.Body, dir); err != nil {
+		return false, fmt.Errorf("failed to extract the cached module: %w", err)
+	}
+	found, err := Digest(dir)
+	if err != nil {
+		return false, err
+	}
+	if found != digest {
+		return false, fmt.Errorf("cached module digest: %q does not match: %q", found, digest)
+	}
+
+	return true, nil
+}
+
+// Upload archives the module in the directory and uploads it to the cache endpoint
+func Upload(ctx context.Context, endpoint, token, digest, dir string) error {
+	encoded := &bytes.Buffer{}
+	if err := Archive(dir, encoded); err != nil {
+		return fmt.Errorf("failed to archive the module: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, endpointURL(endpoint, digest), encoded)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/gzip")
+	setToken(request, token)
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code: %d from the cache", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// setToken adds the token used to access the cache to the request, if any
+func setToken(request *http.Request, token string) {
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// endpointURL returns the url of the module in the cache
+func endpointURL(endpoint, digest string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/" + digest
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package sources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCacheServer returns a test server serving the cache
+func newCacheServer(t *testing.T, cache *Cache) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		digest := strings.TrimPrefix(req.URL.Path, "/v1/sources/")
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			reader, found, err := cache.Get(digest)
+			switch {
+			case err != nil:
+				w.WriteHeader(http.StatusInternalServerError)
+			case !found:
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				defer reader.Close()
+				_, _ = io.Copy(w, reader)
+			}
+
+		case http.MethodPut:
+			if err := cache.Put(digest, req.Body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// mustRead returns the content of the reader
+func mustRead(t *testing.T, reader io.Reader) []byte {
+	content := &bytes.Buffer{}
+	_, err := content.ReadFrom(reader)
+	require.NoError(t, err)
+
+	return content.Bytes()
+}
+
+func TestNewCache(t *testing.T) {
+	_, err := NewCache("", MaxArchiveSize)
+	assert.Error(t, err)
+	_, err = NewCache(t.TempDir(), 0)
+	assert.Error(t, err)
+
+	cache, err := NewCache(filepath.Join(t.TempDir(), "cache"), MaxArchiveSize)
+	require.NoError(t, err)
+	assert.NotNil(t, cache)
+}
+
+func TestCacheGetNotFound(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), MaxArchiveSize)
+	require.NoError(t, err)
+
+	_, found, err := cache.Get(DigestPrefix + strings.Repeat("a", 64))
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = cache.Get("../../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestCachePut(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), MaxArchiveSize)
+	require.NoError(t, err)
+
+	dir := makeModule(t)
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+
+	encoded := &bytes.Buffer{}
+	require.NoError(t, Archive(dir, encoded))
+	require.NoError(t, cache.Put(digest, bytes.NewReader(encoded.Bytes())))
+
+	reader, found, err := cache.Get(digest)
+	require.NoError(t, err)
+	require.True(t, found)
+	defer reader.Close()
+	assert.Equal(t, encoded.Bytes(), mustRead(t, reader))
+}
+
+func TestCachePutDigestMismatch(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), MaxArchiveSize)
+	require.NoError(t, err)
+
+	encoded := &bytes.Buffer{}
+	require.NoError(t, Archive(makeModule(t), encoded))
+
+	digest := DigestPrefix + strings.Repeat("a", 64)
+	assert.Error(t, cache.Put(digest, encoded))
+
+	_, found, err := cache.Get(digest)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestCachePutEvicts(t *testing.T) {
+	var archives [][]byte
+	var digests []string
+	for i := 0; i < 3; i++ {
+		dir := makeModule(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(fmt.Sprintf("# %d\n", i)), 0600))
+		digest, err := Digest(dir)
+		require.NoError(t, err)
+
+		encoded := &bytes.Buffer{}
+		require.NoError(t, Archive(dir, encoded))
+		archives = append(archives, encoded.Bytes())
+		digests = append(digests, digest)
+	}
+
+	// @note: room for two of the archives
+	cache, err := NewCache(t.TempDir(), int64(len(archives[0])+len(archives[1])+len(archives[2])/2))
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Put(digests[0], bytes.NewReader(archives[0])))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, cache.Put(digests[1], bytes.NewReader(archives[1])))
+	time.Sleep(10 * time.Millisecond)
+
+	// @note: retrieving the first archive makes the second the least recently used
+	reader, found, err := cache.Get(digests[0])
+	require.NoError(t, err)
+	require.True(t, found)
+	reader.Close()
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, cache.Put(digests[2], bytes.NewReader(archives[2])))
+
+	for i, expected := range []bool{true, false, true} {
+		reader, found, err := cache.Get(digests[i])
+		require.NoError(t, err)
+		assert.Equal(t, expected, found, "archive: %d", i)
+		if found {
+			reader.Close()
+		}
+	}
+}
+
+func TestCachePutTooLarge(t *testing.T) {
+	dir := makeModule(t)
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+	encoded := &bytes.Buffer{}
+	require.NoError(t, Archive(dir, encoded))
+
+	cache, err := NewCache(t.TempDir(), 10)
+	require.NoError(t, err)
+	assert.Error(t, cache.Put(digest, encoded))
+}
+
+func TestUploadAndFetchUnauthorized(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), MaxArchiveSize)
+	require.NoError(t, err)
+	endpoint := newCacheServer(t, cache).URL + "/v1/sources"
+
+	dir := makeModule(t)
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+
+	assert.Error(t, Upload(context.Background(), endpoint, "", digest, dir))
+	_, err = Fetch(context.Background(), endpoint, "invalid", digest, t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestUploadAndFetch(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), MaxArchiveSize)
+	require.NoError(t, err)
+	server := newCacheServer(t, cache)
+	endpoint := server.URL + "/v1/sources"
+
+	dir := makeModule(t)
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+
+	found, err := Fetch(context.Background(), endpoint, "token", digest, t.TempDir())
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, Upload(context.Background(), endpoint, "token", digest, dir))
+
+	dest := t.TempDir()
+	found, err = Fetch(context.Background(), endpoint, "token", digest, dest)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	content, err := os.ReadFile(filepath.Join(dest, "modules/vpc/main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# vpc module\n", string(content))
+}
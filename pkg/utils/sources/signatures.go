@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sources
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitKeys are the public keys trusted to sign the commits or tags of a git source
+type GitKeys struct {
+	// GPG is a collection of armored gpg public keys
+	GPG [][]byte
+	// SSH is a collection of ssh public keys in the authorized keys format
+	SSH []string
+}
+
+// IsEmpty returns true if no keys have been provided
+func (g GitKeys) IsEmpty() bool {
+	return len(g.GPG) == 0 && len(g.SSH) == 0
+}
+
+// VerifyGitSignature checks the checked out commit of the repository, or a tag pointing at it,
+// carries a valid signature from one of the keys. When the source was retrieved by a tag, only a
+// signature on that tag is accepted in place of a signed commit
+func VerifyGitSignature(dir, ref string, keys GitKeys) error {
+	if keys.IsEmpty() {
+		return errors.New("no public keys provided to verify the signature")
+	}
+
+	home, err := os.MkdirTemp("", "signatures")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(home)
+
+	// @step: provision a keyring and allowed signers file holding only the trusted keys
+	keyring := filepath.Join(home, "gnupg")
+	if err := os.Mkdir(keyring, 0700); err != nil {
+		return err
+	}
+	for _, key := range keys.GPG {
+		//nolint:gosec
+		cmd := exec.Command("gpg", "--batch", "--quiet", "--import")
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+keyring)
+		cmd.Stdin = bytes.NewReader(key)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to import the gpg public key: %s", strings.TrimSpace(string(output)))
+		}
+	}
+
+	signers := &bytes.Buffer{}
+	for _, key := range keys.SSH {
+		fmt.Fprintf(signers, "* %s\n", strings.TrimSpace(key))
+	}
+	allowed := filepath.Join(home, "allowed_signers")
+	if err := os.WriteFile(allowed, signers.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	git := func(args ...string) ([]byte, error) {
+		//nolint:gosec
+		cmd := exec.Command("git", append([]string{"-c", "gpg.ssh.allowedSignersFile=" + allowed, "-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+keyring)
+
+		return cmd.Output()
+	}
+
+	if _, err := git("verify-commit", "HEAD"); err == nil {
+		return nil
+	}
+
+	// @step: the commit is not signed, check for a signed tag on the commit
+	output, err := git("tag", "--points-at", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the tags on the commit: %w", err)
+	}
+	tags := strings.Fields(string(output))
+
+	if ref != "" {
+		if _, err := git("rev-parse", "--verify", "--quiet", "refs/tags/"+ref); err == nil {
+			tags = []string{ref}
+		}
+	}
+
+	for _, tag := range tags {
+		if _, err := git("verify-tag", tag); err != nil {
+			continue
+		}
+		// @step: the name is part of the signed tag, a signed tag copied under another name
+		// does not attest to the name it is referenced by
+		content, err := git("cat-file", "tag", tag)
+		if err != nil {
+			continue
+		}
+		if signedTagName(content) != tag {
+			continue
+		}
+		// @step: and the tag must be for the commit we have checked out
+		commit, err := git("rev-parse", tag+"^{commit}")
+		if err != nil {
+			continue
+		}
+		if head, err := git("rev-parse", "HEAD"); err == nil && bytes.Equal(commit, head) {
+			return nil
+		}
+	}
+
+	return errors.New("neither the commit nor any tag on it has a valid signature from the trusted keys")
+}
+
+// signedTagName returns the name recorded in the content of an annotated tag
+func signedTagName(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "tag ") {
+			return strings.TrimPrefix(line, "tag ")
+		}
+	}
+
+	return ""
+}
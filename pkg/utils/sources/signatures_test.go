@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sources
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSSHKey generates an ssh key returning the path to the private key and the public key
+func newSSHKey(t *testing.T) (string, string) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not available")
+	}
+	path := filepath.Join(t.TempDir(), "key")
+
+	//nolint:gosec
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", path).Run())
+	public, err := os.ReadFile(path + ".pub")
+	require.NoError(t, err)
+
+	return path, string(public)
+}
+
+// makeRepository creates a git repository with a single commit, signed by the key if provided
+func makeRepository(t *testing.T, key string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir := t.TempDir()
+
+	git(t, dir, "", "init", "-q")
+	git(t, dir, key, "commit", "-q", "--allow-empty", "-m", "initial commit")
+
+	return dir
+}
+
+// git runs the git command in the repository, signing with the key if provided
+func git(t *testing.T, dir, key string, args ...string) {
+	options := []string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}
+	if key != "" {
+		options = append(options, "-c", "gpg.format=ssh", "-c", "user.signingkey="+key,
+			"-c", "commit.gpgsign=true", "-c", "tag.gpgsign=true")
+	}
+
+	//nolint:gosec
+	output, err := exec.Command("git", append(options, args...)...).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestVerifyGitSignatureNoKeys(t *testing.T) {
+	err := VerifyGitSignature(t.TempDir(), "", GitKeys{})
+	require.Error(t, err)
+	assert.Equal(t, "no public keys provided to verify the signature", err.Error())
+}
+
+func TestVerifyGitSignatureCommit(t *testing.T) {
+	key, public := newSSHKey(t)
+	dir := makeRepository(t, key)
+
+	assert.NoError(t, VerifyGitSignature(dir, "", GitKeys{SSH: []string{public}}))
+}
+
+func TestVerifyGitSignatureUnsigned(t *testing.T) {
+	_, public := newSSHKey(t)
+	dir := makeRepository(t, "")
+
+	err := VerifyGitSignature(dir, "", GitKeys{SSH: []string{public}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "valid signature")
+}
+
+func TestVerifyGitSignatureWrongKey(t *testing.T) {
+	key, _ := newSSHKey(t)
+	_, public := newSSHKey(t)
+	dir := makeRepository(t, key)
+
+	assert.Error(t, VerifyGitSignature(dir, "", GitKeys{SSH: []string{public}}))
+}
+
+func TestVerifyGitSignatureTag(t *testing.T) {
+	key, public := newSSHKey(t)
+	dir := makeRepository(t, "")
+	git(t, dir, key, "tag", "-s", "-m", "release", "v1.0.0")
+
+	assert.NoError(t, VerifyGitSignature(dir, "", GitKeys{SSH: []string{public}}))
+}
+
+func TestVerifyGitSignatureRequestedTag(t *testing.T) {
+	key, public := newSSHKey(t)
+	dir := makeRepository(t, "")
+	git(t, dir, key, "tag", "-s", "-m", "release", "v1.0.0")
+
+	assert.NoError(t, VerifyGitSignature(dir, "v1.0.0", GitKeys{SSH: []string{public}}))
+}
+
+func TestVerifyGitSignatureWrongTag(t *testing.T) {
+	key, public := newSSHKey(t)
+	dir := makeRepository(t, "")
+	git(t, dir, key, "tag", "-s", "-m", "release", "v1.0.0")
+	git(t, dir, "", "tag", "-a", "-m", "unsigned", "v2.0.0")
+
+	err := VerifyGitSignature(dir, "v2.0.0", GitKeys{SSH: []string{public}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "valid signature")
+}
+
+func TestVerifyGitSignatureRenamedTag(t *testing.T) {
+	key, public := newSSHKey(t)
+	dir := makeRepository(t, "")
+	git(t, dir, key, "tag", "-s", "-m", "release", "v1.0.0")
+	// @note: the signed tag object of v1.0.0 referenced under another name
+	git(t, dir, "", "tag", "v2.0.0", "v1.0.0")
+	git(t, dir, "", "tag", "-d", "v1.0.0")
+
+	err := VerifyGitSignature(dir, "v2.0.0", GitKeys{SSH: []string{public}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "valid signature")
+
+	assert.Error(t, VerifyGitSignature(dir, "", GitKeys{SSH: []string{public}}))
+}
@@ -0,0 +1,406 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package sources
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-getter"
+)
+
+// DigestPrefix is the prefix of the digest of a module source
+const DigestPrefix = "sha256:"
+
+var digestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// IsDigest returns true if the value is a valid module digest
+func IsDigest(value string) bool {
+	return digestRegex.MatchString(value)
+}
+
+// IsGitSource returns true if the (detected) source is retrieved via git
+func IsGitSource(location string) bool {
+	return strings.HasPrefix(location, "git::")
+}
+
+// GitRef returns the branch, tag or commit the git source is retrieved at, if any
+func GitRef(location string) string {
+	if !IsGitSource(location) {
+		return ""
+	}
+	source, _ := getter.SourceDirSubdir(location)
+
+	uri, err := url.Parse(strings.TrimPrefix(source, "git::"))
+	if err != nil {
+		return ""
+	}
+
+	return uri.Query().Get("ref")
+}
+
+// PinRevision returns the source location pinned to the revision. Only git sources can be pinned,
+// other sources are returned unchanged and must be verified against the digest post download
+func PinRevision(location, revision string) (string, error) {
+	if revision == "" || !IsGitSource(location) {
+		return location, nil
+	}
+	source, subdir := getter.SourceDirSubdir(location)
+
+	uri, err := url.Parse(strings.TrimPrefix(source, "git::"))
+	if err != nil {
+		return "", err
+	}
+	// @note: a shallow clone can only be used with a branch or tag, not a commit
+	values := uri.Query()
+	values.Set("ref", revision)
+	values.Del("depth")
+	uri.RawQuery = values.Encode()
+
+	if subdir != "" {
+		uri.Path = strings.TrimSuffix(uri.Path, "/") + "//" + subdir
+	}
+
+	return "git::" + uri.String(), nil
+}
+
+// GitRevision returns the commit the git repository in the directory is checked out at
+func GitRevision(dir string) (string, error) {
+	//nolint:gosec
+	output, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve the git revision: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Digest returns a digest of the content of the module in the directory. The digest covers the
+// path and content of every file and link, ignoring any git metadata
+func Digest(dir string) (string, error) {
+	hash := sha256.New()
+
+	err := walk(dir, func(path, name string, entry fs.DirEntry) error {
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\x00link\x00%s\n", name, target)
+
+		case entry.Type().IsRegular():
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			sum := sha256.New()
+			if _, err := io.Copy(sum, file); err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\x00file\x00%s\n", name, hex.EncodeToString(sum.Sum(nil)))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return DigestPrefix + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Archive writes a gzipped tarball of the module in the directory, ignoring any git metadata
+func Archive(dir string, writer io.Writer) error {
+	compressed := gzip.NewWriter(writer)
+	tw := tar.NewWriter(compressed)
+
+	err := walk(dir, func(path, name string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tw, file)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return compressed.Close()
+}
+
+// ArchiveDigest returns the digest of the module held in a gzipped tarball produced by Archive,
+// without unpacking it. The digest matches that of Digest on the directory which was archived
+func ArchiveDigest(reader io.Reader) (string, error) {
+	compressed, err := gzip.NewReader(reader)
+	if err != nil {
+		return "", err
+	}
+	defer compressed.Close()
+
+	hash := sha256.New()
+	tr := tar.NewReader(compressed)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			fmt.Fprintf(hash, "%s\x00link\x00%s\n", header.Name, header.Linkname)
+
+		case tar.TypeReg:
+			sum := sha256.New()
+			//nolint:gosec
+			if _, err := io.Copy(sum, tr); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(hash, "%s\x00file\x00%s\n", header.Name, hex.EncodeToString(sum.Sum(nil)))
+		}
+	}
+
+	return DigestPrefix + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Extract unpacks a gzipped tarball produced by Archive into the directory. Links must resolve
+// within the directory once any links already extracted are followed, and no entry is written
+// through a link
+func Extract(reader io.Reader, dir string) error {
+	compressed, err := gzip.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	defer compressed.Close()
+
+	root := filepath.Clean(dir)
+	tr := tar.NewReader(compressed)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(root, header.Name)
+		if !within(root, target) {
+			return fmt.Errorf("archive contains an illegal path: %q", header.Name)
+		}
+		if err := isLinkFree(root, target); err != nil {
+			return fmt.Errorf("archive contains an illegal path: %q, %w", header.Name, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) {
+				return fmt.Errorf("archive contains an illegal link: %q", header.Name)
+			}
+			if _, err := resolve(root, filepath.Dir(target), header.Linkname, 0); err != nil {
+				return fmt.Errorf("archive contains an illegal link: %q, %w", header.Name, err)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			if err := extractFile(tr, target, os.FileMode(header.Mode).Perm()|0600); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// maxLinkDepth is the maximum number of links followed when resolving a path
+const maxLinkDepth = 40
+
+// isLinkFree returns an error if the path, or any of its parents under the root, is a link
+func isLinkFree(root, path string) error {
+	for current := path; within(root, current) && current != root; current = filepath.Dir(current) {
+		info, err := os.Lstat(current)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%q is a link", current)
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the path of the name relative to the base directory, following any links
+// already present, and returns an error should the path leave the root
+func resolve(root, base, name string, depth int) (string, error) {
+	if depth > maxLinkDepth {
+		return "", errors.New("too many levels of links")
+	}
+	current := base
+
+	for _, x := range strings.Split(filepath.ToSlash(name), "/") {
+		switch x {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+		default:
+			next := filepath.Join(current, x)
+
+			info, err := os.Lstat(next)
+			switch {
+			case err != nil && !os.IsNotExist(err):
+				return "", err
+			case err == nil && info.Mode()&fs.ModeSymlink != 0:
+				link, err := os.Readlink(next)
+				if err != nil {
+					return "", err
+				}
+				if filepath.IsAbs(link) {
+					return "", fmt.Errorf("%q links outside of the directory", next)
+				}
+				if next, err = resolve(root, current, link, depth+1); err != nil {
+					return "", err
+				}
+			}
+			current = next
+		}
+		if !within(root, current) {
+			return "", fmt.Errorf("%q resolves outside of the directory", name)
+		}
+	}
+
+	return current, nil
+}
+
+// extractFile writes the content of the reader to the file
+func extractFile(reader io.Reader, path string, mode os.FileMode) error {
+	//nolint:gosec
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	//nolint:gosec
+	if _, err := io.Copy(file, reader); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// within returns true if the path is inside the root directory
+func within(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// walk calls the method for every entry under the directory in lexical order, skipping any
+// git metadata. The name is the slash separated path relative to the directory
+func walk(dir string, method func(path, name string, entry fs.DirEntry) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if entry.Name() == ".git" {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		return method(path, filepath.ToSlash(name), entry)
+	})
+}
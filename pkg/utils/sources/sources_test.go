@@ -0,0 +1,254 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package sources
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeModule creates a module with a few files under a temporary directory
+func makeModule(t *testing.T) string {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"main.tf":             "resource \"null_resource\" \"test\" {}\n",
+		"variables.tf":        "variable \"name\" {}\n",
+		"modules/vpc/main.tf": "# vpc module\n",
+		".git/HEAD":           "ref: refs/heads/main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	require.NoError(t, os.Symlink("modules/vpc", filepath.Join(dir, "vpc")))
+
+	return dir
+}
+
+func TestIsDigest(t *testing.T) {
+	assert.True(t, IsDigest("sha256:"+string(bytes.Repeat([]byte("a"), 64))))
+	assert.False(t, IsDigest(""))
+	assert.False(t, IsDigest("sha256:abc"))
+	assert.False(t, IsDigest(string(bytes.Repeat([]byte("a"), 64))))
+	assert.False(t, IsDigest("sha256:../../"+string(bytes.Repeat([]byte("a"), 58))))
+}
+
+func TestGitRef(t *testing.T) {
+	assert.Equal(t, "v1.0.0", GitRef("git::https://github.com/appvia/terranetes-controller.git?ref=v1.0.0"))
+	assert.Equal(t, "v1.0.0", GitRef("git::https://github.com/appvia/terranetes-controller.git//examples/module?ref=v1.0.0"))
+	assert.Equal(t, "", GitRef("git::https://github.com/appvia/terranetes-controller.git"))
+	assert.Equal(t, "", GitRef("https://example.com/module.tar.gz?ref=v1.0.0"))
+}
+
+func TestPinRevision(t *testing.T) {
+	cases := []struct {
+		Location string
+		Revision string
+		Expected string
+	}{
+		{
+			Location: "git::https://github.com/appvia/terranetes-controller.git",
+			Revision: "4a46f18c",
+			Expected: "git::https://github.com/appvia/terranetes-controller.git?ref=4a46f18c",
+		},
+		{
+			Location: "git::https://github.com/appvia/terranetes-controller.git?ref=main&depth=1",
+			Revision: "4a46f18c",
+			Expected: "git::https://github.com/appvia/terranetes-controller.git?ref=4a46f18c",
+		},
+		{
+			Location: "git::https://github.com/appvia/terranetes-controller.git//examples/module?ref=v0.1.0",
+			Revision: "4a46f18c",
+			Expected: "git::https://github.com/appvia/terranetes-controller.git//examples/module?ref=4a46f18c",
+		},
+		{
+			Location: "git::ssh://git@github.com/appvia/terranetes-controller.git?ref=main",
+			Revision: "4a46f18c",
+			Expected: "git::ssh://git@github.com/appvia/terranetes-controller.git?ref=4a46f18c",
+		},
+		{
+			Location: "git::https://github.com/appvia/terranetes-controller.git?ref=main",
+			Expected: "git::https://github.com/appvia/terranetes-controller.git?ref=main",
+		},
+		{
+			Location: "https://example.com/module.tar.gz",
+			Revision: "4a46f18c",
+			Expected: "https://example.com/module.tar.gz",
+		},
+	}
+	for _, c := range cases {
+		pinned, err := PinRevision(c.Location, c.Revision)
+		require.NoError(t, err)
+		assert.Equal(t, c.Expected, pinned, "location: %s", c.Location)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	dir := makeModule(t)
+
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+	assert.True(t, IsDigest(digest))
+
+	// @note: the git metadata should not affect the digest
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git/HEAD"), []byte("changed"), 0600))
+	unchanged, err := Digest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, digest, unchanged)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("changed"), 0600))
+	changed, err := Digest(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, changed)
+}
+
+func TestArchiveAndExtract(t *testing.T) {
+	dir := makeModule(t)
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+
+	encoded := &bytes.Buffer{}
+	require.NoError(t, Archive(dir, encoded))
+
+	extracted := t.TempDir()
+	require.NoError(t, Extract(encoded, extracted))
+
+	found, err := Digest(extracted)
+	require.NoError(t, err)
+	assert.Equal(t, digest, found)
+
+	exists, err := os.Stat(filepath.Join(extracted, ".git"))
+	assert.Nil(t, exists)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// makeArchive returns a gzipped tarball of the entries
+func makeArchive(t *testing.T, headers ...*tar.Header) *bytes.Buffer {
+	encoded := &bytes.Buffer{}
+	compressed := gzip.NewWriter(encoded)
+	tw := tar.NewWriter(compressed)
+	for _, header := range headers {
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len("content"))
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		if header.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte("content"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, compressed.Close())
+
+	return encoded
+}
+
+func TestExtractIllegalPaths(t *testing.T) {
+	cases := []*tar.Header{
+		{Name: "../escape.tf", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"},
+	}
+	for _, header := range cases {
+		err := Extract(makeArchive(t, header), t.TempDir())
+		assert.Error(t, err, "expected an error for %q", header.Name)
+	}
+}
+
+func TestExtractLinkChain(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "root")
+	require.NoError(t, os.Mkdir(dir, 0750))
+
+	// @note: each link resolves within the directory when taken alone, but not when followed
+	encoded := makeArchive(t,
+		&tar.Header{Name: "s/b", Typeflag: tar.TypeSymlink, Linkname: ".."},
+		&tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "s/b/.."},
+		&tar.Header{Name: "a/escaped", Typeflag: tar.TypeReg, Mode: 0600},
+	)
+	err := Extract(encoded, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `archive contains an illegal link: "a"`)
+
+	_, err = os.Stat(filepath.Join(parent, "escaped"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExtractThroughLink(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "root")
+	require.NoError(t, os.Mkdir(dir, 0750))
+
+	cases := [][]*tar.Header{
+		{
+			{Name: "sub", Typeflag: tar.TypeDir, Mode: 0750},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "sub"},
+			{Name: "link/file", Typeflag: tar.TypeReg, Mode: 0600},
+		},
+		{
+			{Name: "file", Typeflag: tar.TypeReg, Mode: 0600},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "file"},
+			{Name: "link", Typeflag: tar.TypeReg, Mode: 0600},
+		},
+	}
+	for i, headers := range cases {
+		dest := filepath.Join(dir, fmt.Sprintf("%d", i))
+		require.NoError(t, os.Mkdir(dest, 0750))
+		assert.Error(t, Extract(makeArchive(t, headers...), dest), "case: %d", i)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	dir := t.TempDir()
+
+	encoded := makeArchive(t,
+		&tar.Header{Name: "modules/vpc/main.tf", Typeflag: tar.TypeReg, Mode: 0600},
+		&tar.Header{Name: "modules/up", Typeflag: tar.TypeSymlink, Linkname: ".."},
+		&tar.Header{Name: "vpc", Typeflag: tar.TypeSymlink, Linkname: "modules/up/modules/vpc"},
+	)
+	require.NoError(t, Extract(encoded, dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, "vpc/main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
+func TestArchiveDigest(t *testing.T) {
+	dir := makeModule(t)
+	digest, err := Digest(dir)
+	require.NoError(t, err)
+
+	encoded := &bytes.Buffer{}
+	require.NoError(t, Archive(dir, encoded))
+
+	found, err := ArchiveDigest(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, digest, found)
+
+	_, err = ArchiveDigest(bytes.NewReader([]byte("not an archive")))
+	assert.Error(t, err)
+}
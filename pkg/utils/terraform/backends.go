@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package terraform
+
+import (
+	"fmt"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+// S3BackendTemplate is the template for a s3 compatible backend
+var S3BackendTemplate = `
+terraform {
+	backend "s3" {
+		bucket         = "{{ .backend.S3.Bucket }}"
+		key            = "{{ default "terranetes" .backend.S3.KeyPrefix }}/{{ .namespace }}/{{ .name }}/{{ .controller.suffix }}.tfstate"
+		region         = "{{ .backend.S3.Region }}"
+		{{- if .credentials.AWS_ACCESS_KEY_ID }}
+		access_key     = {{ quote .credentials.AWS_ACCESS_KEY_ID }}
+		secret_key     = {{ quote .credentials.AWS_SECRET_ACCESS_KEY }}
+		{{- end }}
+		{{- if .backend.S3.DynamoDBTable }}
+		dynamodb_table = "{{ .backend.S3.DynamoDBTable }}"
+		{{- end }}
+		{{- if .backend.S3.Encrypt }}
+		encrypt        = true
+		{{- end }}
+		{{- if .backend.S3.Endpoint }}
+		endpoint       = "{{ .backend.S3.Endpoint }}"
+		skip_credentials_validation = true
+		skip_metadata_api_check     = true
+		skip_region_validation      = true
+		{{- end }}
+		{{- if .backend.S3.ForcePathStyle }}
+		force_path_style = true
+		{{- end }}
+	}
+}
+`
+
+// PostgresBackendTemplate is the template for a postgres backend
+var PostgresBackendTemplate = `
+terraform {
+	backend "pg" {
+		conn_str    = {{ quote .credentials.PG_CONN_STR }}
+		schema_name = "{{ default "terranetes" .backend.Postgres.SchemaPrefix }}_{{ replace "-" "_" .controller.suffix }}"
+	}
+}
+`
+
+// HTTPBackendTemplate is the template for a http backend
+var HTTPBackendTemplate = `
+{{- $address := printf "%s/%s/%s/%s" (trimSuffix "/" .backend.HTTP.Address) .namespace .name .controller.suffix }}
+terraform {
+	backend "http" {
+		address        = "{{ $address }}"
+		{{- if .backend.HTTP.EnableLocking }}
+		lock_address   = "{{ $address }}"
+		unlock_address = "{{ $address }}"
+		{{- end }}
+		{{- if .credentials.TF_HTTP_USERNAME }}
+		username       = {{ quote .credentials.TF_HTTP_USERNAME }}
+		password       = {{ quote .credentials.TF_HTTP_PASSWORD }}
+		{{- end }}
+		{{- if .backend.HTTP.SkipCertVerification }}
+		skip_cert_verification = true
+		{{- end }}
+	}
+}
+`
+
+// GetBackendTemplate returns the template used to render the typed backend
+func GetBackendTemplate(backend *terraformv1alpha1.Backend) (string, error) {
+	switch backend.GetType() {
+	case terraformv1alpha1.BackendTypeHTTP:
+		return HTTPBackendTemplate, nil
+	case terraformv1alpha1.BackendTypePostgres:
+		return PostgresBackendTemplate, nil
+	case terraformv1alpha1.BackendTypeS3:
+		return S3BackendTemplate, nil
+	}
+
+	return "", fmt.Errorf("no supported backend type defined")
+}
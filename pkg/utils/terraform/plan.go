@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package terraform
+
+import (
+	"encoding/json"
+	"sort"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+// MaxPlanResourceChanges is the maximum number of resource changes listed in the summary of a plan
+const MaxPlanResourceChanges = 100
+
+// Plan is the json representation of a terraform plan, as produced by terraform show -json
+type Plan struct {
+	// ResourceChanges is a collection of the resource changes in the plan
+	ResourceChanges []ResourceChange `json:"resource_changes,omitempty"`
+}
+
+// ResourceChange is a change to a resource in the plan
+type ResourceChange struct {
+	// Address is the absolute address of the resource
+	Address string `json:"address"`
+	// Mode is the mode of the resource, i.e. managed or data
+	Mode string `json:"mode,omitempty"`
+	// Change describes the change to the resource
+	Change Change `json:"change"`
+}
+
+// Change describes the actions to be taken on a resource
+type Change struct {
+	// Actions is the list of actions to be taken
+	Actions []string `json:"actions"`
+	// Importing is present when the resource is being imported
+	Importing *Importing `json:"importing,omitempty"`
+}
+
+// Importing describes the existing resource being imported
+type Importing struct {
+	// ID is the identifier of the existing resource
+	ID string `json:"id,omitempty"`
+}
+
+// Action returns the action which will be taken on the resource, or an empty string
+// if the resource is not being changed
+func (r *ResourceChange) Action() string {
+	switch len(r.Change.Actions) {
+	case 1:
+		switch r.Change.Actions[0] {
+		case "create":
+			return terraformv1alpha1.ResourceChangeCreate
+		case "update":
+			return terraformv1alpha1.ResourceChangeUpdate
+		case "delete":
+			return terraformv1alpha1.ResourceChangeDelete
+		}
+	case 2:
+		return terraformv1alpha1.ResourceChangeReplace
+	}
+
+	return ""
+}
+
+// ParsePlanChanges decodes the json terraform plan and returns a summary of the resource changes,
+// listing at most MaxPlanResourceChanges of the resources
+func ParsePlanChanges(in []byte) (*terraformv1alpha1.TerraformPlanChanges, error) {
+	plan := &Plan{}
+	if err := json.Unmarshal(in, plan); err != nil {
+		return nil, err
+	}
+
+	changes := &terraformv1alpha1.TerraformPlanChanges{}
+	for i := 0; i < len(plan.ResourceChanges); i++ {
+		change := plan.ResourceChanges[i]
+		if change.Mode == "data" {
+			continue
+		}
+
+		action := change.Action()
+		if change.Change.Importing != nil {
+			changes.Import++
+			if action == "" {
+				action = terraformv1alpha1.ResourceChangeImport
+			}
+		}
+
+		switch action {
+		case terraformv1alpha1.ResourceChangeCreate:
+			changes.Create++
+		case terraformv1alpha1.ResourceChangeUpdate:
+			changes.Update++
+		case terraformv1alpha1.ResourceChangeDelete:
+			changes.Delete++
+		case terraformv1alpha1.ResourceChangeReplace:
+			changes.Replace++
+		case terraformv1alpha1.ResourceChangeImport:
+			// @note: the import has already been counted above
+		default:
+			continue
+		}
+
+		changes.Resources = append(changes.Resources, terraformv1alpha1.TerraformPlanResourceChange{
+			Action:  action,
+			Address: change.Address,
+		})
+	}
+
+	sort.SliceStable(changes.Resources, func(i, j int) bool {
+		return changes.Resources[i].Address < changes.Resources[j].Address
+	})
+	if len(changes.Resources) > MaxPlanResourceChanges {
+		changes.Truncated = len(changes.Resources) - MaxPlanResourceChanges
+		changes.Resources = changes.Resources[:MaxPlanResourceChanges]
+	}
+
+	return changes, nil
+}
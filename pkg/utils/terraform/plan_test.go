@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package terraform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+func TestParsePlanChangesBadInput(t *testing.T) {
+	changes, err := ParsePlanChanges([]byte("not json"))
+	assert.Error(t, err)
+	assert.Nil(t, changes)
+}
+
+func TestParsePlanChangesNoChanges(t *testing.T) {
+	changes, err := ParsePlanChanges([]byte(`{"format_version":"1.1"}`))
+	require.NoError(t, err)
+	require.NotNil(t, changes)
+	assert.False(t, changes.HasChanges())
+	assert.Empty(t, changes.Resources)
+}
+
+func TestParsePlanChanges(t *testing.T) {
+	plan := `{
+  "resource_changes": [
+    {"address": "aws_s3_bucket.b", "mode": "managed", "change": {"actions": ["update"]}},
+    {"address": "aws_s3_bucket.a", "mode": "managed", "change": {"actions": ["create"]}},
+    {"address": "aws_iam_role.r", "mode": "managed", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_iam_role.s", "mode": "managed", "change": {"actions": ["create", "delete"]}},
+    {"address": "aws_iam_policy.p", "mode": "managed", "change": {"actions": ["delete"]}},
+    {"address": "aws_iam_policy.q", "mode": "managed", "change": {"actions": ["no-op"]}},
+    {"address": "data.aws_caller_identity.current", "mode": "data", "change": {"actions": ["read"]}}
+  ]
+}`
+	changes, err := ParsePlanChanges([]byte(plan))
+	require.NoError(t, err)
+	require.NotNil(t, changes)
+
+	assert.True(t, changes.HasChanges())
+	assert.Equal(t, 1, changes.Create)
+	assert.Equal(t, 1, changes.Update)
+	assert.Equal(t, 1, changes.Delete)
+	assert.Equal(t, 2, changes.Replace)
+	assert.Equal(t, []terraformv1alpha1.TerraformPlanResourceChange{
+		{Action: terraformv1alpha1.ResourceChangeDelete, Address: "aws_iam_policy.p"},
+		{Action: terraformv1alpha1.ResourceChangeReplace, Address: "aws_iam_role.r"},
+		{Action: terraformv1alpha1.ResourceChangeReplace, Address: "aws_iam_role.s"},
+		{Action: terraformv1alpha1.ResourceChangeCreate, Address: "aws_s3_bucket.a"},
+		{Action: terraformv1alpha1.ResourceChangeUpdate, Address: "aws_s3_bucket.b"},
+	}, changes.Resources)
+}
+
+func TestParsePlanChangesImports(t *testing.T) {
+	plan := `{
+  "resource_changes": [
+    {"address": "aws_s3_bucket.a", "mode": "managed", "change": {"actions": ["no-op"], "importing": {"id": "bucket-a"}}},
+    {"address": "aws_s3_bucket.b", "mode": "managed", "change": {"actions": ["update"], "importing": {"id": "bucket-b"}}},
+    {"address": "aws_s3_bucket.c", "mode": "managed", "change": {"actions": ["no-op"]}}
+  ]
+}`
+	changes, err := ParsePlanChanges([]byte(plan))
+	require.NoError(t, err)
+	require.NotNil(t, changes)
+
+	assert.True(t, changes.HasChanges())
+	assert.Equal(t, 2, changes.Import)
+	assert.Equal(t, 1, changes.Update)
+	assert.Equal(t, []terraformv1alpha1.TerraformPlanResourceChange{
+		{Action: terraformv1alpha1.ResourceChangeImport, Address: "aws_s3_bucket.a"},
+		{Action: terraformv1alpha1.ResourceChangeUpdate, Address: "aws_s3_bucket.b"},
+	}, changes.Resources)
+}
+
+func TestParsePlanChangesTruncated(t *testing.T) {
+	var resources []string
+	for i := 0; i < MaxPlanResourceChanges+20; i++ {
+		resources = append(resources, fmt.Sprintf(`{"address": "null_resource.r%03d", "mode": "managed", "change": {"actions": ["create"]}}`, i))
+	}
+	plan := fmt.Sprintf(`{"resource_changes": [%s]}`, strings.Join(resources, ","))
+
+	changes, err := ParsePlanChanges([]byte(plan))
+	require.NoError(t, err)
+	require.NotNil(t, changes)
+
+	assert.Equal(t, MaxPlanResourceChanges+20, changes.Create)
+	assert.Equal(t, 20, changes.Truncated)
+	assert.Len(t, changes.Resources, MaxPlanResourceChanges)
+	assert.Equal(t, "null_resource.r000", changes.Resources[0].Address)
+	assert.Equal(t, fmt.Sprintf("null_resource.r%03d", MaxPlanResourceChanges-1), changes.Resources[MaxPlanResourceChanges-1].Address)
+}
+
+func TestParsePlanChangesNotTruncated(t *testing.T) {
+	plan := `{"resource_changes": [{"address": "null_resource.a", "mode": "managed", "change": {"actions": ["create"]}}]}`
+
+	changes, err := ParsePlanChanges([]byte(plan))
+	require.NoError(t, err)
+	assert.Zero(t, changes.Truncated)
+	assert.Len(t, changes.Resources, 1)
+}
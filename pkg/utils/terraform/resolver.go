@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/appvia/terranetes-controller/pkg/utils"
+)
+
+// ResolveVersion resolves the terraform version a configuration is run with. The requested version
+// can be a version, a version constraint, i.e. "~> 1.6", or a free-form image tag. A constraint is
+// resolved to the newest of the allowed versions satisfying both the requested constraint and the
+// policy constraints. An empty version is returned when the default version should be used.
+func ResolveVersion(requested string, allowed, constraints []string) (string, error) {
+	var policies []*semver.Constraints
+
+	for _, x := range constraints {
+		constraint, err := semver.NewConstraint(x)
+		if err != nil {
+			return "", fmt.Errorf("policy version constraint %q is invalid, %w", x, err)
+		}
+		policies = append(policies, constraint)
+	}
+
+	switch {
+	case requested == "" && (len(policies) == 0 || len(allowed) == 0):
+		return "", nil
+	case requested == "":
+		return resolveNewest(allowed, policies, fmt.Sprintf("the policy constraints (%s)", strings.Join(constraints, ", ")))
+	}
+
+	// @step: a version is used as is, providing the policies permit it
+	if version, err := semver.NewVersion(requested); err == nil {
+		if len(allowed) > 0 && !utils.Contains(requested, allowed) {
+			return "", fmt.Errorf("terraform version %q is not one of the allowed versions", requested)
+		}
+		for i, x := range policies {
+			if !x.Check(version) {
+				return "", fmt.Errorf("terraform version %q is not permitted by the policy constraint %q", requested, constraints[i])
+			}
+		}
+
+		return requested, nil
+	}
+
+	// @step: anything which is not a constraint is treated as an image tag
+	constraint, err := semver.NewConstraint(requested)
+	if err != nil {
+		if len(policies) > 0 {
+			return "", fmt.Errorf("terraform version %q is not a version and cannot be checked against the policy constraints", requested)
+		}
+
+		return requested, nil
+	}
+	if len(allowed) == 0 {
+		return "", fmt.Errorf("terraform version constraint %q cannot be resolved as no versions have been allowed", requested)
+	}
+
+	return resolveNewest(allowed, append([]*semver.Constraints{constraint}, policies...), fmt.Sprintf("the constraint %q", requested))
+}
+
+// IsVersionConstraint returns true if the value is a version constraint, i.e. "~> 1.6", rather than
+// a version or an image tag
+func IsVersionConstraint(value string) bool {
+	if _, err := semver.NewVersion(value); err == nil {
+		return false
+	}
+	_, err := semver.NewConstraint(value)
+
+	return err == nil
+}
+
+// resolveNewest returns the newest of the allowed versions which satisfies all the constraints
+func resolveNewest(allowed []string, constraints []*semver.Constraints, description string) (string, error) {
+	sorted, err := utils.SortSemverVersions(allowed)
+	if err != nil {
+		return "", fmt.Errorf("allowed terraform versions are invalid, %w", err)
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		version, err := semver.NewVersion(sorted[i])
+		if err != nil {
+			return "", err
+		}
+
+		matched := true
+		for _, x := range constraints {
+			if !x.Check(version) {
+				matched = false
+
+				break
+			}
+		}
+		if matched {
+			return sorted[i], nil
+		}
+	}
+
+	return "", fmt.Errorf("none of the allowed terraform versions satisfy %s", description)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsVersionConstraint(t *testing.T) {
+	assert.True(t, IsVersionConstraint("~> 1.6"))
+	assert.True(t, IsVersionConstraint(">= 1.5.0, < 2.0.0"))
+	assert.False(t, IsVersionConstraint("1.6.0"))
+	assert.False(t, IsVersionConstraint("latest"))
+	assert.False(t, IsVersionConstraint(""))
+}
+
+func TestResolveVersion(t *testing.T) {
+	allowed := []string{"1.5.7", "1.6.0", "1.6.6", "1.7.2"}
+
+	cases := []struct {
+		Requested   string
+		Allowed     []string
+		Constraints []string
+		Expected    string
+		Error       string
+	}{
+		{},
+		{Requested: "test", Expected: "test"},
+		{Requested: "1.6.0", Allowed: allowed, Expected: "1.6.0"},
+		{Requested: "1.6.0", Allowed: allowed, Constraints: []string{">= 1.5.0, < 2.0.0"}, Expected: "1.6.0"},
+		{Requested: "~> 1.6", Allowed: allowed, Expected: "1.6.6"},
+		{Requested: ">= 1.5", Allowed: allowed, Constraints: []string{"< 1.7.0"}, Expected: "1.6.6"},
+		{Allowed: allowed, Constraints: []string{"< 1.6.0"}, Expected: "1.5.7"},
+		{Constraints: []string{"< 1.6.0"}},
+		{
+			Requested: "1.4.0",
+			Allowed:   allowed,
+			Error:     `terraform version "1.4.0" is not one of the allowed versions`,
+		},
+		{
+			Requested:   "1.7.2",
+			Allowed:     allowed,
+			Constraints: []string{">= 1.5.0, < 1.7.0"},
+			Error:       `terraform version "1.7.2" is not permitted by the policy constraint ">= 1.5.0, < 1.7.0"`,
+		},
+		{
+			Requested:   "latest",
+			Constraints: []string{">= 1.5.0"},
+			Error:       `terraform version "latest" is not a version and cannot be checked against the policy constraints`,
+		},
+		{
+			Requested: "~> 1.6",
+			Error:     `terraform version constraint "~> 1.6" cannot be resolved as no versions have been allowed`,
+		},
+		{
+			Requested:   "~> 1.6",
+			Allowed:     allowed,
+			Constraints: []string{">= 1.7.0"},
+			Error:       `none of the allowed terraform versions satisfy the constraint "~> 1.6"`,
+		},
+		{
+			Allowed:     allowed,
+			Constraints: []string{">= 2.0.0"},
+			Error:       `none of the allowed terraform versions satisfy the policy constraints (>= 2.0.0)`,
+		},
+		{
+			Requested:   "1.6.0",
+			Constraints: []string{"bad"},
+			Error:       `policy version constraint "bad" is invalid, improper constraint: bad`,
+		},
+	}
+	for _, c := range cases {
+		version, err := ResolveVersion(c.Requested, c.Allowed, c.Constraints)
+		if c.Error != "" {
+			assert.Error(t, err)
+			assert.Equal(t, c.Error, err.Error())
+
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.Expected, version, "requested: %q", c.Requested)
+	}
+}
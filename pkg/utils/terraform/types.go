@@ -46,10 +46,14 @@ func (o *OutputValue) String() string {
 
 // State is the state of the terraform
 type State struct {
+	// Lineage is the unique identifier assigned to the state when first created
+	Lineage string `json:"lineage,omitempty"`
 	// Outputs are the terraform outputs
 	Outputs map[string]OutputValue `json:"outputs"`
 	// Resources is a collection of resources in the state
 	Resources []Resource `json:"resources,omitempty"`
+	// Serial is incremented every time the state is written
+	Serial int64 `json:"serial,omitempty"`
 	// TerraformVersion is the version of terraform used
 	TerraformVersion string `json:"terraform_version,omitempty"`
 }
@@ -79,12 +79,38 @@ terraform {
 
 // providerTF is a template for a terraform provider
 var providerTF = `provider "{{ .provider }}" {
+{{- if .alias }}
+  alias = "{{ .alias }}"
+{{- end }}
 {{- if .configuration }}
   {{ toHCL .configuration | nindent 2 }}
 {{- end }}
 }
 `
 
+// requiredProvidersTF is a template for the terraform required providers
+var requiredProvidersTF = `terraform {
+  required_providers {
+{{- range . }}
+    {{ .Name }} = {
+      source = "{{ .Source }}"
+{{- if .Version }}
+      version = "{{ .Version }}"
+{{- end }}
+    }
+{{- end }}
+  }
+}
+`
+
+// importsTF is a template for the terraform import blocks
+var importsTF = `{{ range . -}}
+import {
+  to = {{ .Address }}
+  id = {{ printf "%q" .ID }}
+}
+{{ end }}`
+
 // Decode decodes the terraform state returning the json output
 func Decode(state []byte) ([]byte, error) {
 	in, err := gzip.NewReader(bytes.NewReader(state))
@@ -124,6 +150,11 @@ func NewCheckovPolicy(data map[string]interface{}) ([]byte, error) {
 
 // NewTerraformProvider generates a terraform provider configuration
 func NewTerraformProvider(provider string, configuration []byte) ([]byte, error) {
+	return NewTerraformProviderWithAlias(provider, "", configuration)
+}
+
+// NewTerraformProviderWithAlias generates a terraform provider configuration using the alias
+func NewTerraformProviderWithAlias(provider, alias string, configuration []byte) ([]byte, error) {
 	// @step: azure requires the configuration for features
 	switch terraformv1alpha1.ProviderType(provider) {
 	case terraformv1alpha1.AzureProviderType:
@@ -146,15 +177,115 @@ func NewTerraformProvider(provider string, configuration []byte) ([]byte, error)
 	}
 
 	return Template(providerTF, map[string]interface{}{
+		"alias":         alias,
 		"configuration": config,
 		"provider":      provider,
 	})
 }
 
+// NewProviderConfiguration returns the terraform configuration for the provider, merging in the
+// settings required to exchange the projected token when the provider uses federated credentials
+func NewProviderConfiguration(provider *terraformv1alpha1.Provider) ([]byte, error) {
+	if !provider.IsFederated() {
+		return provider.GetConfiguration(), nil
+	}
+
+	config := make(map[string]interface{})
+	if provider.HasConfiguration() {
+		if err := json.NewDecoder(bytes.NewReader(provider.GetConfiguration())).Decode(&config); err != nil {
+			return nil, err
+		}
+	}
+	federation := provider.Spec.Federation
+
+	switch provider.Spec.Provider {
+	case terraformv1alpha1.AWSProviderType:
+		config["assume_role_with_web_identity"] = map[string]interface{}{
+			"role_arn":                federation.RoleARN,
+			"web_identity_token_file": provider.GetFederatedTokenFile(),
+		}
+
+	case terraformv1alpha1.AzureProviderType, terraformv1alpha1.AzureActiveDirectoryProviderType:
+		config["client_id"] = federation.ClientID
+		config["oidc_token_file_path"] = provider.GetFederatedTokenFile()
+		config["tenant_id"] = federation.TenantID
+		config["use_oidc"] = true
+
+	case terraformv1alpha1.GCPProviderType:
+		config["credentials"] = provider.GetFederatedCredentialsFile()
+
+	default:
+		return nil, fmt.Errorf("federated credentials are not supported for provider type %q", provider.Spec.Provider)
+	}
+
+	return json.Marshal(config)
+}
+
+// NewFederatedCredentials returns the credentials configuration used to exchange the projected token,
+// for the providers which require one, i.e. the google external account configuration. Nil is returned
+// when the provider does not require a credentials file
+func NewFederatedCredentials(provider *terraformv1alpha1.Provider) ([]byte, error) {
+	if !provider.IsFederated() || provider.Spec.Provider != terraformv1alpha1.GCPProviderType {
+		return nil, nil
+	}
+
+	credentials := map[string]interface{}{
+		"audience":           provider.GetFederatedAudience(),
+		"credential_source":  map[string]interface{}{"file": provider.GetFederatedTokenFile()},
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          "https://sts.googleapis.com/v1/token",
+		"type":               "external_account",
+	}
+	if email := provider.Spec.Federation.ServiceAccountEmail; email != "" {
+		credentials["service_account_impersonation_url"] = fmt.Sprintf(
+			"https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%s:generateAccessToken", email)
+	}
+
+	return json.Marshal(credentials)
+}
+
+// NewTerraformRequiredProviders generates the terraform required_providers block for any providers
+// which declare a registry source. Nil is returned when none of the providers declare a source
+func NewTerraformRequiredProviders(providers []*terraformv1alpha1.Provider) ([]byte, error) {
+	var values []map[string]string
+
+	seen := make(map[string]bool)
+	for _, x := range providers {
+		if x == nil || x.Spec.Registry == nil || seen[string(x.Spec.Provider)] {
+			continue
+		}
+		seen[string(x.Spec.Provider)] = true
+
+		values = append(values, map[string]string{
+			"Name":    string(x.Spec.Provider),
+			"Source":  x.Spec.Registry.Source,
+			"Version": x.Spec.Registry.Version,
+		})
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return Template(requiredProvidersTF, values)
+}
+
+// NewTerraformImports generates the terraform import blocks for the imports
+func NewTerraformImports(imports terraformv1alpha1.ImportList) ([]byte, error) {
+	if err := imports.IsValid(); err != nil {
+		return nil, err
+	}
+
+	return template.New(importsTF, imports)
+}
+
 // BackendOptions are the options used to generate the backend
 type BackendOptions struct {
+	// Backend is the typed backend configuration when provided by the provider
+	Backend *terraformv1alpha1.Backend
 	// Configuration is a reference to the terraform configuration
 	Configuration *terraformv1alpha1.Configuration
+	// Credentials are the credentials taken from the backend secret
+	Credentials map[string]string
 	// Namespace is a reference to the controller namespace
 	Namespace string
 	// Suffix is an expexted suffix for the terraform state
@@ -176,7 +307,9 @@ func NewKubernetesBackend(options BackendOptions) ([]byte, error) {
 			},
 			"suffix": options.Suffix,
 		},
+		"backend":       options.Backend,
 		"configuration": options.Configuration,
+		"credentials":   options.Credentials,
 		"name":          options.Configuration.Name,
 		"namespace":     options.Configuration.Namespace,
 	}
@@ -18,6 +18,7 @@
 package terraform
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -129,3 +130,136 @@ func TestNewTerraformProvider(t *testing.T) {
 		assert.Equal(t, string(c.Expected), string(x))
 	}
 }
+
+func TestNewTerraformProviderWithAlias(t *testing.T) {
+	x, err := NewTerraformProviderWithAlias("aws", "east", []byte(`{"region":"us-east-1"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "provider \"aws\" {\n  alias = \"east\"\n  \n  region = \"us-east-1\"\n  \n}\n", string(x))
+
+	x, err = NewTerraformProviderWithAlias("aws", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "provider \"aws\" {\n}\n", string(x))
+}
+
+func TestNewProviderConfiguration(t *testing.T) {
+	provider := &terraformv1alpha1.Provider{Spec: terraformv1alpha1.ProviderSpec{
+		Provider:      terraformv1alpha1.AWSProviderType,
+		Configuration: &runtime.RawExtension{Raw: []byte(`{"region":"eu-west-2"}`)},
+		Source:        terraformv1alpha1.SourceSecret,
+	}}
+	provider.Name = "test"
+
+	x, err := NewProviderConfiguration(provider)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"region":"eu-west-2"}`, string(x))
+
+	provider.Spec.Source = terraformv1alpha1.SourceFederated
+	provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{RoleARN: "arn:aws:iam::123456789012:role/test"}
+
+	x, err = NewProviderConfiguration(provider)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"assume_role_with_web_identity":{"role_arn":"arn:aws:iam::123456789012:role/test","web_identity_token_file":"/run/federated/test/token"},"region":"eu-west-2"}`, string(x))
+
+	provider.Spec.Provider = terraformv1alpha1.AzureProviderType
+	provider.Spec.Configuration = nil
+	provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{ClientID: "client", TenantID: "tenant"}
+
+	x, err = NewProviderConfiguration(provider)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"client_id":"client","oidc_token_file_path":"/run/federated/test/token","tenant_id":"tenant","use_oidc":true}`, string(x))
+
+	provider.Spec.Provider = terraformv1alpha1.GCPProviderType
+	provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{
+		ServiceAccountEmail:      "terraform@project.iam.gserviceaccount.com",
+		WorkloadIdentityProvider: "projects/1/locations/global/workloadIdentityPools/pool/providers/provider",
+	}
+
+	x, err = NewProviderConfiguration(provider)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"credentials":"/run/federated/test/credentials.json"}`, string(x))
+
+	provider.Spec.Provider = terraformv1alpha1.VaultProviderType
+	_, err = NewProviderConfiguration(provider)
+	assert.Error(t, err)
+}
+
+func TestNewFederatedCredentials(t *testing.T) {
+	provider := &terraformv1alpha1.Provider{Spec: terraformv1alpha1.ProviderSpec{
+		Provider: terraformv1alpha1.AWSProviderType,
+		Source:   terraformv1alpha1.SourceFederated,
+		Federation: &terraformv1alpha1.ProviderFederation{
+			RoleARN: "arn:aws:iam::123456789012:role/test",
+		},
+	}}
+	provider.Name = "test"
+
+	x, err := NewFederatedCredentials(provider)
+	assert.NoError(t, err)
+	assert.Nil(t, x)
+
+	provider.Spec.Provider = terraformv1alpha1.GCPProviderType
+	provider.Spec.Federation = &terraformv1alpha1.ProviderFederation{
+		ServiceAccountEmail:      "terraform@project.iam.gserviceaccount.com",
+		WorkloadIdentityProvider: "projects/1/locations/global/workloadIdentityPools/pool/providers/provider",
+	}
+
+	x, err = NewFederatedCredentials(provider)
+	assert.NoError(t, err)
+
+	credentials := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(x, &credentials))
+	assert.Equal(t, "external_account", credentials["type"])
+	assert.Equal(t, "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider", credentials["audience"])
+	assert.Equal(t, map[string]interface{}{"file": "/run/federated/test/token"}, credentials["credential_source"])
+	assert.Equal(t, "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/terraform@project.iam.gserviceaccount.com:generateAccessToken", credentials["service_account_impersonation_url"])
+}
+
+func TestNewTerraformRequiredProviders(t *testing.T) {
+	cloudflare := &terraformv1alpha1.Provider{Spec: terraformv1alpha1.ProviderSpec{
+		Provider: "cloudflare",
+		Registry: &terraformv1alpha1.ProviderRegistry{Source: "cloudflare/cloudflare", Version: "~> 4.0"},
+	}}
+	datadog := &terraformv1alpha1.Provider{Spec: terraformv1alpha1.ProviderSpec{
+		Provider: "datadog",
+		Registry: &terraformv1alpha1.ProviderRegistry{Source: "datadog/datadog"},
+	}}
+	aws := &terraformv1alpha1.Provider{Spec: terraformv1alpha1.ProviderSpec{
+		Provider: terraformv1alpha1.AWSProviderType,
+	}}
+
+	x, err := NewTerraformRequiredProviders([]*terraformv1alpha1.Provider{aws, nil})
+	assert.NoError(t, err)
+	assert.Nil(t, x)
+
+	expected := "terraform {\n  required_providers {\n    cloudflare = {\n      source = \"cloudflare/cloudflare\"\n      version = \"~> 4.0\"\n    }\n    datadog = {\n      source = \"datadog/datadog\"\n    }\n  }\n}\n"
+
+	x, err = NewTerraformRequiredProviders([]*terraformv1alpha1.Provider{cloudflare, aws, datadog, cloudflare})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(x))
+}
+
+func TestNewTerraformImports(t *testing.T) {
+	imports := terraformv1alpha1.ImportList{
+		{Address: "aws_s3_bucket.this", ID: "my-bucket"},
+		{Address: `module.vpc.aws_vpc.this["main"]`, ID: "vpc-0123"},
+	}
+	expected := "import {\n  to = aws_s3_bucket.this\n  id = \"my-bucket\"\n}\nimport {\n  to = module.vpc.aws_vpc.this[\"main\"]\n  id = \"vpc-0123\"\n}\n"
+
+	x, err := NewTerraformImports(imports)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(x))
+}
+
+func TestNewTerraformImportsInvalid(t *testing.T) {
+	cases := []terraformv1alpha1.ImportList{
+		{{Address: "", ID: "my-bucket"}},
+		{{Address: "aws_s3_bucket", ID: "my-bucket"}},
+		{{Address: "aws_s3_bucket.this", ID: ""}},
+		{{Address: "aws_s3_bucket.this", ID: "${var.name}"}},
+		{{Address: "aws_s3_bucket.this", ID: "a"}, {Address: "aws_s3_bucket.this", ID: "b"}},
+	}
+	for _, c := range cases {
+		_, err := NewTerraformImports(c)
+		assert.Error(t, err)
+	}
+}
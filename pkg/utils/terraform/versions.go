@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package terraform
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+// GetStateVersion returns the version of the terraform state held in the secret
+func GetStateVersion(secret *v1.Secret) int {
+	version, err := strconv.Atoi(secret.GetLabels()[terraformv1alpha1.ConfigurationStateVersionLabel])
+	if err != nil {
+		return 0
+	}
+
+	return version
+}
+
+// ListStateVersions returns the retained versions of the terraform state for the configuration, ordered
+// from oldest to newest
+func ListStateVersions(ctx context.Context, cc client.Client, namespace string, configuration *terraformv1alpha1.Configuration) ([]v1.Secret, error) {
+	list := &v1.SecretList{}
+
+	err := cc.List(ctx, list,
+		client.InNamespace(namespace),
+		client.MatchingLabels{terraformv1alpha1.ConfigurationUIDLabel: string(configuration.GetUID())},
+		client.HasLabels{terraformv1alpha1.ConfigurationStateVersionLabel},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return GetStateVersion(&list.Items[i]) < GetStateVersion(&list.Items[j])
+	})
+
+	return list.Items, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022  Appvia Ltd <info@appvia.io>
+ *
+ * This program is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation; either version 2
+ * of the License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
+)
+
+func newStateVersion(uid string, version int) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("tfstate-%s-v%d", uid, version),
+			Namespace: "terraform-system",
+			Labels: map[string]string{
+				terraformv1alpha1.ConfigurationStateVersionLabel: fmt.Sprintf("%d", version),
+				terraformv1alpha1.ConfigurationUIDLabel:          uid,
+			},
+		},
+	}
+}
+
+func TestGetStateVersion(t *testing.T) {
+	assert.Equal(t, 3, GetStateVersion(newStateVersion("1234", 3)))
+	assert.Equal(t, 0, GetStateVersion(&v1.Secret{}))
+}
+
+func TestListStateVersions(t *testing.T) {
+	state := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tfstate-default-1234",
+			Namespace: "terraform-system",
+			Labels:    map[string]string{terraformv1alpha1.ConfigurationUIDLabel: "1234"},
+		},
+	}
+	objects := []client.Object{
+		state,
+		newStateVersion("1234", 10),
+		newStateVersion("1234", 2),
+		newStateVersion("1234", 1),
+		newStateVersion("5678", 1),
+	}
+	cc := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	configuration := &terraformv1alpha1.Configuration{}
+	configuration.UID = "1234"
+
+	versions, err := ListStateVersions(context.Background(), cc, "terraform-system", configuration)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, 1, GetStateVersion(&versions[0]))
+	assert.Equal(t, 2, GetStateVersion(&versions[1]))
+	assert.Equal(t, 10, GetStateVersion(&versions[2]))
+}
@@ -18,6 +18,9 @@
 package fixtures
 
 import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	terraformv1alpha1 "github.com/appvia/terranetes-controller/pkg/apis/terraform/v1alpha1"
@@ -48,3 +51,87 @@ func NewMatchAllModuleConstraint(name string) *terraformv1alpha1.Policy {
 
 	return p
 }
+
+// NewMaintenancePolicy returns a policy with a maintenance window opening on the schedule
+func NewMaintenancePolicy(name, schedule string, duration time.Duration) *terraformv1alpha1.Policy {
+	p := NewPolicy(name)
+	p.Spec.Constraints = &terraformv1alpha1.Constraints{}
+	p.Spec.Constraints.Maintenance = &terraformv1alpha1.MaintenanceConstraint{
+		Windows: []terraformv1alpha1.MaintenanceWindow{
+			{
+				Schedule: schedule,
+				Duration: metav1.Duration{Duration: duration},
+			},
+		},
+	}
+
+	return p
+}
+
+// NewOperationsPolicy returns a policy restricting the replace and target operations to the users
+func NewOperationsPolicy(name string, users ...string) *terraformv1alpha1.Policy {
+	p := NewPolicy(name)
+	p.Spec.Constraints = &terraformv1alpha1.Constraints{}
+	p.Spec.Constraints.Operations = &terraformv1alpha1.OperationsConstraint{
+		Users: users,
+	}
+
+	return p
+}
+
+// NewVersionsPolicy returns a policy constraining the terraform versions of the configurations
+func NewVersionsPolicy(name, constraint string) *terraformv1alpha1.Policy {
+	p := NewPolicy(name)
+	p.Spec.Constraints = &terraformv1alpha1.Constraints{}
+	p.Spec.Constraints.Versions = &terraformv1alpha1.VersionConstraint{
+		Constraint: constraint,
+	}
+
+	return p
+}
+
+// NewSignaturePolicy returns a policy trusting a single ssh key to sign the modules
+func NewSignaturePolicy(name string) *terraformv1alpha1.Policy {
+	p := NewPolicy(name)
+	p.Spec.Constraints = &terraformv1alpha1.Constraints{}
+	p.Spec.Constraints.Signatures = &terraformv1alpha1.SignatureConstraint{
+		Keys: []terraformv1alpha1.SignatureKey{
+			{
+				Name:      "release",
+				PublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHRlc3Q= release@example.com",
+				Type:      terraformv1alpha1.SignatureKeySSH,
+			},
+		},
+	}
+
+	return p
+}
+
+// NewRegoPolicy returns a policy with a single inline rego module
+func NewRegoPolicy(name, module string) *terraformv1alpha1.Policy {
+	p := NewPolicy(name)
+	p.Spec.Constraints = &terraformv1alpha1.Constraints{}
+	p.Spec.Constraints.Rego = &terraformv1alpha1.RegoConstraint{
+		Modules: []terraformv1alpha1.RegoModule{
+			{
+				Name:    "main",
+				Content: module,
+			},
+		},
+	}
+
+	return p
+}
+
+// NewCostPolicy returns a policy with a monthly cost budget
+func NewCostPolicy(name, maxMonthly string) *terraformv1alpha1.Policy {
+	budget := resource.MustParse(maxMonthly)
+
+	p := NewPolicy(name)
+	p.Spec.Constraints = &terraformv1alpha1.Constraints{}
+	p.Spec.Constraints.Costs = &terraformv1alpha1.CostConstraint{
+		MaxMonthly: &budget,
+	}
+
+	return p
+}
@@ -20,6 +20,7 @@ package fixtures
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 
@@ -29,6 +30,8 @@ import (
 var state = `
 {
 	"terraform_version": "1.1.9",
+	"serial": 4,
+	"lineage": "8b1e6a3c-5f2d-4b7a-9c1e-2d3f4a5b6c7d",
 	"resources": [
 		{
 			"mode": "managed",
@@ -117,3 +120,44 @@ func NewTerraformState(configuration *terraformv1alpha1.Configuration) *v1.Secre
 
 	return secret
 }
+
+// NewTerraformStateVersion returns a fake version of the terraform state
+func NewTerraformStateVersion(configuration *terraformv1alpha1.Configuration, version int) *v1.Secret {
+	secret := NewTerraformState(configuration)
+	secret.Name = configuration.GetTerraformStateVersionSecretName(version)
+	secret.Labels = map[string]string{
+		terraformv1alpha1.ConfigurationGenerationLabel:   fmt.Sprintf("%d", configuration.GetGeneration()),
+		terraformv1alpha1.ConfigurationNameLabel:         configuration.Name,
+		terraformv1alpha1.ConfigurationNamespaceLabel:    configuration.Namespace,
+		terraformv1alpha1.ConfigurationStateSerialLabel:  "4",
+		terraformv1alpha1.ConfigurationStateVersionLabel: fmt.Sprintf("%d", version),
+		terraformv1alpha1.ConfigurationTimestampLabel:    "1700000000",
+		terraformv1alpha1.ConfigurationUIDLabel:          string(configuration.GetUID()),
+	}
+
+	return secret
+}
+
+const (
+	// FakeSourceDigest is the digest of the module source recorded in the fake terraform plan
+	FakeSourceDigest = "sha256:5d41402abc4b2a76b9719d911017c5925d41402abc4b2a76b9719d911017c592"
+	// FakeSourceRevision is the revision of the module source recorded in the fake terraform plan
+	FakeSourceRevision = "4a46f18c5e0b2d7f6f3c1a9e8b7d6c5a4f3e2d1c"
+)
+
+// NewTerraformPlan returns a fake terraform plan
+func NewTerraformPlan(configuration *terraformv1alpha1.Configuration) *v1.Secret {
+	secret := &v1.Secret{}
+	secret.Name = configuration.GetTerraformPlanSecretName()
+	secret.Data = map[string][]byte{
+		terraformv1alpha1.TerraformPlanSecretKey: []byte("fake-plan"),
+		terraformv1alpha1.TerraformPlanChangesSecretKey: []byte(`{"create":1,"delete":0,"replace":1,"update":0,"resources":[
+			{"action":"create","address":"aws_s3_bucket.bucket"},
+			{"action":"replace","address":"aws_s3_bucket_policy.policy"}
+		]}`),
+		terraformv1alpha1.TerraformSourceSecretKey: []byte(fmt.Sprintf(`{"digest":"%s","module":"%s","revision":"%s"}`,
+			FakeSourceDigest, configuration.Spec.Module, FakeSourceRevision)),
+	}
+
+	return secret
+}